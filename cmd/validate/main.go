@@ -1,6 +1,8 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"flag"
 	"log"
 	"os"
@@ -10,6 +12,9 @@ import (
 
 	"github.com/getkin/kin-openapi/openapi2"
 	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/openapi3har"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
 )
 
 var (
@@ -37,11 +42,21 @@ var (
 	multi        = flag.Bool("multi", defaultMulti, "when true, aggregate independent validation errors instead of returning the first one")
 )
 
+var (
+	defaultHAR = ""
+	har        = flag.String("har", defaultHAR, "validates the requests and responses recorded in this HAR file against the document")
+)
+
+var (
+	defaultHARJSON = false
+	harJSON        = flag.Bool("har-json", defaultHARJSON, "when true, writes the report of --har as JSON")
+)
+
 func main() {
 	flag.Parse()
 	filename := flag.Arg(0)
 	if len(flag.Args()) != 1 || filename == "" {
-		log.Fatalf("Usage: go run github.com/getkin/kin-openapi/cmd/validate@latest [--defaults] [--examples] [--ext] [--patterns] [--multi] -- <local YAML or JSON file>\nGot: %+v\n", os.Args)
+		log.Fatalf("Usage: go run github.com/getkin/kin-openapi/cmd/validate@latest [--defaults] [--examples] [--ext] [--patterns] [--multi] [--har <HAR file> [--har-json]] -- <local YAML or JSON file>\nGot: %+v\n", os.Args)
 	}
 
 	data, err := os.ReadFile(filename)
@@ -90,6 +105,10 @@ func main() {
 			log.Fatalln("Validation error:", err)
 		}
 
+		if *har != defaultHAR {
+			validateHAR(loader.Context, doc)
+		}
+
 	case vd.OpenAPI == "2" || strings.HasPrefix(vd.OpenAPI, "2."),
 		vd.Swagger == "2" || strings.HasPrefix(vd.Swagger, "2."):
 		if *defaults != defaultDefaults {
@@ -107,6 +126,9 @@ func main() {
 		if *multi != defaultMulti {
 			log.Fatal("Flag --multi is only for OpenAPIv3")
 		}
+		if *har != defaultHAR {
+			log.Fatal("Flag --har is only for OpenAPIv3")
+		}
 
 		var doc openapi2.T
 		if _, err := yaml.Unmarshal(data, &doc, yaml.DecodeOpts{DisableTimestamps: true}); err != nil {
@@ -117,3 +139,32 @@ func main() {
 		log.Fatal("Missing or incorrect 'openapi' or 'swagger' field")
 	}
 }
+
+// validateHAR validates the entries of the --har file against doc, writes the report
+// and exits with status 1 if they are not all valid.
+func validateHAR(ctx context.Context, doc *openapi3.T) {
+	archive, err := openapi3har.LoadFile(*har)
+	if err != nil {
+		log.Fatalln("Loading error:", err)
+	}
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		log.Fatalln("Routing error:", err)
+	}
+	options := openapi3filter.Options{MultiError: *multi}
+	report := openapi3har.NewValidator(router, openapi3har.ValidationOptions(options)).Validate(ctx, archive)
+
+	if *harJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(report)
+	} else {
+		err = report.WriteText(os.Stdout)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	if !report.Valid() {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,108 @@
+// Package refs holds the helpers the loaders of openapi2 and openapi3 share to resolve $ref values.
+package refs
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// UnescapeRefString unescapes a token of a JSON pointer.
+func UnescapeRefString(ref string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(ref, "~1", "/"), "~0", "~")
+}
+
+// IsFile reports whether location is a path of the local file system.
+func IsFile(location *url.URL) bool {
+	return location.Path != "" &&
+		location.Host == "" &&
+		(location.Scheme == "" || location.Scheme == "file")
+}
+
+// DefaultJoin resolves relativePath against the directory of basePath.
+func DefaultJoin(basePath *url.URL, relativePath *url.URL) *url.URL {
+	if basePath == nil {
+		return relativePath
+	}
+	newPath := *basePath
+	newPath.Path = path.Join(path.Dir(newPath.Path), relativePath.Path)
+	return &newPath
+}
+
+// ResolvePath resolves componentPath against basePath with join, or DefaultJoin if join is nil,
+// when it is a relative file path.
+func ResolvePath(basePath *url.URL, componentPath *url.URL, join func(*url.URL, *url.URL) *url.URL) *url.URL {
+	if IsFile(componentPath) {
+		// support absolute paths
+		if filepath.IsAbs(componentPath.Path) {
+			return componentPath
+		}
+		if join != nil {
+			return join(basePath, componentPath)
+		}
+		return DefaultJoin(basePath, componentPath)
+	}
+	return componentPath
+}
+
+// DrillIntoField returns the field, element or extension fieldName of cursor,
+// a struct (whose fields are named by their yaml tags), a map or a slice.
+// Ref wrappers are drilled through their Value field.
+func DrillIntoField(cursor any, fieldName string) (any, error) {
+	switch val := reflect.Indirect(reflect.ValueOf(cursor)); val.Kind() {
+
+	case reflect.Map:
+		elementValue := val.MapIndex(reflect.ValueOf(fieldName))
+		if !elementValue.IsValid() {
+			return nil, fmt.Errorf("map key %q not found", fieldName)
+		}
+		return elementValue.Interface(), nil
+
+	case reflect.Slice:
+		i, err := strconv.ParseUint(fieldName, 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		index := int(i)
+		if 0 > index || index >= val.Len() {
+			return nil, errors.New("slice index out of bounds")
+		}
+		return val.Index(index).Interface(), nil
+
+	case reflect.Struct:
+		hasFields := false
+		for i := range val.NumField() {
+			hasFields = true
+			if yamlTag := val.Type().Field(i).Tag.Get("yaml"); yamlTag != "-" {
+				if tagName, _, _ := strings.Cut(yamlTag, ","); tagName != "" {
+					if fieldName == tagName {
+						return val.Field(i).Interface(), nil
+					}
+				}
+			}
+		}
+
+		// if cursor is a "ref wrapper" struct (e.g. RequestBodyRef),
+		if _, ok := val.Type().FieldByName("Value"); ok {
+			// try digging into its Value field
+			return DrillIntoField(val.FieldByName("Value").Interface(), fieldName)
+		}
+		if hasFields {
+			if ff := val.Type().Field(0); ff.PkgPath == "" && ff.Name == "Extensions" {
+				extensions := val.Field(0).Interface().(map[string]any)
+				if enc, ok := extensions[fieldName]; ok {
+					return enc, nil
+				}
+			}
+		}
+		return nil, fmt.Errorf("struct field %q not found", fieldName)
+
+	default:
+		return nil, errors.New("not a map, slice nor struct")
+	}
+}
@@ -0,0 +1,532 @@
+package openapi2
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/oasdiff/yaml"
+
+	"github.com/getkin/kin-openapi/internal/origins"
+	"github.com/getkin/kin-openapi/internal/refs"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// ReadFromURIFunc defines a function which reads the contents of a resource
+// located at a URI.
+type ReadFromURIFunc func(loader *Loader, location *url.URL) ([]byte, error)
+
+// DefaultReadFromURI reads remote HTTP URIs and local file URIs.
+// It shares its cache with openapi3.DefaultReadFromURI.
+func DefaultReadFromURI(loader *Loader, location *url.URL) ([]byte, error) {
+	return openapi3.DefaultReadFromURI(nil, location)
+}
+
+// Loader helps deserialize an OpenAPIv2 document.
+//
+// It resolves the $refs of schemas, parameters and responses, whether they
+// point within the document or to other files.
+// Resolved parameters and responses keep their Ref (so they marshal back to
+// the same document) and are filled with the fields of their target.
+// Note that additionalProperties holds an openapi3.SchemaRef which is not resolved.
+type Loader struct {
+	// IsExternalRefsAllowed enables visiting other files. Enforced only when
+	// ReadFromURIFunc is nil; a custom ReadFromURIFunc bypasses this flag and
+	// owns the access policy itself — see ReadFromURIFunc.
+	IsExternalRefsAllowed bool
+
+	// IncludeOrigin enables recording the file/line/column of each element.
+	IncludeOrigin bool
+
+	// ReadFromURIFunc overrides how the loader reads a referenced file or URL.
+	//
+	// SECURITY: when a custom ReadFromURIFunc is set, IsExternalRefsAllowed is
+	// NOT enforced — this function alone decides which locations may be read.
+	// A custom func must apply its own scheme/host allowlist, or re-check
+	// IsExternalRefsAllowed, before reading.
+	ReadFromURIFunc ReadFromURIFunc
+
+	// JoinFunc allows overriding how relative $ref paths are resolved against
+	// a base path. See openapi3.Loader.JoinFunc.
+	JoinFunc func(basePath *url.URL, relativePath *url.URL) *url.URL
+
+	visitedDocuments map[string]*T
+	originTrees      map[*T]*yaml.OriginTree
+
+	// decoded caches components that had to be decoded anew (single-file
+	// elements and values under arbitrary keys), keyed by their absolute $ref,
+	// so that recursive references share a single value.
+	decoded        map[string]any
+	visitedSchemas map[*Schema]struct{}
+	resolving      map[string]struct{}
+}
+
+// NewLoader returns an empty Loader
+func NewLoader() *Loader {
+	return &Loader{}
+}
+
+func (loader *Loader) reset() {
+	loader.visitedDocuments = make(map[string]*T)
+	loader.originTrees = make(map[*T]*yaml.OriginTree)
+	loader.decoded = make(map[string]any)
+	loader.visitedSchemas = make(map[*Schema]struct{})
+	loader.resolving = make(map[string]struct{})
+}
+
+// LoadFromURI loads a spec from a remote URL
+func (loader *Loader) LoadFromURI(location *url.URL) (*T, error) {
+	loader.reset()
+	return loader.loadFromURIInternal(location)
+}
+
+// LoadFromFile loads a spec from a local file path
+func (loader *Loader) LoadFromFile(location string) (*T, error) {
+	return loader.LoadFromURI(&url.URL{Path: filepath.ToSlash(location)})
+}
+
+// LoadFromIoReader loads a spec from io.Reader
+func (loader *Loader) LoadFromIoReader(reader io.Reader) (*T, error) {
+	if reader == nil {
+		return nil, fmt.Errorf("invalid reader: %v", reader)
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	return loader.LoadFromData(data)
+}
+
+// LoadFromStdin loads a spec from stdin
+func (loader *Loader) LoadFromStdin() (*T, error) {
+	return loader.LoadFromIoReader(os.Stdin)
+}
+
+// LoadFromData loads a spec from a byte array
+func (loader *Loader) LoadFromData(data []byte) (*T, error) {
+	loader.reset()
+	doc := &T{}
+	tree, err := unmarshal(data, doc, loader.IncludeOrigin, nil)
+	if err != nil {
+		return nil, err
+	}
+	loader.rememberOriginTree(doc, tree)
+	if err := loader.ResolveRefsIn(doc, nil); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// LoadFromDataWithPath takes the OpenAPIv2 document data in bytes and a path where the resolver can find referred
+// elements and returns a *T with all resolved data or an error if unable to load data or resolve refs.
+func (loader *Loader) LoadFromDataWithPath(data []byte, location *url.URL) (*T, error) {
+	loader.reset()
+	return loader.loadFromDataWithPathInternal(data, location)
+}
+
+func (loader *Loader) loadFromURIInternal(location *url.URL) (*T, error) {
+	if doc, ok := loader.visitedDocuments[location.String()]; ok {
+		return doc, nil
+	}
+	data, err := loader.readURL(location)
+	if err != nil {
+		return nil, err
+	}
+	return loader.loadFromDataWithPathInternal(data, location)
+}
+
+func (loader *Loader) loadFromDataWithPathInternal(data []byte, location *url.URL) (*T, error) {
+	uri := location.String()
+	if doc, ok := loader.visitedDocuments[uri]; ok {
+		return doc, nil
+	}
+
+	doc := &T{}
+	loader.visitedDocuments[uri] = doc
+
+	tree, err := unmarshal(data, doc, loader.IncludeOrigin, location)
+	if err != nil {
+		return nil, err
+	}
+	loader.rememberOriginTree(doc, tree)
+
+	if err := loader.ResolveRefsIn(doc, location); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// rememberOriginTree retains doc's origin tree for attachOrigin.
+// tree is nil when IncludeOrigin is off or the data took the json path.
+func (loader *Loader) rememberOriginTree(doc *T, tree *yaml.OriginTree) {
+	if tree != nil {
+		loader.originTrees[doc] = tree
+	}
+}
+
+func (loader *Loader) readURL(location *url.URL) ([]byte, error) {
+	if f := loader.ReadFromURIFunc; f != nil {
+		return f(loader, location)
+	}
+	return DefaultReadFromURI(loader, location)
+}
+
+// ResolveRefsIn expands references if for instance spec was just unmarshaled
+func (loader *Loader) ResolveRefsIn(doc *T, location *url.URL) (err error) {
+	if loader.visitedDocuments == nil {
+		loader.reset()
+	}
+
+	for _, name := range componentNames(doc.Definitions) {
+		if err = loader.resolveSchemaRef(doc, doc.Definitions[name], location); err != nil {
+			return
+		}
+	}
+	for _, name := range componentNames(doc.Parameters) {
+		if err = loader.resolveParameter(doc, doc.Parameters[name], location); err != nil {
+			return
+		}
+	}
+	for _, name := range componentNames(doc.Responses) {
+		if err = loader.resolveResponse(doc, doc.Responses[name], location); err != nil {
+			return
+		}
+	}
+
+	for _, name := range componentNames(doc.Paths) {
+		pathItem := doc.Paths[name]
+		if pathItem == nil {
+			continue
+		}
+		for _, parameter := range pathItem.Parameters {
+			if err = loader.resolveParameter(doc, parameter, location); err != nil {
+				return
+			}
+		}
+		operations := pathItem.Operations()
+		for _, method := range componentNames(operations) {
+			operation := operations[method]
+			for _, parameter := range operation.Parameters {
+				if err = loader.resolveParameter(doc, parameter, location); err != nil {
+					return
+				}
+			}
+			for _, code := range componentNames(operation.Responses) {
+				if err = loader.resolveResponse(doc, operation.Responses[code], location); err != nil {
+					return
+				}
+			}
+		}
+	}
+	return
+}
+
+func (loader *Loader) resolveSchemaRef(doc *T, component *SchemaRef, documentPath *url.URL) error {
+	if component == nil {
+		return nil
+	}
+	ref := component.Ref
+	if ref == "" {
+		return loader.resolveSchema(doc, component.Value, documentPath)
+	}
+	if component.Value != nil {
+		return nil
+	}
+
+	var target *SchemaRef
+	cursor, componentDoc, componentPath, refPath, err := loader.resolveComponent(doc, ref, documentPath, func() any { return &SchemaRef{} })
+	if err != nil {
+		return err
+	}
+	switch c := cursor.(type) {
+	case *SchemaRef:
+		target = c
+	case *Schema:
+		target = &SchemaRef{Value: c}
+	default:
+		return fmt.Errorf("bad data in %q (expecting %s)", ref, "ref to schema object")
+	}
+
+	if err := loader.enter(refPath); err != nil {
+		return err
+	}
+	defer loader.leave(refPath)
+	if err := loader.resolveSchemaRef(componentDoc, target, componentPath); err != nil {
+		return err
+	}
+	component.Value = target.Value
+	if target.Ref != "" {
+		component.refPath = target.RefPath()
+	} else {
+		component.refPath = refPath
+	}
+	return nil
+}
+
+func (loader *Loader) resolveSchema(doc *T, schema *Schema, documentPath *url.URL) error {
+	if schema == nil {
+		return nil
+	}
+	if _, ok := loader.visitedSchemas[schema]; ok {
+		return nil
+	}
+	loader.visitedSchemas[schema] = struct{}{}
+
+	for _, item := range schema.AllOf {
+		if err := loader.resolveSchemaRef(doc, item, documentPath); err != nil {
+			return err
+		}
+	}
+	if err := loader.resolveSchemaRef(doc, schema.Not, documentPath); err != nil {
+		return err
+	}
+	if err := loader.resolveSchemaRef(doc, schema.Items, documentPath); err != nil {
+		return err
+	}
+	for _, name := range componentNames(schema.Properties) {
+		if err := loader.resolveSchemaRef(doc, schema.Properties[name], documentPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (loader *Loader) resolveParameter(doc *T, parameter *Parameter, documentPath *url.URL) error {
+	if parameter == nil {
+		return nil
+	}
+	if ref := parameter.Ref; ref != "" {
+		if parameter.refPath != nil {
+			return nil
+		}
+		cursor, componentDoc, componentPath, refPath, err := loader.resolveComponent(doc, ref, documentPath, func() any { return &Parameter{} })
+		if err != nil {
+			return err
+		}
+		target, ok := cursor.(*Parameter)
+		if !ok {
+			return fmt.Errorf("bad data in %q (expecting %s)", ref, "parameter object")
+		}
+
+		if err := loader.enter(refPath); err != nil {
+			return err
+		}
+		defer loader.leave(refPath)
+		if err := loader.resolveParameter(componentDoc, target, componentPath); err != nil {
+			return err
+		}
+		*parameter = *target
+		parameter.Ref = ref
+		if target.Ref == "" {
+			parameter.refPath = refPath
+		}
+		return nil
+	}
+
+	if err := loader.resolveSchemaRef(doc, parameter.Schema, documentPath); err != nil {
+		return err
+	}
+	return loader.resolveSchemaRef(doc, parameter.Items, documentPath)
+}
+
+func (loader *Loader) resolveResponse(doc *T, response *Response, documentPath *url.URL) error {
+	if response == nil {
+		return nil
+	}
+	if ref := response.Ref; ref != "" {
+		if response.refPath != nil {
+			return nil
+		}
+		cursor, componentDoc, componentPath, refPath, err := loader.resolveComponent(doc, ref, documentPath, func() any { return &Response{} })
+		if err != nil {
+			return err
+		}
+		target, ok := cursor.(*Response)
+		if !ok {
+			return fmt.Errorf("bad data in %q (expecting %s)", ref, "response object")
+		}
+
+		if err := loader.enter(refPath); err != nil {
+			return err
+		}
+		defer loader.leave(refPath)
+		if err := loader.resolveResponse(componentDoc, target, componentPath); err != nil {
+			return err
+		}
+		*response = *target
+		response.Ref = ref
+		if target.Ref == "" {
+			response.refPath = refPath
+		}
+		return nil
+	}
+
+	if err := loader.resolveSchemaRef(doc, response.Schema, documentPath); err != nil {
+		return err
+	}
+	for _, name := range componentNames(response.Headers) {
+		if header := response.Headers[name]; header != nil {
+			if err := loader.resolveParameter(doc, &header.Parameter, documentPath); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// enter guards against $ref chains that loop back onto themselves.
+func (loader *Loader) enter(refPath *url.URL) error {
+	key := refPath.String()
+	if _, ok := loader.resolving[key]; ok {
+		return fmt.Errorf("circular reference %q", key)
+	}
+	loader.resolving[key] = struct{}{}
+	return nil
+}
+
+func (loader *Loader) leave(refPath *url.URL) {
+	delete(loader.resolving, refPath.String())
+}
+
+// resolveComponent finds the element ref points to.
+// Elements that are not already typed (single-file elements, values under
+// arbitrary keys) are decoded into the value returned by newElement.
+// It returns the document and path the element's own refs are relative to.
+func (loader *Loader) resolveComponent(doc *T, ref string, documentPath *url.URL, newElement func() any) (
+	cursor any,
+	componentDoc *T,
+	componentPath *url.URL,
+	refPath *url.URL,
+	err error,
+) {
+	if refPath, err = loader.resolveRefPath(ref, documentPath); err != nil {
+		return
+	}
+	key := refPath.String()
+
+	componentPath = copyURI(refPath)
+	fragment := componentPath.Fragment
+	componentPath.Fragment = ""
+	if componentPath.String() == "" {
+		// Resolving internal refs of a doc loaded from memory
+		componentPath = nil
+	}
+
+	if isSingleRefElement(ref) {
+		if cursor = loader.decoded[key]; cursor != nil {
+			return
+		}
+		var data []byte
+		if data, err = loader.readURL(componentPath); err != nil {
+			return
+		}
+		element := newElement()
+		if _, err = unmarshal(data, element, loader.IncludeOrigin, componentPath); err != nil {
+			return
+		}
+		loader.decoded[key] = element
+		cursor = element
+		return
+	}
+
+	componentDoc = doc
+	if ref[0] != '#' || componentDoc == nil {
+		if componentDoc, err = loader.loadFromURIInternal(componentPath); err != nil {
+			err = fmt.Errorf("error resolving reference %q: %w", ref, err)
+			return
+		}
+	}
+
+	if fragment == "" || fragment[0] != '/' {
+		err = fmt.Errorf("expected fragment prefix '#/' in URI %q", ref)
+		return
+	}
+	cursor = componentDoc
+	for part := range strings.SplitSeq(fragment[1:], "/") {
+		part = refs.UnescapeRefString(part)
+		if cursor, err = refs.DrillIntoField(cursor, part); err != nil {
+			err = fmt.Errorf("failed to resolve %q in fragment in URI: %q: %w", part, ref, err)
+			return
+		}
+		if cursor == nil || (reflect.ValueOf(cursor).Kind() == reflect.Pointer && reflect.ValueOf(cursor).IsNil()) {
+			err = fmt.Errorf("failed to resolve %q in fragment in URI: %q", part, ref)
+			return
+		}
+	}
+
+	switch cursor.(type) {
+	case map[string]any:
+		// The value sits under an arbitrary key (e.g. in T.Extensions)
+		if cached := loader.decoded[key]; cached != nil {
+			cursor = cached
+			return
+		}
+		element := newElement()
+		var data []byte
+		if data, err = json.Marshal(cursor); err != nil {
+			return
+		}
+		if err = json.Unmarshal(data, element); err != nil {
+			err = fmt.Errorf("bad data in %q: %w", ref, err)
+			return
+		}
+		loader.attachOrigin(element, componentDoc, fragment)
+		loader.decoded[key] = element
+		cursor = element
+	}
+	return
+}
+
+// attachOrigin re-attaches source origins to an element decoded from a
+// generic map, walking the document's retained origin tree down to fragment.
+func (loader *Loader) attachOrigin(element any, componentDoc *T, fragment string) {
+	originWalker.Apply(element, origins.Subtree(loader.originTrees[componentDoc], fragment))
+}
+
+func isSingleRefElement(ref string) bool {
+	return !strings.Contains(ref, "#")
+}
+
+func (loader *Loader) resolveRefPath(ref string, documentPath *url.URL) (*url.URL, error) {
+	if ref != "" && ref[0] == '#' {
+		refPath := copyURI(documentPath)
+		// Resolving internal refs of a doc loaded from memory
+		// has no path, so just set the Fragment.
+		if refPath == nil {
+			refPath = new(url.URL)
+		}
+		refPath.Fragment = ref[1:]
+		return refPath, nil
+	}
+
+	// IsExternalRefsAllowed is enforced here only when no custom ReadFromURIFunc
+	// is installed; otherwise the custom func owns the access policy (see the
+	// SECURITY note on the ReadFromURIFunc field).
+	if loader.ReadFromURIFunc == nil && !loader.IsExternalRefsAllowed {
+		return nil, fmt.Errorf("encountered disallowed external reference: %q", ref)
+	}
+
+	parsedURL, err := url.Parse(ref)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse reference: %q: %w", ref, err)
+	}
+	resolvedPath := refs.ResolvePath(documentPath, parsedURL, loader.JoinFunc)
+	resolvedPath.Fragment = parsedURL.Fragment
+	return resolvedPath, nil
+}
+
+func componentNames[E any](s map[string]E) []string {
+	out := make([]string, 0, len(s))
+	for i := range s {
+		out = append(out, i)
+	}
+	slices.Sort(out)
+	return out
+}
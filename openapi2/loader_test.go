@@ -0,0 +1,122 @@
+package openapi2_test
+
+import (
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/getkin/kin-openapi/openapi2"
+)
+
+func TestLoaderResolvesRefs(t *testing.T) {
+	loader := openapi2.NewLoader()
+	loader.IsExternalRefsAllowed = true
+	doc, err := loader.LoadFromFile("testdata/loader/root.yaml")
+	require.NoError(t, err)
+
+	pathItem := doc.Paths["/pets/{id}"]
+	require.NotNil(t, pathItem)
+
+	petID := pathItem.Parameters[0]
+	require.Equal(t, "#/parameters/PetID", petID.Ref)
+	require.Equal(t, "id", petID.Name)
+	require.Equal(t, "path", petID.In)
+	require.Equal(t, "/parameters/PetID", petID.RefPath().Fragment)
+
+	limit := pathItem.Get.Parameters[0]
+	require.Equal(t, "limit", limit.Name)
+	require.Equal(t, "query", limit.In)
+	require.Equal(t, "testdata/loader/common.yaml", limit.RefPath().Path)
+
+	errResponse := pathItem.Get.Responses["default"]
+	require.Equal(t, "An error", errResponse.Description)
+	require.NotNil(t, errResponse.Schema.Value)
+	require.Contains(t, errResponse.Schema.Value.Properties, "message")
+	require.Equal(t, &url.URL{Path: "testdata/loader/common.yaml", Fragment: "/definitions/Error"}, errResponse.Schema.RefPath())
+
+	pet := pathItem.Get.Responses["200"].Schema.Value
+	require.Same(t, doc.Definitions["Pet"].Value, pet)
+	require.Same(t, pet, pet.Properties["children"].Value.Items.Value)
+	require.Contains(t, pet.Properties["owner"].Value.Properties, "name")
+	require.Equal(t, []any{"cat", "dog"}, pet.Properties["tag"].Value.Enum)
+	require.Equal(t, "testdata/loader/tag.yaml", pet.Properties["tag"].RefPath().Path)
+
+	// Refs marshal back as they were written
+	data, err := petID.MarshalJSON()
+	require.NoError(t, err)
+	require.JSONEq(t, `{"$ref":"#/parameters/PetID"}`, string(data))
+}
+
+func TestLoaderDisallowsExternalRefs(t *testing.T) {
+	loader := openapi2.NewLoader()
+	_, err := loader.LoadFromFile("testdata/loader/root.yaml")
+	require.ErrorContains(t, err, `encountered disallowed external reference: "common.yaml#/definitions/Owner"`)
+}
+
+func TestLoaderReadFromURIFunc(t *testing.T) {
+	var visited []string
+	loader := openapi2.NewLoader()
+	loader.ReadFromURIFunc = func(loader *openapi2.Loader, location *url.URL) ([]byte, error) {
+		visited = append(visited, location.Path)
+		return openapi2.DefaultReadFromURI(loader, location)
+	}
+	_, err := loader.LoadFromFile("testdata/loader/root.yaml")
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"testdata/loader/root.yaml",
+		"testdata/loader/common.yaml",
+		"testdata/loader/tag.yaml",
+	}, visited)
+}
+
+func TestLoaderCircularRefs(t *testing.T) {
+	loader := openapi2.NewLoader()
+	_, err := loader.LoadFromFile("testdata/loader/circular.yaml")
+	require.ErrorContains(t, err, `circular reference`)
+}
+
+func TestLoaderIncludeOrigin(t *testing.T) {
+	loader := openapi2.NewLoader()
+	loader.IsExternalRefsAllowed = true
+	loader.IncludeOrigin = true
+	doc, err := loader.LoadFromFile("testdata/loader/root.yaml")
+	require.NoError(t, err)
+
+	pet := doc.Definitions["Pet"].Value
+	require.NotNil(t, pet.Origin)
+	require.Equal(t, "testdata/loader/root.yaml", pet.Origin.Key.File)
+	require.Equal(t, 26, pet.Origin.Key.Line)
+	require.Equal(t, 27, pet.Origin.Fields["type"].Line)
+	require.Equal(t, 36, pet.Origin.Key.EndLine)
+	require.NotZero(t, pet.Origin.Key.EndColumn)
+
+	owner := pet.Properties["owner"].Value
+	require.NotNil(t, owner.Origin)
+	require.Equal(t, "common.yaml", filepath.Base(owner.Origin.Key.File))
+	require.Equal(t, 12, owner.Origin.Key.Line)
+
+	tag := pet.Properties["tag"].Value
+	require.NotNil(t, tag.Origin)
+	require.Equal(t, "tag.yaml", filepath.Base(tag.Origin.Key.File))
+	require.Equal(t, 1, tag.Origin.Fields["type"].Line)
+
+	limit := doc.Paths["/pets/{id}"].Get.Parameters[0]
+	require.NotNil(t, limit.Origin)
+	require.Equal(t, "common.yaml", filepath.Base(limit.Origin.Key.File))
+	require.Equal(t, 3, limit.Origin.Fields["name"].Line)
+
+	operation := doc.Paths["/pets/{id}"].Get
+	require.NotNil(t, operation.Origin)
+	require.Equal(t, 9, operation.Origin.Key.Line)
+
+	// Headers record the origin of the Parameter they embed
+	total := doc.Responses["Paged"].Headers["X-Total"]
+	require.NotNil(t, total.Origin)
+	require.Equal(t, 41, total.Origin.Key.Line)
+	require.Equal(t, 42, total.Origin.Fields["type"].Line)
+	items := doc.Responses["Paged"].Headers["X-Tags"].Items.Value
+	require.NotNil(t, items.Origin)
+	require.Equal(t, 45, items.Origin.Key.Line)
+}
@@ -3,11 +3,20 @@ package openapi2
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
+	"reflect"
 	"strings"
 
 	"github.com/oasdiff/yaml"
+
+	"github.com/getkin/kin-openapi/internal/origins"
+	"github.com/getkin/kin-openapi/openapi3"
 )
 
+// originWalker sets the Origin fields of the documents decoded by unmarshal,
+// including the fields of the Parameter embedded in Header.
+var originWalker = origins.Walker{OriginType: reflect.TypeFor[*openapi3.Origin](), Embedded: true}
+
 func unmarshalError(jsonUnmarshalErr error) error {
 	if before, after, found := strings.Cut(jsonUnmarshalErr.Error(), "Bis"); found && before != "" && after != "" {
 		before = strings.ReplaceAll(before, " Go struct ", " ")
@@ -16,19 +25,32 @@ func unmarshalError(jsonUnmarshalErr error) error {
 	return jsonUnmarshalErr
 }
 
-func unmarshal(data []byte, v any) error {
+// unmarshal decodes data into v. It returns the document origin tree when
+// includeOrigin is set and the data took the yaml path (json input carries no
+// origins), so the caller can retain it.
+func unmarshal(data []byte, v any, includeOrigin bool, location *url.URL) (*yaml.OriginTree, error) {
 	var jsonErr, yamlErr error
 
 	// See https://github.com/getkin/kin-openapi/issues/680
 	if jsonErr = json.Unmarshal(data, v); jsonErr == nil {
-		return nil
+		return nil, nil
 	}
 
 	// UnmarshalStrict(data, v) TODO: investigate how ymlv3 handles duplicate map keys
-	if _, yamlErr = yaml.Unmarshal(data, v, yaml.DecodeOpts{DisableTimestamps: true}); yamlErr == nil {
-		return nil
+	var file string
+	if location != nil {
+		file = location.String()
+	}
+	if tree, err := yaml.Unmarshal(data, v, yaml.DecodeOpts{
+		Origin:            yaml.OriginOpt{Enabled: includeOrigin, File: file},
+		DisableTimestamps: true,
+	}); err == nil {
+		originWalker.Apply(v, tree)
+		return tree, nil
+	} else {
+		yamlErr = err
 	}
 
 	// If both unmarshaling attempts fail, return a new error that includes both errors
-	return fmt.Errorf("failed to unmarshal data: json error: %v, yaml error: %v", jsonErr, yamlErr)
+	return nil, fmt.Errorf("failed to unmarshal data: json error: %v, yaml error: %v", jsonErr, yamlErr)
 }
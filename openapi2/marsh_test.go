@@ -30,7 +30,7 @@ paths:
 `[1:])
 
 		var doc T
-		err := unmarshal(v2, &doc)
+		_, err := unmarshal(v2, &doc, false, nil)
 		require.ErrorContains(t, err, `json: cannot unmarshal object into field Operation.parameters of type openapi2.Parameters`)
 	}
 
@@ -56,6 +56,6 @@ paths:
 `[1:])
 
 	var doc T
-	err := unmarshal(v2, &doc)
+	_, err := unmarshal(v2, &doc, false, nil)
 	require.NoError(t, err)
 }
@@ -9,7 +9,8 @@ import (
 
 // T is the root of an OpenAPI v2 document
 type T struct {
-	Extensions map[string]any `json:"-" yaml:"-"`
+	Extensions map[string]any   `json:"-" yaml:"-"`
+	Origin     *openapi3.Origin `json:"-" yaml:"-"`
 
 	Swagger             string                     `json:"swagger" yaml:"swagger"` // required
 	Info                openapi3.Info              `json:"info" yaml:"info"`       // required
@@ -8,7 +8,8 @@ import (
 )
 
 type Operation struct {
-	Extensions map[string]any `json:"-" yaml:"-"`
+	Extensions map[string]any   `json:"-" yaml:"-"`
+	Origin     *openapi3.Origin `json:"-" yaml:"-"`
 
 	Summary      string                 `json:"summary,omitempty" yaml:"summary,omitempty"`
 	Description  string                 `json:"description,omitempty" yaml:"description,omitempty"`
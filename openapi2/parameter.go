@@ -3,6 +3,7 @@ package openapi2
 import (
 	"encoding/json"
 	"maps"
+	"net/url"
 
 	"github.com/getkin/kin-openapi/openapi3"
 )
@@ -10,7 +11,8 @@ import (
 type Parameters []*Parameter
 
 type Parameter struct {
-	Extensions map[string]any `json:"-" yaml:"-"`
+	Extensions map[string]any   `json:"-" yaml:"-"`
+	Origin     *openapi3.Origin `json:"-" yaml:"-"`
 
 	Ref string `json:"$ref,omitempty" yaml:"$ref,omitempty"`
 
@@ -37,8 +39,13 @@ type Parameter struct {
 	MinLength        uint64          `json:"minLength,omitempty" yaml:"minLength,omitempty"`
 	MinItems         uint64          `json:"minItems,omitempty" yaml:"minItems,omitempty"`
 	Default          any             `json:"default,omitempty" yaml:"default,omitempty"`
+
+	refPath *url.URL
 }
 
+// RefPath returns the path of the $ref relative to the root document.
+func (parameter *Parameter) RefPath() *url.URL { return copyURI(parameter.refPath) }
+
 // MarshalJSON returns the JSON encoding of Parameter.
 func (parameter Parameter) MarshalJSON() ([]byte, error) {
 	if ref := parameter.Ref; ref != "" {
@@ -10,7 +10,8 @@ import (
 )
 
 type PathItem struct {
-	Extensions map[string]any `json:"-" yaml:"-"`
+	Extensions map[string]any   `json:"-" yaml:"-"`
+	Origin     *openapi3.Origin `json:"-" yaml:"-"`
 
 	Ref string `json:"$ref,omitempty" yaml:"$ref,omitempty"`
 
@@ -3,12 +3,14 @@ package openapi2
 import (
 	"encoding/json"
 	"maps"
+	"net/url"
 
 	"github.com/getkin/kin-openapi/openapi3"
 )
 
 type Response struct {
-	Extensions map[string]any `json:"-" yaml:"-"`
+	Extensions map[string]any   `json:"-" yaml:"-"`
+	Origin     *openapi3.Origin `json:"-" yaml:"-"`
 
 	Ref string `json:"$ref,omitempty" yaml:"$ref,omitempty"`
 
@@ -16,8 +18,13 @@ type Response struct {
 	Schema      *SchemaRef         `json:"schema,omitempty" yaml:"schema,omitempty"`
 	Headers     map[string]*Header `json:"headers,omitempty" yaml:"headers,omitempty"`
 	Examples    map[string]any     `json:"examples,omitempty" yaml:"examples,omitempty"`
+
+	refPath *url.URL
 }
 
+// RefPath returns the path of the $ref relative to the root document.
+func (response *Response) RefPath() *url.URL { return copyURI(response.refPath) }
+
 // MarshalJSON returns the JSON encoding of Response.
 func (response Response) MarshalJSON() ([]byte, error) {
 	if ref := response.Ref; ref != "" {
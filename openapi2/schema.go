@@ -13,7 +13,8 @@ type SchemaRefs []*SchemaRef
 // Schema is specified by OpenAPI/Swagger 2.0 standard.
 // See https://swagger.io/specification/v2/#schema-object
 type Schema struct {
-	Extensions map[string]any `json:"-" yaml:"-"`
+	Extensions map[string]any   `json:"-" yaml:"-"`
+	Origin     *openapi3.Origin `json:"-" yaml:"-"`
 
 	AllOf        SchemaRefs             `json:"allOf,omitempty" yaml:"allOf,omitempty"`
 	Not          *SchemaRef             `json:"not,omitempty" yaml:"not,omitempty"`
@@ -25,6 +25,7 @@ func ToV3WithLoader(doc2 *openapi2.T, loader *openapi3.Loader, location *url.URL
 		Components:   &openapi3.Components{},
 		Tags:         doc2.Tags,
 		Extensions:   stripNonExtensions(doc2.Extensions),
+		Origin:       doc2.Origin,
 		ExternalDocs: doc2.ExternalDocs,
 	}
 
@@ -120,6 +121,7 @@ func ToV3WithLoader(doc2 *openapi2.T, loader *openapi3.Loader, location *url.URL
 func ToV3PathItem(doc2 *openapi2.T, components *openapi3.Components, pathItem *openapi2.PathItem, consumes []string) (*openapi3.PathItem, error) {
 	doc3 := &openapi3.PathItem{
 		Extensions: stripNonExtensions(pathItem.Extensions),
+		Origin:     pathItem.Origin,
 	}
 	for method, operation := range pathItem.Operations() {
 		doc3Operation, err := ToV3Operation(components, pathItem, operation, consumes)
@@ -155,6 +157,7 @@ func ToV3Operation(components *openapi3.Components, pathItem *openapi2.PathItem,
 		Deprecated:   operation.Deprecated,
 		Tags:         operation.Tags,
 		Extensions:   stripNonExtensions(operation.Extensions),
+		Origin:       operation.Origin,
 		ExternalDocs: operation.ExternalDocs,
 	}
 	if v := operation.Security; v != nil {
@@ -232,6 +235,7 @@ func ToV3Parameter(components *openapi3.Components, parameter *openapi2.Paramete
 			Description: parameter.Description,
 			Required:    parameter.Required,
 			Extensions:  stripNonExtensions(parameter.Extensions),
+			Origin:      parameter.Origin,
 		}
 		if parameter.Name != "" {
 			if result.Extensions == nil {
@@ -262,6 +266,7 @@ func ToV3Parameter(components *openapi3.Components, parameter *openapi2.Paramete
 			Description:     parameter.Description,
 			Type:            typ,
 			Extensions:      stripNonExtensions(parameter.Extensions),
+			Origin:          parameter.Origin,
 			Format:          format,
 			Enum:            parameter.Enum,
 			Min:             parameter.Minimum,
@@ -303,6 +308,7 @@ func ToV3Parameter(components *openapi3.Components, parameter *openapi2.Paramete
 			Description: parameter.Description,
 			Required:    required,
 			Extensions:  stripNonExtensions(parameter.Extensions),
+			Origin:      parameter.Origin,
 			Schema: ToV3SchemaRef(&openapi2.SchemaRef{Value: &openapi2.Schema{
 				Type:            parameter.Type,
 				Format:          parameter.Format,
@@ -421,6 +427,7 @@ func ToV3Response(response *openapi2.Response, produces []string) (*openapi3.Res
 	result := &openapi3.Response{
 		Description: &response.Description,
 		Extensions:  stripNonExtensions(response.Extensions),
+		Origin:      response.Origin,
 	}
 
 	// Default to "application/json" if "produces" is not specified.
@@ -483,6 +490,7 @@ func ToV3SchemaRef(schema *openapi2.SchemaRef) *openapi3.SchemaRef {
 
 	v3Schema := &openapi3.Schema{
 		Extensions:           schema.Value.Extensions,
+		Origin:               schema.Value.Origin,
 		Type:                 schema.Value.Type,
 		Title:                schema.Value.Title,
 		Format:               schema.Value.Format,
@@ -622,6 +630,7 @@ func ToV3SecurityScheme(securityScheme *openapi2.SecurityScheme) (*openapi3.Secu
 	result := &openapi3.SecurityScheme{
 		Description: securityScheme.Description,
 		Extensions:  stripNonExtensions(securityScheme.Extensions),
+		Origin:      securityScheme.Origin,
 	}
 	switch securityScheme.Type {
 	case "basic":
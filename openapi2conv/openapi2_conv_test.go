@@ -2,6 +2,7 @@ package openapi2conv_test
 
 import (
 	"encoding/json"
+	"net/url"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -65,6 +66,33 @@ func TestConvOpenAPIV2ToV3(t *testing.T) {
 	require.JSONEq(t, exampleV3, string(data))
 }
 
+func TestConvOpenAPIV2ToV3KeepsOrigins(t *testing.T) {
+	loader := openapi2.NewLoader()
+	loader.IsExternalRefsAllowed = true
+	loader.IncludeOrigin = true
+	doc2, err := loader.LoadFromFile("../openapi2/testdata/loader/root.yaml")
+	require.NoError(t, err)
+
+	loader3 := openapi3.NewLoader()
+	loader3.IsExternalRefsAllowed = true
+	doc3, err := openapi2conv.ToV3WithLoader(doc2, loader3, &url.URL{Path: "../openapi2/testdata/loader/root.yaml"})
+	require.NoError(t, err)
+
+	pet := doc3.Components.Schemas["Pet"].Value
+	require.NotNil(t, pet.Origin)
+	require.Equal(t, "../openapi2/testdata/loader/root.yaml", pet.Origin.Key.File)
+	require.Equal(t, 26, pet.Origin.Key.Line)
+
+	parameter := doc3.Components.Parameters["PetID"].Value
+	require.NotNil(t, parameter.Origin)
+	require.Equal(t, 20, parameter.Origin.Key.Line)
+
+	operation := doc3.Paths.Value("/pets/{id}").Get
+	require.NotNil(t, operation.Origin)
+	require.Equal(t, 9, operation.Origin.Key.Line)
+	require.Equal(t, 13, operation.Responses.Value("200").Value.Origin.Key.Line)
+}
+
 func TestConvOpenAPIV2ToV3WithAdditionalPropertiesSchemaRef(t *testing.T) {
 	v2 := []byte(`
 {
@@ -11,8 +11,10 @@ import (
 	"path"
 	"path/filepath"
 	"reflect"
-	"strconv"
 	"strings"
+
+	"github.com/getkin/kin-openapi/internal/origins"
+	"github.com/getkin/kin-openapi/internal/refs"
 )
 
 // IncludeOrigin specifies whether to include the origin of the OpenAPI elements.
@@ -318,27 +320,8 @@ func (loader *Loader) ResolveRefsIn(doc *T, location *url.URL) (err error) {
 	return
 }
 
-func defaultJoin(basePath *url.URL, relativePath *url.URL) *url.URL {
-	if basePath == nil {
-		return relativePath
-	}
-	newPath := *basePath
-	newPath.Path = path.Join(path.Dir(newPath.Path), relativePath.Path)
-	return &newPath
-}
-
 func (loader *Loader) resolvePath(basePath *url.URL, componentPath *url.URL) *url.URL {
-	if is_file(componentPath) {
-		// support absolute paths
-		if filepath.IsAbs(componentPath.Path) {
-			return componentPath
-		}
-		if loader.JoinFunc != nil {
-			return loader.JoinFunc(basePath, componentPath)
-		}
-		return defaultJoin(basePath, componentPath)
-	}
-	return componentPath
+	return refs.ResolvePath(basePath, componentPath, loader.JoinFunc)
 }
 
 func (loader *Loader) resolvePathWithRef(ref string, rootPath *url.URL) (*url.URL, error) {
@@ -437,7 +420,7 @@ func (loader *Loader) resolveComponent(doc *T, ref string, path *url.URL, resolv
 
 	drill := func(cursor any) (any, error) {
 		for pathPart := range strings.SplitSeq(fragment[1:], "/") {
-			pathPart = unescapeRefString(pathPart)
+			pathPart = refs.UnescapeRefString(pathPart)
 			attempted := false
 
 			switch c := cursor.(type) {
@@ -469,7 +452,7 @@ func (loader *Loader) resolveComponent(doc *T, ref string, path *url.URL, resolv
 			}
 
 			if !attempted {
-				if cursor, err = drillIntoField(cursor, pathPart); err != nil {
+				if cursor, err = refs.DrillIntoField(cursor, pathPart); err != nil {
 					e := failedToResolveRefFragmentPart(ref, pathPart)
 					return nil, fmt.Errorf("%s: %w", e, err)
 				}
@@ -562,19 +545,7 @@ func (loader *Loader) attachOriginToResolved(resolved any, componentDoc *T, frag
 	if !loader.IncludeOrigin {
 		return
 	}
-	tree := loader.originTrees[componentDoc]
-	if tree == nil {
-		return
-	}
-	for part := range strings.SplitSeq(strings.Trim(fragment, "/"), "/") {
-		if part == "" {
-			continue
-		}
-		if tree = tree.Fields[unescapeRefString(part)]; tree == nil {
-			return
-		}
-	}
-	applyOrigins(resolved, tree)
+	applyOrigins(resolved, origins.Subtree(loader.originTrees[componentDoc], fragment))
 }
 
 func readableType(x any) string {
@@ -606,60 +577,6 @@ func readableType(x any) string {
 	}
 }
 
-func drillIntoField(cursor any, fieldName string) (any, error) {
-	switch val := reflect.Indirect(reflect.ValueOf(cursor)); val.Kind() {
-
-	case reflect.Map:
-		elementValue := val.MapIndex(reflect.ValueOf(fieldName))
-		if !elementValue.IsValid() {
-			return nil, fmt.Errorf("map key %q not found", fieldName)
-		}
-		return elementValue.Interface(), nil
-
-	case reflect.Slice:
-		i, err := strconv.ParseUint(fieldName, 10, 32)
-		if err != nil {
-			return nil, err
-		}
-		index := int(i)
-		if 0 > index || index >= val.Len() {
-			return nil, errors.New("slice index out of bounds")
-		}
-		return val.Index(index).Interface(), nil
-
-	case reflect.Struct:
-		hasFields := false
-		for i := range val.NumField() {
-			hasFields = true
-			if yamlTag := val.Type().Field(i).Tag.Get("yaml"); yamlTag != "-" {
-				if tagName, _, _ := strings.Cut(yamlTag, ","); tagName != "" {
-					if fieldName == tagName {
-						return val.Field(i).Interface(), nil
-					}
-				}
-			}
-		}
-
-		// if cursor is a "ref wrapper" struct (e.g. RequestBodyRef),
-		if _, ok := val.Type().FieldByName("Value"); ok {
-			// try digging into its Value field
-			return drillIntoField(val.FieldByName("Value").Interface(), fieldName)
-		}
-		if hasFields {
-			if ff := val.Type().Field(0); ff.PkgPath == "" && ff.Name == "Extensions" {
-				extensions := val.Field(0).Interface().(map[string]any)
-				if enc, ok := extensions[fieldName]; ok {
-					return enc, nil
-				}
-			}
-		}
-		return nil, fmt.Errorf("struct field %q not found", fieldName)
-
-	default:
-		return nil, errors.New("not a map, slice nor struct")
-	}
-}
-
 func (loader *Loader) resolveRefAndDocument(doc *T, ref string, path *url.URL) (*T, string, *url.URL, error) {
 	if ref != "" && ref[0] == '#' {
 		return doc, ref, path, nil
@@ -1496,10 +1413,6 @@ func (loader *Loader) resolvePathItemRef(doc *T, pathItem *PathItem, documentPat
 	return
 }
 
-func unescapeRefString(ref string) string {
-	return strings.ReplaceAll(strings.ReplaceAll(ref, "~1", "/"), "~0", "~")
-}
-
 // applySiblingSchemaFields overlays the fields listed in presentFields from sibling onto dst.
 // It is used to honour keyword siblings of $ref in OpenAPI 3.1 / JSON Schema 2020-12, where
 // sibling keywords are applied in addition to (not instead of) the referenced schema.
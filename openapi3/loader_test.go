@@ -11,6 +11,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/getkin/kin-openapi/internal/refs"
 )
 
 const addr = "localhost:7965"
@@ -833,12 +835,12 @@ func TestDefaultJoin(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			rel := &url.URL{Path: tt.rel}
 			if tt.base == "" {
-				result := defaultJoin(nil, rel)
+				result := refs.DefaultJoin(nil, rel)
 				require.Equal(t, tt.expected, result.Path)
 				return
 			}
 			base := &url.URL{Path: tt.base}
-			result := defaultJoin(base, rel)
+			result := refs.DefaultJoin(base, rel)
 			require.Equal(t, tt.expected, result.Path)
 		})
 	}
@@ -10,6 +10,8 @@ import (
 	"path"
 	"path/filepath"
 	"sync"
+
+	"github.com/getkin/kin-openapi/internal/refs"
 )
 
 // ReadFromURIFunc defines a function which reads the contents of a resource
@@ -69,15 +71,9 @@ func ReadFromHTTP(cl *http.Client) ReadFromURIFunc {
 	}
 }
 
-func is_file(location *url.URL) bool {
-	return location.Path != "" &&
-		location.Host == "" &&
-		(location.Scheme == "" || location.Scheme == "file")
-}
-
 // ReadFromFile is a ReadFromURIFunc which reads local file URIs.
 func ReadFromFile(loader *Loader, location *url.URL) ([]byte, error) {
-	if !is_file(location) {
+	if !refs.IsFile(location) {
 		return nil, ErrURINotSupported
 	}
 	return os.ReadFile(path.Clean(filepath.FromSlash(location.Path)))
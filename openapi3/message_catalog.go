@@ -0,0 +1,354 @@
+package openapi3
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// ErrorKind is a stable, kebab-case identifier of a kind of validation failure,
+// keying the messages of a MessageCatalog.
+// See SchemaError.Kind.
+type ErrorKind string
+
+// Kinds of schema errors, one per schema keyword failure.
+const (
+	ErrorKindSchemaType                 ErrorKind = "schema-type"                  // Types
+	ErrorKindSchemaTypeUnhandled        ErrorKind = "schema-type-unhandled"        // Detail: the Go type of the value
+	ErrorKindSchemaNumberInvalid        ErrorKind = "schema-number-invalid"        // a json.Number that is not a number
+	ErrorKindSchemaEnum                 ErrorKind = "schema-enum"                  // Allowed
+	ErrorKindSchemaConst                ErrorKind = "schema-const"                 // Allowed: the const value
+	ErrorKindSchemaNot                  ErrorKind = "schema-not"                   //
+	ErrorKindSchemaDiscriminatorMissing ErrorKind = "schema-discriminator-missing" // Property
+	ErrorKindSchemaDiscriminatorType    ErrorKind = "schema-discriminator-type"    // Property
+	ErrorKindSchemaDiscriminatorInvalid ErrorKind = "schema-discriminator-invalid" // Property
+	ErrorKindSchemaOneOf                ErrorKind = "schema-one-of"                //
+	ErrorKindSchemaOneOfConflict        ErrorKind = "schema-one-of-conflict"       // Indices
+	ErrorKindSchemaAnyOf                ErrorKind = "schema-any-of"                //
+	ErrorKindSchemaAllOf                ErrorKind = "schema-all-of"                //
+	ErrorKindSchemaNullable             ErrorKind = "schema-nullable"              //
+	ErrorKindSchemaFormat               ErrorKind = "schema-format"                // Types, Format, Detail
+	ErrorKindSchemaExclusiveMinimum     ErrorKind = "schema-exclusive-minimum"     // Limit
+	ErrorKindSchemaExclusiveMaximum     ErrorKind = "schema-exclusive-maximum"     // Limit
+	ErrorKindSchemaMinimum              ErrorKind = "schema-minimum"               // Limit
+	ErrorKindSchemaMaximum              ErrorKind = "schema-maximum"               // Limit
+	ErrorKindSchemaMultipleOf           ErrorKind = "schema-multiple-of"           // Limit
+	ErrorKindSchemaMinLength            ErrorKind = "schema-min-length"            // Limit, Actual
+	ErrorKindSchemaMaxLength            ErrorKind = "schema-max-length"            // Limit, Actual
+	ErrorKindSchemaPattern              ErrorKind = "schema-pattern"               // Pattern
+	ErrorKindSchemaPatternInvalid       ErrorKind = "schema-pattern-invalid"       // Pattern, Detail
+	ErrorKindSchemaMinItems             ErrorKind = "schema-min-items"             // Limit, Actual
+	ErrorKindSchemaMaxItems             ErrorKind = "schema-max-items"             // Limit, Actual
+	ErrorKindSchemaUniqueItems          ErrorKind = "schema-unique-items"          //
+	ErrorKindSchemaMinProperties        ErrorKind = "schema-min-properties"        // Limit, Actual
+	ErrorKindSchemaMaxProperties        ErrorKind = "schema-max-properties"        // Limit, Actual
+	ErrorKindSchemaAdditionalProperties ErrorKind = "schema-additional-properties" // Property
+	ErrorKindSchemaRequired             ErrorKind = "schema-required"              // Property
+	// ErrorKindSchemaKeyword is the kind of the failures of the JSON Schema 2020-12 validator
+	// not having one of the kinds above.
+	ErrorKindSchemaKeyword ErrorKind = "schema-keyword" // Keyword, Detail
+)
+
+// Kinds of the errors of parsing parameters and bodies (see openapi3filter.ParseError).
+const (
+	ErrorKindParseInvalidFormat       ErrorKind = "parse-invalid-format"        // Value, Types
+	ErrorKindParseNonPrimitiveType    ErrorKind = "parse-non-primitive-type"    // Value, Types
+	ErrorKindParsePrefix              ErrorKind = "parse-prefix"                // Value, Delimiters: the prefix
+	ErrorKindParseObject              ErrorKind = "parse-object"                // Value, Delimiters: the value then the property delimiter
+	ErrorKindParseArrayIndexes        ErrorKind = "parse-array-indexes"         //
+	ErrorKindParseArrayConversion     ErrorKind = "parse-array-conversion"      // Detail: why the items are not an array
+	ErrorKindParseNotPrimitive        ErrorKind = "parse-not-primitive"         // Value
+	ErrorKindParseDeepObject          ErrorKind = "parse-deep-object"           // Value
+	ErrorKindParseUnsupportedFormat   ErrorKind = "parse-unsupported-format"    // MediaType
+	ErrorKindParseContentTypeMismatch ErrorKind = "parse-content-type-mismatch" // MediaType, Allowed: the content type of the encoding
+)
+
+// MessageParams are the parameters of the message of an error, as used by its template.
+// Which are set depends on the ErrorKind.
+type MessageParams struct {
+	// Value is the offending value, set for parse errors only:
+	// messages of schema errors should not leak potentially sensitive inputs.
+	Value any
+	// Limit is the bound a number, length, or count of items or properties violates.
+	// Format lengths and counts with {{printf "%.0f" .Limit}}: {{.Limit}} prints 1e+06 for 1000000.
+	Limit float64
+	// Actual is the length, or count of items or properties, that violates Limit.
+	Actual float64
+	// Pattern is the regular expression of the schema.
+	Pattern string
+	// Allowed are the allowed values of an enum, or the const value.
+	Allowed []any
+	// MediaType is the media type of a body.
+	MediaType string
+	// Delimiters are the delimiters, or the prefix, of a serialized parameter.
+	Delimiters []string
+	// Property is the name of the missing, unsupported or discriminator property.
+	Property string
+	// Types are the expected types (e.g. "integer").
+	Types []string
+	// Format is the format of the schema.
+	Format string
+	// Indices are the indices of the subschemas a value matches.
+	Indices []int
+	// Keyword is the failing schema keyword.
+	Keyword string
+	// Detail is an underlying, unlocalized, error message.
+	Detail string
+}
+
+// DefaultLocale is the locale of the built-in messages, in English, and the fallback of MessageCatalog.
+const DefaultLocale = "en"
+
+var defaultMessages = map[ErrorKind]string{
+	ErrorKindSchemaType:                 `value must be {{if eq (len .Types) 1}}{{$t := index .Types 0}}{{if or (eq $t "array") (eq $t "object") (eq $t "integer")}}an{{else}}a{{end}} {{$t}}{{else}}one of {{join .Types ", "}}{{end}}`,
+	ErrorKindSchemaTypeUnhandled:        `unhandled value of type {{.Detail}}`,
+	ErrorKindSchemaNumberInvalid:        `cannot convert json.Number to float64`,
+	ErrorKindSchemaEnum:                 `value is not one of the allowed values {{json .Allowed}}`,
+	ErrorKindSchemaConst:                `value must be {{json (index .Allowed 0)}}`,
+	ErrorKindSchemaNot:                  `doesn't match schema "not"`,
+	ErrorKindSchemaDiscriminatorMissing: `input does not contain the discriminator property {{printf "%q" .Property}}`,
+	ErrorKindSchemaDiscriminatorType:    `value of discriminator property {{printf "%q" .Property}} is not a string`,
+	ErrorKindSchemaDiscriminatorInvalid: `discriminator property {{printf "%q" .Property}} has invalid value`,
+	ErrorKindSchemaOneOf:                `value doesn't match any schema from "oneOf"`,
+	ErrorKindSchemaOneOfConflict:        `value matches more than one schema from "oneOf" (matches schemas at indices {{.Indices}})`,
+	ErrorKindSchemaAnyOf:                `doesn't match any schema from "anyOf"`,
+	ErrorKindSchemaAllOf:                `doesn't match all schemas from "allOf"`,
+	ErrorKindSchemaNullable:             `Value is not nullable`,
+	ErrorKindSchemaFormat:               `{{index .Types 0}} doesn't match the format {{printf "%q" .Format}} ({{.Detail}})`,
+	ErrorKindSchemaExclusiveMinimum:     `number must be more than {{printf "%g" .Limit}}`,
+	ErrorKindSchemaExclusiveMaximum:     `number must be less than {{printf "%g" .Limit}}`,
+	ErrorKindSchemaMinimum:              `number must be at least {{printf "%g" .Limit}}`,
+	ErrorKindSchemaMaximum:              `number must be at most {{printf "%g" .Limit}}`,
+	ErrorKindSchemaMultipleOf:           `number must be a multiple of {{printf "%g" .Limit}}`,
+	ErrorKindSchemaMinLength:            `minimum string length is {{printf "%.0f" .Limit}}`,
+	ErrorKindSchemaMaxLength:            `maximum string length is {{printf "%.0f" .Limit}}`,
+	ErrorKindSchemaPattern:              `string doesn't match the regular expression "{{.Pattern}}"`,
+	ErrorKindSchemaPatternInvalid:       `cannot compile pattern {{printf "%q" .Pattern}}: {{.Detail}}`,
+	ErrorKindSchemaMinItems:             `minimum number of items is {{printf "%.0f" .Limit}}`,
+	ErrorKindSchemaMaxItems:             `maximum number of items is {{printf "%.0f" .Limit}}`,
+	ErrorKindSchemaUniqueItems:          `duplicate items found`,
+	ErrorKindSchemaMinProperties:        `there must be at least {{printf "%.0f" .Limit}} properties`,
+	ErrorKindSchemaMaxProperties:        `there must be at most {{printf "%.0f" .Limit}} properties`,
+	ErrorKindSchemaAdditionalProperties: `property {{printf "%q" .Property}} is unsupported`,
+	ErrorKindSchemaRequired:             `property {{printf "%q" .Property}} is missing`,
+	ErrorKindSchemaKeyword:              `{{.Detail}}`,
+	ErrorKindParseInvalidFormat:         `an invalid {{index .Types 0}}`,
+	ErrorKindParseNonPrimitiveType:      `schema has non primitive type {{index .Types 0}}`,
+	ErrorKindParsePrefix:                `a value must be prefixed with {{printf "%q" (index .Delimiters 0)}}`,
+	ErrorKindParseObject:                `a value must be a list of object's properties in format "name{{index .Delimiters 0}}value" separated by {{index .Delimiters 1}}`,
+	ErrorKindParseArrayIndexes:          `array items must be set with indexes`,
+	ErrorKindParseArrayConversion:       `could not convert value map to array: {{.Detail}}`,
+	ErrorKindParseNotPrimitive:          `path is not convertible to primitive`,
+	ErrorKindParseDeepObject:            `invalid param object`,
+	ErrorKindParseUnsupportedFormat:     `unsupported content type {{printf "%q" .MediaType}}`,
+	ErrorKindParseContentTypeMismatch:   `not matching content types: header {{printf "%q" .MediaType}}, encoding {{printf "%q" (index .Allowed 0)}}`,
+}
+
+// ErrorKinds returns every kind of error having a built-in message, sorted.
+func ErrorKinds() []ErrorKind {
+	return slices.Sorted(maps.Keys(defaultMessages))
+}
+
+var messageFuncs = template.FuncMap{
+	"join": strings.Join,
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// MessageCatalog holds the message templates of error kinds, by locale.
+// Templates use the text/template syntax, with MessageParams as data and the additional functions
+// "join" (strings.Join) and "json" (the JSON encoding of a value):
+//
+//	catalog := openapi3.NewMessageCatalog()
+//	err := catalog.Register("fr", map[openapi3.ErrorKind]string{
+//		openapi3.ErrorKindSchemaMaxLength: `la longueur maximale est {{printf "%.0f" .Limit}}`,
+//	})
+//
+// A MessageCatalog is safe for concurrent use.
+type MessageCatalog struct {
+	mu        sync.RWMutex
+	templates map[string]map[ErrorKind]*template.Template
+}
+
+// NewMessageCatalog returns a catalog holding the built-in messages in DefaultLocale.
+func NewMessageCatalog() *MessageCatalog {
+	c := &MessageCatalog{templates: make(map[string]map[ErrorKind]*template.Template)}
+	if err := c.Register(DefaultLocale, defaultMessages); err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// Register adds the message templates of locale (e.g. "fr" or "pt-BR"), replacing those of the same kinds.
+func (c *MessageCatalog) Register(locale string, messages map[ErrorKind]string) error {
+	locale = normalizeLocale(locale)
+	templates := make(map[ErrorKind]*template.Template, len(messages))
+	for kind, text := range messages {
+		tmpl, err := template.New(string(kind)).Funcs(messageFuncs).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return fmt.Errorf("invalid message of %q in locale %q: %w", kind, locale, err)
+		}
+		templates[kind] = tmpl
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.templates == nil {
+		c.templates = make(map[string]map[ErrorKind]*template.Template)
+	}
+	if c.templates[locale] == nil {
+		c.templates[locale] = templates
+	} else {
+		maps.Copy(c.templates[locale], templates)
+	}
+	return nil
+}
+
+// Locales returns the locales having messages, sorted.
+func (c *MessageCatalog) Locales() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return slices.Sorted(maps.Keys(c.templates))
+}
+
+// Message returns the message of kind with params in locale.
+// Messages missing in locale (e.g. "pt-BR") fall back to its language ("pt"), then to DefaultLocale.
+// It returns "" if kind has no message.
+func (c *MessageCatalog) Message(locale string, kind ErrorKind, params MessageParams) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, locale := range localeFallbacks(locale) {
+		tmpl := c.templates[locale][kind]
+		if tmpl == nil {
+			continue
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, params); err == nil {
+			return buf.String()
+		}
+	}
+	return ""
+}
+
+// MatchLocale returns the locale of the catalog best matching an Accept-Language header value
+// (e.g. "fr-CH, fr;q=0.9, en;q=0.8"), or DefaultLocale.
+func (c *MessageCatalog) MatchLocale(acceptLanguage string) string {
+	type weighted struct {
+		locale string
+		q      float64
+	}
+	var ranges []weighted
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		locale, params, _ := strings.Cut(part, ";")
+		w := weighted{locale: normalizeLocale(locale), q: 1}
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if v, err := strconv.ParseFloat(q, 64); err == nil {
+				w.q = v
+			}
+		}
+		if w.locale != "" && w.q > 0 {
+			ranges = append(ranges, w)
+		}
+	}
+	slices.SortStableFunc(ranges, func(a, b weighted) int {
+		switch {
+		case a.q > b.q:
+			return -1
+		case a.q < b.q:
+			return 1
+		}
+		return 0
+	})
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, r := range ranges {
+		if r.locale == "*" {
+			break
+		}
+		// Without falling back to DefaultLocale, which may be a later choice
+		locales := localeFallbacks(r.locale)
+		for _, locale := range locales[:len(locales)-1] {
+			if c.templates[locale] != nil {
+				return locale
+			}
+		}
+	}
+	return DefaultLocale
+}
+
+// localeFallbacks returns locale, its language and DefaultLocale.
+func localeFallbacks(locale string) []string {
+	locale = normalizeLocale(locale)
+	locales := []string{locale}
+	if language, _, ok := strings.Cut(locale, "-"); ok {
+		locales = append(locales, language)
+	}
+	return append(locales, DefaultLocale)
+}
+
+// normalizeLocale returns locale in lower case, with "-" separators (e.g. "pt-br").
+func normalizeLocale(locale string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(locale)), "_", "-")
+}
+
+// WithMessageCatalog makes the reasons of the schema errors the messages of catalog in locale.
+func WithMessageCatalog(catalog *MessageCatalog, locale string) SchemaValidationOption {
+	return func(s *schemaValidationSettings) { s.messageCatalog, s.locale = catalog, locale }
+}
+
+// localize sets the reasons of the schema errors of err to their messages in the catalog of settings, if any.
+func (settings *schemaValidationSettings) localize(err error) error {
+	if err != nil && settings.messageCatalog != nil {
+		localizeSchemaErrors(settings.messageCatalog, settings.locale, err)
+	}
+	return err
+}
+
+func localizeSchemaErrors(catalog *MessageCatalog, locale string, err error) {
+	switch e := err.(type) {
+	case MultiError:
+		for _, err := range e {
+			localizeSchemaErrors(catalog, locale, err)
+		}
+		return
+	case multiErrorForOneOf:
+		localizeSchemaErrors(catalog, locale, MultiError(e))
+		return
+	case multiErrorForAllOf:
+		localizeSchemaErrors(catalog, locale, MultiError(e))
+		return
+	case *SchemaError:
+		if e.kind != "" {
+			if msg := catalog.Message(locale, e.kind, e.params); msg != "" {
+				e.Reason = msg
+			}
+		}
+		if e.Origin != nil {
+			localizeSchemaErrors(catalog, locale, e.Origin)
+		}
+		return
+	}
+	if inner := errors.Unwrap(err); inner != nil {
+		localizeSchemaErrors(catalog, locale, inner)
+	}
+}
+
+// Kind returns the kind of err, or "" for errors of custom format validators.
+func (err *SchemaError) Kind() ErrorKind {
+	return err.kind
+}
+
+// Params returns the parameters of the message of err.
+func (err *SchemaError) Params() MessageParams {
+	return err.params
+}
@@ -0,0 +1,163 @@
+package openapi3
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageCatalogDefaultMessages(t *testing.T) {
+	catalog := NewMessageCatalog()
+
+	var check func(err error)
+	check = func(err error) {
+		switch e := err.(type) {
+		case MultiError:
+			for _, err := range e {
+				check(err)
+			}
+			return
+		case multiErrorForOneOf:
+			check(MultiError(e))
+			return
+		case multiErrorForAllOf:
+			check(MultiError(e))
+			return
+		case *SchemaError:
+			if e.Kind() != "" && e.Reason != "" {
+				require.Equal(t, e.Reason, catalog.Message(DefaultLocale, e.Kind(), e.Params()), e.Kind())
+			}
+			if e.Origin != nil {
+				check(e.Origin)
+			}
+			return
+		}
+		if inner := errors.Unwrap(err); inner != nil {
+			check(inner)
+		}
+	}
+
+	// The built-in messages are the reasons of schema errors
+	for _, e := range schemaExamples {
+		for _, value := range e.AllInvalid {
+			data, err := json.Marshal(value)
+			require.NoError(t, err)
+			var v any
+			require.NoError(t, json.Unmarshal(data, &v))
+			check(e.Schema.VisitJSON(v, MultiErrors()))
+		}
+	}
+
+	// Lengths and counts of a million or more are not printed in exponent notation
+	large := NewObjectSchema().
+		WithProperty("name", NewStringSchema().WithMinLength(1_000_000)).
+		WithProperty("tags", NewArraySchema().WithItems(NewStringSchema()).WithMinItems(1_000_000)).
+		WithProperty("labels", NewObjectSchema().WithMinProperties(1_000_000))
+	err := large.VisitJSON(map[string]any{"name": "Rex", "tags": []any{}, "labels": map[string]any{}}, MultiErrors())
+	check(err)
+	require.ErrorContains(t, err, "minimum string length is 1000000")
+	require.ErrorContains(t, err, "minimum number of items is 1000000")
+	require.ErrorContains(t, err, "there must be at least 1000000 properties")
+	require.Equal(t, "maximum string length is 1000000",
+		catalog.Message(DefaultLocale, ErrorKindSchemaMaxLength, MessageParams{Limit: 1e6, Actual: 1e6 + 1}))
+
+	require.Contains(t, ErrorKinds(), ErrorKindSchemaMaxLength)
+	require.Contains(t, ErrorKinds(), ErrorKindParseInvalidFormat)
+	require.Equal(t, []string{"en"}, catalog.Locales())
+}
+
+func TestMessageCatalogLocalize(t *testing.T) {
+	catalog := NewMessageCatalog()
+	require.NoError(t, catalog.Register("fr", map[ErrorKind]string{
+		ErrorKindSchemaMaxLength: `la longueur maximale est {{.Limit}} (reçu {{.Actual}})`,
+		ErrorKindSchemaRequired:  `la propriété {{printf "%q" .Property}} est manquante`,
+		ErrorKindSchemaEnum:      `la valeur doit être l'une de {{json .Allowed}}`,
+	}))
+	require.NoError(t, catalog.Register("fr-CA", map[ErrorKind]string{
+		ErrorKindSchemaMaxLength: `la longueur maximale est de {{.Limit}}`,
+	}))
+	err := catalog.Register("de", map[ErrorKind]string{ErrorKindSchemaPattern: `{{.Pattern`})
+	require.ErrorContains(t, err, `invalid message of "schema-pattern" in locale "de"`)
+	require.Equal(t, []string{"en", "fr", "fr-ca"}, catalog.Locales())
+
+	schema := NewObjectSchema().
+		WithProperty("name", NewStringSchema().WithMaxLength(2)).
+		WithProperty("kind", NewStringSchema().WithEnum("cat", "dog")).
+		WithProperty("age", NewIntegerSchema().WithMin(0))
+	schema.Required = []string{"name", "id"}
+	value := map[string]any{"name": "Felix", "kind": "fish", "age": -1.0}
+
+	reasons := func(err error) (reasons []string) {
+		var me MultiError
+		require.ErrorAs(t, err, &me)
+		for _, err := range me {
+			reasons = append(reasons, err.(*SchemaError).Reason)
+		}
+		return
+	}
+
+	for _, opts := range [][]SchemaValidationOption{
+		{MultiErrors(), WithMessageCatalog(catalog, "fr-FR")},
+		{MultiErrors(), WithMessageCatalog(catalog, "FR")},
+	} {
+		want := []string{
+			`number must be at least 0`,
+			`la valeur doit être l'une de ["cat","dog"]`,
+			`la longueur maximale est 2 (reçu 5)`,
+			`la propriété "id" est manquante`,
+		}
+		require.Equal(t, want, reasons(schema.VisitJSON(value, opts...)))
+		require.Equal(t, want, reasons(Compile(schema, opts...).VisitJSON(value)))
+		require.Equal(t, want, reasons(Compile(schema, MultiErrors()).VisitJSON(value, opts[1:]...)))
+	}
+
+	require.Equal(t, []string{
+		`number must be at least 0`,
+		`la valeur doit être l'une de ["cat","dog"]`,
+		`la longueur maximale est de 2`,
+		`la propriété "id" est manquante`,
+	}, reasons(schema.VisitJSON(value, MultiErrors(), WithMessageCatalog(catalog, "fr_CA"))))
+
+	// Unlocalized
+	require.Equal(t, []string{
+		`number must be at least 0`,
+		`value is not one of the allowed values ["cat","dog"]`,
+		`maximum string length is 2`,
+		`property "id" is missing`,
+	}, reasons(schema.VisitJSON(value, MultiErrors())))
+
+	// With the JSON Schema 2020-12 validator
+	err = schema.VisitJSON(map[string]any{"name": "Felix", "id": 1}, EnableJSONSchema2020(), WithMessageCatalog(catalog, "fr"))
+	var schemaErr *SchemaError
+	require.ErrorAs(t, err, &schemaErr)
+	require.Equal(t, ErrorKindSchemaKeyword, schemaErr.Kind())
+	var causes MultiError
+	require.ErrorAs(t, schemaErr.Origin, &causes)
+	require.Equal(t, ErrorKindSchemaMaxLength, causes[0].(*SchemaError).Kind())
+	require.Equal(t, `la longueur maximale est 2 (reçu 5)`, causes[0].(*SchemaError).Reason)
+}
+
+func TestMessageCatalogMatchLocale(t *testing.T) {
+	catalog := NewMessageCatalog()
+	require.NoError(t, catalog.Register("fr", nil))
+	require.NoError(t, catalog.Register("pt-BR", nil))
+
+	for acceptLanguage, want := range map[string]string{
+		"":                             "en",
+		"*":                            "en",
+		"de":                           "en",
+		"fr":                           "fr",
+		"fr-CH, fr;q=0.9, en;q=0.8":    "fr",
+		"de-DE, en;q=0.5, fr;q=0.7":    "fr",
+		"en;q=0.9, pt-BR":              "pt-br",
+		"pt":                           "en",
+		"pt-PT, en;q=0.3":              "en",
+		"fr;q=0, de":                   "en",
+		"es, *;q=0.5, fr;q=0.1":        "en",
+		"  PT_br ;q=1.0 , fr ; q=0.5 ": "pt-br",
+	} {
+		require.Equal(t, want, catalog.MatchLocale(acceptLanguage), acceptLanguage)
+	}
+}
@@ -17,6 +17,7 @@ import (
 	"unicode/utf16"
 
 	"github.com/go-openapi/jsonpointer"
+	"github.com/santhosh-tekuri/jsonschema/v6"
 )
 
 const (
@@ -1943,12 +1944,15 @@ func (schema *Schema) VisitJSON(value any, opts ...SchemaValidationOption) error
 	settings := newSchemaValidationSettings(opts...)
 
 	if settings.useJSONSchema2020 {
-		return schema.useJSONSchema2020(settings, value)
+		return settings.localize(schema.useJSONSchema2020(settings, value))
 	}
-	return schema.visitJSON(settings, value)
+	return settings.localize(schema.visitJSON(settings, value))
 }
 
 func (schema *Schema) visitJSON(settings *schemaValidationSettings, value any) (err error) {
+	if settings.schemaVisited != nil {
+		defer func() { settings.schemaVisited(schema, value, err) }()
+	}
 	switch value := value.(type) {
 	case nil:
 		// Don't use VisitJSONNull, as we still want to reach 'visitXOFOperations', since
@@ -2001,6 +2005,7 @@ func (schema *Schema) visitJSON(settings *schemaValidationSettings, value any) (
 				Schema:                schema,
 				SchemaField:           "type",
 				Reason:                "cannot convert json.Number to float64",
+				kind:                  ErrorKindSchemaNumberInvalid,
 				customizeMessageError: settings.customizeMessageError,
 				Origin:                err,
 			}
@@ -2047,31 +2052,17 @@ func (schema *Schema) visitJSON(settings *schemaValidationSettings, value any) (
 		Schema:                schema,
 		SchemaField:           "type",
 		Reason:                fmt.Sprintf("unhandled value of type %T", value),
+		kind:                  ErrorKindSchemaTypeUnhandled,
+		params:                MessageParams{Detail: fmt.Sprintf("%T", value)},
 		customizeMessageError: settings.customizeMessageError,
 	}
 }
 
 func (schema *Schema) visitEnumOperation(settings *schemaValidationSettings, value any) (err error) {
 	if enum := schema.Enum; len(enum) != 0 {
-		for _, v := range enum {
-			switch c := value.(type) {
-			case json.Number:
-				var f float64
-				if f, err = strconv.ParseFloat(c.String(), 64); err != nil {
-					return err
-				}
-				if v == f {
-					return
-				}
-			case int64:
-				if v == float64(c) {
-					return
-				}
-			default:
-				if reflect.DeepEqual(v, value) {
-					return
-				}
-			}
+		var found bool
+		if found, err = settings.enumContains(schema, value); err != nil || found {
+			return
 		}
 		if settings.failfast {
 			return errSchema
@@ -2082,12 +2073,39 @@ func (schema *Schema) visitEnumOperation(settings *schemaValidationSettings, val
 			Schema:                schema,
 			SchemaField:           "enum",
 			Reason:                "value is not one of the allowed values " + string(allowedValues),
+			kind:                  ErrorKindSchemaEnum,
+			params:                MessageParams{Allowed: enum},
 			customizeMessageError: settings.customizeMessageError,
 		}
 	}
 	return
 }
 
+// enumContains reports whether enum contains value.
+func enumContains(enum []any, value any) (bool, error) {
+	for _, v := range enum {
+		switch c := value.(type) {
+		case json.Number:
+			f, err := strconv.ParseFloat(c.String(), 64)
+			if err != nil {
+				return false, err
+			}
+			if v == f {
+				return true, nil
+			}
+		case int64:
+			if v == float64(c) {
+				return true, nil
+			}
+		default:
+			if reflect.DeepEqual(v, value) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
 func (schema *Schema) visitConstOperation(settings *schemaValidationSettings, value any) (err error) {
 	if schema.Const == nil {
 		return
@@ -2115,6 +2133,8 @@ func (schema *Schema) visitConstOperation(settings *schemaValidationSettings, va
 			Schema:                schema,
 			SchemaField:           "const",
 			Reason:                "value must be " + string(constVal),
+			kind:                  ErrorKindSchemaConst,
+			params:                MessageParams{Allowed: []any{schema.Const}},
 			customizeMessageError: settings.customizeMessageError,
 		}
 	}
@@ -2135,6 +2155,7 @@ func (schema *Schema) visitNotOperation(settings *schemaValidationSettings, valu
 				Value:                 value,
 				Schema:                schema,
 				SchemaField:           "not",
+				kind:                  ErrorKindSchemaNot,
 				customizeMessageError: settings.customizeMessageError,
 			}
 		}
@@ -2161,6 +2182,8 @@ func (schema *Schema) resolveDiscriminatorRef(value any) (string, error) {
 			Schema:      schema,
 			SchemaField: "discriminator",
 			Reason:      fmt.Sprintf("input does not contain the discriminator property %q", pn),
+			kind:        ErrorKindSchemaDiscriminatorMissing,
+			params:      MessageParams{Property: pn},
 		}
 	}
 
@@ -2171,6 +2194,8 @@ func (schema *Schema) resolveDiscriminatorRef(value any) (string, error) {
 			Schema:      schema,
 			SchemaField: "discriminator",
 			Reason:      fmt.Sprintf("value of discriminator property %q is not a string", pn),
+			kind:        ErrorKindSchemaDiscriminatorType,
+			params:      MessageParams{Property: pn},
 		}
 	}
 
@@ -2180,12 +2205,24 @@ func (schema *Schema) resolveDiscriminatorRef(value any) (string, error) {
 			Schema:      schema,
 			SchemaField: "discriminator",
 			Reason:      fmt.Sprintf("discriminator property %q has invalid value", pn),
+			kind:        ErrorKindSchemaDiscriminatorInvalid,
+			params:      MessageParams{Property: pn},
 		}
 	} else {
 		return discriminatorRef.Ref, nil
 	}
 }
 
+// unresolvedSchemaRefs returns an error for the first of refs without a value.
+func unresolvedSchemaRefs(refs SchemaRefs) error {
+	for _, item := range refs {
+		if item.Value == nil {
+			return newUnresolvedRef(item.Ref, item.Origin)
+		}
+	}
+	return nil
+}
+
 func (schema *Schema) visitXOFOperations(settings *schemaValidationSettings, value any) (err error, run bool) {
 	var visitedOneOf, visitedAnyOf, visitedAllOf bool
 	if v := schema.OneOf; len(v) > 0 {
@@ -2200,15 +2237,11 @@ func (schema *Schema) visitXOFOperations(settings *schemaValidationSettings, val
 			matchedOneOfIndices = make([]int, 0)
 			tempValue           = value
 		)
-		for idx, item := range v {
-			v := item.Value
-			if v == nil {
-				return newUnresolvedRef(item.Ref, item.Origin), false
-			}
-
-			if discriminatorRef != "" && discriminatorRef != item.Ref {
-				continue
-			}
+		if err := unresolvedSchemaRefs(v); err != nil {
+			return err, false
+		}
+		for _, idx := range settings.branches(schema, false, discriminatorRef) {
+			v := v[idx].Value
 
 			// make a deep copy to protect origin value from being injected default value that defined in mismatched oneOf schema
 			if settings.asreq || settings.asrep {
@@ -2237,9 +2270,11 @@ func (schema *Schema) visitXOFOperations(settings *schemaValidationSettings, val
 			if ok > 1 {
 				e.Origin = ErrOneOfConflict
 				e.Reason = fmt.Sprintf(`value matches more than one schema from "oneOf" (matches schemas at indices %v)`, matchedOneOfIndices)
+				e.kind, e.params = ErrorKindSchemaOneOfConflict, MessageParams{Indices: matchedOneOfIndices}
 			} else {
 				e.Origin = fmt.Errorf("doesn't match schema due to: %w", validationErrors)
 				e.Reason = `value doesn't match any schema from "oneOf"`
+				e.kind = ErrorKindSchemaOneOf
 			}
 
 			return e, false
@@ -2247,7 +2282,7 @@ func (schema *Schema) visitXOFOperations(settings *schemaValidationSettings, val
 
 		// run again to inject default value that defined in matched oneOf schema
 		if settings.asreq || settings.asrep {
-			_ = v[matchedOneOfIndices[0]].Value.visitJSON(settings, value)
+			settings.unvisited(func() { _ = v[matchedOneOfIndices[0]].Value.visitJSON(settings, value) })
 		}
 		visitedOneOf = true
 	}
@@ -2263,15 +2298,11 @@ func (schema *Schema) visitXOFOperations(settings *schemaValidationSettings, val
 			matchedAnyOfIdx = 0
 			tempValue       = value
 		)
-		for idx, item := range v {
-			v := item.Value
-			if v == nil {
-				return newUnresolvedRef(item.Ref, item.Origin), false
-			}
-
-			if discriminatorRef != "" && discriminatorRef != item.Ref {
-				continue
-			}
+		if err := unresolvedSchemaRefs(v); err != nil {
+			return err, false
+		}
+		for _, idx := range settings.branches(schema, true, discriminatorRef) {
+			v := v[idx].Value
 
 			// make a deep copy to protect origin value from being injected default value that defined in mismatched anyOf schema
 			if settings.asreq || settings.asrep {
@@ -2292,11 +2323,12 @@ func (schema *Schema) visitXOFOperations(settings *schemaValidationSettings, val
 				Schema:                schema,
 				SchemaField:           "anyOf",
 				Reason:                `doesn't match any schema from "anyOf"`,
+				kind:                  ErrorKindSchemaAnyOf,
 				customizeMessageError: settings.customizeMessageError,
 			}, false
 		}
 
-		_ = v[matchedAnyOfIdx].Value.visitJSON(settings, value)
+		settings.unvisited(func() { _ = v[matchedAnyOfIdx].Value.visitJSON(settings, value) })
 		visitedAnyOf = true
 	}
 
@@ -2320,6 +2352,7 @@ func (schema *Schema) visitXOFOperations(settings *schemaValidationSettings, val
 			Schema:                schema,
 			SchemaField:           "allOf",
 			Reason:                `doesn't match all schemas from "allOf"`,
+			kind:                  ErrorKindSchemaAllOf,
 			Origin:                fmt.Errorf("doesn't match schema due to: %w", validationErrors),
 			customizeMessageError: settings.customizeMessageError,
 		}, false
@@ -2345,6 +2378,7 @@ func (schema *Schema) visitJSONNull(settings *schemaValidationSettings) (err err
 		Schema:                schema,
 		SchemaField:           "nullable",
 		Reason:                "Value is not nullable",
+		kind:                  ErrorKindSchemaNullable,
 		customizeMessageError: settings.customizeMessageError,
 	}
 }
@@ -2381,6 +2415,8 @@ func (schema *Schema) visitJSONNumber(settings *schemaValidationSettings, value
 				Schema:                schema,
 				SchemaField:           "type",
 				Reason:                "value must be an integer",
+				kind:                  ErrorKindSchemaType,
+				params:                MessageParams{Types: []string{TypeInteger}},
 				customizeMessageError: settings.customizeMessageError,
 			}
 			if !settings.multiError {
@@ -2393,17 +2429,13 @@ func (schema *Schema) visitJSONNumber(settings *schemaValidationSettings, value
 	}
 
 	// formats
-	var formatStrErr string
+	var formatStrErr, formatType, formatReason string
 	var formatErr error
 	format := schema.Format
 	if format != "" {
 		if requireInteger {
 			// Check per-validation validators first, then fall back to global
-			f, ok := settings.integerFormats[format]
-			if !ok {
-				f, ok = SchemaIntegerFormats[format]
-			}
-			if ok {
+			if f, ok := settings.integerFormat(schema); ok {
 				if err := f.Validate(int64(value)); err != nil {
 					var reason string
 					schemaErr := &SchemaError{}
@@ -2413,16 +2445,13 @@ func (schema *Schema) visitJSONNumber(settings *schemaValidationSettings, value
 						reason = err.Error()
 					}
 					formatStrErr = fmt.Sprintf(`integer doesn't match the format %q (%v)`, format, reason)
+					formatType, formatReason = TypeInteger, reason
 					formatErr = fmt.Errorf("integer doesn't match the format %q: %w", format, err)
 				}
 			}
 		} else {
 			// Check per-validation validators first, then fall back to global
-			f, ok := settings.numberFormats[format]
-			if !ok {
-				f, ok = SchemaNumberFormats[format]
-			}
-			if ok {
+			if f, ok := settings.numberFormat(schema); ok {
 				if err := f.Validate(value); err != nil {
 					var reason string
 					schemaErr := &SchemaError{}
@@ -2432,6 +2461,7 @@ func (schema *Schema) visitJSONNumber(settings *schemaValidationSettings, value
 						reason = err.Error()
 					}
 					formatStrErr = fmt.Sprintf(`number doesn't match the format %q (%v)`, format, reason)
+					formatType, formatReason = TypeNumber, reason
 					formatErr = fmt.Errorf("number doesn't match the format %q: %w", format, err)
 				}
 			}
@@ -2444,6 +2474,8 @@ func (schema *Schema) visitJSONNumber(settings *schemaValidationSettings, value
 			Schema:                schema,
 			SchemaField:           "format",
 			Reason:                formatStrErr,
+			kind:                  ErrorKindSchemaFormat,
+			params:                MessageParams{Types: []string{formatType}, Format: format, Detail: formatReason},
 			Origin:                formatErr,
 			customizeMessageError: settings.customizeMessageError,
 		}
@@ -2479,6 +2511,8 @@ func (schema *Schema) visitJSONNumber(settings *schemaValidationSettings, value
 				Schema:                schema,
 				SchemaField:           "exclusiveMinimum",
 				Reason:                fmt.Sprintf("number must be more than %g", exclusiveMinBound),
+				kind:                  ErrorKindSchemaExclusiveMinimum,
+				params:                MessageParams{Limit: exclusiveMinBound},
 				customizeMessageError: settings.customizeMessageError,
 			}
 			if !settings.multiError {
@@ -2514,6 +2548,8 @@ func (schema *Schema) visitJSONNumber(settings *schemaValidationSettings, value
 				Schema:                schema,
 				SchemaField:           "exclusiveMaximum",
 				Reason:                fmt.Sprintf("number must be less than %g", exclusiveMaxBound),
+				kind:                  ErrorKindSchemaExclusiveMaximum,
+				params:                MessageParams{Limit: exclusiveMaxBound},
 				customizeMessageError: settings.customizeMessageError,
 			}
 			if !settings.multiError {
@@ -2533,6 +2569,8 @@ func (schema *Schema) visitJSONNumber(settings *schemaValidationSettings, value
 			Schema:                schema,
 			SchemaField:           "minimum",
 			Reason:                fmt.Sprintf("number must be at least %g", *v),
+			kind:                  ErrorKindSchemaMinimum,
+			params:                MessageParams{Limit: *v},
 			customizeMessageError: settings.customizeMessageError,
 		}
 		if !settings.multiError {
@@ -2551,6 +2589,8 @@ func (schema *Schema) visitJSONNumber(settings *schemaValidationSettings, value
 			Schema:                schema,
 			SchemaField:           "maximum",
 			Reason:                fmt.Sprintf("number must be at most %g", *v),
+			kind:                  ErrorKindSchemaMaximum,
+			params:                MessageParams{Limit: *v},
 			customizeMessageError: settings.customizeMessageError,
 		}
 		if !settings.multiError {
@@ -2575,6 +2615,8 @@ func (schema *Schema) visitJSONNumber(settings *schemaValidationSettings, value
 				Schema:                schema,
 				SchemaField:           "multipleOf",
 				Reason:                fmt.Sprintf("number must be a multiple of %g", *v),
+				kind:                  ErrorKindSchemaMultipleOf,
+				params:                MessageParams{Limit: *v},
 				customizeMessageError: settings.customizeMessageError,
 			}
 			if !settings.multiError {
@@ -2625,6 +2667,8 @@ func (schema *Schema) visitJSONString(settings *schemaValidationSettings, value
 				Schema:                schema,
 				SchemaField:           "minLength",
 				Reason:                fmt.Sprintf("minimum string length is %d", minLength),
+				kind:                  ErrorKindSchemaMinLength,
+				params:                MessageParams{Limit: float64(minLength), Actual: float64(length)},
 				customizeMessageError: settings.customizeMessageError,
 			}
 			if !settings.multiError {
@@ -2641,6 +2685,8 @@ func (schema *Schema) visitJSONString(settings *schemaValidationSettings, value
 				Schema:                schema,
 				SchemaField:           "maxLength",
 				Reason:                fmt.Sprintf("maximum string length is %d", *maxLength),
+				kind:                  ErrorKindSchemaMaxLength,
+				params:                MessageParams{Limit: float64(*maxLength), Actual: float64(length)},
 				customizeMessageError: settings.customizeMessageError,
 			}
 			if !settings.multiError {
@@ -2652,23 +2698,20 @@ func (schema *Schema) visitJSONString(settings *schemaValidationSettings, value
 
 	// "pattern"
 	if !settings.patternValidationDisabled && schema.Pattern != "" {
-		cpiface, _ := compiledPatterns.Load(schema.Pattern)
-		cp, _ := cpiface.(RegexMatcher)
-		if cp == nil {
-			var err error
-			if cp, err = schema.compilePattern(settings.regexCompiler); err != nil {
-				if !settings.multiError {
-					return err
-				}
-				me = append(me, err)
+		cp, err := settings.pattern(schema)
+		if err != nil {
+			if !settings.multiError {
+				return err
 			}
-		}
-		if !cp.MatchString(value) {
+			me = append(me, err)
+		} else if !cp.MatchString(value) {
 			err := &SchemaError{
 				Value:                 value,
 				Schema:                schema,
 				SchemaField:           "pattern",
 				Reason:                fmt.Sprintf(`string doesn't match the regular expression "%s"`, schema.Pattern),
+				kind:                  ErrorKindSchemaPattern,
+				params:                MessageParams{Pattern: schema.Pattern},
 				customizeMessageError: settings.customizeMessageError,
 			}
 			if !settings.multiError {
@@ -2679,15 +2722,11 @@ func (schema *Schema) visitJSONString(settings *schemaValidationSettings, value
 	}
 
 	// "format"
-	var formatStrErr string
+	var formatStrErr, formatReason string
 	var formatErr error
 	if format := schema.Format; format != "" {
 		// Check per-validation validators first, then fall back to global
-		f, ok := settings.stringFormats[format]
-		if !ok {
-			f, ok = SchemaStringFormats[format]
-		}
-		if ok {
+		if f, ok := settings.stringFormat(schema); ok {
 			if err := f.Validate(value); err != nil {
 				var reason string
 				schemaErr := &SchemaError{}
@@ -2697,6 +2736,7 @@ func (schema *Schema) visitJSONString(settings *schemaValidationSettings, value
 					reason = err.Error()
 				}
 				formatStrErr = fmt.Sprintf(`string doesn't match the format %q (%v)`, format, reason)
+				formatReason = reason
 				formatErr = fmt.Errorf("string doesn't match the format %q: %w", format, err)
 			}
 		}
@@ -2707,6 +2747,8 @@ func (schema *Schema) visitJSONString(settings *schemaValidationSettings, value
 			Schema:                schema,
 			SchemaField:           "format",
 			Reason:                formatStrErr,
+			kind:                  ErrorKindSchemaFormat,
+			params:                MessageParams{Types: []string{TypeString}, Format: schema.Format, Detail: formatReason},
 			Origin:                formatErr,
 			customizeMessageError: settings.customizeMessageError,
 		}
@@ -2748,6 +2790,8 @@ func (schema *Schema) visitJSONArray(settings *schemaValidationSettings, value [
 			Schema:                schema,
 			SchemaField:           "minItems",
 			Reason:                fmt.Sprintf("minimum number of items is %d", v),
+			kind:                  ErrorKindSchemaMinItems,
+			params:                MessageParams{Limit: float64(v), Actual: float64(lenValue)},
 			customizeMessageError: settings.customizeMessageError,
 		}
 		if !settings.multiError {
@@ -2766,6 +2810,8 @@ func (schema *Schema) visitJSONArray(settings *schemaValidationSettings, value [
 			Schema:                schema,
 			SchemaField:           "maxItems",
 			Reason:                fmt.Sprintf("maximum number of items is %d", *v),
+			kind:                  ErrorKindSchemaMaxItems,
+			params:                MessageParams{Limit: float64(*v), Actual: float64(lenValue)},
 			customizeMessageError: settings.customizeMessageError,
 		}
 		if !settings.multiError {
@@ -2787,6 +2833,7 @@ func (schema *Schema) visitJSONArray(settings *schemaValidationSettings, value [
 			Schema:                schema,
 			SchemaField:           "uniqueItems",
 			Reason:                "duplicate items found",
+			kind:                  ErrorKindSchemaUniqueItems,
 			customizeMessageError: settings.customizeMessageError,
 		}
 		if !settings.multiError {
@@ -2844,7 +2891,10 @@ func (schema *Schema) visitJSONObject(settings *schemaValidationSettings, value
 			if f := settings.defaultsSet; f != nil && value[propName] == nil {
 				if dflt := propSchema.Value.Default; dflt != nil && !reqRO && !repWO {
 					value[propName] = dflt
-					settings.onceSettingDefaults.Do(f)
+					if !settings.defaultsSetCalled {
+						settings.defaultsSetCalled = true
+						f()
+					}
 				}
 			}
 
@@ -2872,6 +2922,8 @@ func (schema *Schema) visitJSONObject(settings *schemaValidationSettings, value
 			Schema:                schema,
 			SchemaField:           "minProperties",
 			Reason:                fmt.Sprintf("there must be at least %d properties", v),
+			kind:                  ErrorKindSchemaMinProperties,
+			params:                MessageParams{Limit: float64(v), Actual: float64(lenValue)},
 			customizeMessageError: settings.customizeMessageError,
 		}
 		if !settings.multiError {
@@ -2890,6 +2942,8 @@ func (schema *Schema) visitJSONObject(settings *schemaValidationSettings, value
 			Schema:                schema,
 			SchemaField:           "maxProperties",
 			Reason:                fmt.Sprintf("there must be at most %d properties", *v),
+			kind:                  ErrorKindSchemaMaxProperties,
+			params:                MessageParams{Limit: float64(*v), Actual: float64(lenValue)},
 			customizeMessageError: settings.customizeMessageError,
 		}
 		if !settings.multiError {
@@ -2956,6 +3010,8 @@ func (schema *Schema) visitJSONObject(settings *schemaValidationSettings, value
 			Schema:                schema,
 			SchemaField:           "properties",
 			Reason:                fmt.Sprintf("property %q is unsupported", k),
+			kind:                  ErrorKindSchemaAdditionalProperties,
+			params:                MessageParams{Property: k},
 			customizeMessageError: settings.customizeMessageError,
 		}
 		if !settings.multiError {
@@ -2981,6 +3037,8 @@ func (schema *Schema) visitJSONObject(settings *schemaValidationSettings, value
 				Schema:                schema,
 				SchemaField:           "required",
 				Reason:                fmt.Sprintf("property %q is missing", k),
+				kind:                  ErrorKindSchemaRequired,
+				params:                MessageParams{Property: k},
 				customizeMessageError: settings.customizeMessageError,
 			}, k)
 			if !settings.multiError {
@@ -3020,6 +3078,8 @@ func (schema *Schema) expectedType(settings *schemaValidationSettings, value any
 		Schema:                schema,
 		SchemaField:           "type",
 		Reason:                fmt.Sprintf("value must be %s %s", a, x),
+		kind:                  ErrorKindSchemaType,
+		params:                MessageParams{Types: schemaTypes},
 		customizeMessageError: settings.customizeMessageError,
 	}
 }
@@ -3041,6 +3101,11 @@ type SchemaError struct {
 	Origin error
 	// customizeMessageError is a function that can be used to customize the error message.
 	customizeMessageError func(err *SchemaError) string
+	// validationError is the error of the JSON Schema 2020-12 validator this error was converted from, if any.
+	validationError *jsonschema.ValidationError
+	// kind and params are those of the message of Reason
+	kind   ErrorKind
+	params MessageParams
 }
 
 var _ interface{ Unwrap() error } = SchemaError{}
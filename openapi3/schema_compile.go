@@ -0,0 +1,293 @@
+package openapi3
+
+import (
+	"encoding/json"
+	"slices"
+)
+
+// CompiledSchema validates values against a Schema compiled by Compile.
+//
+// Formats, patterns, enums and discriminator mappings of the schema and of its subschemas
+// are resolved once, by Compile, instead of on each validation.
+// A CompiledSchema is immutable and safe for concurrent use.
+type CompiledSchema struct {
+	schema   *Schema
+	settings *schemaValidationSettings
+	// jsonSchema is the JSON Schema 2020-12 validator, if enabled and the schema compiles
+	jsonSchema *jsonSchemaValidator
+}
+
+// schemaProgram is what Compile resolves for each schema it reaches.
+type schemaProgram struct {
+	nodes map[*Schema]*schemaNode
+}
+
+type schemaNode struct {
+	pattern    RegexMatcher
+	patternErr error
+
+	stringFormat  StringFormatValidator
+	numberFormat  NumberFormatValidator
+	integerFormat IntegerFormatValidator
+	hasFormat     [3]bool // string, number, integer
+
+	enum *enumSet
+
+	// Indices of the oneOf and anyOf schemas selected by each discriminator ref ("" selects all of them)
+	oneOf, anyOf map[string][]int
+}
+
+// enumSet holds the enum values that are strings, booleans, float64 numbers or null.
+type enumSet struct {
+	enum    []any
+	scalars map[any]struct{}
+}
+
+// Compile resolves, for schema and its subschemas, what validating values against them requires,
+// considering opts. Validating with the result is equivalent to Schema.VisitJSON with the same options,
+// including the errors returned.
+//
+// The schema must not be modified afterwards. Neither must format validators be defined
+// (e.g. with DefineStringFormatValidator), as those are resolved by Compile.
+func Compile(schema *Schema, opts ...SchemaValidationOption) *CompiledSchema {
+	settings := newSchemaValidationSettings(opts...)
+	c := &CompiledSchema{
+		schema:   schema,
+		settings: settings,
+	}
+	if settings.useJSONSchema2020 {
+		// As with VisitJSON, fall back to the built-in validator if compilation fails
+		c.jsonSchema, _ = newJSONSchemaValidator(schema)
+	}
+	if c.jsonSchema == nil {
+		program := &schemaProgram{nodes: make(map[*Schema]*schemaNode)}
+		program.compile(settings, schema)
+		settings.program = program
+	}
+	return c
+}
+
+// Schema returns the schema c was compiled from.
+func (c *CompiledSchema) Schema() *Schema {
+	return c.schema
+}
+
+// VisitJSON validates value, considering the options given to Compile.
+//
+// opts apply to this validation only, such as DefaultsSet or VisitAsRequest.
+// Options resolved by Compile (EnableJSONSchema2020, the regex compiler and format validators) are ignored.
+func (c *CompiledSchema) VisitJSON(value any, opts ...SchemaValidationOption) error {
+	settings := *c.settings
+	for _, opt := range opts {
+		opt(&settings)
+	}
+	if c.jsonSchema != nil {
+		return settings.localize(c.jsonSchema.validate(value))
+	}
+	return settings.localize(c.schema.visitJSON(&settings, value))
+}
+
+func (program *schemaProgram) compile(settings *schemaValidationSettings, schema *Schema) {
+	if schema == nil || program.nodes[schema] != nil {
+		return
+	}
+	node := &schemaNode{}
+	program.nodes[schema] = node
+
+	if schema.Pattern != "" {
+		node.pattern, node.patternErr = compileRegex(schema.Pattern, settings.regexCompiler)
+	}
+	if format := schema.Format; format != "" {
+		node.stringFormat, node.hasFormat[0] = settings.stringFormats[format]
+		if !node.hasFormat[0] {
+			node.stringFormat, node.hasFormat[0] = SchemaStringFormats[format]
+		}
+		node.numberFormat, node.hasFormat[1] = settings.numberFormats[format]
+		if !node.hasFormat[1] {
+			node.numberFormat, node.hasFormat[1] = SchemaNumberFormats[format]
+		}
+		node.integerFormat, node.hasFormat[2] = settings.integerFormats[format]
+		if !node.hasFormat[2] {
+			node.integerFormat, node.hasFormat[2] = SchemaIntegerFormats[format]
+		}
+	}
+	if len(schema.Enum) != 0 {
+		node.enum = newEnumSet(schema.Enum)
+	}
+	if schema.Discriminator != nil {
+		node.oneOf = discriminatedBranches(schema.OneOf)
+		node.anyOf = discriminatedBranches(schema.AnyOf)
+	}
+
+	for _, refs := range []SchemaRefs{schema.OneOf, schema.AnyOf, schema.AllOf, schema.PrefixItems} {
+		for _, ref := range refs {
+			program.compileRef(settings, ref)
+		}
+	}
+	for _, ref := range []*SchemaRef{
+		schema.Not,
+		schema.Items,
+		schema.AdditionalProperties.Schema,
+		schema.Contains,
+		schema.PropertyNames,
+		schema.UnevaluatedItems.Schema,
+		schema.UnevaluatedProperties.Schema,
+		schema.If,
+		schema.Then,
+		schema.Else,
+		schema.ContentSchema,
+	} {
+		program.compileRef(settings, ref)
+	}
+	for _, schemas := range []Schemas{schema.Properties, schema.PatternProperties, schema.DependentSchemas, schema.Defs} {
+		for _, ref := range schemas {
+			program.compileRef(settings, ref)
+		}
+	}
+}
+
+func (program *schemaProgram) compileRef(settings *schemaValidationSettings, ref *SchemaRef) {
+	if ref != nil {
+		program.compile(settings, ref.Value)
+	}
+}
+
+func newEnumSet(enum []any) *enumSet {
+	set := &enumSet{enum: enum, scalars: make(map[any]struct{}, len(enum))}
+	for _, v := range enum {
+		switch v.(type) {
+		case nil, bool, float64, string:
+			set.scalars[v] = struct{}{}
+		}
+	}
+	return set
+}
+
+// contains behaves as enumContains(set.enum, value).
+func (set *enumSet) contains(value any) (bool, error) {
+	switch c := value.(type) {
+	case nil, bool, float64, string:
+		// Values of other types cannot be equal to these
+		_, ok := set.scalars[c]
+		return ok, nil
+	case json.Number:
+		f, err := c.Float64()
+		if err != nil {
+			// Same error as enumContains
+			return enumContains(set.enum, value)
+		}
+		_, ok := set.scalars[f]
+		return ok, nil
+	case int64:
+		_, ok := set.scalars[float64(c)]
+		return ok, nil
+	}
+	return enumContains(set.enum, value)
+}
+
+// discriminatedBranches returns, for "" and each ref of refs, the indices of the schemas it selects.
+// It returns nil if some of refs are not resolved.
+func discriminatedBranches(refs SchemaRefs) map[string][]int {
+	if len(refs) == 0 || unresolvedSchemaRefs(refs) != nil {
+		return nil
+	}
+	branches := make(map[string][]int, len(refs)+1)
+	for idx, ref := range refs {
+		branches[""] = append(branches[""], idx)
+		if ref.Ref != "" {
+			branches[ref.Ref] = append(branches[ref.Ref], idx)
+		}
+	}
+	return branches
+}
+
+func (settings *schemaValidationSettings) node(schema *Schema) *schemaNode {
+	if settings.program == nil {
+		return nil
+	}
+	return settings.program.nodes[schema]
+}
+
+// pattern returns the compiled pattern of schema.
+func (settings *schemaValidationSettings) pattern(schema *Schema) (RegexMatcher, error) {
+	if node := settings.node(schema); node != nil {
+		if node.patternErr != nil {
+			return nil, schema.patternError(node.patternErr)
+		}
+		return node.pattern, nil
+	}
+	cpiface, _ := compiledPatterns.Load(schema.Pattern)
+	if cp, _ := cpiface.(RegexMatcher); cp != nil {
+		return cp, nil
+	}
+	return schema.compilePattern(settings.regexCompiler)
+}
+
+// stringFormat returns the validator of the format of schema: a per-validation one, else a global one.
+func (settings *schemaValidationSettings) stringFormat(schema *Schema) (StringFormatValidator, bool) {
+	if node := settings.node(schema); node != nil {
+		return node.stringFormat, node.hasFormat[0]
+	}
+	f, ok := settings.stringFormats[schema.Format]
+	if !ok {
+		f, ok = SchemaStringFormats[schema.Format]
+	}
+	return f, ok
+}
+
+// numberFormat returns the validator of the format of schema: a per-validation one, else a global one.
+func (settings *schemaValidationSettings) numberFormat(schema *Schema) (NumberFormatValidator, bool) {
+	if node := settings.node(schema); node != nil {
+		return node.numberFormat, node.hasFormat[1]
+	}
+	f, ok := settings.numberFormats[schema.Format]
+	if !ok {
+		f, ok = SchemaNumberFormats[schema.Format]
+	}
+	return f, ok
+}
+
+// integerFormat returns the validator of the format of schema: a per-validation one, else a global one.
+func (settings *schemaValidationSettings) integerFormat(schema *Schema) (IntegerFormatValidator, bool) {
+	if node := settings.node(schema); node != nil {
+		return node.integerFormat, node.hasFormat[2]
+	}
+	f, ok := settings.integerFormats[schema.Format]
+	if !ok {
+		f, ok = SchemaIntegerFormats[schema.Format]
+	}
+	return f, ok
+}
+
+// enumContains reports whether the enum of schema contains value.
+func (settings *schemaValidationSettings) enumContains(schema *Schema, value any) (bool, error) {
+	if node := settings.node(schema); node != nil && node.enum != nil {
+		return node.enum.contains(value)
+	}
+	return enumContains(schema.Enum, value)
+}
+
+// branches returns the indices of the oneOf (or, if anyOf, the anyOf) schemas of schema
+// selected by discriminatorRef: all of them if it is empty.
+func (settings *schemaValidationSettings) branches(schema *Schema, anyOf bool, discriminatorRef string) []int {
+	refs, node := schema.OneOf, settings.node(schema)
+	if anyOf {
+		refs = schema.AnyOf
+	}
+	if node != nil {
+		branches := node.oneOf
+		if anyOf {
+			branches = node.anyOf
+		}
+		if branches != nil {
+			return branches[discriminatorRef]
+		}
+	}
+	indices := make([]int, 0, len(refs))
+	for idx, item := range refs {
+		if discriminatorRef == "" || discriminatorRef == item.Ref {
+			indices = append(indices, idx)
+		}
+	}
+	return slices.Clip(indices)
+}
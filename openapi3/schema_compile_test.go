@@ -0,0 +1,214 @@
+package openapi3
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompileSchemas(t *testing.T) {
+	DefineStringFormatValidator("uuid", NewRegexpFormatValidator(FormatOfStringForUUIDOfRFC4122))
+
+	optionSets := [][]SchemaValidationOption{
+		nil,
+		{MultiErrors()},
+		{FailFast()},
+		{EnableFormatValidation()},
+		{VisitAsRequest()},
+		{VisitAsResponse(), MultiErrors()},
+		{DisablePatternValidation(), DisableReadOnlyValidation(), DisableWriteOnlyValidation()},
+	}
+
+	type example struct {
+		title  string
+		schema *Schema
+		values []any
+	}
+	var examples []example
+	for _, e := range schemaExamples {
+		values := append(append([]any{}, e.AllValid...), e.AllInvalid...)
+		examples = append(examples, example{e.Title, e.Schema, values})
+	}
+	for _, e := range schemaMultiErrorExamples {
+		examples = append(examples, example{e.Title, e.Schema, e.Values})
+	}
+
+	for _, e := range examples {
+		t.Run(e.title, func(t *testing.T) {
+			for i, opts := range optionSets {
+				compiled := Compile(e.schema, opts...)
+				for j, value := range e.values {
+					data, err := json.Marshal(value)
+					require.NoError(t, err)
+					var want, got any
+					require.NoError(t, json.Unmarshal(data, &want))
+					require.NoError(t, json.Unmarshal(data, &got))
+
+					wantErr := e.schema.VisitJSON(want, opts...)
+					gotErr := compiled.VisitJSON(got)
+					require.Equalf(t, wantErr, gotErr, "options #%d, value #%d: %s", i, j, data)
+					require.Equal(t, want, got)
+				}
+				for _, value := range []any{math.NaN(), math.Inf(1)} {
+					require.Equal(t, e.schema.VisitJSON(value, opts...), compiled.VisitJSON(value))
+				}
+			}
+		})
+	}
+}
+
+func TestCompileEnum(t *testing.T) {
+	schema := NewSchema().WithEnum("a", 1.0, true, nil, map[string]any{"b": "c"}, []any{2.0})
+	compiled := Compile(schema)
+
+	for _, value := range []any{
+		"a", 1.0, int64(1), json.Number("1"), json.Number("1.0"), true, nil,
+		map[string]any{"b": "c"}, []any{2.0},
+		"b", 2.0, int64(2), json.Number("2"), false, 1, map[string]any{}, []any{},
+		json.Number("x"),
+	} {
+		require.Equalf(t, schema.VisitJSON(value), compiled.VisitJSON(value), "%#v", value)
+	}
+}
+
+func TestCompileDiscriminator(t *testing.T) {
+	cat := NewObjectSchema().WithProperty("kind", NewStringSchema()).WithProperty("meow", NewBoolSchema())
+	cat.Required = []string{"meow"}
+	dog := NewObjectSchema().WithProperty("kind", NewStringSchema()).WithProperty("bark", NewBoolSchema())
+	dog.Required = []string{"bark"}
+	schema := &Schema{
+		OneOf: SchemaRefs{
+			{Ref: "#/components/schemas/Cat", Value: cat},
+			{Ref: "#/components/schemas/Dog", Value: dog},
+		},
+		Discriminator: &Discriminator{
+			PropertyName: "kind",
+			Mapping: map[string]MappingRef{
+				"cat":  {Ref: "#/components/schemas/Cat"},
+				"dog":  {Ref: "#/components/schemas/Dog"},
+				"bird": {Ref: "#/components/schemas/Bird"},
+			},
+		},
+	}
+	compiled := Compile(schema)
+
+	for _, value := range []map[string]any{
+		{"kind": "cat", "meow": true},
+		{"kind": "dog", "bark": true},
+		{"kind": "cat", "bark": true},
+		{"kind": "bird"},
+		{"kind": "fish"},
+		{"kind": 1},
+		{},
+	} {
+		require.Equalf(t, schema.VisitJSON(value), compiled.VisitJSON(value), "%v", value)
+	}
+	require.NoError(t, compiled.VisitJSON(map[string]any{"kind": "cat", "meow": true}))
+	require.Error(t, compiled.VisitJSON(map[string]any{"kind": "cat", "bark": true}))
+
+	schema.AnyOf, schema.OneOf = schema.OneOf, nil
+	compiled = Compile(schema)
+	for _, value := range []map[string]any{
+		{"kind": "cat", "meow": true},
+		{"kind": "dog", "meow": true},
+		{"kind": "bird"},
+	} {
+		require.Equalf(t, schema.VisitJSON(value), compiled.VisitJSON(value), "%v", value)
+	}
+}
+
+func TestCompilePatternError(t *testing.T) {
+	schema := NewStringSchema().WithPattern(`^\p{Foo}$`)
+	for _, opts := range [][]SchemaValidationOption{nil, {MultiErrors()}} {
+		err := Compile(schema, opts...).VisitJSON("x")
+		require.Equal(t, schema.VisitJSON("x", opts...), err)
+		var patternErr *SchemaPatternRegexError
+		require.ErrorAs(t, err, &patternErr)
+	}
+}
+
+func TestCompileRegexCompiler(t *testing.T) {
+	var compiled []string
+	compiler := func(expr string) (RegexMatcher, error) {
+		compiled = append(compiled, expr)
+		return prefixMatcher(expr), nil
+	}
+	schema := NewStringSchema().WithPattern("foo")
+	c := Compile(schema, SetSchemaRegexCompiler(compiler))
+	require.NoError(t, c.VisitJSON("foobar"))
+	require.Error(t, c.VisitJSON("barfoo"))
+	require.NoError(t, c.VisitJSON("foo"))
+	require.Equal(t, []string{"foo"}, compiled)
+}
+
+type prefixMatcher string
+
+func (m prefixMatcher) MatchString(s string) bool {
+	return len(s) >= len(m) && s[:len(m)] == string(m)
+}
+
+func TestCompileFormats(t *testing.T) {
+	schema := NewStringSchema().WithFormat("even")
+	even := NewCallbackValidator(func(s string) error {
+		if len(s)%2 != 0 {
+			return fmt.Errorf("odd length")
+		}
+		return nil
+	})
+	c := Compile(schema, WithStringFormatValidator("even", even))
+	require.NoError(t, c.VisitJSON("ab"))
+	err := c.VisitJSON("abc")
+	require.Equal(t, schema.VisitJSON("abc", WithStringFormatValidator("even", even)), err)
+	require.ErrorContains(t, err, `string doesn't match the format "even": odd length`)
+
+	// Unknown formats are ignored
+	require.NoError(t, Compile(schema).VisitJSON("abc"))
+}
+
+func TestCompileDefaultsSet(t *testing.T) {
+	schema := NewObjectSchema().WithProperty("a", NewStringSchema().WithDefault("x"))
+	c := Compile(schema, VisitAsRequest())
+	for range 2 {
+		calls := 0
+		value := map[string]any{}
+		require.NoError(t, c.VisitJSON(value, DefaultsSet(func() { calls++ })))
+		require.Equal(t, map[string]any{"a": "x"}, value)
+		require.Equal(t, 1, calls)
+	}
+}
+
+func TestCompileJSONSchema2020(t *testing.T) {
+	schema := NewObjectSchema().WithProperty("a", NewStringSchema().WithMinLength(2))
+	schema.Required = []string{"a"}
+	c := Compile(schema, EnableJSONSchema2020())
+	for _, value := range []any{
+		map[string]any{"a": "xy"},
+		map[string]any{"a": "x"},
+		map[string]any{},
+	} {
+		require.Equal(t, schema.VisitJSON(value, EnableJSONSchema2020()), c.VisitJSON(value))
+	}
+}
+
+func TestCompileConcurrent(t *testing.T) {
+	schema := NewObjectSchema().
+		WithProperty("name", NewStringSchema().WithPattern(`^[a-z]+$`).WithFormat("email")).
+		WithProperty("size", NewFloat64Schema().WithEnum(1.0, 2.0))
+	c := Compile(schema, MultiErrors(), EnableFormatValidation())
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Go(func() {
+			for range 100 {
+				value := map[string]any{"name": "A", "size": float64(i)}
+				assert.Equal(t, schema.VisitJSON(value, MultiErrors(), EnableFormatValidation()), c.VisitJSON(value))
+			}
+		})
+	}
+	wg.Wait()
+}
@@ -4,11 +4,16 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math/big"
 	"strings"
 
 	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
 )
 
+// jsonSchemaValidatorURL is the URL of the schemas compiled by the JSON Schema 2020-12 validator
+const jsonSchemaValidatorURL = "https://example.com/schema.json"
+
 // jsonSchemaValidator wraps the santhosh-tekuri/jsonschema validator
 type jsonSchemaValidator struct {
 	compiler *jsonschema.Compiler
@@ -36,7 +41,7 @@ func newJSONSchemaValidator(schema *Schema) (*jsonSchemaValidator, error) {
 	compiler.DefaultDraft(jsonschema.Draft2020)
 
 	// Add the schema
-	schemaURL := "https://example.com/schema.json"
+	schemaURL := jsonSchemaValidatorURL
 	if err := compiler.AddResource(schemaURL, schemaMap); err != nil {
 		return nil, fmt.Errorf("failed to add schema resource: %w", err)
 	}
@@ -157,7 +162,12 @@ func convertJSONSchemaError(err error) error {
 	// 	return formatValidationError(err, "")
 	var validationErr *jsonschema.ValidationError
 	if errors.As(err, &validationErr) {
-		return formatValidationError(validationErr, "")
+		err := formatValidationError(validationErr, "")
+		if schemaErr, ok := err.(*SchemaError); ok {
+			// Kept for Schema.Output
+			schemaErr.validationError = validationErr
+		}
+		return err
 	}
 	return err
 }
@@ -188,16 +198,110 @@ func formatValidationError(verr *jsonschema.ValidationError, parentPath string)
 			}
 		}
 		if len(subErrors) > 0 {
-			return &SchemaError{
+			e := &SchemaError{
 				Reason: msg.String(),
 				Origin: fmt.Errorf("validation failed due to: %w", subErrors),
 			}
+			e.kind, e.params = jsonSchemaErrorMessage(verr, e.Reason)
+			return e
 		}
 	}
 
-	return &SchemaError{
+	e := &SchemaError{
 		Reason: msg.String(),
 	}
+	e.kind, e.params = jsonSchemaErrorMessage(verr, e.Reason)
+	return e
+}
+
+// jsonSchemaErrorMessage returns the kind and message parameters of verr, with reason its message.
+func jsonSchemaErrorMessage(verr *jsonschema.ValidationError, reason string) (ErrorKind, MessageParams) {
+	rat := func(r *big.Rat) float64 {
+		f, _ := r.Float64()
+		return f
+	}
+	switch k := verr.ErrorKind.(type) {
+	case *kind.Type:
+		return ErrorKindSchemaType, MessageParams{Types: k.Want}
+	case *kind.Enum:
+		return ErrorKindSchemaEnum, MessageParams{Allowed: k.Want}
+	case *kind.Const:
+		return ErrorKindSchemaConst, MessageParams{Allowed: []any{k.Want}}
+	case *kind.Not:
+		return ErrorKindSchemaNot, MessageParams{}
+	case *kind.OneOf:
+		if len(k.Subschemas) > 1 {
+			return ErrorKindSchemaOneOfConflict, MessageParams{Indices: k.Subschemas}
+		}
+		return ErrorKindSchemaOneOf, MessageParams{}
+	case *kind.AnyOf:
+		return ErrorKindSchemaAnyOf, MessageParams{}
+	case *kind.AllOf:
+		return ErrorKindSchemaAllOf, MessageParams{}
+	case *kind.Format:
+		detail := ""
+		if k.Err != nil {
+			detail = k.Err.Error()
+		}
+		return ErrorKindSchemaFormat, MessageParams{Types: []string{jsonSchemaType(k.Got)}, Format: k.Want, Detail: detail}
+	case *kind.ExclusiveMinimum:
+		return ErrorKindSchemaExclusiveMinimum, MessageParams{Limit: rat(k.Want)}
+	case *kind.ExclusiveMaximum:
+		return ErrorKindSchemaExclusiveMaximum, MessageParams{Limit: rat(k.Want)}
+	case *kind.Minimum:
+		return ErrorKindSchemaMinimum, MessageParams{Limit: rat(k.Want)}
+	case *kind.Maximum:
+		return ErrorKindSchemaMaximum, MessageParams{Limit: rat(k.Want)}
+	case *kind.MultipleOf:
+		return ErrorKindSchemaMultipleOf, MessageParams{Limit: rat(k.Want)}
+	case *kind.MinLength:
+		return ErrorKindSchemaMinLength, MessageParams{Limit: float64(k.Want), Actual: float64(k.Got)}
+	case *kind.MaxLength:
+		return ErrorKindSchemaMaxLength, MessageParams{Limit: float64(k.Want), Actual: float64(k.Got)}
+	case *kind.Pattern:
+		return ErrorKindSchemaPattern, MessageParams{Pattern: k.Want}
+	case *kind.MinItems:
+		return ErrorKindSchemaMinItems, MessageParams{Limit: float64(k.Want), Actual: float64(k.Got)}
+	case *kind.MaxItems:
+		return ErrorKindSchemaMaxItems, MessageParams{Limit: float64(k.Want), Actual: float64(k.Got)}
+	case *kind.UniqueItems:
+		return ErrorKindSchemaUniqueItems, MessageParams{}
+	case *kind.MinProperties:
+		return ErrorKindSchemaMinProperties, MessageParams{Limit: float64(k.Want), Actual: float64(k.Got)}
+	case *kind.MaxProperties:
+		return ErrorKindSchemaMaxProperties, MessageParams{Limit: float64(k.Want), Actual: float64(k.Got)}
+	case *kind.AdditionalProperties:
+		if len(k.Properties) == 1 {
+			return ErrorKindSchemaAdditionalProperties, MessageParams{Property: k.Properties[0]}
+		}
+	case *kind.Required:
+		if len(k.Missing) == 1 {
+			return ErrorKindSchemaRequired, MessageParams{Property: k.Missing[0]}
+		}
+	}
+	var keyword string
+	if path := verr.ErrorKind.KeywordPath(); len(path) != 0 {
+		keyword = path[0]
+	}
+	return ErrorKindSchemaKeyword, MessageParams{Keyword: keyword, Detail: reason}
+}
+
+// jsonSchemaType returns the JSON type of a value decoded by the JSON Schema 2020-12 validator.
+func jsonSchemaType(value any) string {
+	switch value.(type) {
+	case nil:
+		return TypeNull
+	case bool:
+		return TypeBoolean
+	case json.Number, float32, float64, int, int32, int64:
+		return TypeNumber
+	case string:
+		return TypeString
+	case []any:
+		return TypeArray
+	default:
+		return TypeObject
+	}
 }
 
 // useJSONSchema2020 validates using the JSON Schema 2020-12 validator
@@ -0,0 +1,334 @@
+package openapi3
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// OutputFormat is a JSON Schema 2020-12 output format.
+// See https://json-schema.org/draft/2020-12/json-schema-core#name-output-formatting
+type OutputFormat string
+
+const (
+	// OutputFlag is a bare {"valid": false}.
+	OutputFlag OutputFormat = "flag"
+	// OutputBasic is a flat list of the failing keywords.
+	OutputBasic OutputFormat = "basic"
+	// OutputDetailed is the hierarchy of the failing keywords, following the schema,
+	// without the units having a single child.
+	OutputDetailed OutputFormat = "detailed"
+	// OutputVerbose is the hierarchy of the failing keywords, following the schema.
+	OutputVerbose OutputFormat = "verbose"
+)
+
+// OutputUnit is a unit of the JSON Schema 2020-12 output formats.
+type OutputUnit struct {
+	Valid bool `json:"valid" yaml:"valid"`
+	// KeywordLocation is the JSON pointer of the keyword within the schema validated against,
+	// crossing "$ref"s (e.g. "/properties/pet/$ref/properties/name/pattern").
+	KeywordLocation string `json:"keywordLocation" yaml:"keywordLocation"`
+	// AbsoluteKeywordLocation is the location of the keyword after the last "$ref" crossed
+	// (e.g. "#/components/schemas/Pet/properties/name/pattern"), if any.
+	AbsoluteKeywordLocation string `json:"absoluteKeywordLocation,omitempty" yaml:"absoluteKeywordLocation,omitempty"`
+	// InstanceLocation is the JSON pointer of the value within the value validated.
+	InstanceLocation string        `json:"instanceLocation" yaml:"instanceLocation"`
+	Error            string        `json:"error,omitempty" yaml:"error,omitempty"`
+	Errors           []*OutputUnit `json:"errors,omitempty" yaml:"errors,omitempty"`
+
+	// flag is set on OutputFlag results, which only have Valid
+	flag bool
+}
+
+// MarshalYAML returns the YAML encoding of unit.
+func (unit OutputUnit) MarshalYAML() (any, error) {
+	m := map[string]any{"valid": unit.Valid}
+	if unit.flag {
+		return m, nil
+	}
+	m["keywordLocation"] = unit.KeywordLocation
+	m["instanceLocation"] = unit.InstanceLocation
+	if x := unit.AbsoluteKeywordLocation; x != "" {
+		m["absoluteKeywordLocation"] = x
+	}
+	if x := unit.Error; x != "" {
+		m["error"] = x
+	}
+	if x := unit.Errors; len(x) != 0 {
+		m["errors"] = x
+	}
+	return m, nil
+}
+
+// MarshalJSON returns the JSON encoding of unit.
+func (unit OutputUnit) MarshalJSON() ([]byte, error) {
+	x, err := unit.MarshalYAML()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(x)
+}
+
+// Output returns err, as returned by validating a value against schema (e.g. with VisitJSON), in format.
+// A nil err gives a valid result.
+//
+// Pass the MultiErrors option when validating to list every failing keyword.
+func (schema *Schema) Output(err error, format OutputFormat) *OutputUnit {
+	if format == OutputFlag {
+		return &OutputUnit{Valid: err == nil, flag: true}
+	}
+	root := &OutputUnit{Valid: err == nil}
+	if err == nil {
+		return root
+	}
+
+	var verr *SchemaError
+	if errors.As(err, &verr) && verr.validationError != nil {
+		// From the JSON Schema 2020-12 validator
+		root = newOutputUnit(*verr.validationError.DetailedOutput())
+	} else {
+		b := outputBuilder{nodes: make(map[[2]string]*OutputUnit)}
+		b.add(root, schema, err, "")
+	}
+
+	switch format {
+	case OutputBasic:
+		flat := &OutputUnit{KeywordLocation: root.KeywordLocation, InstanceLocation: root.InstanceLocation}
+		flattenOutputUnit(flat, root)
+		return flat
+	case OutputDetailed:
+		collapseOutputUnit(root)
+	}
+	return root
+}
+
+// newOutputUnit converts a unit of the JSON Schema 2020-12 validator.
+func newOutputUnit(u jsonschema.OutputUnit) *OutputUnit {
+	unit := &OutputUnit{
+		Valid:                   u.Valid,
+		KeywordLocation:         u.KeywordLocation,
+		AbsoluteKeywordLocation: strings.TrimPrefix(u.AbsoluteKeywordLocation, jsonSchemaValidatorURL),
+		InstanceLocation:        u.InstanceLocation,
+	}
+	if u.Error != nil {
+		unit.Error = u.Error.String()
+	}
+	for _, e := range u.Errors {
+		unit.Errors = append(unit.Errors, newOutputUnit(e))
+	}
+	return unit
+}
+
+// flattenOutputUnit appends to flat the units of unit having an error message.
+func flattenOutputUnit(flat, unit *OutputUnit) {
+	for _, e := range unit.Errors {
+		if e.Error != "" {
+			leaf := *e
+			leaf.Errors = nil
+			flat.Errors = append(flat.Errors, &leaf)
+		}
+		flattenOutputUnit(flat, e)
+	}
+}
+
+// collapseOutputUnit replaces the units below unit that have no error message and a single child with that child.
+func collapseOutputUnit(unit *OutputUnit) {
+	for i, e := range unit.Errors {
+		for e.Error == "" && len(e.Errors) == 1 {
+			e = e.Errors[0]
+		}
+		collapseOutputUnit(e)
+		unit.Errors[i] = e
+	}
+}
+
+// outputBuilder builds the verbose output of the errors of the built-in validator.
+type outputBuilder struct {
+	// nodes holds the units of subschemas, by keyword and instance locations
+	nodes map[[2]string]*OutputUnit
+}
+
+// outputHop is a step from a schema to one of its subschemas.
+type outputHop struct {
+	keyword  []string
+	ref      string
+	instance string
+	consumes bool
+}
+
+// add adds to unit, the unit of schema, the units of err.
+// Unless applicator is empty, err is an error of one of the subschemas of this applicator keyword (e.g. "oneOf").
+func (b *outputBuilder) add(unit *OutputUnit, schema *Schema, err error, applicator string) {
+	if me, ok := err.(MultiError); ok {
+		for _, e := range me {
+			b.add(unit, schema, e, applicator)
+		}
+		return
+	}
+
+	e, ok := err.(*SchemaError)
+	if !ok || e.Schema == nil {
+		unit.Errors = append(unit.Errors, &OutputUnit{
+			KeywordLocation:  unit.KeywordLocation,
+			InstanceLocation: unit.InstanceLocation,
+			Error:            err.Error(),
+		})
+		return
+	}
+
+	keyword, instance := e.SchemaField, e.JSONPointer()
+	switch keyword {
+	case "required":
+		// The pointer is that of the missing property
+		if len(instance) != 0 {
+			instance = instance[:len(instance)-1]
+		}
+	case "properties":
+		// Unsupported property
+		keyword = "additionalProperties"
+	}
+
+	depth := len(splitJSONPointer(unit.InstanceLocation))
+	if depth > len(instance) {
+		depth = len(instance)
+	}
+	if hops, ok := findOutputHops(schema, e.Schema, instance[depth:], applicator); ok {
+		for _, hop := range hops {
+			unit = b.node(unit, hop)
+		}
+	}
+
+	leaf := &OutputUnit{
+		KeywordLocation:         unit.KeywordLocation + "/" + keyword,
+		AbsoluteKeywordLocation: appendPointer(unit.AbsoluteKeywordLocation, keyword),
+		InstanceLocation:        unit.InstanceLocation,
+		Error:                   e.message(),
+	}
+	unit.Errors = append(unit.Errors, leaf)
+
+	switch me := errors.Unwrap(e.Origin).(type) {
+	case multiErrorForOneOf:
+		b.add(leaf, e.Schema, MultiError(me), keyword)
+	case multiErrorForAllOf:
+		b.add(leaf, e.Schema, MultiError(me), keyword)
+	}
+}
+
+// node returns the child of unit reached with hop, adding it if needed.
+func (b *outputBuilder) node(unit *OutputUnit, hop outputHop) *OutputUnit {
+	child := &OutputUnit{
+		KeywordLocation:  unit.KeywordLocation,
+		InstanceLocation: unit.InstanceLocation,
+	}
+	for _, token := range hop.keyword {
+		child.KeywordLocation += "/" + escapeRefString(token)
+	}
+	child.AbsoluteKeywordLocation = appendPointer(unit.AbsoluteKeywordLocation, hop.keyword...)
+	if hop.ref != "" {
+		child.KeywordLocation += "/$ref"
+		child.AbsoluteKeywordLocation = hop.ref
+		if !strings.Contains(hop.ref, "#") {
+			child.AbsoluteKeywordLocation += "#"
+		}
+	}
+	if hop.consumes {
+		child.InstanceLocation += "/" + escapeRefString(hop.instance)
+	}
+
+	key := [2]string{child.KeywordLocation, child.InstanceLocation}
+	if existing := b.nodes[key]; existing != nil {
+		return existing
+	}
+	b.nodes[key] = child
+	unit.Errors = append(unit.Errors, child)
+	return child
+}
+
+// findOutputHops returns the hops from schema to target, following instance as the built-in validator does.
+// Unless applicator is empty, the first hop is to one of the subschemas of this applicator keyword.
+func findOutputHops(schema, target *Schema, instance []string, applicator string) ([]outputHop, bool) {
+	if applicator != "" {
+		refs := schema.OneOf
+		if applicator == "allOf" {
+			refs = schema.AllOf
+		}
+		for i, ref := range refs {
+			if ref.Value == nil {
+				continue
+			}
+			// From the unit of the applicator keyword
+			hop := outputHop{keyword: []string{strconv.Itoa(i)}, ref: ref.Ref}
+			if hops, ok := findOutputHops(ref.Value, target, instance, ""); ok {
+				return append([]outputHop{hop}, hops...), true
+			}
+		}
+		return nil, false
+	}
+
+	if len(instance) == 0 {
+		return nil, schema == target
+	}
+	token := instance[0]
+	var next []outputHop
+	if ref := schema.Properties[token]; ref != nil {
+		next = append(next, outputHop{keyword: []string{"properties", token}, ref: ref.Ref})
+	} else if schema.AdditionalProperties.Schema != nil {
+		next = append(next, outputHop{keyword: []string{"additionalProperties"}, ref: schema.AdditionalProperties.Schema.Ref})
+	}
+	if schema.Items != nil {
+		if _, err := strconv.Atoi(token); err == nil {
+			next = append(next, outputHop{keyword: []string{"items"}, ref: schema.Items.Ref})
+		}
+	}
+	for _, hop := range next {
+		hop.instance, hop.consumes = token, true
+		sub := schema.Items
+		switch hop.keyword[0] {
+		case "properties":
+			sub = schema.Properties[token]
+		case "additionalProperties":
+			sub = schema.AdditionalProperties.Schema
+		}
+		if sub.Value == nil {
+			continue
+		}
+		if hops, ok := findOutputHops(sub.Value, target, instance[1:], ""); ok {
+			return append([]outputHop{hop}, hops...), true
+		}
+	}
+	return nil, false
+}
+
+// message returns the error message of err, without its location.
+func (err *SchemaError) message() string {
+	if err.customizeMessageError != nil {
+		if msg := err.customizeMessageError(err); msg != "" {
+			return msg
+		}
+	}
+	if err.Reason != "" {
+		return err.Reason
+	}
+	return `doesn't match schema "` + err.SchemaField + `"`
+}
+
+// appendPointer appends tokens to pointer, unless it is empty.
+func appendPointer(pointer string, tokens ...string) string {
+	if pointer == "" {
+		return ""
+	}
+	for _, token := range tokens {
+		pointer += "/" + escapeRefString(token)
+	}
+	return pointer
+}
+
+func splitJSONPointer(pointer string) []string {
+	if pointer == "" {
+		return nil
+	}
+	return strings.Split(pointer[1:], "/")
+}
@@ -0,0 +1,219 @@
+package openapi3
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaOutput(t *testing.T) {
+	doc, err := NewLoader().LoadFromData([]byte(`
+openapi: 3.0.3
+info:
+  title: Pets
+  version: 1.0.0
+paths: {}
+components:
+  schemas:
+    Pet:
+      type: object
+      required: [name, id]
+      properties:
+        id:
+          type: integer
+        name:
+          type: string
+          pattern: '^[A-Z]'
+        tags:
+          type: array
+          items:
+            $ref: '#/components/schemas/Tag'
+        kind:
+          oneOf:
+            - type: integer
+            - $ref: '#/components/schemas/Tag'
+      additionalProperties: false
+    Tag:
+      type: string
+      maxLength: 2
+`))
+	require.NoError(t, err)
+	schema := doc.Components.Schemas["Pet"].Value
+
+	output := func(value any, format OutputFormat) string {
+		out := schema.Output(schema.VisitJSON(value, MultiErrors()), format)
+		data, err := json.Marshal(out)
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	valid := map[string]any{"id": 1, "name": "Rex", "tags": []any{"ab"}}
+	require.JSONEq(t, `{"valid":true}`, output(valid, OutputFlag))
+	require.JSONEq(t, `{"valid":true,"keywordLocation":"","instanceLocation":""}`, output(valid, OutputBasic))
+	require.JSONEq(t, `{"valid":true,"keywordLocation":"","instanceLocation":""}`, output(valid, OutputVerbose))
+
+	invalid := map[string]any{"id": 1, "name": "rex", "tags": []any{"abc", "x"}, "foo": 1, "kind": "xyz"}
+	require.JSONEq(t, `{"valid":false}`, output(invalid, OutputFlag))
+
+	require.JSONEq(t, `{
+  "valid": false,
+  "keywordLocation": "",
+  "instanceLocation": "",
+  "errors": [
+    {
+      "valid": false,
+      "keywordLocation": "/additionalProperties",
+      "instanceLocation": "",
+      "error": "property \"foo\" is unsupported"
+    },
+    {
+      "valid": false,
+      "keywordLocation": "/properties/kind/oneOf",
+      "instanceLocation": "/kind",
+      "error": "value doesn't match any schema from \"oneOf\""
+    },
+    {
+      "valid": false,
+      "keywordLocation": "/properties/kind/oneOf/0/type",
+      "instanceLocation": "/kind",
+      "error": "value must be an integer"
+    },
+    {
+      "valid": false,
+      "keywordLocation": "/properties/kind/oneOf/1/$ref/maxLength",
+      "absoluteKeywordLocation": "#/components/schemas/Tag/maxLength",
+      "instanceLocation": "/kind",
+      "error": "maximum string length is 2"
+    },
+    {
+      "valid": false,
+      "keywordLocation": "/properties/name/pattern",
+      "instanceLocation": "/name",
+      "error": "string doesn't match the regular expression \"^[A-Z]\""
+    },
+    {
+      "valid": false,
+      "keywordLocation": "/properties/tags/items/$ref/maxLength",
+      "absoluteKeywordLocation": "#/components/schemas/Tag/maxLength",
+      "instanceLocation": "/tags/0",
+      "error": "maximum string length is 2"
+    }
+  ]
+}`, output(invalid, OutputBasic))
+
+	require.JSONEq(t, `{
+  "valid": false,
+  "keywordLocation": "",
+  "instanceLocation": "",
+  "errors": [
+    {
+      "valid": false,
+      "keywordLocation": "/additionalProperties",
+      "instanceLocation": "",
+      "error": "property \"foo\" is unsupported"
+    },
+    {
+      "valid": false,
+      "keywordLocation": "/properties/kind/oneOf",
+      "instanceLocation": "/kind",
+      "error": "value doesn't match any schema from \"oneOf\"",
+      "errors": [
+        {
+          "valid": false,
+          "keywordLocation": "/properties/kind/oneOf/0/type",
+          "instanceLocation": "/kind",
+          "error": "value must be an integer"
+        },
+        {
+          "valid": false,
+          "keywordLocation": "/properties/kind/oneOf/1/$ref/maxLength",
+          "absoluteKeywordLocation": "#/components/schemas/Tag/maxLength",
+          "instanceLocation": "/kind",
+          "error": "maximum string length is 2"
+        }
+      ]
+    },
+    {
+      "valid": false,
+      "keywordLocation": "/properties/name/pattern",
+      "instanceLocation": "/name",
+      "error": "string doesn't match the regular expression \"^[A-Z]\""
+    },
+    {
+      "valid": false,
+      "keywordLocation": "/properties/tags/items/$ref/maxLength",
+      "absoluteKeywordLocation": "#/components/schemas/Tag/maxLength",
+      "instanceLocation": "/tags/0",
+      "error": "maximum string length is 2"
+    }
+  ]
+}`, output(invalid, OutputDetailed))
+
+	require.JSONEq(t, `{
+  "valid": false,
+  "keywordLocation": "",
+  "instanceLocation": "",
+  "errors": [
+    {
+      "valid": false,
+      "keywordLocation": "/properties/tags",
+      "instanceLocation": "/tags",
+      "errors": [
+        {
+          "valid": false,
+          "keywordLocation": "/properties/tags/items/$ref",
+          "absoluteKeywordLocation": "#/components/schemas/Tag",
+          "instanceLocation": "/tags/0",
+          "errors": [
+            {
+              "valid": false,
+              "keywordLocation": "/properties/tags/items/$ref/maxLength",
+              "absoluteKeywordLocation": "#/components/schemas/Tag/maxLength",
+              "instanceLocation": "/tags/0",
+              "error": "maximum string length is 2"
+            }
+          ]
+        }
+      ]
+    }
+  ]
+}`, output(map[string]any{"id": 1, "name": "Rex", "tags": []any{"abc"}}, OutputVerbose))
+
+	// Units of "required" are located at the object
+	require.JSONEq(t, `{
+  "valid": false,
+  "keywordLocation": "",
+  "instanceLocation": "",
+  "errors": [
+    {
+      "valid": false,
+      "keywordLocation": "/required",
+      "instanceLocation": "",
+      "error": "property \"id\" is missing"
+    }
+  ]
+}`, output(map[string]any{"name": "Rex"}, OutputBasic))
+}
+
+func TestSchemaOutputJSONSchema2020(t *testing.T) {
+	schema := NewObjectSchema().WithProperty("a", NewStringSchema().WithMaxLength(1))
+	err := schema.VisitJSON(map[string]any{"a": "abc"}, EnableJSONSchema2020())
+	require.Error(t, err)
+
+	data, err := json.Marshal(schema.Output(err, OutputBasic))
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+  "valid": false,
+  "keywordLocation": "",
+  "instanceLocation": "",
+  "errors": [
+    {
+      "valid": false,
+      "keywordLocation": "/properties/a/maxLength",
+      "instanceLocation": "/a",
+      "error": "maxLength: got 3, want 1"
+    }
+  ]
+}`, string(data))
+}
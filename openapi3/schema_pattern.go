@@ -15,22 +15,32 @@ func intoGoRegexp(re string) string {
 // NOTE: racey WRT [writes to schema.Pattern] vs [reads schema.Pattern then writes to compiledPatterns]
 func (schema *Schema) compilePattern(c RegexCompilerFunc) (cp RegexMatcher, err error) {
 	pattern := schema.Pattern
-	if c != nil {
-		cp, err = c(pattern)
-	} else {
-		cp, err = regexp.Compile(intoGoRegexp(pattern))
-	}
-	if err != nil {
-		schemaErr := &SchemaError{
-			Schema:      schema,
-			SchemaField: "pattern",
-			Origin:      err,
-			Reason:      fmt.Sprintf("cannot compile pattern %q: %v", pattern, err),
-		}
-		err = newSchemaPatternRegexError(pattern, schemaErr, schema.Origin)
+	if cp, err = compileRegex(pattern, c); err != nil {
+		err = schema.patternError(err)
 		return
 	}
 
 	compiledPatterns.Store(pattern, cp)
 	return
 }
+
+func compileRegex(pattern string, c RegexCompilerFunc) (RegexMatcher, error) {
+	if c != nil {
+		return c(pattern)
+	}
+	return regexp.Compile(intoGoRegexp(pattern))
+}
+
+// patternError returns the error of a pattern that failed to compile.
+func (schema *Schema) patternError(err error) error {
+	pattern := schema.Pattern
+	schemaErr := &SchemaError{
+		Schema:      schema,
+		SchemaField: "pattern",
+		Origin:      err,
+		Reason:      fmt.Sprintf("cannot compile pattern %q: %v", pattern, err),
+		kind:        ErrorKindSchemaPatternInvalid,
+		params:      MessageParams{Pattern: pattern, Detail: err.Error()},
+	}
+	return newSchemaPatternRegexError(pattern, schemaErr, schema.Origin)
+}
@@ -1,7 +1,7 @@
 package openapi3
 
 import (
-	"sync"
+	"maps"
 )
 
 // SchemaValidationOption describes options a user has when validating request / response bodies.
@@ -25,11 +25,21 @@ type schemaValidationSettings struct {
 
 	regexCompiler RegexCompilerFunc
 
-	onceSettingDefaults sync.Once
-	defaultsSet         func()
+	defaultsSet       func()
+	defaultsSetCalled bool
+
+	// program holds what Compile resolved ahead of validation, or nil
+	program *schemaProgram
 
 	customizeMessageError func(err *SchemaError) string
 
+	// schemaVisited, if set, is called after each schema and subschema a value is validated against
+	schemaVisited func(schema *Schema, value any, err error)
+
+	// messageCatalog, if set, localizes the reasons of schema errors in locale
+	messageCatalog *MessageCatalog
+	locale         string
+
 	// Per-validation format validators (checked before global ones)
 	stringFormats  map[string]StringFormatValidator
 	numberFormats  map[string]NumberFormatValidator
@@ -84,6 +94,14 @@ func SetSchemaErrorMessageCustomizer(f func(err *SchemaError) string) SchemaVali
 	return func(s *schemaValidationSettings) { s.customizeMessageError = f }
 }
 
+// SchemaVisited calls f after validating a value against a schema or any of its subschemas, with the error
+// of that validation, e.g. to record which parts of a document are exercised.
+// The oneOf and anyOf subschemas a value does not match are reported with their errors.
+// f is not called when validating with EnableJSONSchema2020.
+func SchemaVisited(f func(schema *Schema, value any, err error)) SchemaValidationOption {
+	return func(s *schemaValidationSettings) { s.schemaVisited = f }
+}
+
 // SetSchemaRegexCompiler allows to override the regex implementation used to validate field "pattern".
 func SetSchemaRegexCompiler(c RegexCompilerFunc) SchemaValidationOption {
 	return func(s *schemaValidationSettings) { s.regexCompiler = c }
@@ -103,6 +121,8 @@ func WithStringFormatValidators(validators map[string]StringFormatValidator) Sch
 // different validations for the same format name across different specs.
 func WithStringFormatValidator(name string, validator StringFormatValidator) SchemaValidationOption {
 	return func(s *schemaValidationSettings) {
+		// Copy so as not to modify maps given to WithStringFormatValidators or shared by compiled settings
+		s.stringFormats = maps.Clone(s.stringFormats)
 		if s.stringFormats == nil {
 			s.stringFormats = make(map[string]StringFormatValidator)
 		}
@@ -124,6 +144,8 @@ func WithNumberFormatValidators(validators map[string]NumberFormatValidator) Sch
 // different validations for the same format name across different specs.
 func WithNumberFormatValidator(name string, validator NumberFormatValidator) SchemaValidationOption {
 	return func(s *schemaValidationSettings) {
+		// Copy so as not to modify maps given to WithNumberFormatValidators or shared by compiled settings
+		s.numberFormats = maps.Clone(s.numberFormats)
 		if s.numberFormats == nil {
 			s.numberFormats = make(map[string]NumberFormatValidator)
 		}
@@ -145,6 +167,8 @@ func WithIntegerFormatValidators(validators map[string]IntegerFormatValidator) S
 // different validations for the same format name across different specs.
 func WithIntegerFormatValidator(name string, validator IntegerFormatValidator) SchemaValidationOption {
 	return func(s *schemaValidationSettings) {
+		// Copy so as not to modify maps given to WithIntegerFormatValidators or shared by compiled settings
+		s.integerFormats = maps.Clone(s.integerFormats)
 		if s.integerFormats == nil {
 			s.integerFormats = make(map[string]IntegerFormatValidator)
 		}
@@ -166,3 +190,11 @@ func newSchemaValidationSettings(opts ...SchemaValidationOption) *schemaValidati
 	}
 	return settings
 }
+
+// unvisited runs f, which visits schemas again, without calling schemaVisited.
+func (settings *schemaValidationSettings) unvisited(f func()) {
+	visited := settings.schemaVisited
+	settings.schemaVisited = nil
+	defer func() { settings.schemaVisited = visited }()
+	f()
+}
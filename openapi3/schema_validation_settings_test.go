@@ -34,3 +34,45 @@ components:
 
 	// Output: field "Some field" should be string
 }
+
+func ExampleSchemaVisited() {
+	loader := openapi3.NewLoader()
+	spc := `
+components:
+  schemas:
+    Pet:
+      oneOf:
+        - type: object
+          title: Cat
+          required: [meows]
+          properties:
+            meows:
+              type: boolean
+        - type: object
+          title: Dog
+          required: [barks]
+          properties:
+            barks:
+              type: boolean
+`[1:]
+
+	doc, err := loader.LoadFromData([]byte(spc))
+	if err != nil {
+		panic(err)
+	}
+
+	opt := openapi3.SchemaVisited(func(schema *openapi3.Schema, value any, err error) {
+		if schema.Title != "" {
+			fmt.Printf("%s: %v\n", schema.Title, err == nil)
+		}
+	})
+
+	err = doc.Components.Schemas["Pet"].Value.VisitJSON(map[string]any{"barks": true}, opt)
+
+	fmt.Println(err)
+
+	// Output:
+	// Cat: false
+	// Dog: true
+	// <nil>
+}
@@ -60,7 +60,7 @@ type schemaWalker struct {
 }
 
 // escapeRefString escapes a single JSON Pointer reference token per RFC 6901:
-// '~' becomes '~0' and '/' becomes '~1'. It is the inverse of unescapeRefString.
+// '~' becomes '~0' and '/' becomes '~1'. It is the inverse of refs.UnescapeRefString.
 func escapeRefString(s string) string {
 	if !strings.ContainsAny(s, "~/") {
 		return s
@@ -0,0 +1,377 @@
+package openapi3auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// KeySet holds the public keys of a JSON Web Key Set (RFC 7517) verifying JWT signatures.
+//
+// RSA, EC (P-256, P-384, P-521), OKP (Ed25519) and symmetric ("oct") keys are supported.
+// Other keys and encryption keys are ignored.
+type KeySet struct {
+	keys []*jsonWebKey
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Use string `json:"use,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+	K   string `json:"k,omitempty"`
+
+	key any
+}
+
+// LoadKeySet reads the JSON Web Key Set of the file at path.
+func LoadKeySet(path string) (*KeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseKeySet(data)
+}
+
+// ParseKeySet parses a JSON Web Key Set.
+func ParseKeySet(data []byte) (*KeySet, error) {
+	var jwks struct {
+		Keys []*jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &jwks); err != nil {
+		return nil, fmt.Errorf("invalid JWKS: %w", err)
+	}
+	ks := &KeySet{}
+	for i, jwk := range jwks.Keys {
+		if jwk == nil || jwk.Use == "enc" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %d of JWKS: %w", i, err)
+		}
+		if key == nil {
+			continue
+		}
+		jwk.key = key
+		ks.keys = append(ks.keys, jwk)
+	}
+	return ks, nil
+}
+
+// Len returns the number of keys of the set.
+func (ks *KeySet) Len() int {
+	return len(ks.keys)
+}
+
+func (jwk *jsonWebKey) publicKey() (any, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decodeSegment(jwk.N)
+		if err != nil || len(n) == 0 {
+			return nil, errors.New(`invalid RSA modulus "n"`)
+		}
+		e, err := decodeSegment(jwk.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, errors.New(`invalid RSA exponent "e"`)
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, nil
+		}
+		size := (curve.Params().BitSize + 7) / 8
+		x, err := decodeSegment(jwk.X)
+		if err != nil || len(x) != size {
+			return nil, errors.New(`invalid EC coordinate "x"`)
+		}
+		y, err := decodeSegment(jwk.Y)
+		if err != nil || len(y) != size {
+			return nil, errors.New(`invalid EC coordinate "y"`)
+		}
+		return ecdsa.ParseUncompressedPublicKey(curve, slices.Concat([]byte{4}, x, y))
+	case "OKP":
+		if jwk.Crv != "Ed25519" {
+			return nil, nil
+		}
+		x, err := decodeSegment(jwk.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New(`invalid Ed25519 key "x"`)
+		}
+		return ed25519.PublicKey(x), nil
+	case "oct":
+		k, err := decodeSegment(jwk.K)
+		if err != nil || len(k) == 0 {
+			return nil, errors.New(`invalid symmetric key "k"`)
+		}
+		return k, nil
+	default:
+		return nil, nil
+	}
+}
+
+// JWTOptions are the claims checks of JWTVerifier.
+type JWTOptions struct {
+	// Issuer, if set, must be the "iss" claim of tokens.
+	Issuer string
+
+	// Audience, if set, must be the "aud" claim of tokens, or one of them.
+	Audience string
+
+	// Leeway tolerates clock skew when checking the "exp" and "nbf" claims.
+	Leeway time.Duration
+
+	// AllowMissingExpiration accepts tokens without an "exp" claim, which never expire.
+	AllowMissingExpiration bool
+
+	// Now returns the current time. It defaults to time.Now.
+	Now func() time.Time
+}
+
+// JWTVerifier returns a BearerVerifier of JSON Web Tokens (RFC 7519) signed with a key of keys.
+//
+// Tokens must be unexpired JWS compact serializations with an RS*, PS*, ES*, EdDSA or HS* signature
+// matching a key of the set (the one of their "kid" header, if any). They must have an "exp" claim
+// (unless options.AllowMissingExpiration) and no "crit" header, as no extension is supported. options may be nil.
+//
+// The principal of a token has its "sub" claim as subject and its "scope" (space-separated)
+// or "scp" claim as scopes, checked by Bearer against the scopes of OAuth2 and OpenID Connect requirements.
+func JWTVerifier(keys *KeySet, options *JWTOptions) BearerVerifier {
+	if options == nil {
+		options = &JWTOptions{}
+	}
+	return func(ctx context.Context, token string) (*Principal, error) {
+		claims, err := keys.verify(token)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidCredentials, err)
+		}
+		if err := options.checkClaims(claims); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidCredentials, err)
+		}
+		principal := &Principal{Claims: claims}
+		principal.Subject, _ = claims["sub"].(string)
+		switch scopes := claims["scope"].(type) {
+		case string:
+			principal.Scopes = strings.Fields(scopes)
+		default:
+			principal.Scopes = stringsClaim(claims["scp"])
+		}
+		return principal, nil
+	}
+}
+
+// verify checks the signature of token and returns its claims.
+func (ks *KeySet) verify(token string) (map[string]any, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed JWT")
+	}
+	var header struct {
+		Alg  string          `json:"alg"`
+		Kid  string          `json:"kid"`
+		Crit json.RawMessage `json:"crit"`
+	}
+	if err := decodeJSONSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("malformed JWT header: %w", err)
+	}
+	// None of the extensions a "crit" header could require is understood (RFC 7515 §4.1.11)
+	if header.Crit != nil {
+		return nil, fmt.Errorf("unsupported critical JWT header parameters %s", header.Crit)
+	}
+	signature, err := decodeSegment(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("malformed JWT signature: %w", err)
+	}
+	kty, hash, ok := jwsAlgorithm(header.Alg)
+	if !ok {
+		return nil, fmt.Errorf("unsupported JWT algorithm %q", header.Alg)
+	}
+
+	signed := []byte(parts[0] + "." + parts[1])
+	var digest []byte
+	if hash != 0 {
+		h := hash.New()
+		h.Write(signed)
+		digest = h.Sum(nil)
+	}
+	verified := false
+	for _, jwk := range ks.keys {
+		if jwk.Kty != kty || (header.Kid != "" && jwk.Kid != header.Kid) || (jwk.Alg != "" && jwk.Alg != header.Alg) {
+			continue
+		}
+		if verified = verifySignature(jwk.key, header.Alg, hash, signed, digest, signature); verified {
+			break
+		}
+	}
+	if !verified {
+		return nil, errors.New("JWT signature verification failed")
+	}
+
+	var claims map[string]any
+	if err := decodeJSONSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("malformed JWT claims: %w", err)
+	}
+	return claims, nil
+}
+
+// jwsAlgorithm returns the key type and the hash of a JWS algorithm.
+func jwsAlgorithm(alg string) (kty string, hash crypto.Hash, ok bool) {
+	switch alg {
+	case "RS256", "PS256":
+		return "RSA", crypto.SHA256, true
+	case "RS384", "PS384":
+		return "RSA", crypto.SHA384, true
+	case "RS512", "PS512":
+		return "RSA", crypto.SHA512, true
+	case "ES256":
+		return "EC", crypto.SHA256, true
+	case "ES384":
+		return "EC", crypto.SHA384, true
+	case "ES512":
+		return "EC", crypto.SHA512, true
+	case "EdDSA", "Ed25519":
+		return "OKP", 0, true
+	case "HS256":
+		return "oct", crypto.SHA256, true
+	case "HS384":
+		return "oct", crypto.SHA384, true
+	case "HS512":
+		return "oct", crypto.SHA512, true
+	}
+	return "", 0, false
+}
+
+func verifySignature(key any, alg string, hash crypto.Hash, signed, digest, signature []byte) bool {
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		if strings.HasPrefix(alg, "PS") {
+			return rsa.VerifyPSS(key, hash, digest, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}) == nil
+		}
+		return rsa.VerifyPKCS1v15(key, hash, digest, signature) == nil
+	case *ecdsa.PublicKey:
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		return ecdsa.Verify(key, digest, r, s)
+	case ed25519.PublicKey:
+		return ed25519.Verify(key, signed, signature)
+	case []byte:
+		mac := hmac.New(hash.New, key)
+		mac.Write(signed)
+		return hmac.Equal(mac.Sum(nil), signature)
+	}
+	return false
+}
+
+// checkClaims checks the registered claims of a token.
+func (options *JWTOptions) checkClaims(claims map[string]any) error {
+	now := time.Now
+	if options.Now != nil {
+		now = options.Now
+	}
+	t := now()
+	if exp, ok := claims["exp"]; ok {
+		exp, ok := numericDate(exp)
+		if !ok {
+			return errors.New(`invalid "exp" claim`)
+		}
+		if !t.Before(exp.Add(options.Leeway)) {
+			return errors.New("token is expired")
+		}
+	} else if !options.AllowMissingExpiration {
+		return errors.New(`missing "exp" claim`)
+	}
+	if nbf, ok := claims["nbf"]; ok {
+		nbf, ok := numericDate(nbf)
+		if !ok {
+			return errors.New(`invalid "nbf" claim`)
+		}
+		if t.Add(options.Leeway).Before(nbf) {
+			return errors.New("token is not valid yet")
+		}
+	}
+	if options.Issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != options.Issuer {
+			return fmt.Errorf("unexpected issuer %q", iss)
+		}
+	}
+	if options.Audience != "" && !slices.Contains(stringsClaim(claims["aud"]), options.Audience) {
+		return fmt.Errorf("token is not intended for audience %q", options.Audience)
+	}
+	return nil
+}
+
+// maxNumericDate is the NumericDate of 9999-12-31T23:59:59Z.
+const maxNumericDate = 253402300799
+
+// numericDate returns the time of a NumericDate claim, a number of seconds since the epoch.
+// It fails if v is not a number, or is not finite, or is more than maxNumericDate seconds away from the epoch.
+func numericDate(v any) (time.Time, bool) {
+	seconds, ok := v.(float64)
+	if !ok || math.IsNaN(seconds) || math.Abs(seconds) > maxNumericDate {
+		return time.Time{}, false
+	}
+	sec, frac := math.Modf(seconds)
+	return time.Unix(int64(sec), int64(frac*float64(time.Second))), true
+}
+
+// stringsClaim returns the strings of a claim that is either a string or an array of strings.
+func stringsClaim(v any) []string {
+	switch v := v.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+func decodeSegment(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
+
+func decodeJSONSegment(s string, v any) error {
+	data, err := decodeSegment(s)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
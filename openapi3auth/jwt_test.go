@@ -0,0 +1,187 @@
+package openapi3auth_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/getkin/kin-openapi/openapi3auth"
+)
+
+var b64 = base64.RawURLEncoding
+
+func signJWT(t *testing.T, alg, kid string, key any, claims map[string]any) string {
+	t.Helper()
+	header := map[string]any{"alg": alg, "typ": "JWT"}
+	if kid != "" {
+		header["kid"] = kid
+	}
+	return signJWTWithHeader(t, header, key, claims)
+}
+
+func signJWTWithHeader(t *testing.T, header map[string]any, key any, claims map[string]any) string {
+	t.Helper()
+	h, err := json.Marshal(header)
+	require.NoError(t, err)
+	c, err := json.Marshal(claims)
+	require.NoError(t, err)
+	signed := b64.EncodeToString(h) + "." + b64.EncodeToString(c)
+	digest := sha256.Sum256([]byte(signed))
+
+	var signature []byte
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		signature, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	case *ecdsa.PrivateKey:
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, key, digest[:])
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	case ed25519.PrivateKey:
+		signature = ed25519.Sign(key, []byte(signed))
+	case []byte:
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(signed))
+		signature = mac.Sum(nil)
+	}
+	require.NoError(t, err)
+	return signed + "." + b64.EncodeToString(signature)
+}
+
+func TestJWTVerifier(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	ecPoint, err := ecKey.PublicKey.Bytes()
+	require.NoError(t, err)
+	edPublic, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	otherRSAKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	jwks, err := json.Marshal(map[string]any{"keys": []map[string]any{
+		{"kty": "RSA", "kid": "rsa", "use": "sig", "n": b64.EncodeToString(rsaKey.N.Bytes()), "e": b64.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes())},
+		{"kty": "EC", "kid": "ec", "crv": "P-256", "x": b64.EncodeToString(ecPoint[1:33]), "y": b64.EncodeToString(ecPoint[33:])},
+		{"kty": "OKP", "kid": "ed", "crv": "Ed25519", "x": b64.EncodeToString(edPublic)},
+		{"kty": "oct", "kid": "hmac", "alg": "HS256", "k": b64.EncodeToString(secret)},
+		{"kty": "RSA", "kid": "enc", "use": "enc", "n": "AQAB", "e": "AQAB"},
+		{"kty": "OKP", "crv": "X25519", "x": "AAAA"},
+	}})
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, jwks, 0o600))
+	keys, err := openapi3auth.LoadKeySet(path)
+	require.NoError(t, err)
+	require.Equal(t, 4, keys.Len())
+
+	_, err = openapi3auth.ParseKeySet([]byte(`{"keys":[{"kty":"EC","crv":"P-256","x":"AA","y":"AA"}]}`))
+	require.EqualError(t, err, `invalid key 0 of JWKS: invalid EC coordinate "x"`)
+
+	now := time.Unix(1700000000, 0)
+	verify := openapi3auth.JWTVerifier(keys, &openapi3auth.JWTOptions{
+		Issuer:   "https://auth.example.com",
+		Audience: "pets",
+		Leeway:   time.Minute,
+		Now:      func() time.Time { return now },
+	})
+	claims := func(extra map[string]any) map[string]any {
+		c := map[string]any{
+			"iss":   "https://auth.example.com",
+			"aud":   []string{"pets", "stores"},
+			"sub":   "alice",
+			"exp":   now.Add(time.Hour).Unix(),
+			"scope": "pets:read pets:write",
+		}
+		for k, v := range extra {
+			if v == nil {
+				delete(c, k)
+			} else {
+				c[k] = v
+			}
+		}
+		return c
+	}
+
+	for _, token := range []string{
+		signJWT(t, "RS256", "rsa", rsaKey, claims(nil)),
+		signJWT(t, "ES256", "ec", ecKey, claims(nil)),
+		signJWT(t, "EdDSA", "ed", edKey, claims(nil)),
+		signJWT(t, "HS256", "", secret, claims(nil)),
+	} {
+		principal, err := verify(context.Background(), token)
+		require.NoError(t, err)
+		require.Equal(t, "alice", principal.Subject)
+		require.Equal(t, []string{"pets:read", "pets:write"}, principal.Scopes)
+		require.Equal(t, "https://auth.example.com", principal.Claims["iss"])
+	}
+
+	principal, err := verify(context.Background(), signJWT(t, "RS256", "", rsaKey, claims(map[string]any{"scope": nil, "scp": []string{"pets:read"}})))
+	require.NoError(t, err)
+	require.Equal(t, []string{"pets:read"}, principal.Scopes)
+
+	for token, want := range map[string]string{
+		"abc": "invalid credentials: malformed JWT",
+		signJWT(t, "RS256", "ec", rsaKey, claims(nil)):                                                                   "invalid credentials: JWT signature verification failed",
+		signJWT(t, "RS256", "rsa", otherRSAKey, claims(nil)):                                                             "invalid credentials: JWT signature verification failed",
+		signJWT(t, "HS256", "rsa", secret, claims(nil)):                                                                  "invalid credentials: JWT signature verification failed",
+		signJWT(t, "none", "", nil, claims(nil)):                                                                         `invalid credentials: unsupported JWT algorithm "none"`,
+		signJWT(t, "RS256", "rsa", rsaKey, claims(map[string]any{"exp": now.Add(-2 * time.Minute).Unix()})):              "invalid credentials: token is expired",
+		signJWT(t, "RS256", "rsa", rsaKey, claims(map[string]any{"exp": now.Add(-30 * time.Second).Unix()})):             "",
+		signJWT(t, "RS256", "rsa", rsaKey, claims(map[string]any{"nbf": now.Add(2 * time.Minute).Unix()})):               "invalid credentials: token is not valid yet",
+		signJWT(t, "RS256", "rsa", rsaKey, claims(map[string]any{"exp": "tomorrow"})):                                    `invalid credentials: invalid "exp" claim`,
+		signJWT(t, "RS256", "rsa", rsaKey, claims(map[string]any{"exp": 1e19})):                                          `invalid credentials: invalid "exp" claim`,
+		signJWT(t, "RS256", "rsa", rsaKey, claims(map[string]any{"nbf": 1e19})):                                          `invalid credentials: invalid "nbf" claim`,
+		signJWT(t, "RS256", "rsa", rsaKey, claims(map[string]any{"nbf": float64(now.Add(30*time.Second).Unix()) + 0.5})): "",
+		signJWT(t, "RS256", "rsa", rsaKey, claims(map[string]any{"exp": nil})):                                           `invalid credentials: missing "exp" claim`,
+		signJWTWithHeader(t, map[string]any{"alg": "RS256", "crit": []string{"b64"}, "b64": false}, rsaKey, claims(nil)): `invalid credentials: unsupported critical JWT header parameters ["b64"]`,
+		signJWT(t, "RS256", "rsa", rsaKey, claims(map[string]any{"iss": "https://evil.example.com"})):                    `invalid credentials: unexpected issuer "https://evil.example.com"`,
+		signJWT(t, "RS256", "rsa", rsaKey, claims(map[string]any{"aud": "stores"})):                                      `invalid credentials: token is not intended for audience "pets"`,
+	} {
+		_, err := verify(context.Background(), token)
+		if want == "" {
+			require.NoError(t, err)
+			continue
+		}
+		require.ErrorIs(t, err, openapi3auth.ErrInvalidCredentials)
+		require.EqualError(t, err, want)
+	}
+
+	// Tokens without expiration, when allowed
+	verifyNoExp := openapi3auth.JWTVerifier(keys, &openapi3auth.JWTOptions{AllowMissingExpiration: true, Now: func() time.Time { return now }})
+	_, err = verifyNoExp(context.Background(), signJWT(t, "RS256", "rsa", rsaKey, claims(map[string]any{"exp": nil})))
+	require.NoError(t, err)
+	_, err = verifyNoExp(context.Background(), signJWT(t, "RS256", "rsa", rsaKey, claims(map[string]any{"exp": now.Add(-time.Hour).Unix()})))
+	require.EqualError(t, err, "invalid credentials: token is expired")
+
+	// Scopes of OAuth2 requirements
+	h := newHandler(t, openapi3auth.Bearer(openapi3auth.JWTVerifier(keys, &openapi3auth.JWTOptions{Now: func() time.Time { return now }})))
+	req := httptest.NewRequest(http.MethodPost, "/pets", nil)
+	req.Header.Set("Authorization", "Bearer "+signJWT(t, "ES256", "ec", ecKey, claims(nil)))
+	status, body := serve(h, req)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, "oauth:alice", body)
+
+	req = httptest.NewRequest(http.MethodPost, "/pets", nil)
+	req.Header.Set("Authorization", "Bearer "+signJWT(t, "ES256", "ec", ecKey, claims(map[string]any{"scope": "pets:read"})))
+	status, body = serve(h, req)
+	require.Equal(t, http.StatusBadRequest, status)
+	require.Contains(t, body, "insufficient scope: missing scopes pets:write")
+}
@@ -0,0 +1,239 @@
+// Package openapi3auth provides openapi3filter.AuthenticationFunc implementations
+// for the security schemes of an OpenAPI document.
+//
+// APIKey, Basic and Bearer read the credentials where the security scheme declares them
+// and check them with a verifier. JWTVerifier is a Bearer verifier of JSON Web Tokens
+// signed by the keys of a local JWKS file. Compose and ForScheme combine them:
+//
+//	keys, err := openapi3auth.LoadKeySet("jwks.json")
+//	...
+//	options := openapi3filter.Options{
+//		AuthenticationFunc: openapi3auth.Compose(
+//			openapi3auth.APIKey(checkAPIKey),
+//			openapi3auth.Bearer(openapi3auth.JWTVerifier(keys, &openapi3auth.JWTOptions{Issuer: "https://auth.example.com"})),
+//		),
+//	}
+//
+// The scopes of a security requirement must all be granted to the verified Principal,
+// which is then stored in the context of a copy of the request (see PrincipalFromContext).
+package openapi3auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3filter"
+)
+
+var (
+	// ErrUnsupportedScheme is returned by the AuthenticationFuncs of this package
+	// for security schemes of other types. Compose then tries the next AuthenticationFunc.
+	ErrUnsupportedScheme = errors.New("unsupported security scheme")
+
+	// ErrCredentialsMissing is returned when a request carries no credentials for a security scheme.
+	ErrCredentialsMissing = errors.New("missing credentials")
+
+	// ErrInvalidCredentials is returned when credentials are malformed or rejected.
+	ErrInvalidCredentials = errors.New("invalid credentials")
+
+	// ErrInsufficientScope is returned when the scopes of a security requirement
+	// are not all granted to the principal.
+	ErrInsufficientScope = errors.New("insufficient scope")
+)
+
+// Principal is the identity authenticated by a security scheme.
+type Principal struct {
+	// SecuritySchemeName is the name of the security scheme which authenticated the principal.
+	// It is set by the AuthenticationFuncs of this package.
+	SecuritySchemeName string
+
+	// Subject identifies the principal, e.g. a user name or the "sub" claim of a JWT.
+	Subject string
+
+	// Scopes are the scopes granted to the principal.
+	Scopes []string
+
+	// Claims holds the claims of a JWT, or any other attributes set by verifiers.
+	Claims map[string]any
+}
+
+// HasScopes returns true if all scopes are granted to the principal.
+func (p *Principal) HasScopes(scopes ...string) bool {
+	for _, scope := range scopes {
+		if !slices.Contains(p.Scopes, scope) {
+			return false
+		}
+	}
+	return true
+}
+
+// APIKeyVerifier checks an API key. It returns ErrInvalidCredentials
+// (or any other error) to reject the key.
+type APIKeyVerifier func(ctx context.Context, key string) (*Principal, error)
+
+// BasicVerifier checks the credentials of HTTP basic authentication.
+// It returns ErrInvalidCredentials (or any other error) to reject them.
+type BasicVerifier func(ctx context.Context, username, password string) (*Principal, error)
+
+// BearerVerifier checks a bearer token. It returns ErrInvalidCredentials
+// (or any other error) to reject the token.
+type BearerVerifier func(ctx context.Context, token string) (*Principal, error)
+
+// APIKey returns an AuthenticationFunc of "apiKey" security schemes,
+// reading keys from the header, query parameter or cookie named by the scheme.
+func APIKey(verify APIKeyVerifier) openapi3filter.AuthenticationFunc {
+	return func(ctx context.Context, input *openapi3filter.AuthenticationInput) error {
+		scheme := input.SecurityScheme
+		if scheme.Type != "apiKey" {
+			return ErrUnsupportedScheme
+		}
+		req := input.RequestValidationInput.Request
+		var key string
+		switch scheme.In {
+		case "header":
+			key = req.Header.Get(scheme.Name)
+		case "query":
+			key = req.URL.Query().Get(scheme.Name)
+		case "cookie":
+			if cookie, err := req.Cookie(scheme.Name); err == nil {
+				key = cookie.Value
+			}
+		default:
+			return fmt.Errorf("%w: api key in %q", ErrUnsupportedScheme, scheme.In)
+		}
+		if key == "" {
+			return fmt.Errorf("%w: api key %q in %s", ErrCredentialsMissing, scheme.Name, scheme.In)
+		}
+		principal, err := verify(ctx, key)
+		return authorize(input, principal, err)
+	}
+}
+
+// Basic returns an AuthenticationFunc of "http" security schemes of the "basic" scheme.
+func Basic(verify BasicVerifier) openapi3filter.AuthenticationFunc {
+	return func(ctx context.Context, input *openapi3filter.AuthenticationInput) error {
+		scheme := input.SecurityScheme
+		if scheme.Type != "http" || !strings.EqualFold(scheme.Scheme, "basic") {
+			return ErrUnsupportedScheme
+		}
+		req := input.RequestValidationInput.Request
+		if req.Header.Get("Authorization") == "" {
+			return fmt.Errorf("%w: basic authorization", ErrCredentialsMissing)
+		}
+		username, password, ok := req.BasicAuth()
+		if !ok {
+			return fmt.Errorf("%w: malformed basic authorization", ErrInvalidCredentials)
+		}
+		principal, err := verify(ctx, username, password)
+		if principal != nil && principal.Subject == "" {
+			principal.Subject = username
+		}
+		return authorize(input, principal, err)
+	}
+}
+
+// Bearer returns an AuthenticationFunc of "http" security schemes of the "bearer" scheme,
+// and of "oauth2" and "openIdConnect" security schemes, whose access tokens are bearer tokens.
+func Bearer(verify BearerVerifier) openapi3filter.AuthenticationFunc {
+	return func(ctx context.Context, input *openapi3filter.AuthenticationInput) error {
+		scheme := input.SecurityScheme
+		switch {
+		case scheme.Type == "http" && strings.EqualFold(scheme.Scheme, "bearer"):
+		case scheme.Type == "oauth2", scheme.Type == "openIdConnect":
+		default:
+			return ErrUnsupportedScheme
+		}
+		authorization := input.RequestValidationInput.Request.Header.Get("Authorization")
+		if authorization == "" {
+			return fmt.Errorf("%w: bearer token", ErrCredentialsMissing)
+		}
+		prefix, token, ok := strings.Cut(authorization, " ")
+		if token = strings.TrimSpace(token); !ok || !strings.EqualFold(prefix, "bearer") || token == "" {
+			return fmt.Errorf("%w: malformed bearer authorization", ErrInvalidCredentials)
+		}
+		principal, err := verify(ctx, token)
+		return authorize(input, principal, err)
+	}
+}
+
+// Compose returns an AuthenticationFunc calling funcs in order
+// until one of them does not return ErrUnsupportedScheme.
+func Compose(funcs ...openapi3filter.AuthenticationFunc) openapi3filter.AuthenticationFunc {
+	return func(ctx context.Context, input *openapi3filter.AuthenticationInput) error {
+		for _, f := range funcs {
+			if err := f(ctx, input); !errors.Is(err, ErrUnsupportedScheme) {
+				return err
+			}
+		}
+		return fmt.Errorf("%w: %q", ErrUnsupportedScheme, input.SecuritySchemeName)
+	}
+}
+
+// ForScheme returns an AuthenticationFunc calling f for the security scheme
+// named name only, e.g. to check distinct API keys with Compose.
+func ForScheme(name string, f openapi3filter.AuthenticationFunc) openapi3filter.AuthenticationFunc {
+	return func(ctx context.Context, input *openapi3filter.AuthenticationInput) error {
+		if input.SecuritySchemeName != name {
+			return ErrUnsupportedScheme
+		}
+		return f(ctx, input)
+	}
+}
+
+// authorize checks the principal returned by a verifier against the scopes of input,
+// and replaces the request of input with a copy whose context carries it.
+func authorize(input *openapi3filter.AuthenticationInput, principal *Principal, err error) error {
+	if err != nil {
+		return err
+	}
+	if principal == nil {
+		return ErrInvalidCredentials
+	}
+	var missing []string
+	for _, scope := range input.Scopes {
+		if !slices.Contains(principal.Scopes, scope) {
+			missing = append(missing, scope)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: missing scopes %s", ErrInsufficientScope, strings.Join(missing, ", "))
+	}
+	principal.SecuritySchemeName = input.SecuritySchemeName
+	req := input.RequestValidationInput.Request
+	input.RequestValidationInput.Request = req.WithContext(ContextWithPrincipal(req.Context(), principal))
+	return nil
+}
+
+type principalsContextKey struct{}
+
+// ContextWithPrincipal returns a copy of ctx carrying principal, in addition to the principals
+// already stored in ctx.
+//
+// The AuthenticationFuncs of this package do not modify the request they authenticate:
+// they replace RequestValidationInput.Request with a shallow copy whose context is built
+// with ContextWithPrincipal. Validator.Middleware passes that copy on to the handler it wraps,
+// while direct callers of openapi3filter.ValidateRequest must read the principals
+// from input.Request after validation, not from the request they passed in.
+func ContextWithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	principals := PrincipalsFromContext(ctx)
+	return context.WithValue(ctx, principalsContextKey{}, append(principals[:len(principals):len(principals)], principal))
+}
+
+// PrincipalFromContext returns the first principal stored by ContextWithPrincipal, or nil.
+// See ContextWithPrincipal for the request whose context carries it.
+func PrincipalFromContext(ctx context.Context) *Principal {
+	if principals := PrincipalsFromContext(ctx); len(principals) > 0 {
+		return principals[0]
+	}
+	return nil
+}
+
+// PrincipalsFromContext returns the principals stored by ContextWithPrincipal:
+// one per security scheme of the met security requirement.
+func PrincipalsFromContext(ctx context.Context) []*Principal {
+	principals, _ := ctx.Value(principalsContextKey{}).([]*Principal)
+	return principals
+}
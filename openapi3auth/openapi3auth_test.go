@@ -0,0 +1,239 @@
+package openapi3auth_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3auth"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+)
+
+const spec = `
+openapi: 3.0.3
+info:
+  title: Pets
+  version: 1.0.0
+components:
+  securitySchemes:
+    headerKey:
+      type: apiKey
+      in: header
+      name: X-API-Key
+    queryKey:
+      type: apiKey
+      in: query
+      name: api_key
+    cookieKey:
+      type: apiKey
+      in: cookie
+      name: session
+    basic:
+      type: http
+      scheme: basic
+    bearer:
+      type: http
+      scheme: bearer
+    oauth:
+      type: oauth2
+      flows:
+        clientCredentials:
+          tokenUrl: https://auth.example.com/token
+          scopes:
+            pets:read: read pets
+            pets:write: write pets
+paths:
+  /keys:
+    get:
+      security:
+        - headerKey: []
+        - queryKey: []
+        - cookieKey: []
+      responses:
+        '200':
+          description: ok
+  /both:
+    get:
+      security:
+        - headerKey: []
+          basic: []
+        - bearer: []
+      responses:
+        '200':
+          description: ok
+  /pets:
+    post:
+      security:
+        - oauth: [pets:write]
+      responses:
+        '200':
+          description: ok
+`
+
+func newHandler(t *testing.T, f openapi3filter.AuthenticationFunc) http.Handler {
+	t.Helper()
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(spec))
+	require.NoError(t, err)
+	require.NoError(t, doc.Validate(context.Background()))
+	router, err := gorillamux.NewRouter(doc)
+	require.NoError(t, err)
+	return openapi3filter.NewValidator(router,
+		openapi3filter.OnErr(func(_ context.Context, w http.ResponseWriter, status int, _ openapi3filter.ErrCode, err error) {
+			http.Error(w, err.Error(), status)
+		}),
+		openapi3filter.OnLog(func(context.Context, string, error) {}),
+		openapi3filter.ValidationOptions(openapi3filter.Options{AuthenticationFunc: f}),
+	).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var names []string
+		for _, p := range openapi3auth.PrincipalsFromContext(r.Context()) {
+			names = append(names, p.SecuritySchemeName+":"+p.Subject)
+		}
+		_, _ = w.Write([]byte(strings.Join(names, ",")))
+	}))
+}
+
+func serve(h http.Handler, req *http.Request) (int, string) {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w.Code, strings.TrimSpace(w.Body.String())
+}
+
+func TestAuthenticators(t *testing.T) {
+	checkKey := func(ctx context.Context, key string) (*openapi3auth.Principal, error) {
+		if key != "s3cret" {
+			return nil, openapi3auth.ErrInvalidCredentials
+		}
+		return &openapi3auth.Principal{Subject: "service"}, nil
+	}
+	checkBasic := func(ctx context.Context, username, password string) (*openapi3auth.Principal, error) {
+		if password != "pa55" {
+			return nil, openapi3auth.ErrInvalidCredentials
+		}
+		return &openapi3auth.Principal{}, nil
+	}
+	checkBearer := func(ctx context.Context, token string) (*openapi3auth.Principal, error) {
+		switch token {
+		case "reader":
+			return &openapi3auth.Principal{Subject: token, Scopes: []string{"pets:read"}}, nil
+		case "writer":
+			return &openapi3auth.Principal{Subject: token, Scopes: []string{"pets:read", "pets:write"}}, nil
+		case "down":
+			return nil, errors.New("token store unavailable")
+		}
+		return nil, nil
+	}
+	h := newHandler(t, openapi3auth.Compose(
+		openapi3auth.ForScheme("cookieKey", openapi3auth.APIKey(func(ctx context.Context, key string) (*openapi3auth.Principal, error) {
+			return &openapi3auth.Principal{Subject: "session " + key}, nil
+		})),
+		openapi3auth.APIKey(checkKey),
+		openapi3auth.Basic(checkBasic),
+		openapi3auth.Bearer(checkBearer),
+	))
+
+	t.Run("api keys", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/keys", nil)
+		req.Header.Set("X-API-Key", "s3cret")
+		status, body := serve(h, req)
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, "headerKey:service", body)
+
+		status, body = serve(h, httptest.NewRequest(http.MethodGet, "/keys?api_key=s3cret", nil))
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, "queryKey:service", body)
+
+		req = httptest.NewRequest(http.MethodGet, "/keys", nil)
+		req.AddCookie(&http.Cookie{Name: "session", Value: "abc"})
+		status, body = serve(h, req)
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, "cookieKey:session abc", body)
+
+		req = httptest.NewRequest(http.MethodGet, "/keys?api_key=wrong", nil)
+		status, body = serve(h, req)
+		require.Equal(t, http.StatusBadRequest, status)
+		require.Contains(t, body, `missing credentials: api key "X-API-Key" in header`)
+		require.Contains(t, body, `invalid credentials`)
+		require.Contains(t, body, `missing credentials: api key "session" in cookie`)
+	})
+
+	t.Run("all schemes of a requirement", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/both", nil)
+		req.Header.Set("X-API-Key", "s3cret")
+		req.SetBasicAuth("alice", "pa55")
+		status, body := serve(h, req)
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, "basic:alice,headerKey:service", body)
+
+		// The principal of the unmet requirement is dropped
+		req = httptest.NewRequest(http.MethodGet, "/both", nil)
+		req.Header.Set("X-API-Key", "s3cret")
+		req.Header.Set("Authorization", "Bearer reader")
+		status, body = serve(h, req)
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, "bearer:reader", body)
+
+		req = httptest.NewRequest(http.MethodGet, "/both", nil)
+		req.Header.Set("Authorization", "Bearer unknown")
+		status, body = serve(h, req)
+		require.Equal(t, http.StatusBadRequest, status)
+		require.Contains(t, body, "invalid credentials")
+
+		req = httptest.NewRequest(http.MethodGet, "/both", nil)
+		req.Header.Set("Authorization", "Bearer down")
+		status, body = serve(h, req)
+		require.Equal(t, http.StatusBadRequest, status)
+		require.Contains(t, body, "token store unavailable")
+
+		req = httptest.NewRequest(http.MethodGet, "/both", nil)
+		req.Header.Set("Authorization", "Token abc")
+		_, body = serve(h, req)
+		require.Contains(t, body, "malformed bearer authorization")
+	})
+
+	t.Run("scopes", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/pets", nil)
+		req.Header.Set("Authorization", "bearer writer")
+		status, body := serve(h, req)
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, "oauth:writer", body)
+
+		req = httptest.NewRequest(http.MethodPost, "/pets", nil)
+		req.Header.Set("Authorization", "Bearer reader")
+		status, body = serve(h, req)
+		require.Equal(t, http.StatusBadRequest, status)
+		require.Contains(t, body, "insufficient scope: missing scopes pets:write")
+	})
+
+	t.Run("unsupported schemes", func(t *testing.T) {
+		h := newHandler(t, openapi3auth.Compose(openapi3auth.Basic(checkBasic)))
+		req := httptest.NewRequest(http.MethodGet, "/keys", nil)
+		req.Header.Set("X-API-Key", "s3cret")
+		status, body := serve(h, req)
+		require.Equal(t, http.StatusBadRequest, status)
+		require.Contains(t, body, `unsupported security scheme: "headerKey"`)
+	})
+}
+
+func TestPrincipalFromContext(t *testing.T) {
+	ctx := context.Background()
+	require.Nil(t, openapi3auth.PrincipalFromContext(ctx))
+
+	alice := &openapi3auth.Principal{Subject: "alice", Scopes: []string{"a", "b"}}
+	bob := &openapi3auth.Principal{Subject: "bob"}
+	ctx1 := openapi3auth.ContextWithPrincipal(ctx, alice)
+	ctx2 := openapi3auth.ContextWithPrincipal(ctx1, bob)
+	require.Equal(t, alice, openapi3auth.PrincipalFromContext(ctx2))
+	require.Equal(t, []*openapi3auth.Principal{alice, bob}, openapi3auth.PrincipalsFromContext(ctx2))
+	require.Equal(t, []*openapi3auth.Principal{alice}, openapi3auth.PrincipalsFromContext(ctx1))
+
+	require.True(t, alice.HasScopes("b", "a"))
+	require.False(t, alice.HasScopes("a", "c"))
+	require.True(t, bob.HasScopes())
+}
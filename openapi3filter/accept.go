@@ -0,0 +1,180 @@
+package openapi3filter
+
+import (
+	"fmt"
+	"maps"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+var _ error = &NotAcceptableError{}
+
+// NotAcceptableError is returned by ValidateRequest (wrapped in a RequestError) when Options.RejectUnacceptableRequests
+// is set and none of the media types of the responses of the operation matches the Accept header of the request,
+// and by ValidateResponse (wrapped in a ResponseError) when Options.RejectUnacceptableResponses is set
+// and the Content-Type of the response does not match it.
+// ValidationErrorEncoder and ProblemDetailsEncoder answer requests with 406 Not Acceptable.
+type NotAcceptableError struct {
+	// Accept is the Accept header of the request.
+	Accept string
+
+	// MediaTypes are the media types that do not match Accept: the ones declared by the responses
+	// of the operation for requests, the Content-Type of the response for responses.
+	MediaTypes []string
+}
+
+func (err *NotAcceptableError) Error() string {
+	if len(err.MediaTypes) == 1 {
+		return fmt.Sprintf("media type %q does not match header Accept %q", err.MediaTypes[0], err.Accept)
+	}
+	quoted := make([]string, 0, len(err.MediaTypes))
+	for _, mediaType := range err.MediaTypes {
+		quoted = append(quoted, strconv.Quote(mediaType))
+	}
+	return fmt.Sprintf("none of the media types %s matches header Accept %q", strings.Join(quoted, ", "), err.Accept)
+}
+
+// validateAccept checks that a media type of the successful responses of operation
+// (or of its default response if it has none) matches the Accept header of the request.
+func validateAccept(input *RequestValidationInput, operation *openapi3.Operation) error {
+	accept := strings.Join(input.Request.Header.Values("Accept"), ", ")
+	if strings.TrimSpace(accept) == "" || operation.Responses == nil {
+		return nil
+	}
+
+	var mediaTypes []string
+	for status, responseRef := range operation.Responses.Map() {
+		if responseRef.Value != nil && strings.HasPrefix(status, "2") {
+			mediaTypes = append(mediaTypes, slices.Collect(maps.Keys(responseRef.Value.Content))...)
+		}
+	}
+	if len(mediaTypes) == 0 {
+		if responseRef := operation.Responses.Default(); responseRef != nil && responseRef.Value != nil {
+			mediaTypes = slices.Collect(maps.Keys(responseRef.Value.Content))
+		}
+	}
+	if len(mediaTypes) == 0 {
+		// Responses have no body
+		return nil
+	}
+
+	ranges := parseAccept(accept)
+	for _, mediaType := range mediaTypes {
+		if acceptQuality(ranges, mediaType) > 0 {
+			return nil
+		}
+	}
+	slices.Sort(mediaTypes)
+	mediaTypes = slices.Compact(mediaTypes)
+	return &RequestError{Input: input, Err: &NotAcceptableError{Accept: accept, MediaTypes: mediaTypes}}
+}
+
+// validateResponseAccept checks that the Content-Type of a response matches the Accept header of its request.
+// Responses with status 406 Not Acceptable are not checked.
+func validateResponseAccept(input *ResponseValidationInput) error {
+	contentType := input.Header.Get(headerCT)
+	accept := strings.Join(input.RequestValidationInput.Request.Header.Values("Accept"), ", ")
+	if contentType == "" || strings.TrimSpace(accept) == "" || input.Status == http.StatusNotAcceptable {
+		return nil
+	}
+	if acceptQuality(parseAccept(accept), contentType) > 0 {
+		return nil
+	}
+	return &ResponseError{
+		Input:  input,
+		Reason: "response Content-Type is not acceptable",
+		Err:    &NotAcceptableError{Accept: accept, MediaTypes: []string{contentType}},
+	}
+}
+
+// mediaRange is a media range of an Accept header.
+type mediaRange struct {
+	typ, subtype string
+	params       map[string]string
+	q            float64
+}
+
+// parseAccept parses the media ranges of an Accept header, skipping invalid ones.
+func parseAccept(accept string) []mediaRange {
+	var ranges []mediaRange
+	for value := range strings.SplitSeq(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(value))
+		if err != nil {
+			continue
+		}
+		typ, subtype, ok := strings.Cut(mediaType, "/")
+		if !ok || (typ == "*" && subtype != "*") {
+			continue
+		}
+		r := mediaRange{typ: typ, subtype: subtype, params: params, q: 1}
+		if q, ok := params["q"]; ok {
+			delete(params, "q")
+			if r.q, err = strconv.ParseFloat(q, 64); err != nil || r.q < 0 || r.q > 1 {
+				continue
+			}
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges
+}
+
+// acceptQuality returns the quality the media ranges give to a media type, between 0 (not acceptable) and 1:
+// the one of the most specific matching range (RFC 9110, section 12.5.1).
+// A media type that is itself a range (e.g. "image/*", as declared by a response) gets the highest quality
+// of the ranges overlapping it.
+func acceptQuality(ranges []mediaRange, mediaType string) float64 {
+	mt, params, err := mime.ParseMediaType(mediaType)
+	if err != nil {
+		return 0
+	}
+	typ, subtype, _ := strings.Cut(mt, "/")
+
+	if typ == "*" || subtype == "*" {
+		q := 0.0
+		for _, r := range ranges {
+			if (r.typ == "*" || typ == "*" || r.typ == typ) && (r.subtype == "*" || subtype == "*" || r.subtype == subtype) {
+				q = max(q, r.q)
+			}
+		}
+		return q
+	}
+
+	q, specificity := 0.0, -1
+	for _, r := range ranges {
+		s := 0
+		switch {
+		case r.typ == "*":
+		case r.typ != typ:
+			continue
+		case r.subtype == "*":
+			s = 1
+		case r.subtype != subtype:
+			continue
+		default:
+			s = 2 + len(r.params)
+			if !paramsMatch(r.params, params) {
+				continue
+			}
+		}
+		if s > specificity {
+			q, specificity = r.q, s
+		}
+	}
+	return q
+}
+
+// paramsMatch reports whether the parameters of a media type match those of a media range.
+// Parameters the media type does not carry (e.g. the charset of "application/json") do not restrict it.
+func paramsMatch(want, got map[string]string) bool {
+	for k, v := range want {
+		if g, ok := got[k]; ok && !strings.EqualFold(g, v) {
+			return false
+		}
+	}
+	return true
+}
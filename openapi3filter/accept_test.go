@@ -0,0 +1,182 @@
+package openapi3filter_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+)
+
+func TestContentNegotiation(t *testing.T) {
+	const spec = `
+openapi: 3.0.3
+info:
+  title: Reports
+  version: 1.0.0
+paths:
+  /reports:
+    get:
+      responses:
+        '200':
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: object
+            text/csv:
+              schema:
+                type: string
+        default:
+          description: error
+          content:
+            application/problem+json:
+              schema:
+                type: object
+  /charts:
+    get:
+      responses:
+        '2XX':
+          description: ok
+          content:
+            image/*:
+              schema:
+                type: string
+                format: binary
+  /errors:
+    get:
+      responses:
+        default:
+          description: error
+          content:
+            application/problem+json:
+              schema:
+                type: object
+  /pings:
+    get:
+      responses:
+        '204':
+          description: ok
+`
+	ctx := context.Background()
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(spec))
+	require.NoError(t, err)
+	require.NoError(t, doc.Validate(ctx))
+	router, err := gorillamux.NewRouter(doc)
+	require.NoError(t, err)
+
+	request := func(path string, accept ...string) *openapi3filter.RequestValidationInput {
+		req, err := http.NewRequest(http.MethodGet, path, nil)
+		require.NoError(t, err)
+		for _, value := range accept {
+			req.Header.Add("Accept", value)
+		}
+		route, pathParams, err := router.FindRoute(req)
+		require.NoError(t, err)
+		return &openapi3filter.RequestValidationInput{
+			Request:    req,
+			PathParams: pathParams,
+			Route:      route,
+			Options:    &openapi3filter.Options{RejectUnacceptableRequests: true, RejectUnacceptableResponses: true},
+		}
+	}
+
+	// Requests
+	for _, tt := range []struct {
+		path   string
+		accept []string
+		err    string
+	}{
+		{path: "/reports"},
+		{path: "/reports", accept: []string{""}},
+		{path: "/reports", accept: []string{"*/*"}},
+		{path: "/reports", accept: []string{"text/*"}},
+		{path: "/reports", accept: []string{"text/html", "text/csv"}},
+		{path: "/reports", accept: []string{"application/json;q=0.5, text/html"}},
+		{path: "/reports", accept: []string{"*/*, application/json;q=0"}},
+		{path: "/reports", accept: []string{"application/json; charset=utf-8"}},
+		{path: "/reports", accept: []string{"*/*;q=0, application/json;q=0, text/csv;q=0.001"}},
+		{path: "/charts", accept: []string{"image/png"}},
+		{path: "/charts", accept: []string{"*/*"}},
+		{path: "/errors", accept: []string{"application/problem+json"}},
+		{path: "/pings", accept: []string{"text/html"}},
+
+		{
+			path:   "/reports",
+			accept: []string{"text/html"},
+			err:    `none of the media types "application/json", "text/csv" matches header Accept "text/html"`,
+		},
+		{
+			path:   "/reports",
+			accept: []string{"application/json;q=0, text/*;q=0"},
+			err:    `none of the media types "application/json", "text/csv" matches header Accept "application/json;q=0, text/*;q=0"`,
+		},
+		{
+			path:   "/charts",
+			accept: []string{"application/json", "text/*"},
+			err:    `media type "image/*" does not match header Accept "application/json, text/*"`,
+		},
+		{
+			path:   "/errors",
+			accept: []string{"application/json"},
+			err:    `media type "application/problem+json" does not match header Accept "application/json"`,
+		},
+	} {
+		err := openapi3filter.ValidateRequest(ctx, request(tt.path, tt.accept...))
+		if tt.err == "" {
+			require.NoError(t, err, "%s %q", tt.path, tt.accept)
+			continue
+		}
+		require.EqualError(t, err, tt.err)
+		var notAcceptable *openapi3filter.NotAcceptableError
+		require.ErrorAs(t, err, &notAcceptable)
+
+		var verr *openapi3filter.ValidationError
+		require.ErrorAs(t, openapi3filter.ConvertErrors(err), &verr)
+		require.Equal(t, http.StatusNotAcceptable, verr.Status)
+		p := openapi3filter.NewProblemDetails(err, http.StatusBadRequest)
+		require.Equal(t, http.StatusNotAcceptable, p.Status)
+		require.Equal(t, "not-acceptable", p.Errors[0].Code)
+	}
+
+	// The check is off by default
+	input := request("/reports", "text/html")
+	input.Options = nil
+	require.NoError(t, openapi3filter.ValidateRequest(ctx, input))
+
+	// Responses
+	response := func(status int, contentType string, accept ...string) *openapi3filter.ResponseValidationInput {
+		input := &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: request("/reports", accept...),
+			Status:                 status,
+			Header:                 http.Header{"Content-Type": {contentType}},
+			Options:                &openapi3filter.Options{RejectUnacceptableResponses: true},
+		}
+		input.SetBodyBytes([]byte(`{}`))
+		return input
+	}
+	require.NoError(t, openapi3filter.ValidateResponse(ctx, response(http.StatusOK, "application/json", "application/*")))
+	require.NoError(t, openapi3filter.ValidateResponse(ctx, response(http.StatusOK, "application/json; charset=utf-8", "application/json")))
+	require.NoError(t, openapi3filter.ValidateResponse(ctx, response(http.StatusOK, "application/json")))
+	require.NoError(t, openapi3filter.ValidateResponse(ctx, response(http.StatusOK, "text/csv; header=present", "text/csv;header=present")))
+	require.NoError(t, openapi3filter.ValidateResponse(ctx, response(http.StatusNotAcceptable, "application/problem+json", "text/csv")))
+
+	err = openapi3filter.ValidateResponse(ctx, response(http.StatusOK, "application/json", "text/csv, application/*;q=0"))
+	require.EqualError(t, err, `response Content-Type is not acceptable: media type "application/json" does not match header Accept "text/csv, application/*;q=0"`)
+	p := openapi3filter.NewProblemDetails(err, http.StatusInternalServerError)
+	require.Equal(t, http.StatusInternalServerError, p.Status)
+	require.Equal(t, "not-acceptable", p.Errors[0].Code)
+	err = openapi3filter.ValidateResponse(ctx, response(http.StatusOK, "text/csv; header=absent", "text/csv;header=present"))
+	require.ErrorContains(t, err, "response Content-Type is not acceptable")
+	err = openapi3filter.ValidateResponse(ctx, response(http.StatusBadRequest, "application/problem+json", "application/json"))
+	require.ErrorContains(t, err, "response Content-Type is not acceptable")
+
+	// The check is off by default
+	input2 := response(http.StatusOK, "application/json", "text/csv")
+	input2.Options = nil
+	require.NoError(t, openapi3filter.ValidateResponse(ctx, input2))
+}
@@ -0,0 +1,225 @@
+package openapi3filter
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// maxBinaryBodyDepth bounds the nesting of arrays and maps of CBOR and MessagePack bodies,
+// and of messages and groups of protobuf bodies.
+const maxBinaryBodyDepth = 1000
+
+// byteString is a byte string of a CBOR or MessagePack body, converted by bytesToStrings.
+type byteString []byte
+
+// bytesToStrings converts the byte strings of a decoded CBOR or MessagePack value to strings,
+// as a JSON body would carry them: base64-encoded where the schema has format "byte", raw otherwise
+// (as format "binary" expects).
+func bytesToStrings(value any, schema *openapi3.Schema) any {
+	switch v := value.(type) {
+	case byteString:
+		if schemaHasFormat(schema, "byte") {
+			return base64.StdEncoding.EncodeToString(v)
+		}
+		return string(v)
+	case map[string]any:
+		for k, item := range v {
+			v[k] = bytesToStrings(item, propertySchema(schema, k))
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = bytesToStrings(item, itemsSchema(schema))
+		}
+	}
+	return value
+}
+
+// byteStringDecoders decode the CBOR and MessagePack bodies restoreByteStrings looks into.
+var byteStringDecoders = map[string]func(data []byte) (any, error){
+	"application/cbor":        decodeCBOR,
+	"application/msgpack":     decodeMsgpack,
+	"application/vnd.msgpack": decodeMsgpack,
+	"application/x-msgpack":   decodeMsgpack,
+}
+
+// restoreByteStrings puts back as []byte the strings bytesToStrings converted from the byte strings
+// of body, in value, the body decoded then given defaults. Re-encoding value then keeps them
+// byte strings even where they are valid UTF-8.
+func restoreByteStrings(mediaType string, body []byte, value any) any {
+	decode := byteStringDecoders[mediaType]
+	if decode == nil {
+		return value
+	}
+	raw, err := decode(body)
+	if err != nil {
+		return value
+	}
+	return withByteStrings(raw, value)
+}
+
+func withByteStrings(raw, value any) any {
+	switch r := raw.(type) {
+	case byteString:
+		// Setting defaults leaves the values present in the body as they were
+		if _, ok := value.(string); ok {
+			return []byte(r)
+		}
+	case map[string]any:
+		if v, ok := value.(map[string]any); ok {
+			for k, item := range r {
+				if vItem, ok := v[k]; ok {
+					v[k] = withByteStrings(item, vItem)
+				}
+			}
+		}
+	case []any:
+		if v, ok := value.([]any); ok && len(v) == len(r) {
+			for i, item := range r {
+				v[i] = withByteStrings(item, v[i])
+			}
+		}
+	}
+	return value
+}
+
+// composedSchemas returns schema and its allOf, anyOf and oneOf subschemas.
+func composedSchemas(schema *openapi3.Schema) []*openapi3.Schema {
+	if schema == nil {
+		return nil
+	}
+	schemas := []*openapi3.Schema{schema}
+	for _, refs := range []openapi3.SchemaRefs{schema.AllOf, schema.AnyOf, schema.OneOf} {
+		for _, ref := range refs {
+			if ref != nil && ref.Value != nil {
+				schemas = append(schemas, ref.Value)
+			}
+		}
+	}
+	return schemas
+}
+
+func schemaHasFormat(schema *openapi3.Schema, format string) bool {
+	for _, s := range composedSchemas(schema) {
+		if s.Format == format {
+			return true
+		}
+	}
+	return false
+}
+
+func propertySchema(schema *openapi3.Schema, name string) *openapi3.Schema {
+	for _, s := range composedSchemas(schema) {
+		if ref := s.Properties[name]; ref != nil && ref.Value != nil {
+			return ref.Value
+		}
+	}
+	for _, s := range composedSchemas(schema) {
+		if ref := s.AdditionalProperties.Schema; ref != nil && ref.Value != nil {
+			return ref.Value
+		}
+	}
+	return nil
+}
+
+func itemsSchema(schema *openapi3.Schema) *openapi3.Schema {
+	for _, s := range composedSchemas(schema) {
+		if s.Items != nil && s.Items.Value != nil {
+			return s.Items.Value
+		}
+	}
+	return nil
+}
+
+// numberValue returns f as the JSON path would decode it.
+func numberValue(f float64) any {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return f
+	}
+	return json.Number(strconv.FormatFloat(f, 'g', -1, 64))
+}
+
+func bigIntValue(n *big.Int) json.Number {
+	return json.Number(n.String())
+}
+
+func timestampValue(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// binaryBodyValue returns v as one of the values the JSON path produces
+// (nil, bool, string, json.Number, float64, []any, map[string]any),
+// or []byte, converting other values as encoding/json would.
+func binaryBodyValue(v any) (any, error) {
+	switch v := v.(type) {
+	case nil, bool, string, []byte, json.Number, float64, map[string]any, []any,
+		int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32:
+		return v, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var value any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// binaryNumber is an integer or float to encode.
+type binaryNumber struct {
+	isInt, isUint bool
+	i             int64
+	u             uint64
+	f             float64
+}
+
+// toBinaryNumber returns the number of v, or false if v is not a number.
+func toBinaryNumber(v any) (binaryNumber, bool) {
+	switch v := v.(type) {
+	case int:
+		return binaryNumber{isInt: true, i: int64(v)}, true
+	case int8:
+		return binaryNumber{isInt: true, i: int64(v)}, true
+	case int16:
+		return binaryNumber{isInt: true, i: int64(v)}, true
+	case int32:
+		return binaryNumber{isInt: true, i: int64(v)}, true
+	case int64:
+		return binaryNumber{isInt: true, i: v}, true
+	case uint:
+		return binaryNumber{isUint: true, u: uint64(v)}, true
+	case uint8:
+		return binaryNumber{isUint: true, u: uint64(v)}, true
+	case uint16:
+		return binaryNumber{isUint: true, u: uint64(v)}, true
+	case uint32:
+		return binaryNumber{isUint: true, u: uint64(v)}, true
+	case uint64:
+		return binaryNumber{isUint: true, u: v}, true
+	case float32:
+		return binaryNumber{f: float64(v)}, true
+	case float64:
+		return binaryNumber{f: v}, true
+	case json.Number:
+		if i, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+			return binaryNumber{isInt: true, i: i}, true
+		}
+		if u, err := strconv.ParseUint(string(v), 10, 64); err == nil {
+			return binaryNumber{isUint: true, u: u}, true
+		}
+		if f, err := strconv.ParseFloat(string(v), 64); err == nil {
+			return binaryNumber{f: f}, true
+		}
+	}
+	return binaryNumber{}, false
+}
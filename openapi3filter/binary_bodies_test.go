@@ -0,0 +1,280 @@
+package openapi3filter
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	legacyrouter "github.com/getkin/kin-openapi/routers/legacy"
+)
+
+var binaryBodyContentTypes = []string{"application/cbor", "application/msgpack", "application/x-msgpack", "application/vnd.msgpack"}
+
+func TestBinaryBodiesValidateAsJSON(t *testing.T) {
+	doc, err := openapi3.NewLoader().LoadFromFile("testdata/fixtures/petstore.json")
+	require.NoError(t, err)
+	// Declare binary content types alongside JSON ones
+	for _, path := range doc.Paths.Map() {
+		for _, operation := range path.Operations() {
+			if operation.RequestBody == nil || operation.RequestBody.Value == nil {
+				continue
+			}
+			content := operation.RequestBody.Value.Content
+			if media := content.Get("application/json"); media != nil {
+				for _, contentType := range binaryBodyContentTypes {
+					content[contentType] = media
+				}
+			}
+		}
+	}
+	h := &ValidationHandler{AuthenticationFunc: NoopAuthenticationFunc}
+	h.router, err = legacyrouter.NewRouter(doc)
+	require.NoError(t, err)
+
+	// The JSON bodies of the validation tests give the same results once transcoded
+	transcoded := 0
+	for _, tt := range getValidationTests(t) {
+		r := tt.args.r
+		if tt.fields.File != "" || r.Body == nil || r.Header.Get(headerCT) != "application/json" {
+			continue
+		}
+		data, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		var value any
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		if err := dec.Decode(&value); err != nil {
+			continue
+		}
+
+		validate := func(contentType string, body []byte) error {
+			req := r.Clone(r.Context())
+			req.Header.Set(headerCT, contentType)
+			req.Body, req.GetBody, req.ContentLength = io.NopCloser(bytes.NewReader(body)), nil, int64(len(body))
+			return h.validateRequest(req)
+		}
+		jsonErr := validate("application/json", data)
+		for _, contentType := range binaryBodyContentTypes {
+			body, err := encodeBody(value, contentType)
+			require.NoError(t, err)
+			err = validate(contentType, body)
+			if jsonErr == nil {
+				require.NoError(t, err, "%s: %s", tt.name, contentType)
+			} else {
+				require.EqualError(t, err, jsonErr.Error(), "%s: %s", tt.name, contentType)
+			}
+		}
+		transcoded++
+	}
+	require.Greater(t, transcoded, 10)
+}
+
+func TestBinaryBodyCodecs(t *testing.T) {
+	decode := func(decoder BodyDecoder, data []byte, schema *openapi3.Schema) (any, error) {
+		var ref *openapi3.SchemaRef
+		if schema != nil {
+			ref = schema.NewRef()
+		}
+		return decoder(bytes.NewReader(data), http.Header{}, ref, nil)
+	}
+	unhex := func(s string) []byte {
+		data, err := hex.DecodeString(strings.ReplaceAll(s, " ", ""))
+		require.NoError(t, err)
+		return data
+	}
+
+	// Round trips
+	value := map[string]any{
+		"null":    nil,
+		"true":    true,
+		"false":   false,
+		"string":  "héllo",
+		"long":    strings.Repeat("x", 300),
+		"ints":    []any{json.Number("0"), json.Number("-1"), json.Number("-33"), json.Number("200"), json.Number("-40000"), json.Number("70000"), json.Number("-5000000000"), json.Number("18446744073709551615")},
+		"float":   json.Number("1.5"),
+		"nested":  map[string]any{"items": []any{map[string]any{}}},
+		"entries": make([]any, 20),
+	}
+	for _, codec := range []struct {
+		encoder BodyEncoder
+		decoder BodyDecoder
+	}{
+		{CBORBodyEncoder, CBORBodyDecoder},
+		{MsgpackBodyEncoder, MsgpackBodyDecoder},
+	} {
+		data, err := codec.encoder(value)
+		require.NoError(t, err)
+		decoded, err := decode(codec.decoder, data, nil)
+		require.NoError(t, err)
+		require.Equal(t, value, decoded)
+
+		// Go values are converted as encoding/json would
+		data, err = codec.encoder(struct {
+			ID   int               `json:"id"`
+			Tags map[string]string `json:"tags"`
+		}{ID: 7, Tags: map[string]string{"a": "b"}})
+		require.NoError(t, err)
+		decoded, err = decode(codec.decoder, data, nil)
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{"id": json.Number("7"), "tags": map[string]any{"a": "b"}}, decoded)
+
+		// Byte strings follow the format of their schema
+		data, err = codec.encoder(map[string]any{"raw": []byte("abc"), "b64": []byte("abc"), "items": []any{[]byte("abc")}})
+		require.NoError(t, err)
+		schema := openapi3.NewObjectSchema().
+			WithProperty("raw", openapi3.NewStringSchema().WithFormat("binary")).
+			WithProperty("b64", openapi3.NewBytesSchema()).
+			WithProperty("items", openapi3.NewArraySchema().WithItems(openapi3.NewBytesSchema()))
+		decoded, err = decode(codec.decoder, data, schema)
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{"raw": "abc", "b64": "YWJj", "items": []any{"YWJj"}}, decoded)
+
+		// Malformed bodies
+		_, err = decode(codec.decoder, data[:len(data)-1], nil)
+		require.ErrorContains(t, err, "unexpected end of data")
+		var perr *ParseError
+		require.ErrorAs(t, err, &perr)
+		require.Equal(t, KindInvalidFormat, perr.Kind)
+		_, err = decode(codec.decoder, append(data, 0), nil)
+		require.ErrorContains(t, err, "unexpected data after top-level value")
+	}
+
+	// CBOR examples of RFC 8949 Appendix A
+	for hexData, expected := range map[string]any{
+		"f9 3c00":             json.Number("1"),
+		"f9 c400":             json.Number("-4"),
+		"fa 47c35000":         json.Number("100000"),
+		"fb 7e37e43c8800759c": json.Number("1e+300"),
+		"c0 74 323031332d30332d32315432303a30343a30305a": "2013-03-21T20:04:00Z",
+		"c1 1a 514b67b0":                   "2013-03-21T20:04:00Z",
+		"c1 fb 41d452d9ec200000":           "2013-03-21T20:04:00.5Z",
+		"c2 49 010000000000000000":         json.Number("18446744073709551616"),
+		"3b ffffffffffffffff":              json.Number("-18446744073709551616"),
+		"5f 42 0102 43 030405 ff":          "\x01\x02\x03\x04\x05",
+		"7f 65 7374726561 64 6d696e67 ff":  "streaming",
+		"9f 01 82 02 03 9f 04 05 ff ff":    []any{json.Number("1"), []any{json.Number("2"), json.Number("3")}, []any{json.Number("4"), json.Number("5")}},
+		"bf 61 61 01 61 62 9f 02 03 ff ff": map[string]any{"a": json.Number("1"), "b": []any{json.Number("2"), json.Number("3")}},
+		"a2 01 02 03 04":                   map[string]any{"1": json.Number("2"), "3": json.Number("4")},
+		"f7":                               nil,
+	} {
+		decoded, err := decode(CBORBodyDecoder, unhex(hexData), nil)
+		require.NoError(t, err, hexData)
+		require.Equal(t, expected, decoded, hexData)
+	}
+	// Non-finite floats are left as float64
+	decoded, err := decode(CBORBodyDecoder, unhex("f9 7c00"), nil)
+	require.NoError(t, err)
+	require.Equal(t, math.Inf(1), decoded)
+
+	for hexData, reason := range map[string]string{
+		"a1 80 01":    "cbor: unsupported map key of type []interface {}",
+		"ff":          "cbor: unexpected break",
+		"82 01 ff":    "cbor: unexpected break",
+		"1c":          "cbor: invalid additional information 28",
+		"62 c328":     "cbor: invalid UTF-8 in text string",
+		"f0":          "cbor: unsupported simple value 16",
+		"5f 61 61 ff": "cbor: invalid chunk of indefinite-length string",
+	} {
+		_, err := decode(CBORBodyDecoder, unhex(hexData), nil)
+		require.ErrorContains(t, err, reason, hexData)
+	}
+	_, err = decode(CBORBodyDecoder, bytes.Repeat([]byte{0x81}, maxBinaryBodyDepth+2), nil)
+	require.ErrorContains(t, err, "cbor: maximum nesting depth exceeded")
+	// Lengths larger than the body do not allocate
+	_, err = decode(CBORBodyDecoder, unhex("9b 7fffffffffffffff"), nil)
+	require.ErrorContains(t, err, "cbor: unexpected end of data")
+
+	// MessagePack timestamps and errors
+	for hexData, expected := range map[string]any{
+		"d6 ff 514b67b0":                     "2013-03-21T20:04:00Z",
+		"d7 ff 77359400 514b67b0":            "2013-03-21T20:04:00.5Z",
+		"c7 0c ff 1dcd6500 ffffffffffffffff": "1969-12-31T23:59:59.5Z",
+		"d0 80":                              json.Number("-128"),
+		"d3 8000000000000000":                json.Number("-9223372036854775808"),
+		"ca 3fc00000":                        json.Number("1.5"),
+		"82 01 02 a1 61 03":                  map[string]any{"1": json.Number("2"), "a": json.Number("3")},
+	} {
+		decoded, err := decode(MsgpackBodyDecoder, unhex(hexData), nil)
+		require.NoError(t, err, hexData)
+		require.Equal(t, expected, decoded, hexData)
+	}
+	for hexData, reason := range map[string]string{
+		"81 90 01":    "msgpack: unsupported map key of type []interface {}",
+		"c1":          "msgpack: invalid type 0xc1",
+		"d4 01 00":    "msgpack: unsupported extension type 1",
+		"d5 ff 0000":  "msgpack: invalid timestamp",
+		"a2 c328":     "msgpack: invalid UTF-8 in string",
+		"dd ffffffff": "msgpack: unexpected end of data",
+	} {
+		_, err := decode(MsgpackBodyDecoder, unhex(hexData), nil)
+		require.ErrorContains(t, err, reason, hexData)
+	}
+	_, err = decode(MsgpackBodyDecoder, bytes.Repeat([]byte{0x91}, maxBinaryBodyDepth+2), nil)
+	require.ErrorContains(t, err, "msgpack: maximum nesting depth exceeded")
+}
+
+func TestBinaryBodiesKeepByteStringsWithDefaults(t *testing.T) {
+	spec := `
+openapi: 3.0.0
+info: {title: Uploads, version: "1.0"}
+paths:
+  /uploads:
+    post:
+      requestBody:
+        content:
+          application/cbor: &upload
+            schema:
+              type: object
+              properties:
+                file: {type: string, format: binary}
+                chunks: {type: array, items: {type: string, format: byte}}
+                name: {type: string}
+                kind: {type: string, default: plain}
+          application/msgpack: *upload
+          application/x-msgpack: *upload
+          application/vnd.msgpack: *upload
+      responses:
+        "204": {description: Uploaded}
+`
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(spec))
+	require.NoError(t, err)
+	router, err := legacyrouter.NewRouter(doc)
+	require.NoError(t, err)
+
+	for _, contentType := range binaryBodyContentTypes {
+		// Byte strings that are valid UTF-8 and a text string
+		body, err := encodeBody(map[string]any{
+			"file":   []byte("abc"),
+			"chunks": []any{[]byte("xyz")},
+			"name":   "report",
+		}, contentType)
+		require.NoError(t, err)
+		req, err := http.NewRequest(http.MethodPost, "/uploads", bytes.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set(headerCT, contentType)
+		route, pathParams, err := router.FindRoute(req)
+		require.NoError(t, err)
+		err = ValidateRequest(req.Context(), &RequestValidationInput{Request: req, PathParams: pathParams, Route: route})
+		require.NoError(t, err, contentType)
+
+		// The default is set and the byte strings stay byte strings
+		data, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+		value, err := byteStringDecoders[contentType](data)
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{
+			"file":   byteString("abc"),
+			"chunks": []any{byteString("xyz")},
+			"name":   "report",
+			"kind":   "plain",
+		}, value, contentType)
+	}
+}
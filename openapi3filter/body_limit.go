@@ -0,0 +1,199 @@
+package openapi3filter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// ExtensionMaxBodySize is the operation extension setting the maximum size in bytes
+// of the request and response bodies of an operation, overriding the limits of Options:
+//
+//	paths:
+//	  /uploads:
+//	    post:
+//	      x-max-body-size: 10485760
+const ExtensionMaxBodySize = "x-max-body-size"
+
+// Sources of body size limits, see BodyTooLargeError.
+const (
+	BodyLimitFromOptions     = "options"
+	BodyLimitFromContentType = "content type"
+	BodyLimitFromOperation   = "operation"
+	BodyLimitFromSchema      = "schema"
+)
+
+var _ error = &BodyTooLargeError{}
+
+// BodyTooLargeError is returned by ValidateRequest and ValidateResponse when a body exceeds a size limit
+// (see Options.MaxBodySize). The rest of the body is not read.
+// ValidationErrorEncoder and ProblemDetailsEncoder answer it with 413 Request Entity Too Large.
+type BodyTooLargeError struct {
+	// Limit is the maximum size of the body in bytes,
+	// or the maximum number of items of its JSON array if Items is true.
+	Limit int64
+
+	// Items is true when the body is a JSON array with more items than the maxItems of its schema.
+	Items bool
+
+	// Source is where the limit comes from: one of the BodyLimitFrom constants.
+	Source string
+}
+
+func (err *BodyTooLargeError) Error() string {
+	if err.Items {
+		return fmt.Sprintf("body has more than %d items (limit from %s)", err.Limit, err.Source)
+	}
+	return fmt.Sprintf("body is larger than %d bytes (limit from %s)", err.Limit, err.Source)
+}
+
+// bodyLimit holds the limits of a body.
+type bodyLimit struct {
+	size       int64
+	sizeSource string
+	// items is the maximum number of items of a JSON array body, or -1.
+	items int64
+}
+
+// newBodyLimit returns the limits of a body of mediaType described by media,
+// for an operation (which may be nil).
+func newBodyLimit(options *Options, operation *openapi3.Operation, mediaType string, media *openapi3.MediaType) bodyLimit {
+	limit := bodyLimit{items: -1}
+	if operation != nil {
+		if size, ok := extensionInt(operation.Extensions[ExtensionMaxBodySize]); ok {
+			limit.size, limit.sizeSource = size, BodyLimitFromOperation
+		}
+	}
+	if limit.sizeSource == "" {
+		if size, ok := contentTypeBodySize(options.MaxBodySizeByContentType, mediaType); ok {
+			limit.size, limit.sizeSource = size, BodyLimitFromContentType
+		} else if options.MaxBodySize > 0 {
+			limit.size, limit.sizeSource = options.MaxBodySize, BodyLimitFromOptions
+		}
+	}
+
+	if !options.LimitBodySizeFromSchema || media == nil || media.Schema == nil || media.Schema.Value == nil {
+		return limit
+	}
+	schema := media.Schema.Value
+	switch {
+	case isJSONMediaType(mediaType):
+		if schema.Type.Is("array") && schema.MaxItems != nil && *schema.MaxItems <= math.MaxInt64 {
+			limit.items = int64(*schema.MaxItems)
+		}
+	case schema.Type.Is("string") && (schema.Format == "binary" || schema.Format == "byte") && schema.MaxLength != nil:
+		if size := *schema.MaxLength; size <= math.MaxInt64 && (limit.size <= 0 || int64(size) < limit.size) {
+			limit.size, limit.sizeSource = int64(size), BodyLimitFromSchema
+		}
+	}
+	return limit
+}
+
+// requestBodyLimit returns the limits of the body of the request of input, described by requestBody.
+func requestBodyLimit(input *RequestValidationInput, options *Options, requestBody *openapi3.RequestBody) bodyLimit {
+	var operation *openapi3.Operation
+	if input.Route != nil {
+		operation = input.Route.Operation
+	}
+	contentType := input.Request.Header.Get(headerCT)
+	var media *openapi3.MediaType
+	if requestBody != nil {
+		media = requestBody.Content.Get(contentType)
+	}
+	return newBodyLimit(options, operation, parseMediaType(contentType), media)
+}
+
+// checkContentLength fails early when a declared Content-Length exceeds the limit.
+func (limit bodyLimit) checkContentLength(contentLength int64) error {
+	if limit.size > 0 && contentLength > limit.size {
+		return &BodyTooLargeError{Limit: limit.size, Source: limit.sizeSource}
+	}
+	return nil
+}
+
+// read reads body up to the limits. The maximum number of items is only checked if countItems is true.
+func (limit bodyLimit) read(body io.Reader, countItems bool) ([]byte, error) {
+	r := body
+	if limit.size > 0 {
+		r = io.LimitReader(body, limit.size+1)
+	}
+
+	var buf bytes.Buffer
+	if countItems && limit.items >= 0 {
+		// Count the items of the array while streaming it
+		dec := json.NewDecoder(io.TeeReader(r, &buf))
+		if tok, err := dec.Token(); err == nil && tok == json.Delim('[') {
+			for n := int64(1); dec.More(); n++ {
+				var item json.RawMessage
+				if err := dec.Decode(&item); err != nil {
+					// Reported when decoding the body
+					break
+				}
+				if n > limit.items {
+					return buf.Bytes(), &BodyTooLargeError{Limit: limit.items, Items: true, Source: BodyLimitFromSchema}
+				}
+			}
+		}
+	}
+
+	if _, err := buf.ReadFrom(r); err != nil {
+		return buf.Bytes(), err
+	}
+	if limit.size > 0 && int64(buf.Len()) > limit.size {
+		return buf.Bytes(), &BodyTooLargeError{Limit: limit.size, Source: limit.sizeSource}
+	}
+	return buf.Bytes(), nil
+}
+
+// contentTypeBodySize returns the size limit of mediaType: the one of its exact media type,
+// of its type wildcard (e.g. "image/*"), or of "*/*".
+func contentTypeBodySize(sizes map[string]int64, mediaType string) (int64, bool) {
+	if len(sizes) == 0 {
+		return 0, false
+	}
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	typ, _, _ := strings.Cut(mediaType, "/")
+	for _, key := range []string{mediaType, typ + "/*", "*/*"} {
+		if size, ok := sizes[key]; ok {
+			return size, true
+		}
+	}
+	return 0, false
+}
+
+func isJSONMediaType(mediaType string) bool {
+	mediaType = strings.ToLower(mediaType)
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+func extensionInt(v any) (int64, bool) {
+	switch v := v.(type) {
+	case float64:
+		if v > 0 && v <= math.MaxInt64 && v == math.Trunc(v) {
+			return int64(v), true
+		}
+	case int:
+		if v > 0 {
+			return int64(v), true
+		}
+	case int64:
+		if v > 0 {
+			return v, true
+		}
+	case json.Number:
+		if n, err := v.Int64(); err == nil && n > 0 {
+			return n, true
+		}
+	case string:
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil && n > 0 {
+			return n, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,263 @@
+package openapi3filter_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+)
+
+// endlessReader reads prefix then repeats item forever.
+type endlessReader struct {
+	prefix, item string
+	buf          []byte
+}
+
+func (r *endlessReader) Read(p []byte) (int, error) {
+	if r.buf == nil {
+		r.buf = []byte(r.prefix)
+	}
+	for len(r.buf) < len(p) {
+		r.buf = append(r.buf, r.item...)
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func TestBodySizeLimits(t *testing.T) {
+	const spec = `
+openapi: 3.0.3
+info:
+  title: Uploads
+  version: 1.0.0
+paths:
+  /items:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: array
+              maxItems: 3
+              items:
+                type: integer
+          text/plain:
+            schema:
+              type: string
+      responses:
+        '200':
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  type: integer
+  /files:
+    put:
+      x-max-body-size: 8
+      requestBody:
+        content:
+          application/octet-stream:
+            schema:
+              type: string
+              format: binary
+              maxLength: 6
+      responses:
+        '200':
+          description: ok
+`
+	ctx := context.Background()
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(spec))
+	require.NoError(t, err)
+	require.NoError(t, doc.Validate(ctx))
+	router, err := gorillamux.NewRouter(doc)
+	require.NoError(t, err)
+
+	validate := func(method, path, contentType string, body io.Reader, contentLength int64, options *openapi3filter.Options) error {
+		req, err := http.NewRequest(method, path, body)
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", contentType)
+		req.ContentLength = contentLength
+		route, pathParams, err := router.FindRoute(req)
+		require.NoError(t, err)
+		return openapi3filter.ValidateRequest(ctx, &openapi3filter.RequestValidationInput{
+			Request:    req,
+			PathParams: pathParams,
+			Route:      route,
+			Options:    options,
+		})
+	}
+	tooLarge := func(err error) *openapi3filter.BodyTooLargeError {
+		var e *openapi3filter.BodyTooLargeError
+		require.ErrorAs(t, err, &e)
+		var verr *openapi3filter.ValidationError
+		require.ErrorAs(t, openapi3filter.ConvertErrors(err), &verr)
+		require.Equal(t, http.StatusRequestEntityTooLarge, verr.Status)
+		require.Equal(t, e.Error(), verr.Title)
+		p := openapi3filter.NewProblemDetails(err, http.StatusBadRequest)
+		require.Equal(t, "body-too-large", p.Errors[0].Code)
+		require.Equal(t, http.StatusRequestEntityTooLarge, p.Status)
+		return e
+	}
+
+	// No limits
+	require.NoError(t, validate(http.MethodPost, "/items", "text/plain", strings.NewReader(strings.Repeat("x", 1<<16)), -1, nil))
+
+	// Limits of options, checked on Content-Length first then while reading
+	options := &openapi3filter.Options{MaxBodySize: 16}
+	require.NoError(t, validate(http.MethodPost, "/items", "application/json", strings.NewReader(`[1,2,3]`), 7, options))
+	err = validate(http.MethodPost, "/items", "text/plain", strings.NewReader(strings.Repeat("x", 17)), 17, options)
+	require.Equal(t, &openapi3filter.BodyTooLargeError{Limit: 16, Source: openapi3filter.BodyLimitFromOptions}, tooLarge(err))
+	require.EqualError(t, err, "request body has an error: body is larger than 16 bytes (limit from options)")
+	err = validate(http.MethodPost, "/items", "text/plain", &endlessReader{item: "x"}, -1, options)
+	require.Equal(t, &openapi3filter.BodyTooLargeError{Limit: 16, Source: openapi3filter.BodyLimitFromOptions}, tooLarge(err))
+
+	// Limits of content types
+	options = &openapi3filter.Options{MaxBodySize: 16, MaxBodySizeByContentType: map[string]int64{"text/*": 4}}
+	require.NoError(t, validate(http.MethodPost, "/items", "application/json", strings.NewReader(`[1,2,3]`), -1, options))
+	err = validate(http.MethodPost, "/items", "text/plain; charset=utf-8", strings.NewReader("hello"), -1, options)
+	require.Equal(t, &openapi3filter.BodyTooLargeError{Limit: 4, Source: openapi3filter.BodyLimitFromContentType}, tooLarge(err))
+
+	// Limits of operations override options
+	options = &openapi3filter.Options{MaxBodySize: 4}
+	require.NoError(t, validate(http.MethodPut, "/files", "application/octet-stream", strings.NewReader("123456"), -1, options))
+	err = validate(http.MethodPut, "/files", "application/octet-stream", &endlessReader{item: "x"}, -1, options)
+	require.Equal(t, &openapi3filter.BodyTooLargeError{Limit: 8, Source: openapi3filter.BodyLimitFromOperation}, tooLarge(err))
+
+	// Limits of schemas
+	options = &openapi3filter.Options{LimitBodySizeFromSchema: true}
+	require.NoError(t, validate(http.MethodPut, "/files", "application/octet-stream", strings.NewReader("123456"), -1, options))
+	err = validate(http.MethodPut, "/files", "application/octet-stream", strings.NewReader("1234567"), -1, options)
+	require.Equal(t, &openapi3filter.BodyTooLargeError{Limit: 6, Source: openapi3filter.BodyLimitFromSchema}, tooLarge(err))
+	require.NoError(t, validate(http.MethodPost, "/items", "application/json", strings.NewReader(`[1, 2, 3]`), -1, options))
+	err = validate(http.MethodPost, "/items", "application/json", &endlessReader{prefix: "[", item: "1,"}, -1, options)
+	require.Equal(t, &openapi3filter.BodyTooLargeError{Limit: 3, Items: true, Source: openapi3filter.BodyLimitFromSchema}, tooLarge(err))
+	require.ErrorContains(t, err, "body has more than 3 items (limit from schema)")
+	// Other errors are left to the decoding and validation of the body
+	err = validate(http.MethodPost, "/items", "application/json", strings.NewReader(`[1,2,3`), -1, options)
+	require.ErrorContains(t, err, "failed to decode request body")
+	err = validate(http.MethodPost, "/items", "application/json", strings.NewReader(`{"a":[1,2,3,4]}`), -1, options)
+	require.ErrorContains(t, err, "value must be an array")
+
+	// Responses
+	req, err := http.NewRequest(http.MethodPost, "/items", nil)
+	require.NoError(t, err)
+	route, pathParams, err := router.FindRoute(req)
+	require.NoError(t, err)
+	body := `[1,2,3,4,5,6,7,8,9]`
+	input := &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: &openapi3filter.RequestValidationInput{
+			Request:    req,
+			PathParams: pathParams,
+			Route:      route,
+		},
+		Status:  http.StatusOK,
+		Header:  http.Header{"Content-Type": {"application/json"}},
+		Options: &openapi3filter.Options{MaxBodySize: 8},
+	}
+	input.SetBodyBytes([]byte(body))
+	err = openapi3filter.ValidateResponse(ctx, input)
+	require.EqualError(t, err, "response body is too large: body is larger than 8 bytes (limit from options)")
+	p := openapi3filter.NewProblemDetails(err, http.StatusInternalServerError)
+	require.Equal(t, "body-too-large", p.Errors[0].Code)
+	require.Equal(t, http.StatusInternalServerError, p.Status)
+	// The body is left as it was
+	data, err := io.ReadAll(input.Body)
+	require.NoError(t, err)
+	require.Equal(t, body, string(data))
+	require.NoError(t, input.Body.Close())
+
+	input.Options = &openapi3filter.Options{MaxBodySize: 32}
+	input.SetBodyBytes([]byte(body))
+	require.NoError(t, openapi3filter.ValidateResponse(ctx, input))
+	data, err = io.ReadAll(input.Body)
+	require.NoError(t, err)
+	require.True(t, bytes.Equal([]byte(body), data))
+}
+
+func TestBodySizeLimitAfterSecurity(t *testing.T) {
+	const spec = `
+openapi: 3.0.3
+info:
+  title: Uploads
+  version: 1.0.0
+paths:
+  /items:
+    post:
+      security:
+        - apiKey: []
+      parameters:
+        - name: dryRun
+          in: query
+          schema:
+            type: boolean
+      requestBody:
+        content:
+          text/plain:
+            schema:
+              type: string
+      responses:
+        '200':
+          description: ok
+components:
+  securitySchemes:
+    apiKey:
+      type: apiKey
+      in: header
+      name: X-Api-Key
+`
+	ctx := context.Background()
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(spec))
+	require.NoError(t, err)
+	require.NoError(t, doc.Validate(ctx))
+	router, err := gorillamux.NewRouter(doc)
+	require.NoError(t, err)
+
+	validate := func(target string, multiError bool) error {
+		req, err := http.NewRequest(http.MethodPost, target, strings.NewReader(strings.Repeat("x", 32)))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "text/plain")
+		route, pathParams, err := router.FindRoute(req)
+		require.NoError(t, err)
+		return openapi3filter.ValidateRequest(ctx, &openapi3filter.RequestValidationInput{
+			Request:    req,
+			PathParams: pathParams,
+			Route:      route,
+			Options: &openapi3filter.Options{
+				MaxBodySize:        16,
+				MultiError:         multiError,
+				AuthenticationFunc: func(context.Context, *openapi3filter.AuthenticationInput) error { return errors.New("unknown key") },
+			},
+		})
+	}
+
+	// Unauthenticated requests fail on security before their body is checked
+	err = validate("/items", false)
+	var securityErr *openapi3filter.SecurityRequirementsError
+	require.ErrorAs(t, err, &securityErr)
+	require.Equal(t, http.StatusUnauthorized, openapi3filter.NewProblemDetails(err, http.StatusBadRequest).Status)
+
+	// With MultiError the size of the body is reported too, without reading the body
+	err = validate("/items?dryRun=maybe", true)
+	var me openapi3.MultiError
+	require.ErrorAs(t, err, &me)
+	require.Len(t, me, 3)
+	require.ErrorAs(t, me[0], &securityErr)
+	var tooLarge *openapi3filter.BodyTooLargeError
+	require.ErrorAs(t, me[1], &tooLarge)
+	require.Equal(t, &openapi3filter.BodyTooLargeError{Limit: 16, Source: openapi3filter.BodyLimitFromOptions}, tooLarge)
+	var requestErr *openapi3filter.RequestError
+	require.ErrorAs(t, me[2], &requestErr)
+	require.Equal(t, "dryRun", requestErr.Parameter.Name)
+}
@@ -0,0 +1,405 @@
+package openapi3filter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"math/big"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// CBORBodyDecoder decodes a CBOR (RFC 8949) body into the values a JSON body decodes to,
+// so that it is validated against the same schemas:
+//   - maps with text or integer keys to map[string]any, arrays to []any,
+//   - integers, bignums and finite floats to json.Number,
+//   - byte strings to strings: base64-encoded where the schema has format "byte", raw otherwise,
+//   - date/time tags (0 and 1) to RFC 3339 strings, undefined to nil.
+//
+// Other tags are ignored, indefinite lengths are supported.
+func CBORBodyDecoder(body io.Reader, header http.Header, schema *openapi3.SchemaRef, encFn EncodingFn) (any, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, &ParseError{Kind: KindInvalidFormat, Cause: err}
+	}
+	value, err := decodeCBOR(data)
+	if err != nil {
+		return nil, &ParseError{Kind: KindInvalidFormat, Cause: err}
+	}
+	var s *openapi3.Schema
+	if schema != nil {
+		s = schema.Value
+	}
+	return bytesToStrings(value, s), nil
+}
+
+// decodeCBOR decodes a CBOR body, leaving its byte strings as byteString values.
+func decodeCBOR(data []byte) (any, error) {
+	d := &cborDecoder{data: data}
+	value, err := d.value(0)
+	if err == nil && value == cborBreak {
+		err = errors.New("cbor: unexpected break")
+	}
+	if err == nil && d.pos < len(d.data) {
+		err = errors.New("cbor: unexpected data after top-level value")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+var errCBORUnexpectedEnd = errors.New("cbor: unexpected end of data")
+
+// cborBreak is returned by cborDecoder.value on the "break" stop code of indefinite lengths.
+var cborBreak = &struct{}{}
+
+type cborDecoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *cborDecoder) read(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.pos) {
+		return nil, errCBORUnexpectedEnd
+	}
+	b := d.data[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+	return b, nil
+}
+
+// head reads the initial byte of a data item and its argument.
+func (d *cborDecoder) head() (major byte, info byte, arg uint64, err error) {
+	b, err := d.read(1)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	major, info = b[0]>>5, b[0]&0x1f
+	switch {
+	case info < 24:
+		arg = uint64(info)
+	case info <= 27:
+		if b, err = d.read(1 << (info - 24)); err != nil {
+			return 0, 0, 0, err
+		}
+		for _, c := range b {
+			arg = arg<<8 | uint64(c)
+		}
+	case info == 31:
+		if major == 0 || major == 1 || major == 6 {
+			return 0, 0, 0, fmt.Errorf("cbor: invalid indefinite length for major type %d", major)
+		}
+	default:
+		return 0, 0, 0, fmt.Errorf("cbor: invalid additional information %d", info)
+	}
+	return major, info, arg, nil
+}
+
+func (d *cborDecoder) value(depth int) (any, error) {
+	if depth > maxBinaryBodyDepth {
+		return nil, errors.New("cbor: maximum nesting depth exceeded")
+	}
+	major, info, arg, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+	switch major {
+	case 0:
+		return json.Number(strconv.FormatUint(arg, 10)), nil
+	case 1:
+		if arg <= math.MaxInt64 {
+			return json.Number(strconv.FormatInt(-1-int64(arg), 10)), nil
+		}
+		n := new(big.Int).SetUint64(arg)
+		return bigIntValue(n.Neg(n).Sub(n, big.NewInt(1))), nil
+	case 2, 3:
+		b, err := d.stringBytes(major, info, arg)
+		if err != nil {
+			return nil, err
+		}
+		if major == 2 {
+			return byteString(b), nil
+		}
+		if !utf8.Valid(b) {
+			return nil, errors.New("cbor: invalid UTF-8 in text string")
+		}
+		return string(b), nil
+	case 4:
+		return d.array(info, arg, depth)
+	case 5:
+		return d.object(info, arg, depth)
+	case 6:
+		return d.tag(arg, depth)
+	default:
+		return d.simple(info, arg)
+	}
+}
+
+// stringBytes reads the content of a byte or text string, joining the chunks of indefinite lengths.
+func (d *cborDecoder) stringBytes(major, info byte, arg uint64) ([]byte, error) {
+	if info != 31 {
+		return d.read(arg)
+	}
+	var buf []byte
+	for {
+		chunkMajor, chunkInfo, chunkArg, err := d.head()
+		if err != nil {
+			return nil, err
+		}
+		if chunkMajor == 7 && chunkInfo == 31 {
+			return buf, nil
+		}
+		if chunkMajor != major || chunkInfo == 31 {
+			return nil, errors.New("cbor: invalid chunk of indefinite-length string")
+		}
+		b, err := d.read(chunkArg)
+		if err != nil {
+			return nil, err
+		}
+		buf = append(buf, b...)
+	}
+}
+
+func (d *cborDecoder) array(info byte, arg uint64, depth int) (any, error) {
+	// Each item takes at least one byte
+	items := make([]any, 0, min(arg, uint64(len(d.data)-d.pos)))
+	for i := uint64(0); info == 31 || i < arg; i++ {
+		item, err := d.value(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if item == cborBreak {
+			if info != 31 {
+				return nil, errors.New("cbor: unexpected break")
+			}
+			break
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (d *cborDecoder) object(info byte, arg uint64, depth int) (any, error) {
+	obj := make(map[string]any, min(arg, uint64(len(d.data)-d.pos)/2))
+	for i := uint64(0); info == 31 || i < arg; i++ {
+		key, err := d.value(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if key == cborBreak {
+			if info != 31 {
+				return nil, errors.New("cbor: unexpected break")
+			}
+			break
+		}
+		name, err := binaryMapKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("cbor: %w", err)
+		}
+		value, err := d.value(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if value == cborBreak {
+			return nil, errors.New("cbor: unexpected break")
+		}
+		obj[name] = value
+	}
+	return obj, nil
+}
+
+func (d *cborDecoder) tag(number uint64, depth int) (any, error) {
+	content, err := d.value(depth + 1)
+	if err != nil {
+		return nil, err
+	}
+	if content == cborBreak {
+		return nil, errors.New("cbor: unexpected break")
+	}
+	switch number {
+	case 1:
+		// Epoch-based date/time
+		n, ok := content.(json.Number)
+		if !ok {
+			return nil, errors.New("cbor: invalid epoch-based date/time")
+		}
+		f, err := n.Float64()
+		if err != nil || math.IsInf(f, 0) {
+			return nil, errors.New("cbor: invalid epoch-based date/time")
+		}
+		sec, frac := math.Modf(f)
+		return timestampValue(time.Unix(int64(sec), int64(frac*1e9))), nil
+	case 2, 3:
+		// Unsigned and negative bignums
+		b, ok := content.(byteString)
+		if !ok {
+			return nil, errors.New("cbor: invalid bignum")
+		}
+		n := new(big.Int).SetBytes(b)
+		if number == 3 {
+			n.Neg(n).Sub(n, big.NewInt(1))
+		}
+		return bigIntValue(n), nil
+	}
+	return content, nil
+}
+
+func (d *cborDecoder) simple(info byte, arg uint64) (any, error) {
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22, 23:
+		return nil, nil
+	case 25:
+		return numberValue(float16ToFloat64(uint16(arg))), nil
+	case 26:
+		return numberValue(float64(math.Float32frombits(uint32(arg)))), nil
+	case 27:
+		return numberValue(math.Float64frombits(arg)), nil
+	case 31:
+		return cborBreak, nil
+	}
+	return nil, fmt.Errorf("cbor: unsupported simple value %d", arg)
+}
+
+func float16ToFloat64(h uint16) float64 {
+	exp, mant := int(h>>10)&0x1f, float64(h&0x3ff)
+	var f float64
+	switch exp {
+	case 0:
+		f = math.Ldexp(mant, -24)
+	case 0x1f:
+		if mant == 0 {
+			f = math.Inf(1)
+		} else {
+			f = math.NaN()
+		}
+	default:
+		f = math.Ldexp(mant+1024, exp-25)
+	}
+	if h&0x8000 != 0 {
+		return -f
+	}
+	return f
+}
+
+// binaryMapKey returns the name of a property of a decoded map key.
+func binaryMapKey(key any) (string, error) {
+	switch k := key.(type) {
+	case string:
+		return k, nil
+	case json.Number:
+		if _, err := k.Int64(); err == nil {
+			return k.String(), nil
+		}
+		if _, err := strconv.ParseUint(k.String(), 10, 64); err == nil {
+			return k.String(), nil
+		}
+	}
+	return "", fmt.Errorf("unsupported map key of type %T", key)
+}
+
+// CBORBodyEncoder encodes a body as CBOR (RFC 8949). Maps are encoded with sorted keys,
+// json.Number values as integers when they are ones, strings that are not valid UTF-8 and []byte
+// as byte strings. Values of other types are converted as encoding/json would first.
+func CBORBodyEncoder(body any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := encodeCBOR(&buf, body, 0); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func encodeCBORHead(buf *bytes.Buffer, major byte, arg uint64) {
+	major <<= 5
+	switch {
+	case arg < 24:
+		buf.WriteByte(major | byte(arg))
+	case arg <= math.MaxUint8:
+		buf.Write([]byte{major | 24, byte(arg)})
+	case arg <= math.MaxUint16:
+		buf.WriteByte(major | 25)
+		buf.Write(binary.BigEndian.AppendUint16(nil, uint16(arg)))
+	case arg <= math.MaxUint32:
+		buf.WriteByte(major | 26)
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(arg)))
+	default:
+		buf.WriteByte(major | 27)
+		buf.Write(binary.BigEndian.AppendUint64(nil, arg))
+	}
+}
+
+func encodeCBOR(buf *bytes.Buffer, v any, depth int) error {
+	if depth > maxBinaryBodyDepth {
+		return errors.New("cbor: maximum nesting depth exceeded")
+	}
+	if n, ok := toBinaryNumber(v); ok {
+		switch {
+		case n.isUint:
+			encodeCBORHead(buf, 0, n.u)
+		case n.isInt && n.i >= 0:
+			encodeCBORHead(buf, 0, uint64(n.i))
+		case n.isInt:
+			encodeCBORHead(buf, 1, uint64(-1-n.i))
+		default:
+			buf.WriteByte(0xfb)
+			buf.Write(binary.BigEndian.AppendUint64(nil, math.Float64bits(n.f)))
+		}
+		return nil
+	}
+	switch v := v.(type) {
+	case nil:
+		buf.WriteByte(0xf6)
+	case bool:
+		if v {
+			buf.WriteByte(0xf5)
+		} else {
+			buf.WriteByte(0xf4)
+		}
+	case string:
+		if utf8.ValidString(v) {
+			encodeCBORHead(buf, 3, uint64(len(v)))
+		} else {
+			encodeCBORHead(buf, 2, uint64(len(v)))
+		}
+		buf.WriteString(v)
+	case []byte:
+		encodeCBORHead(buf, 2, uint64(len(v)))
+		buf.Write(v)
+	case []any:
+		encodeCBORHead(buf, 4, uint64(len(v)))
+		for _, item := range v {
+			if err := encodeCBOR(buf, item, depth+1); err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		encodeCBORHead(buf, 5, uint64(len(v)))
+		for _, k := range slices.Sorted(maps.Keys(v)) {
+			encodeCBORHead(buf, 3, uint64(len(k)))
+			buf.WriteString(k)
+			if err := encodeCBOR(buf, v[k], depth+1); err != nil {
+				return err
+			}
+		}
+	default:
+		value, err := binaryBodyValue(v)
+		if err != nil {
+			return err
+		}
+		return encodeCBOR(buf, value, depth)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package openapi3filter
+
+import (
+	"runtime"
+	"sync"
+	"weak"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/routers"
+)
+
+// compiledSchemas holds, for each operation, the *operationSchemas compiled for it.
+// Entries are removed once their operation is garbage collected.
+var compiledSchemas sync.Map // weak.Pointer[openapi3.Operation] -> *operationSchemas
+
+// operationSchemas holds the schemas of an operation compiled with openapi3.Compile.
+type operationSchemas struct {
+	compiled sync.Map // compiledSchemaKey -> *openapi3.CompiledSchema
+}
+
+type compiledSchemaKey struct {
+	schema            *openapi3.Schema
+	useJSONSchema2020 bool
+}
+
+// visitJSON validates value against schema, a schema of the operation of route, with opts.
+//
+// Schemas are compiled once per operation if options set CompileSchemas, unless they also set a RegexCompiler
+// or SchemaValidationOptions: as these may resolve patterns and formats differently on each call,
+// the schema is then visited directly.
+func visitJSON(route *routers.Route, options *Options, schema *openapi3.Schema, value any, opts []openapi3.SchemaValidationOption) error {
+	if !options.CompileSchemas || route == nil || route.Operation == nil || options.RegexCompiler != nil || len(options.SchemaValidationOptions) != 0 {
+		return schema.VisitJSON(value, opts...)
+	}
+	return compiledSchema(route, schema).VisitJSON(value, opts...)
+}
+
+func compiledSchema(route *routers.Route, schema *openapi3.Schema) *openapi3.CompiledSchema {
+	key := compiledSchemaKey{
+		schema:            schema,
+		useJSONSchema2020: route.Spec != nil && route.Spec.IsOpenAPI31OrLater(),
+	}
+
+	operation := weak.Make(route.Operation)
+	v, ok := compiledSchemas.Load(operation)
+	if !ok {
+		var loaded bool
+		if v, loaded = compiledSchemas.LoadOrStore(operation, &operationSchemas{}); !loaded {
+			runtime.AddCleanup(route.Operation, func(operation weak.Pointer[openapi3.Operation]) {
+				compiledSchemas.Delete(operation)
+			}, operation)
+		}
+	}
+	schemas := v.(*operationSchemas)
+
+	if compiled, ok := schemas.compiled.Load(key); ok {
+		return compiled.(*openapi3.CompiledSchema)
+	}
+	var opts []openapi3.SchemaValidationOption
+	if key.useJSONSchema2020 {
+		opts = append(opts, openapi3.EnableJSONSchema2020())
+	}
+	compiled, _ := schemas.compiled.LoadOrStore(key, openapi3.Compile(schema, opts...))
+	return compiled.(*openapi3.CompiledSchema)
+}
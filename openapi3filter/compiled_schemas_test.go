@@ -0,0 +1,160 @@
+package openapi3filter
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"weak"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+)
+
+func TestCompiledSchemas(t *testing.T) {
+	const spec = `
+openapi: 3.0.3
+info:
+  title: Pets
+  version: 1.0.0
+paths:
+  /pets:
+    post:
+      parameters:
+        - name: limit
+          in: query
+          schema:
+            type: integer
+            enum: [10, 20]
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [name]
+              properties:
+                name:
+                  type: string
+                  pattern: '^[A-Z][a-z]+$'
+      responses:
+        '200':
+          description: ok
+          headers:
+            X-Total:
+              schema:
+                type: integer
+                format: int32
+`
+	ctx := context.Background()
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(spec))
+	require.NoError(t, err)
+	require.NoError(t, doc.Validate(ctx))
+	router, err := gorillamux.NewRouter(doc)
+	require.NoError(t, err)
+
+	validate := func(options *Options, query, body string) error {
+		req, err := http.NewRequest(http.MethodPost, "/pets?"+query, strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		route, pathParams, err := router.FindRoute(req)
+		require.NoError(t, err)
+		input := &RequestValidationInput{Request: req, PathParams: pathParams, Route: route, Options: options}
+		if err := ValidateRequest(ctx, input); err != nil {
+			return err
+		}
+		responseInput := &ResponseValidationInput{
+			RequestValidationInput: input,
+			Status:                 http.StatusOK,
+			Header:                 http.Header{"X-Total": {"3"}},
+			Options:                options,
+		}
+		return ValidateResponse(ctx, responseInput)
+	}
+
+	operation := doc.Paths.Value("/pets").Post
+	compiledSchemas.Delete(weak.Make(operation))
+	count := func() (n int) {
+		v, ok := compiledSchemas.Load(weak.Make(operation))
+		if !ok {
+			return 0
+		}
+		v.(*operationSchemas).compiled.Range(func(key, value any) bool {
+			n++
+			return true
+		})
+		return
+	}
+
+	// Compiling is opt-in
+	require.NoError(t, validate(nil, "limit=10", `{"name":"Rex"}`))
+	require.Zero(t, count())
+
+	// Options that cannot be compiled once
+	require.NoError(t, validate(&Options{CompileSchemas: true, RegexCompiler: func(expr string) (openapi3.RegexMatcher, error) {
+		return regexp.Compile(expr)
+	}}, "limit=10", `{"name":"Rex"}`))
+	require.Zero(t, count())
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Go(func() {
+			assert.NoError(t, validate(&Options{CompileSchemas: true}, "limit=10", `{"name":"Rex"}`))
+			err := validate(&Options{CompileSchemas: true, MultiError: true}, "limit=30", `{"name":"rex"}`)
+			assert.ErrorContains(t, err, `parameter "limit" in query has an error: value is not one of the allowed values [10,20]`)
+			assert.ErrorContains(t, err, `doesn't match the regular expression "^[A-Z][a-z]+$"`)
+		})
+	}
+	wg.Wait()
+	// The query parameter, request body and response header schemas
+	require.Equal(t, 3, count())
+}
+
+func TestCompiledSchemasFormatDefinedLater(t *testing.T) {
+	const spec = `
+openapi: 3.0.3
+info:
+  title: Pets
+  version: 1.0.0
+paths:
+  /pets:
+    get:
+      parameters:
+        - name: tag
+          in: query
+          schema:
+            type: string
+            format: compiled-schemas-tag
+      responses:
+        '200':
+          description: ok
+`
+	ctx := context.Background()
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(spec))
+	require.NoError(t, err)
+	require.NoError(t, doc.Validate(ctx))
+	router, err := gorillamux.NewRouter(doc)
+	require.NoError(t, err)
+
+	validate := func(options *Options) error {
+		req, err := http.NewRequest(http.MethodGet, "/pets?tag=rex", nil)
+		require.NoError(t, err)
+		route, pathParams, err := router.FindRoute(req)
+		require.NoError(t, err)
+		return ValidateRequest(ctx, &RequestValidationInput{Request: req, PathParams: pathParams, Route: route, Options: options})
+	}
+	compiled := &Options{CompileSchemas: true}
+	require.NoError(t, validate(nil))
+	require.NoError(t, validate(compiled))
+
+	openapi3.DefineStringFormatValidator("compiled-schemas-tag", openapi3.NewRegexpFormatValidator(`^[A-Z]`))
+	defer delete(openapi3.SchemaStringFormats, "compiled-schemas-tag")
+
+	// Formats defined after the first validation apply unless schemas are compiled
+	require.ErrorContains(t, validate(nil), `string doesn't match the format "compiled-schemas-tag"`)
+	require.NoError(t, validate(compiled))
+}
@@ -0,0 +1,155 @@
+package openapi3filter
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// DefaultMaxDecodedBodySize is the default of Options.MaxDecodedBodySize.
+const DefaultMaxDecodedBodySize = 32 << 20
+
+// ErrUnsupportedContentEncoding is returned when no decoder is registered
+// for the Content-Encoding of a body.
+var ErrUnsupportedContentEncoding = errors.New("unsupported content encoding")
+
+// ErrDecodedBodyTooLarge is returned when a content-encoded body exceeds
+// Options.MaxDecodedBodySize once decoded.
+var ErrDecodedBodyTooLarge = errors.New("decoded body is too large")
+
+const (
+	reasonRequestContentEncoding  = "failed to decode request content encoding"
+	reasonResponseContentEncoding = "failed to decode response content encoding"
+)
+
+// ContentEncodingDecoder returns a reader of the decoded content of a body
+// of a content encoding (e.g. "gzip"). The reader is closed after use if it is an io.Closer.
+type ContentEncodingDecoder func(io.Reader) (io.Reader, error)
+
+// contentEncodingDecoders contains decoders for supported content encodings of a body.
+var contentEncodingDecoders = make(map[string]ContentEncodingDecoder)
+
+// RegisteredContentEncodingDecoder returns the registered decoder for the given content encoding.
+//
+// If no decoder was registered for the given content encoding, nil is returned.
+// This call is not thread-safe: content encoding decoders should not be created/destroyed by multiple goroutines.
+func RegisteredContentEncodingDecoder(encoding string) ContentEncodingDecoder {
+	return contentEncodingDecoders[strings.ToLower(encoding)]
+}
+
+// RegisterContentEncodingDecoder registers a body's decoder for a content encoding.
+// Bodies are decoded before their content type is, for both validation of requests and responses.
+//
+// Only "gzip", "x-gzip" and "deflate" are registered by default: Brotli ("br") is left out
+// so that this package does not depend on a Brotli implementation. Register one with e.g.
+// github.com/andybalholm/brotli:
+//
+//	openapi3filter.RegisterContentEncodingDecoder("br", func(body io.Reader) (io.Reader, error) {
+//		return brotli.NewReader(body), nil
+//	})
+//
+// If a decoder for the specified content encoding already exists, the function replaces
+// it with the specified decoder.
+// This call is not thread-safe: content encoding decoders should not be created/destroyed by multiple goroutines.
+func RegisterContentEncodingDecoder(encoding string, decoder ContentEncodingDecoder) {
+	if encoding == "" {
+		panic("encoding is empty")
+	}
+	if decoder == nil {
+		panic("decoder is not defined")
+	}
+	contentEncodingDecoders[strings.ToLower(encoding)] = decoder
+}
+
+// UnregisterContentEncodingDecoder dissociates a body decoder from a content encoding.
+//
+// Decoding this content encoding will result in an error.
+// This call is not thread-safe: content encoding decoders should not be created/destroyed by multiple goroutines.
+func UnregisterContentEncodingDecoder(encoding string) {
+	if encoding == "" {
+		panic("encoding is empty")
+	}
+	delete(contentEncodingDecoders, strings.ToLower(encoding))
+}
+
+// GzipContentEncodingDecoder decodes gzip bodies.
+func GzipContentEncodingDecoder(body io.Reader) (io.Reader, error) {
+	return gzip.NewReader(body)
+}
+
+// DeflateContentEncodingDecoder decodes deflate bodies: zlib streams,
+// or raw deflate streams as sent by some clients.
+func DeflateContentEncodingDecoder(body io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(body)
+	if header, err := br.Peek(2); err == nil && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(br)
+	}
+	return flate.NewReader(br), nil
+}
+
+func init() {
+	RegisterContentEncodingDecoder("gzip", GzipContentEncodingDecoder)
+	RegisterContentEncodingDecoder("x-gzip", GzipContentEncodingDecoder)
+	RegisterContentEncodingDecoder("deflate", DeflateContentEncodingDecoder)
+}
+
+// decodeContentEncoding returns the content of data, a body with the Content-Encoding of header,
+// and whether it was decoded.
+func decodeContentEncoding(data []byte, header http.Header, options *Options) ([]byte, bool, error) {
+	encodings := contentEncodings(header)
+	if len(encodings) == 0 || len(data) == 0 {
+		return data, false, nil
+	}
+
+	maxSize := options.MaxDecodedBodySize
+	if maxSize == 0 {
+		maxSize = DefaultMaxDecodedBodySize
+	}
+
+	var r io.Reader = bytes.NewReader(data)
+	for i := len(encodings) - 1; i >= 0; i-- {
+		decoder := contentEncodingDecoders[encodings[i]]
+		if decoder == nil {
+			return nil, false, fmt.Errorf("%w %q", ErrUnsupportedContentEncoding, encodings[i])
+		}
+		var err error
+		if r, err = decoder(r); err != nil {
+			return nil, false, err
+		}
+		if c, ok := r.(io.Closer); ok {
+			defer c.Close()
+		}
+	}
+	if maxSize > 0 {
+		r = io.LimitReader(r, maxSize+1)
+	}
+	decoded, err := io.ReadAll(r)
+	if err != nil {
+		return nil, false, err
+	}
+	if maxSize > 0 && int64(len(decoded)) > maxSize {
+		return nil, false, fmt.Errorf("%w: limit is %d bytes", ErrDecodedBodyTooLarge, maxSize)
+	}
+	return decoded, true, nil
+}
+
+// contentEncodings returns the content encodings of header other than identity,
+// in the order they were applied.
+func contentEncodings(header http.Header) []string {
+	var encodings []string
+	for _, value := range header.Values("Content-Encoding") {
+		for encoding := range strings.SplitSeq(value, ",") {
+			if encoding = strings.ToLower(strings.TrimSpace(encoding)); encoding != "" && encoding != "identity" {
+				encodings = append(encodings, encoding)
+			}
+		}
+	}
+	return encodings
+}
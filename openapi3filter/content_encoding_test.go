@@ -0,0 +1,216 @@
+package openapi3filter_test
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+)
+
+func TestContentEncoding(t *testing.T) {
+	const spec = `
+openapi: 3.0.3
+info:
+  title: Pets
+  version: 1.0.0
+paths:
+  /pets:
+    post:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [name]
+              properties:
+                name:
+                  type: string
+                  maxLength: 5
+                kind:
+                  type: string
+                  default: cat
+      responses:
+        '200':
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [id]
+                properties:
+                  id:
+                    type: integer
+`
+	ctx := context.Background()
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(spec))
+	require.NoError(t, err)
+	require.NoError(t, doc.Validate(ctx))
+	router, err := gorillamux.NewRouter(doc)
+	require.NoError(t, err)
+
+	gzipped := func(s string) []byte {
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		_, err := io.WriteString(w, s)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		return buf.Bytes()
+	}
+	zlibbed := func(s string) []byte {
+		var buf bytes.Buffer
+		w := zlib.NewWriter(&buf)
+		_, err := io.WriteString(w, s)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		return buf.Bytes()
+	}
+	deflated := func(s string) []byte {
+		var buf bytes.Buffer
+		w, err := flate.NewWriter(&buf, flate.DefaultCompression)
+		require.NoError(t, err)
+		_, err = io.WriteString(w, s)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		return buf.Bytes()
+	}
+
+	validate := func(encoding string, body []byte, options *openapi3filter.Options) (*http.Request, error) {
+		req, err := http.NewRequest(http.MethodPost, "/pets", bytes.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Content-Encoding", encoding)
+		route, pathParams, err := router.FindRoute(req)
+		require.NoError(t, err)
+		input := &openapi3filter.RequestValidationInput{
+			Request:    req,
+			PathParams: pathParams,
+			Route:      route,
+			Options:    options,
+		}
+		err = openapi3filter.ValidateRequest(ctx, input)
+		return input.Request, err
+	}
+
+	for encoding, body := range map[string][]byte{
+		"gzip":          gzipped(`{"name":"Felix"}`),
+		"x-gzip":        gzipped(`{"name":"Felix"}`),
+		"deflate":       zlibbed(`{"name":"Felix"}`),
+		"Deflate":       deflated(`{"name":"Felix"}`),
+		"deflate, gzip": gzipped(string(zlibbed(`{"name":"Felix"}`))),
+	} {
+		req, err := validate(encoding, body, nil)
+		require.NoError(t, err, encoding)
+
+		// The body is left as it was, without defaults
+		data, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+		require.Equal(t, body, data, encoding)
+		require.NotNil(t, req.GetBody)
+		rc, err := req.GetBody()
+		require.NoError(t, err)
+		data, err = io.ReadAll(rc)
+		require.NoError(t, err)
+		require.Equal(t, body, data, encoding)
+	}
+
+	// Defaults are set in bodies without encoding
+	req, err := validate("identity", []byte(`{"name":"Felix"}`), nil)
+	require.NoError(t, err)
+	data, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"name":"Felix","kind":"cat"}`, string(data))
+
+	status := func(err error) int {
+		require.Error(t, err)
+		var verr *openapi3filter.ValidationError
+		require.ErrorAs(t, openapi3filter.ConvertErrors(err), &verr)
+		return verr.Status
+	}
+
+	// Decoded bodies are validated
+	_, err = validate("gzip", gzipped(`{"name":"Garfield"}`), nil)
+	require.ErrorContains(t, err, "maximum string length is 5")
+	require.Equal(t, http.StatusUnprocessableEntity, status(err))
+	_, err = validate("gzip", gzipped(``), nil)
+	require.ErrorIs(t, err, openapi3filter.ErrInvalidRequired)
+
+	// Malformed bodies
+	_, err = validate("gzip", []byte(`{"name":"Felix"}`), nil)
+	require.ErrorContains(t, err, "failed to decode request content encoding: gzip: invalid header")
+	require.Equal(t, http.StatusBadRequest, status(err))
+
+	// Unsupported encodings, Brotli included until registered
+	_, err = validate("br", []byte(`...`), nil)
+	require.ErrorIs(t, err, openapi3filter.ErrUnsupportedContentEncoding)
+	require.ErrorContains(t, err, `unsupported content encoding "br"`)
+	require.Equal(t, http.StatusUnsupportedMediaType, status(err))
+	p := openapi3filter.NewProblemDetails(err, http.StatusBadRequest)
+	require.Equal(t, http.StatusUnsupportedMediaType, p.Status)
+	require.Equal(t, "content-encoding-unsupported", p.Errors[0].Code)
+
+	// Registered encodings
+	openapi3filter.RegisterContentEncodingDecoder("BR", func(body io.Reader) (io.Reader, error) {
+		data, err := io.ReadAll(body)
+		return strings.NewReader(strings.ToLower(string(data))), err
+	})
+	require.NotNil(t, openapi3filter.RegisteredContentEncodingDecoder("br"))
+	_, err = validate("br", []byte(`{"NAME":"FELIX"}`), nil)
+	require.NoError(t, err)
+	openapi3filter.UnregisterContentEncodingDecoder("br")
+	require.Nil(t, openapi3filter.RegisteredContentEncodingDecoder("br"))
+
+	// Zip bombs
+	bomb := gzipped(`{"name":"` + strings.Repeat("a", 1<<20) + `"}`)
+	require.Less(t, len(bomb), 4096)
+	_, err = validate("gzip", bomb, &openapi3filter.Options{MaxDecodedBodySize: 1 << 16})
+	require.ErrorIs(t, err, openapi3filter.ErrDecodedBodyTooLarge)
+	require.ErrorContains(t, err, "decoded body is too large: limit is 65536 bytes")
+	require.Equal(t, http.StatusRequestEntityTooLarge, status(err))
+	_, err = validate("gzip", bomb, &openapi3filter.Options{MaxDecodedBodySize: -1})
+	require.ErrorContains(t, err, "maximum string length is 5")
+
+	// Responses
+	req, err = http.NewRequest(http.MethodPost, "/pets", nil)
+	require.NoError(t, err)
+	route, pathParams, err := router.FindRoute(req)
+	require.NoError(t, err)
+	validateResponse := func(body []byte) ([]byte, error) {
+		input := &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: &openapi3filter.RequestValidationInput{
+				Request:    req,
+				PathParams: pathParams,
+				Route:      route,
+			},
+			Status: http.StatusOK,
+			Header: http.Header{"Content-Type": {"application/json"}, "Content-Encoding": {"gzip"}},
+		}
+		input.SetBodyBytes(body)
+		err := openapi3filter.ValidateResponse(ctx, input)
+		data, rerr := io.ReadAll(input.Body)
+		require.NoError(t, rerr)
+		return data, err
+	}
+	body := gzipped(`{"id":1}`)
+	data, err = validateResponse(body)
+	require.NoError(t, err)
+	require.Equal(t, body, data)
+	_, err = validateResponse(gzipped(`{"id":"x"}`))
+	require.ErrorContains(t, err, "value must be an integer")
+	_, err = validateResponse([]byte(`{"id":1}`))
+	require.ErrorContains(t, err, "failed to decode response content encoding")
+
+	p = openapi3filter.NewProblemDetails(err, http.StatusInternalServerError)
+	require.Equal(t, "content-encoding-invalid", p.Errors[0].Code)
+}
@@ -0,0 +1,204 @@
+package openapi3filter
+
+import (
+	"encoding/json"
+	"strconv"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/routers"
+)
+
+// Coverage records the parts of a document exercised by the requests and responses validated with it
+// (see Options.Coverage): operations, response statuses, media types, parameters, and, within schemas,
+// enum values, oneOf and anyOf branches and optional properties. Report tells which were never exercised.
+//
+// Values are recorded whether they are valid or not, but the subschemas of schemas are only credited
+// with the values that match them. Schemas of OpenAPI 3.1 documents, validated as JSON Schema 2020-12,
+// are not recorded.
+//
+// A Coverage is safe for concurrent use, e.g. by Validator.Middleware.
+type Coverage struct {
+	doc *openapi3.T
+
+	mu         sync.Mutex
+	operations map[*openapi3.Operation]*operationCoverage
+	schemas    map[*openapi3.Schema]*schemaCoverage
+}
+
+type operationCoverage struct {
+	requests      int
+	parameters    map[string]int // by location and name, e.g. "query limit"
+	requestBodies map[string]int // by media type
+	responses     map[string]*responseCoverage
+}
+
+type responseCoverage struct {
+	count      int
+	mediaTypes map[string]int
+}
+
+type schemaCoverage struct {
+	visits     int
+	enum       map[int]int
+	properties map[string]int
+}
+
+// NewCoverage returns a Coverage of doc.
+func NewCoverage(doc *openapi3.T) *Coverage {
+	return &Coverage{
+		doc:        doc,
+		operations: make(map[*openapi3.Operation]*operationCoverage),
+		schemas:    make(map[*openapi3.Schema]*schemaCoverage),
+	}
+}
+
+// coverageOptions returns the schema validation options recording the schemas visited, if options has a Coverage.
+func coverageOptions(options *Options) []openapi3.SchemaValidationOption {
+	if options.Coverage == nil {
+		return nil
+	}
+	return []openapi3.SchemaValidationOption{openapi3.SchemaVisited(options.Coverage.recordSchema)}
+}
+
+func (c *Coverage) operation(route *routers.Route) *operationCoverage {
+	op := c.operations[route.Operation]
+	if op == nil {
+		op = &operationCoverage{
+			parameters:    make(map[string]int),
+			requestBodies: make(map[string]int),
+			responses:     make(map[string]*responseCoverage),
+		}
+		c.operations[route.Operation] = op
+	}
+	return op
+}
+
+func (c *Coverage) recordRequest(route *routers.Route) {
+	if c == nil || route == nil || route.Operation == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.operation(route).requests++
+}
+
+func (c *Coverage) recordParameter(route *routers.Route, parameter *openapi3.Parameter) {
+	if c == nil || route == nil || route.Operation == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.operation(route).parameters[parameterKey(parameter)]++
+}
+
+func (c *Coverage) recordRequestBody(route *routers.Route, content openapi3.Content, mediaType *openapi3.MediaType) {
+	if c == nil || route == nil || route.Operation == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.operation(route).requestBodies[contentKey(content, mediaType)]++
+}
+
+// recordResponse records a response of status and, if mediaType is not nil, its media type in content.
+func (c *Coverage) recordResponse(route *routers.Route, status int, content openapi3.Content, mediaType *openapi3.MediaType) {
+	if c == nil || route == nil || route.Operation == nil || route.Operation.Responses == nil {
+		return
+	}
+	key := responseKey(route.Operation.Responses, status)
+	if key == "" {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	responses := c.operation(route).responses
+	response := responses[key]
+	if response == nil {
+		response = &responseCoverage{mediaTypes: make(map[string]int)}
+		responses[key] = response
+	}
+	if mediaType == nil {
+		response.count++
+	} else {
+		response.mediaTypes[contentKey(content, mediaType)]++
+	}
+}
+
+func (c *Coverage) recordSchema(schema *openapi3.Schema, value any, err error) {
+	if err != nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	sc := c.schemas[schema]
+	if sc == nil {
+		sc = &schemaCoverage{}
+		c.schemas[schema] = sc
+	}
+	sc.visits++
+	if len(schema.Enum) != 0 {
+		if i := enumIndex(schema.Enum, value); i >= 0 {
+			if sc.enum == nil {
+				sc.enum = make(map[int]int)
+			}
+			sc.enum[i]++
+		}
+	}
+	if object, ok := value.(map[string]any); ok && len(schema.Properties) != 0 {
+		for name := range object {
+			if _, ok := schema.Properties[name]; ok {
+				if sc.properties == nil {
+					sc.properties = make(map[string]int)
+				}
+				sc.properties[name]++
+			}
+		}
+	}
+}
+
+func parameterKey(parameter *openapi3.Parameter) string {
+	return parameter.In + " " + parameter.Name
+}
+
+// contentKey returns the key of mediaType in content.
+func contentKey(content openapi3.Content, mediaType *openapi3.MediaType) string {
+	for key, mt := range content {
+		if mt == mediaType {
+			return key
+		}
+	}
+	return ""
+}
+
+// responseKey returns the key of the response of responses for status: the status itself,
+// its range (e.g. "4XX") or "default", as Responses.Status and Responses.Default select it.
+func responseKey(responses *openapi3.Responses, status int) string {
+	key := strconv.Itoa(status)
+	if responses.Value(key) != nil {
+		return key
+	}
+	if 99 < status && status < 600 {
+		if key = key[:1] + "XX"; responses.Value(key) != nil {
+			return key
+		}
+	}
+	if responses.Default() != nil {
+		return "default"
+	}
+	return ""
+}
+
+// enumIndex returns the index of value in enum, comparing their JSON encodings, or -1.
+func enumIndex(enum []any, value any) int {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return -1
+	}
+	for i, v := range enum {
+		if d, err := json.Marshal(v); err == nil && string(d) == string(data) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,353 @@
+package openapi3filter
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// CoverageKind is a kind of part of a document reported by Coverage.
+type CoverageKind string
+
+const (
+	CoverageOperation        CoverageKind = "operation"
+	CoverageParameter        CoverageKind = "parameter"
+	CoverageRequestBody      CoverageKind = "request-body"
+	CoverageResponse         CoverageKind = "response"
+	CoverageResponseBody     CoverageKind = "response-body"
+	CoverageEnumValue        CoverageKind = "enum-value"
+	CoverageBranch           CoverageKind = "branch"
+	CoverageOptionalProperty CoverageKind = "optional-property"
+)
+
+var coverageKinds = []CoverageKind{
+	CoverageOperation,
+	CoverageParameter,
+	CoverageRequestBody,
+	CoverageResponse,
+	CoverageResponseBody,
+	CoverageEnumValue,
+	CoverageBranch,
+	CoverageOptionalProperty,
+}
+
+// CoverageReport tells which parts of a document were exercised.
+// It marshals to JSON and WriteHTML renders it as a web page.
+type CoverageReport struct {
+	// Totals counts the covered and the reported parts of each kind.
+	Totals map[CoverageKind]*CoverageTotal `json:"totals"`
+	// Items are the reported parts, in the order of the document:
+	// its paths (sorted) and their operations, then the schemas of its components.
+	Items []*CoverageItem `json:"items"`
+}
+
+// CoverageTotal counts the covered parts of a kind.
+type CoverageTotal struct {
+	Covered int `json:"covered"`
+	Total   int `json:"total"`
+}
+
+// Percent returns the percentage of covered parts, 100 if there are none.
+func (total *CoverageTotal) Percent() float64 {
+	if total.Total == 0 {
+		return 100
+	}
+	return 100 * float64(total.Covered) / float64(total.Total)
+}
+
+// CoverageItem is a part of a document.
+type CoverageItem struct {
+	Kind CoverageKind `json:"kind"`
+	// Pointer is the JSON pointer of the part in the document, e.g. "#/paths/~1pets/get/responses/200".
+	Pointer string `json:"pointer"`
+	// Name describes the part, e.g. "GET /pets 200" or "query limit".
+	Name string `json:"name"`
+	// Count is the number of times the part was exercised.
+	Count int `json:"count"`
+}
+
+// Covered reports whether the part was exercised.
+func (item *CoverageItem) Covered() bool {
+	return item.Count != 0
+}
+
+// Uncovered returns the parts that were never exercised.
+func (report *CoverageReport) Uncovered() []*CoverageItem {
+	var items []*CoverageItem
+	for _, item := range report.Items {
+		if !item.Covered() {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Report returns what was exercised so far of the document.
+// Schemas are reported where they are first met in the document; those of components at their definition.
+func (c *Coverage) Report() *CoverageReport {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	r := &coverageReporter{
+		c:        c,
+		report:   &CoverageReport{Totals: make(map[CoverageKind]*CoverageTotal, len(coverageKinds))},
+		pointers: make(map[*openapi3.Schema]string),
+	}
+	for _, kind := range coverageKinds {
+		r.report.Totals[kind] = &CoverageTotal{}
+	}
+	doc := c.doc
+
+	// Schemas of components are reported at their definition
+	if doc.Components != nil {
+		for _, name := range componentNames(doc.Components.Schemas) {
+			if ref := doc.Components.Schemas[name]; ref != nil && ref.Value != nil {
+				r.pointers[ref.Value] = "#/components/schemas/" + escapePointer(name)
+			}
+		}
+	}
+
+	if doc.Paths != nil {
+		paths := doc.Paths.Keys()
+		slices.Sort(paths)
+		for _, path := range paths {
+			r.pathItem(path, doc.Paths.Value(path))
+		}
+	}
+
+	if doc.Components != nil {
+		for _, name := range componentNames(doc.Components.Schemas) {
+			r.schema(doc.Components.Schemas[name], "#/components/schemas/"+escapePointer(name))
+		}
+	}
+	return r.report
+}
+
+type coverageReporter struct {
+	c        *Coverage
+	report   *CoverageReport
+	pointers map[*openapi3.Schema]string
+	walked   map[*openapi3.Schema]bool
+}
+
+func (r *coverageReporter) add(kind CoverageKind, pointer, name string, count int) {
+	r.report.Items = append(r.report.Items, &CoverageItem{Kind: kind, Pointer: pointer, Name: name, Count: count})
+	total := r.report.Totals[kind]
+	total.Total++
+	if count != 0 {
+		total.Covered++
+	}
+}
+
+var coverageMethods = []string{
+	http.MethodGet, http.MethodPut, http.MethodPost, http.MethodDelete,
+	http.MethodOptions, http.MethodHead, http.MethodPatch, http.MethodTrace, http.MethodConnect,
+}
+
+func (r *coverageReporter) pathItem(path string, pathItem *openapi3.PathItem) {
+	pathPointer := "#/paths/" + escapePointer(path)
+	operations := pathItem.Operations()
+	for _, method := range coverageMethods {
+		operation := operations[method]
+		if operation == nil {
+			continue
+		}
+		pointer := pathPointer + "/" + strings.ToLower(method)
+		name := method + " " + path
+		oc := r.c.operations[operation]
+		if oc == nil {
+			oc = &operationCoverage{}
+		}
+		r.add(CoverageOperation, pointer, name, oc.requests)
+
+		for i, ref := range pathItem.Parameters {
+			if p := ref.Value; p != nil && operation.Parameters.GetByInAndName(p.In, p.Name) == nil {
+				r.parameter(p, fmt.Sprintf("%s/parameters/%d", pathPointer, i), name, oc)
+			}
+		}
+		for i, ref := range operation.Parameters {
+			if p := ref.Value; p != nil {
+				r.parameter(p, fmt.Sprintf("%s/parameters/%d", pointer, i), name, oc)
+			}
+		}
+
+		if operation.RequestBody != nil && operation.RequestBody.Value != nil {
+			content := operation.RequestBody.Value.Content
+			for _, mediaType := range componentNames(content) {
+				mtPointer := pointer + "/requestBody/content/" + escapePointer(mediaType)
+				r.add(CoverageRequestBody, mtPointer, name+" "+mediaType, oc.requestBodies[mediaType])
+				r.schema(content[mediaType].Schema, mtPointer+"/schema")
+			}
+		}
+
+		if operation.Responses != nil {
+			statuses := operation.Responses.Keys()
+			slices.Sort(statuses)
+			for _, status := range statuses {
+				ref := operation.Responses.Value(status)
+				if ref == nil || ref.Value == nil {
+					continue
+				}
+				rc := oc.responses[status]
+				if rc == nil {
+					rc = &responseCoverage{}
+				}
+				responsePointer := pointer + "/responses/" + escapePointer(status)
+				r.add(CoverageResponse, responsePointer, name+" "+status, rc.count)
+				for _, header := range componentNames(ref.Value.Headers) {
+					if h := ref.Value.Headers[header]; h != nil && h.Value != nil {
+						r.schema(h.Value.Schema, responsePointer+"/headers/"+escapePointer(header)+"/schema")
+					}
+				}
+				for _, mediaType := range componentNames(ref.Value.Content) {
+					mtPointer := responsePointer + "/content/" + escapePointer(mediaType)
+					r.add(CoverageResponseBody, mtPointer, name+" "+status+" "+mediaType, rc.mediaTypes[mediaType])
+					r.schema(ref.Value.Content[mediaType].Schema, mtPointer+"/schema")
+				}
+			}
+		}
+	}
+}
+
+func (r *coverageReporter) parameter(p *openapi3.Parameter, pointer, operation string, oc *operationCoverage) {
+	r.add(CoverageParameter, pointer, operation+" "+parameterKey(p), oc.parameters[parameterKey(p)])
+	r.schema(p.Schema, pointer+"/schema")
+}
+
+// schema reports the enum values, branches and optional properties of a schema and of its subschemas.
+func (r *coverageReporter) schema(ref *openapi3.SchemaRef, pointer string) {
+	if ref == nil || ref.Value == nil {
+		return
+	}
+	schema := ref.Value
+	if p, ok := r.pointers[schema]; ok {
+		// Reported at its definition
+		if p != pointer {
+			return
+		}
+	}
+	if r.walked == nil {
+		r.walked = make(map[*openapi3.Schema]bool)
+	}
+	if r.walked[schema] {
+		return
+	}
+	r.walked[schema] = true
+
+	sc := r.c.schemas[schema]
+	if sc == nil {
+		sc = &schemaCoverage{}
+	}
+	for i, value := range schema.Enum {
+		data, _ := json.Marshal(value)
+		r.add(CoverageEnumValue, pointer+"/enum/"+strconv.Itoa(i), string(data), sc.enum[i])
+	}
+	for _, xOf := range []struct {
+		keyword string
+		refs    openapi3.SchemaRefs
+	}{{"oneOf", schema.OneOf}, {"anyOf", schema.AnyOf}} {
+		for i, branch := range xOf.refs {
+			if branch == nil || branch.Value == nil {
+				continue
+			}
+			name := xOf.keyword + "/" + strconv.Itoa(i)
+			if branch.Ref != "" {
+				name += " " + branch.Ref
+			} else if branch.Value.Title != "" {
+				name += " " + branch.Value.Title
+			}
+			count := 0
+			if bc := r.c.schemas[branch.Value]; bc != nil {
+				count = bc.visits
+			}
+			r.add(CoverageBranch, pointer+"/"+xOf.keyword+"/"+strconv.Itoa(i), name, count)
+		}
+	}
+	for _, name := range componentNames(schema.Properties) {
+		if !slices.Contains(schema.Required, name) {
+			r.add(CoverageOptionalProperty, pointer+"/properties/"+escapePointer(name), name, sc.properties[name])
+		}
+	}
+
+	for _, name := range componentNames(schema.Properties) {
+		r.schema(schema.Properties[name], pointer+"/properties/"+escapePointer(name))
+	}
+	r.schema(schema.Items, pointer+"/items")
+	r.schema(schema.AdditionalProperties.Schema, pointer+"/additionalProperties")
+	r.schema(schema.Not, pointer+"/not")
+	for _, xOf := range []struct {
+		keyword string
+		refs    openapi3.SchemaRefs
+	}{{"allOf", schema.AllOf}, {"oneOf", schema.OneOf}, {"anyOf", schema.AnyOf}} {
+		for i, sub := range xOf.refs {
+			r.schema(sub, pointer+"/"+xOf.keyword+"/"+strconv.Itoa(i))
+		}
+	}
+}
+
+func componentNames[V any](m map[string]V) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func escapePointer(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
+
+// WriteHTML renders the report as a web page.
+func (report *CoverageReport) WriteHTML(w io.Writer) error {
+	type kindTotal struct {
+		Kind CoverageKind
+		*CoverageTotal
+	}
+	totals := make([]kindTotal, 0, len(coverageKinds))
+	for _, kind := range coverageKinds {
+		if total := report.Totals[kind]; total != nil {
+			totals = append(totals, kindTotal{Kind: kind, CoverageTotal: total})
+		}
+	}
+	return coverageTemplate.Execute(w, map[string]any{"Totals": totals, "Items": report.Items})
+}
+
+var coverageTemplate = template.Must(template.New("coverage").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>OpenAPI coverage</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 0.2em 0.6em; text-align: left; }
+tr.covered td { background: #e6ffed; }
+tr.uncovered td { background: #ffeef0; }
+code { font-size: 0.9em; }
+</style>
+</head>
+<body>
+<h1>OpenAPI coverage</h1>
+<table>
+<tr><th>Kind</th><th>Covered</th><th>Total</th><th>%</th></tr>
+{{- range .Totals}}
+<tr><td>{{.Kind}}</td><td>{{.Covered}}</td><td>{{.Total}}</td><td>{{printf "%.1f" .Percent}}</td></tr>
+{{- end}}
+</table>
+<table>
+<tr><th>Kind</th><th>Name</th><th>Pointer</th><th>Count</th></tr>
+{{- range .Items}}
+<tr class="{{if .Covered}}covered{{else}}uncovered{{end}}"><td>{{.Kind}}</td><td>{{.Name}}</td><td><code>{{.Pointer}}</code></td><td>{{.Count}}</td></tr>
+{{- end}}
+</table>
+</body>
+</html>
+`))
@@ -0,0 +1,161 @@
+package openapi3filter_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+)
+
+func TestCoverage(t *testing.T) {
+	const spec = `
+openapi: 3.0.3
+info:
+  title: Pets
+  version: 1.0.0
+paths:
+  /pets:
+    get:
+      parameters:
+        - name: kind
+          in: query
+          schema:
+            type: string
+            enum: [cat, dog, bird]
+        - name: limit
+          in: query
+          schema:
+            type: integer
+      responses:
+        '200':
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: '#/components/schemas/Pet'
+        4XX:
+          description: error
+          content:
+            application/problem+json:
+              schema:
+                type: object
+    post:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Pet'
+      responses:
+        '201':
+          description: created
+components:
+  schemas:
+    Pet:
+      oneOf:
+        - $ref: '#/components/schemas/Cat'
+        - $ref: '#/components/schemas/Dog'
+    Cat:
+      type: object
+      required: [meows]
+      properties:
+        meows:
+          type: boolean
+        name:
+          type: string
+    Dog:
+      type: object
+      required: [barks]
+      properties:
+        barks:
+          type: boolean
+        name:
+          type: string
+`
+	ctx := context.Background()
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(spec))
+	require.NoError(t, err)
+	require.NoError(t, doc.Validate(ctx))
+	router, err := gorillamux.NewRouter(doc)
+	require.NoError(t, err)
+
+	coverage := openapi3filter.NewCoverage(doc)
+	handler := openapi3filter.NewValidator(router,
+		openapi3filter.ValidationOptions(openapi3filter.Options{Coverage: coverage}),
+	).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"meows": true, "name": "Tom"}]`))
+	}))
+
+	for _, target := range []string{"/pets?kind=cat", "/pets?kind=dog", "/pets?kind=fish"} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	}
+
+	report := coverage.Report()
+	counts := make(map[string]int, len(report.Items))
+	for _, item := range report.Items {
+		counts[item.Pointer] = item.Count
+	}
+	require.Equal(t, map[string]int{
+		"#/paths/~1pets/get":                                                 3,
+		"#/paths/~1pets/get/parameters/0":                                    3,
+		"#/paths/~1pets/get/parameters/0/schema/enum/0":                      1,
+		"#/paths/~1pets/get/parameters/0/schema/enum/1":                      1,
+		"#/paths/~1pets/get/parameters/0/schema/enum/2":                      0,
+		"#/paths/~1pets/get/parameters/1":                                    0,
+		"#/paths/~1pets/get/responses/200":                                   2,
+		"#/paths/~1pets/get/responses/200/content/application~1json":         2,
+		"#/paths/~1pets/get/responses/4XX":                                   0,
+		"#/paths/~1pets/get/responses/4XX/content/application~1problem+json": 0,
+		"#/paths/~1pets/post":                                                0,
+		"#/paths/~1pets/post/requestBody/content/application~1json":          0,
+		"#/paths/~1pets/post/responses/201":                                  0,
+		"#/components/schemas/Pet/oneOf/0":                                   2,
+		"#/components/schemas/Pet/oneOf/1":                                   0,
+		"#/components/schemas/Cat/properties/name":                           2,
+		"#/components/schemas/Dog/properties/name":                           0,
+	}, counts)
+
+	require.Equal(t, &openapi3filter.CoverageTotal{Covered: 1, Total: 2}, report.Totals[openapi3filter.CoverageOperation])
+	require.Equal(t, &openapi3filter.CoverageTotal{Covered: 2, Total: 3}, report.Totals[openapi3filter.CoverageEnumValue])
+	require.Equal(t, 50.0, report.Totals[openapi3filter.CoverageBranch].Percent())
+	require.Len(t, report.Uncovered(), 9)
+	require.Equal(t, &openapi3filter.CoverageItem{
+		Kind:    openapi3filter.CoverageBranch,
+		Pointer: "#/components/schemas/Pet/oneOf/1",
+		Name:    "oneOf/1 #/components/schemas/Dog",
+	}, report.Uncovered()[8])
+
+	data, err := json.Marshal(report)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `{"kind":"operation","pointer":"#/paths/~1pets/post","name":"POST /pets","count":0}`)
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteHTML(&buf))
+	require.Contains(t, buf.String(), `<tr class="uncovered"><td>branch</td><td>oneOf/1 #/components/schemas/Dog</td>`)
+	require.True(t, strings.HasPrefix(buf.String(), "<!DOCTYPE html>"))
+
+	// Coverage is optional
+	req := httptest.NewRequest(http.MethodGet, "/pets?kind=bird", nil)
+	route, pathParams, err := router.FindRoute(req)
+	require.NoError(t, err)
+	err = openapi3filter.ValidateRequest(ctx, &openapi3filter.RequestValidationInput{
+		Request:    req,
+		PathParams: pathParams,
+		Route:      route,
+	})
+	require.NoError(t, err)
+	require.Equal(t, &openapi3filter.CoverageTotal{Covered: 2, Total: 3}, coverage.Report().Totals[openapi3filter.CoverageEnumValue])
+}
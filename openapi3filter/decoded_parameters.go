@@ -0,0 +1,143 @@
+package openapi3filter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// DecodedParameters holds the values of the parameters of a request, as decoded
+// and validated by ValidateRequest when set in RequestValidationInput.Parameters, with defaults applied (unless Options.SkipSettingDefaults).
+// Values are coerced to their schema types: int64 (or int32), float64, bool, string,
+// []any or map[string]any, while defaults keep the type they were loaded with (e.g. float64 numbers).
+// Absent optional parameters without a default are missing.
+//
+// Header names are canonicalized (see http.CanonicalHeaderKey).
+type DecodedParameters struct {
+	Path   map[string]any
+	Query  map[string]any
+	Header map[string]any
+	Cookie map[string]any
+}
+
+// Get returns the value of the parameter named name, in location in (e.g. openapi3.ParameterInQuery).
+func (params *DecodedParameters) Get(in, name string) (any, bool) {
+	if params == nil {
+		return nil, false
+	}
+	var values map[string]any
+	switch in {
+	case openapi3.ParameterInPath:
+		values = params.Path
+	case openapi3.ParameterInQuery:
+		values = params.Query
+	case openapi3.ParameterInHeader:
+		values, name = params.Header, http.CanonicalHeaderKey(name)
+	case openapi3.ParameterInCookie:
+		values = params.Cookie
+	}
+	value, ok := values[name]
+	return value, ok
+}
+
+func (params *DecodedParameters) set(parameter *openapi3.Parameter, value any) {
+	var values *map[string]any
+	name := parameter.Name
+	switch parameter.In {
+	case openapi3.ParameterInPath:
+		values = &params.Path
+	case openapi3.ParameterInQuery:
+		values = &params.Query
+	case openapi3.ParameterInHeader:
+		values, name = &params.Header, http.CanonicalHeaderKey(name)
+	case openapi3.ParameterInCookie:
+		values = &params.Cookie
+	default:
+		return
+	}
+	if *values == nil {
+		*values = make(map[string]any)
+	}
+	(*values)[name] = value
+}
+
+// Bind sets the fields of the struct pointed to by dst from the parameters they are tagged with.
+// Tags name a location and a parameter, for instance:
+//
+//	type ListPetsParams struct {
+//		ID      int64    `path:"id"`
+//		Limit   *int     `query:"limit"`
+//		Tags    []string `query:"tags"`
+//		TraceID string   `header:"X-Trace-Id"`
+//		Session string   `cookie:"session"`
+//	}
+//
+// Values are converted to the type of their field as encoding/json would.
+// Fields of absent parameters are left untouched.
+func (params *DecodedParameters) Bind(dst any) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("cannot bind parameters to %T: expected a pointer to a struct", dst)
+	}
+	rv = rv.Elem()
+	rt := rv.Type()
+	for i := range rt.NumField() {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		for _, in := range []string{openapi3.ParameterInPath, openapi3.ParameterInQuery, openapi3.ParameterInHeader, openapi3.ParameterInCookie} {
+			name, ok := field.Tag.Lookup(in)
+			if !ok {
+				continue
+			}
+			value, ok := params.Get(in, name)
+			if !ok {
+				break
+			}
+			data, err := json.Marshal(value)
+			if err != nil {
+				return fmt.Errorf("cannot bind %s parameter %q: %w", in, name, err)
+			}
+			if err := json.Unmarshal(data, rv.Field(i).Addr().Interface()); err != nil {
+				return fmt.Errorf("cannot bind %s parameter %q to field %s: %w", in, name, field.Name, err)
+			}
+			break
+		}
+	}
+	return nil
+}
+
+type parametersContextKey struct{}
+
+// ContextWithParameters returns a copy of ctx carrying the decoded parameters of a request.
+// Validator.Middleware sets it on the context of the requests it passes on.
+// Callers of ValidateRequest use it to pass on the RequestValidationInput.Parameters they set.
+func ContextWithParameters(ctx context.Context, params *DecodedParameters) context.Context {
+	return context.WithValue(ctx, parametersContextKey{}, params)
+}
+
+// ParametersFromContext returns the parameters stored by ContextWithParameters, or nil.
+//
+// Only Validator.Middleware stores them on its own: ValidateRequest never changes the context of
+// the request it validates, and records the parameters in RequestValidationInput.Parameters only.
+func ParametersFromContext(ctx context.Context) *DecodedParameters {
+	params, _ := ctx.Value(parametersContextKey{}).(*DecodedParameters)
+	return params
+}
+
+// BindParameters binds the parameters decoded when validating r into dst.
+// It fails unless they were stored on the context of r (see ParametersFromContext).
+// See DecodedParameters.Bind.
+func BindParameters(r *http.Request, dst any) error {
+	params := ParametersFromContext(r.Context())
+	if params == nil {
+		return errors.New("request has no decoded parameters: was it validated by Validator.Middleware?")
+	}
+	return params.Bind(dst)
+}
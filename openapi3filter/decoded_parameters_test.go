@@ -0,0 +1,158 @@
+package openapi3filter_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+)
+
+const decodedParametersSpec = `
+openapi: 3.0.0
+info:
+  title: Pets
+  version: '1.0'
+paths:
+  /owners/{owner}/pets:
+    parameters:
+    - name: owner
+      in: path
+      required: true
+      schema: {type: integer}
+    get:
+      parameters:
+      - name: limit
+        in: query
+        schema: {type: integer, default: 20}
+      - name: tags
+        in: query
+        explode: false
+        schema: {type: array, items: {type: string}}
+      - name: filter
+        in: query
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                name: {type: string}
+      - name: X-Trace-Id
+        in: header
+        schema: {type: string}
+      - name: session
+        in: cookie
+        schema: {type: string}
+      - name: verbose
+        in: query
+        schema: {type: boolean}
+      responses:
+        '200':
+          description: OK
+`
+
+type listPetsParams struct {
+	Owner   int64             `path:"owner"`
+	Limit   *int              `query:"limit"`
+	Tags    []string          `query:"tags"`
+	Filter  map[string]string `query:"filter"`
+	TraceID string            `header:"x-trace-id"`
+	Session string            `cookie:"session"`
+	Verbose bool              `query:"verbose"`
+}
+
+func TestValidateRequestDecodedParameters(t *testing.T) {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData([]byte(decodedParametersSpec))
+	require.NoError(t, err)
+	err = doc.Validate(loader.Context)
+	require.NoError(t, err)
+	router, err := gorillamux.NewRouter(doc)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, `/owners/7/pets?tags=a,b&filter={"name":"rex"}`, nil)
+	req.Header.Set("X-Trace-Id", "abc")
+	req.AddCookie(&http.Cookie{Name: "session", Value: "s3cr3t"})
+	route, pathParams, err := router.FindRoute(req)
+	require.NoError(t, err)
+
+	// Only recorded when asked
+	input := &openapi3filter.RequestValidationInput{Request: req, PathParams: pathParams, Route: route}
+	err = openapi3filter.ValidateRequest(context.Background(), input)
+	require.NoError(t, err)
+	require.Nil(t, input.Parameters)
+	require.Same(t, req, input.Request)
+
+	params := &openapi3filter.DecodedParameters{}
+	input.Parameters = params
+	err = openapi3filter.ValidateRequest(context.Background(), input)
+	require.NoError(t, err)
+	require.Same(t, req, input.Request)
+	// ValidateRequest leaves the context of the request alone
+	require.Nil(t, openapi3filter.ParametersFromContext(input.Request.Context()))
+	require.Equal(t, map[string]any{"owner": int64(7)}, params.Path)
+	require.Equal(t, map[string]any{
+		"limit":  float64(20),
+		"tags":   []any{"a", "b"},
+		"filter": map[string]any{"name": "rex"},
+	}, params.Query)
+	value, ok := params.Get(openapi3.ParameterInHeader, "x-trace-id")
+	require.True(t, ok)
+	require.Equal(t, "abc", value)
+	_, ok = params.Get(openapi3.ParameterInQuery, "verbose")
+	require.False(t, ok)
+
+	var bound listPetsParams
+	err = openapi3filter.BindParameters(req.WithContext(openapi3filter.ContextWithParameters(req.Context(), params)), &bound)
+	require.NoError(t, err)
+	limit := 20
+	require.Equal(t, listPetsParams{
+		Owner:   7,
+		Limit:   &limit,
+		Tags:    []string{"a", "b"},
+		Filter:  map[string]string{"name": "rex"},
+		TraceID: "abc",
+		Session: "s3cr3t",
+	}, bound)
+
+	err = params.Bind(bound)
+	require.EqualError(t, err, `cannot bind parameters to openapi3filter_test.listPetsParams: expected a pointer to a struct`)
+	var mismatched struct {
+		Tags int `query:"tags"`
+	}
+	err = params.Bind(&mismatched)
+	require.ErrorContains(t, err, `cannot bind query parameter "tags" to field Tags`)
+
+	err = openapi3filter.BindParameters(req, &bound)
+	require.EqualError(t, err, `request has no decoded parameters: was it validated by Validator.Middleware?`)
+}
+
+func TestValidatorMiddlewareDecodedParameters(t *testing.T) {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData([]byte(decodedParametersSpec))
+	require.NoError(t, err)
+	err = doc.Validate(loader.Context)
+	require.NoError(t, err)
+	router, err := gorillamux.NewRouter(doc)
+	require.NoError(t, err)
+
+	h := openapi3filter.NewValidator(router).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var params listPetsParams
+		err := openapi3filter.BindParameters(r, &params)
+		require.NoError(t, err)
+		require.Equal(t, int64(7), params.Owner)
+		require.Equal(t, 20, *params.Limit)
+		require.True(t, params.Verbose)
+		_, _ = io.WriteString(w, "ok")
+	}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/owners/7/pets?verbose=true", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "ok", w.Body.String())
+}
@@ -0,0 +1,145 @@
+package openapi3filter
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/routers"
+)
+
+// Dispatcher is an http.Handler that serves each request with the handler
+// registered for the operationId of the operation it routes to.
+//
+// Handlers find the matched route and its path parameters with RouteFromContext.
+type Dispatcher struct {
+	router             routers.Router
+	handlers           map[string]http.Handler
+	errorEncoder       ErrorEncoder
+	allowUnimplemented bool
+}
+
+// DispatcherOption defines an option that may be specified when creating a
+// Dispatcher.
+type DispatcherOption func(*Dispatcher)
+
+// AllowUnimplemented, if set, lets operations without a handler through
+// NewDispatcher. Requests routed to such operations are answered 501 Not Implemented.
+func AllowUnimplemented(allow bool) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.allowUnimplemented = allow
+	}
+}
+
+// DispatchErrorEncoder sets the ErrorEncoder used when no handler serves a request.
+// Errors passed to it are converted with ConvertErrors: routers.ErrPathNotFound
+// becomes a 404, routers.ErrMethodNotAllowed a 405 and unimplemented operations a 501.
+// It defaults to DefaultErrorEncoder.
+func DispatchErrorEncoder(enc ErrorEncoder) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.errorEncoder = enc
+	}
+}
+
+// NewDispatcher returns a Dispatcher serving the operations of doc, as routed by router,
+// with the given handlers keyed by operationId.
+//
+// It fails if an operation of doc has no operationId or no handler,
+// or if a handler matches no operation of doc.
+func NewDispatcher(doc *openapi3.T, router routers.Router, handlers map[string]http.Handler, options ...DispatcherOption) (*Dispatcher, error) {
+	d := &Dispatcher{
+		router:       router,
+		handlers:     handlers,
+		errorEncoder: DefaultErrorEncoder,
+	}
+	for i := range options {
+		options[i](d)
+	}
+
+	var me openapi3.MultiError
+	operationIDs := make(map[string]struct{})
+	for _, path := range doc.Paths.InMatchingOrder() {
+		operations := doc.Paths.Value(path).Operations()
+		methods := make([]string, 0, len(operations))
+		for method := range operations {
+			methods = append(methods, method)
+		}
+		slices.Sort(methods)
+
+		for _, method := range methods {
+			operationID := operations[method].OperationID
+			if operationID == "" {
+				if !d.allowUnimplemented {
+					me = append(me, fmt.Errorf("operation %s %s has no operationId", method, path))
+				}
+				continue
+			}
+			operationIDs[operationID] = struct{}{}
+			if _, ok := handlers[operationID]; !ok && !d.allowUnimplemented {
+				me = append(me, fmt.Errorf("operation %q (%s %s) has no handler", operationID, method, path))
+			}
+		}
+	}
+
+	unknown := make([]string, 0, len(handlers))
+	for operationID := range handlers {
+		if _, ok := operationIDs[operationID]; !ok {
+			unknown = append(unknown, operationID)
+		}
+	}
+	slices.Sort(unknown)
+	for _, operationID := range unknown {
+		me = append(me, fmt.Errorf("handler %q matches no operation", operationID))
+	}
+
+	if len(me) != 0 {
+		return nil, me
+	}
+	return d, nil
+}
+
+// ServeHTTP implements http.Handler.
+func (d *Dispatcher) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	route, pathParams, ok := RouteFromContext(ctx)
+	if !ok {
+		var err error
+		if route, pathParams, err = d.router.FindRoute(r); err != nil {
+			d.errorEncoder(ctx, ConvertErrors(err), w)
+			return
+		}
+		r = r.WithContext(ContextWithRoute(ctx, route, pathParams))
+	}
+
+	handler, ok := d.handlers[route.Operation.OperationID]
+	if !ok || route.Operation.OperationID == "" {
+		d.errorEncoder(ctx, &ValidationError{
+			Status: http.StatusNotImplemented,
+			Title:  fmt.Sprintf("operation %s %s is not implemented", strings.ToUpper(route.Method), route.Path),
+		}, w)
+		return
+	}
+	handler.ServeHTTP(w, r)
+}
+
+type routeContextKey struct{}
+
+type routeContextValue struct {
+	route      *routers.Route
+	pathParams map[string]string
+}
+
+// ContextWithRoute returns a copy of ctx carrying the route a request matched and its path parameters.
+// Validator.Middleware and Dispatcher set it for the handlers they call.
+func ContextWithRoute(ctx context.Context, route *routers.Route, pathParams map[string]string) context.Context {
+	return context.WithValue(ctx, routeContextKey{}, routeContextValue{route: route, pathParams: pathParams})
+}
+
+// RouteFromContext returns the route and path parameters stored by ContextWithRoute, if any.
+func RouteFromContext(ctx context.Context) (*routers.Route, map[string]string, bool) {
+	v, ok := ctx.Value(routeContextKey{}).(routeContextValue)
+	return v.route, v.pathParams, ok
+}
@@ -0,0 +1,141 @@
+package openapi3filter_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+)
+
+const dispatcherSpec = `
+openapi: 3.0.0
+info:
+  title: Pets
+  version: '1.0'
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      responses:
+        '200':
+          description: OK
+    post:
+      operationId: createPet
+      responses:
+        '201':
+          description: Created
+  /pets/{id}:
+    get:
+      operationId: getPet
+      parameters:
+      - name: id
+        in: path
+        required: true
+        schema: {type: integer}
+      responses:
+        '200':
+          description: OK
+`
+
+func loadDispatcherSpec(t *testing.T) *openapi3.T {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData([]byte(dispatcherSpec))
+	require.NoError(t, err)
+	err = doc.Validate(loader.Context)
+	require.NoError(t, err)
+	return doc
+}
+
+func TestDispatcher(t *testing.T) {
+	doc := loadDispatcherSpec(t)
+	router, err := gorillamux.NewRouter(doc)
+	require.NoError(t, err)
+
+	handlers := map[string]http.Handler{
+		"listPets": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, "all pets")
+		}),
+		"getPet": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route, pathParams, ok := openapi3filter.RouteFromContext(r.Context())
+			require.True(t, ok)
+			_, _ = io.WriteString(w, route.Operation.OperationID+" "+pathParams["id"])
+		}),
+	}
+
+	_, err = openapi3filter.NewDispatcher(doc, router, handlers)
+	require.EqualError(t, err, `operation "createPet" (POST /pets) has no handler`)
+
+	d, err := openapi3filter.NewDispatcher(doc, router, handlers, openapi3filter.AllowUnimplemented(true))
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		method, path string
+		status       int
+		body         string
+	}{
+		{http.MethodGet, "/pets", http.StatusOK, "all pets"},
+		{http.MethodGet, "/pets/42", http.StatusOK, "getPet 42"},
+		{http.MethodPost, "/pets", http.StatusNotImplemented, "[501][][] operation POST /pets is not implemented"},
+		{http.MethodDelete, "/pets", http.StatusMethodNotAllowed, "[405][][] method not allowed"},
+		{http.MethodGet, "/owners", http.StatusNotFound, "[404][][] no matching operation was found"},
+	} {
+		t.Run(tc.method+" "+tc.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			d.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, nil))
+			require.Equal(t, tc.status, w.Code)
+			require.Equal(t, tc.body, strings.TrimSpace(w.Body.String()))
+		})
+	}
+}
+
+func TestDispatcherUnknownHandler(t *testing.T) {
+	doc := loadDispatcherSpec(t)
+	doc.Paths.Value("/pets").Post.OperationID = ""
+	router, err := gorillamux.NewRouter(doc)
+	require.NoError(t, err)
+
+	noop := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+	_, err = openapi3filter.NewDispatcher(doc, router, map[string]http.Handler{
+		"listPets":  noop,
+		"getPet":    noop,
+		"deletePet": noop,
+	})
+	require.EqualError(t, err, `operation POST /pets has no operationId | handler "deletePet" matches no operation`)
+}
+
+func TestDispatcherBehindValidator(t *testing.T) {
+	doc := loadDispatcherSpec(t)
+	router, err := gorillamux.NewRouter(doc)
+	require.NoError(t, err)
+
+	noop := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+	d, err := openapi3filter.NewDispatcher(doc, router, map[string]http.Handler{
+		"listPets":  noop,
+		"createPet": noop,
+		"getPet": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, pathParams, _ := openapi3filter.RouteFromContext(r.Context())
+			_, _ = io.WriteString(w, pathParams["id"])
+		}),
+	})
+	require.NoError(t, err)
+
+	quiet := openapi3filter.OnLog(func(context.Context, string, error) {})
+	h := openapi3filter.NewValidator(router, quiet).Middleware(d)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/pets/42", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "42", w.Body.String())
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/pets/abc", nil))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
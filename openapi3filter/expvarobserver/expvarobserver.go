@@ -0,0 +1,95 @@
+// Package expvarobserver publishes metrics of the validation of requests and responses with expvar,
+// as an openapi3filter.Observer:
+//
+//	options := &openapi3filter.Options{Observer: expvarobserver.Publish("openapi")}
+//
+// Importing expvar registers its handler of /debug/vars on http.DefaultServeMux,
+// which is why this package is not part of openapi3filter.
+package expvarobserver
+
+import (
+	"context"
+	"expvar"
+	"strconv"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3filter"
+)
+
+// Unrouted is the key of the metrics of the requests no route was found for.
+const Unrouted = "-"
+
+// Observer aggregates the stages of the validation of requests and responses in an expvar.Map,
+// per operation, then per stage:
+//
+//	{"listPets": {"parameters": {"count": 3, "errors": 1, "durationNanoseconds": 4200, "codes": {"schema-maximum": 1}}}}
+//
+// Operations are keyed by operationId, else by method and path (e.g. "GET /pets"), or Unrouted.
+// Stages count "bodyBytes" when they read bodies, and responses count their "statuses".
+type Observer struct {
+	m  *expvar.Map
+	mu sync.Mutex
+}
+
+var _ openapi3filter.Observer = (*Observer)(nil)
+
+// New returns an Observer aggregating metrics in m.
+func New(m *expvar.Map) *Observer {
+	return &Observer{m: m}
+}
+
+// Publish returns an Observer aggregating metrics in a new expvar.Map published as name.
+// Like expvar.Publish, it panics if name is already registered.
+func Publish(name string) *Observer {
+	return New(expvar.NewMap(name))
+}
+
+// Map returns the map the metrics are aggregated in.
+func (o *Observer) Map() *expvar.Map {
+	return o.m
+}
+
+// Observe implements openapi3filter.Observer.
+func (o *Observer) Observe(_ context.Context, observation *openapi3filter.Observation) {
+	operation := observation.OperationID
+	if operation == "" {
+		operation = observation.Operation()
+	}
+	if operation == "" {
+		operation = Unrouted
+	}
+	stage := o.child(o.child(o.m, operation), string(observation.Stage))
+
+	stage.Add("count", 1)
+	stage.Add("durationNanoseconds", int64(observation.Duration))
+	if observation.BodySize != 0 {
+		stage.Add("bodyBytes", int64(observation.BodySize))
+	}
+	if observation.Status != 0 {
+		o.child(stage, "statuses").Add(strconv.Itoa(observation.Status), 1)
+	}
+	if observation.Outcome == openapi3filter.OutcomeError {
+		stage.Add("errors", 1)
+		if len(observation.Codes) != 0 {
+			codes := o.child(stage, "codes")
+			for _, code := range observation.Codes {
+				codes.Add(code, 1)
+			}
+		}
+	}
+}
+
+// child returns the map at key in m, adding it if needed.
+func (o *Observer) child(m *expvar.Map, key string) *expvar.Map {
+	if child, ok := m.Get(key).(*expvar.Map); ok {
+		return child
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	child, ok := m.Get(key).(*expvar.Map)
+	if !ok {
+		child = new(expvar.Map)
+		m.Set(key, child)
+	}
+	return child
+}
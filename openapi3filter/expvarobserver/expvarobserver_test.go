@@ -0,0 +1,64 @@
+package expvarobserver_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/openapi3filter/expvarobserver"
+)
+
+func TestObserver(t *testing.T) {
+	ctx := context.Background()
+	observer := expvarobserver.New(new(expvar.Map))
+
+	for _, observation := range []*openapi3filter.Observation{
+		{Stage: openapi3filter.StageParameters, OperationID: "listPets", Duration: time.Microsecond, Outcome: openapi3filter.OutcomeOK},
+		{
+			Stage: openapi3filter.StageParameters, OperationID: "listPets", Duration: 2 * time.Microsecond,
+			Outcome: openapi3filter.OutcomeError, Err: errors.New("invalid"), Codes: []string{"schema-maximum"},
+		},
+		{
+			Stage: openapi3filter.StageResponseValidation, Method: "GET", Path: "/pets", Duration: time.Microsecond,
+			Status: 200, BodySize: 42, Outcome: openapi3filter.OutcomeOK,
+		},
+		{Stage: openapi3filter.StageRouting, Outcome: openapi3filter.OutcomeError, Codes: []string{"route-not-found"}},
+	} {
+		observer.Observe(ctx, observation)
+	}
+
+	var metrics map[string]any
+	require.NoError(t, json.Unmarshal([]byte(observer.Map().String()), &metrics))
+	require.Equal(t, map[string]any{
+		"listPets": map[string]any{
+			"parameters": map[string]any{
+				"count":               2.0,
+				"errors":              1.0,
+				"durationNanoseconds": 3000.0,
+				"codes":               map[string]any{"schema-maximum": 1.0},
+			},
+		},
+		"GET /pets": map[string]any{
+			"response-validation": map[string]any{
+				"count":               1.0,
+				"durationNanoseconds": 1000.0,
+				"bodyBytes":           42.0,
+				"statuses":            map[string]any{"200": 1.0},
+			},
+		},
+		expvarobserver.Unrouted: map[string]any{
+			"routing": map[string]any{
+				"count":               1.0,
+				"errors":              1.0,
+				"durationNanoseconds": 0.0,
+				"codes":               map[string]any{"route-not-found": 1.0},
+			},
+		},
+	}, metrics)
+}
@@ -0,0 +1,56 @@
+package openapi3filter
+
+import (
+	"context"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+type localeContextKey struct{}
+
+// ContextWithLocale returns a copy of ctx carrying the locale of the messages of validation errors
+// (see Options.MessageCatalog).
+// Validator.Middleware sets it from the Accept-Language header of requests.
+func ContextWithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeContextKey{}, locale)
+}
+
+// LocaleFromContext returns the locale stored by ContextWithLocale, or openapi3.DefaultLocale.
+func LocaleFromContext(ctx context.Context) string {
+	if locale, _ := ctx.Value(localeContextKey{}).(string); locale != "" {
+		return locale
+	}
+	return openapi3.DefaultLocale
+}
+
+// localizeOptions returns the schema validation options localizing schema errors in the locale of ctx,
+// if options has a MessageCatalog.
+func localizeOptions(ctx context.Context, options *Options) []openapi3.SchemaValidationOption {
+	if options.MessageCatalog == nil {
+		return nil
+	}
+	return []openapi3.SchemaValidationOption{openapi3.WithMessageCatalog(options.MessageCatalog, LocaleFromContext(ctx))}
+}
+
+// localizeParseErrors returns a copy of the parse errors of err with their reasons set to their messages
+// in the locale of ctx, if options has a MessageCatalog. err itself is left untouched.
+func localizeParseErrors(ctx context.Context, options *Options, err error) error {
+	e, ok := err.(*ParseError)
+	if !ok || options.MessageCatalog == nil {
+		return err
+	}
+	return localizeParseError(options.MessageCatalog, LocaleFromContext(ctx), e)
+}
+
+func localizeParseError(catalog *openapi3.MessageCatalog, locale string, e *ParseError) *ParseError {
+	localized := *e
+	if cause, ok := e.Cause.(*ParseError); ok {
+		localized.Cause = localizeParseError(catalog, locale, cause)
+	}
+	if kind := e.MessageKind(); kind != "" {
+		if msg := catalog.Message(locale, kind, e.MessageParams()); msg != "" {
+			localized.Reason = msg
+		}
+	}
+	return &localized
+}
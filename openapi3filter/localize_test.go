@@ -0,0 +1,116 @@
+package openapi3filter_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+)
+
+func TestLocalizedErrors(t *testing.T) {
+	const spec = `
+openapi: 3.0.3
+info:
+  title: Pets
+  version: 1.0.0
+paths:
+  /pets:
+    post:
+      parameters:
+        - name: limit
+          in: query
+          schema:
+            type: integer
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [name]
+              properties:
+                name:
+                  type: string
+                  maxLength: 3
+      responses:
+        '200':
+          description: ok
+`
+	ctx := context.Background()
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(spec))
+	require.NoError(t, err)
+	require.NoError(t, doc.Validate(ctx))
+	router, err := gorillamux.NewRouter(doc)
+	require.NoError(t, err)
+
+	catalog := openapi3.NewMessageCatalog()
+	require.NoError(t, catalog.Register("fr", map[openapi3.ErrorKind]string{
+		openapi3.ErrorKindSchemaMaxLength:            `la longueur maximale est {{.Limit}}`,
+		openapi3.ErrorKindSchemaRequired:             `la propriété {{printf "%q" .Property}} est manquante`,
+		openapi3.ErrorKindParseInvalidFormat:         `{{printf "%q" .Value}} n'est pas de type {{index .Types 0}}`,
+		openapi3.ErrorKindSchemaAdditionalProperties: `la propriété {{printf "%q" .Property}} n'est pas permise`,
+	}))
+
+	enc := &openapi3filter.ProblemDetailsEncoder{}
+	handler := openapi3filter.NewValidator(router,
+		openapi3filter.OnErr(enc.ErrFunc),
+		openapi3filter.OnLog(func(context.Context, string, error) {}),
+		openapi3filter.ValidationOptions(openapi3filter.Options{MultiError: true, MessageCatalog: catalog}),
+	).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	details := func(acceptLanguage, query, body string) (details []string) {
+		req := httptest.NewRequest(http.MethodPost, "/pets?"+query, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept-Language", acceptLanguage)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		var p openapi3filter.ProblemDetails
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &p))
+		for _, e := range p.Errors {
+			details = append(details, e.Detail)
+		}
+		return
+	}
+
+	require.Equal(t, []string{
+		`"x" n'est pas de type integer`,
+		`la longueur maximale est 3`,
+	}, details("fr-CH, en;q=0.5", "limit=x", `{"name":"Felix"}`))
+	require.Equal(t, []string{
+		`la propriété "name" est manquante`,
+	}, details("fr", "limit=1", `{}`))
+
+	// English by default
+	require.Equal(t, []string{
+		`an invalid integer`,
+		`maximum string length is 3`,
+	}, details("de", "limit=x", `{"name":"Felix"}`))
+	require.Equal(t, []string{
+		`an invalid integer`,
+		`maximum string length is 3`,
+	}, details("", "limit=x", `{"name":"Felix"}`))
+
+	// Without the middleware
+	req := httptest.NewRequest(http.MethodPost, "/pets", strings.NewReader(`{"name":"Felix"}`))
+	req.Header.Set("Content-Type", "application/json")
+	route, pathParams, err := router.FindRoute(req)
+	require.NoError(t, err)
+	err = openapi3filter.ValidateRequest(openapi3filter.ContextWithLocale(ctx, "fr"), &openapi3filter.RequestValidationInput{
+		Request:    req,
+		PathParams: pathParams,
+		Route:      route,
+		Options:    &openapi3filter.Options{MessageCatalog: catalog},
+	})
+	var schemaErr *openapi3.SchemaError
+	require.ErrorAs(t, err, &schemaErr)
+	require.Equal(t, openapi3.ErrorKindSchemaMaxLength, schemaErr.Kind())
+	require.Equal(t, openapi3.MessageParams{Limit: 3, Actual: 5}, schemaErr.Params())
+	require.Equal(t, `la longueur maximale est 3`, schemaErr.Reason)
+}
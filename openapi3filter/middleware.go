@@ -116,10 +116,18 @@ func ValidationOptions(options Options) ValidatorOption {
 
 // Middleware returns an http.Handler which wraps the given handler with
 // request and response validation.
+// The wrapped handler finds the matched route with RouteFromContext
+// and the decoded parameters with ParametersFromContext.
 func (v *Validator) Middleware(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
+		if catalog := v.options.MessageCatalog; catalog != nil {
+			ctx = ContextWithLocale(ctx, catalog.MatchLocale(r.Header.Get("Accept-Language")))
+		}
+		observation := startObservation(&v.options, StageRouting, nil)
 		route, pathParams, err := v.router.FindRoute(r)
+		observation.setRoute(route)
+		observation.done(ctx, err)
 		if err != nil {
 			v.logFunc(ctx, "validation error: failed to find route for "+r.URL.String(), err)
 			v.errFunc(ctx, w, http.StatusNotFound, ErrCodeCannotFindRoute, err)
@@ -130,6 +138,7 @@ func (v *Validator) Middleware(h http.Handler) http.Handler {
 			PathParams: pathParams,
 			Route:      route,
 			Options:    &v.options,
+			Parameters: &DecodedParameters{},
 		}
 		if err = ValidateRequest(ctx, requestValidationInput); err != nil {
 			v.logFunc(ctx, "invalid request", err)
@@ -144,7 +153,9 @@ func (v *Validator) Middleware(h http.Handler) http.Handler {
 			wr = newWarnResponseWrapper(w)
 		}
 
-		h.ServeHTTP(wr, r)
+		r = requestValidationInput.Request
+		rctx := ContextWithParameters(r.Context(), requestValidationInput.Parameters)
+		h.ServeHTTP(wr, r.WithContext(ContextWithRoute(rctx, route, pathParams)))
 
 		if err = ValidateResponse(ctx, &ResponseValidationInput{
 			RequestValidationInput: requestValidationInput,
@@ -0,0 +1,376 @@
+package openapi3filter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// MsgpackBodyDecoder decodes a MessagePack body into the values a JSON body decodes to,
+// so that it is validated against the same schemas:
+//   - maps with string or integer keys to map[string]any, arrays to []any,
+//   - integers and finite floats to json.Number,
+//   - binaries to strings: base64-encoded where the schema has format "byte", raw otherwise,
+//   - timestamps (extension type -1) to RFC 3339 strings.
+//
+// Other extension types are rejected.
+func MsgpackBodyDecoder(body io.Reader, header http.Header, schema *openapi3.SchemaRef, encFn EncodingFn) (any, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, &ParseError{Kind: KindInvalidFormat, Cause: err}
+	}
+	value, err := decodeMsgpack(data)
+	if err != nil {
+		return nil, &ParseError{Kind: KindInvalidFormat, Cause: err}
+	}
+	var s *openapi3.Schema
+	if schema != nil {
+		s = schema.Value
+	}
+	return bytesToStrings(value, s), nil
+}
+
+// decodeMsgpack decodes a MessagePack body, leaving its binaries as byteString values.
+func decodeMsgpack(data []byte) (any, error) {
+	d := &msgpackDecoder{data: data}
+	value, err := d.value(0)
+	if err == nil && d.pos < len(d.data) {
+		err = errors.New("msgpack: unexpected data after top-level value")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+var errMsgpackUnexpectedEnd = errors.New("msgpack: unexpected end of data")
+
+type msgpackDecoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *msgpackDecoder) read(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.pos) {
+		return nil, errMsgpackUnexpectedEnd
+	}
+	b := d.data[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+	return b, nil
+}
+
+// uint reads a big-endian unsigned integer of n bytes.
+func (d *msgpackDecoder) uint(n uint64) (uint64, error) {
+	b, err := d.read(n)
+	if err != nil {
+		return 0, err
+	}
+	var u uint64
+	for _, c := range b {
+		u = u<<8 | uint64(c)
+	}
+	return u, nil
+}
+
+func (d *msgpackDecoder) value(depth int) (any, error) {
+	if depth > maxBinaryBodyDepth {
+		return nil, errors.New("msgpack: maximum nesting depth exceeded")
+	}
+	b, err := d.read(1)
+	if err != nil {
+		return nil, err
+	}
+	c := b[0]
+	switch {
+	case c <= 0x7f:
+		return json.Number(strconv.Itoa(int(c))), nil
+	case c >= 0xe0:
+		return json.Number(strconv.Itoa(int(int8(c)))), nil
+	case c&0xf0 == 0x80:
+		return d.object(uint64(c&0x0f), depth)
+	case c&0xf0 == 0x90:
+		return d.array(uint64(c&0x0f), depth)
+	case c&0xe0 == 0xa0:
+		return d.str(uint64(c & 0x1f))
+	}
+
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.uint(1 << (c - 0xc4))
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.read(n)
+		if err != nil {
+			return nil, err
+		}
+		return byteString(b), nil
+	case 0xc7, 0xc8, 0xc9:
+		n, err := d.uint(1 << (c - 0xc7))
+		if err != nil {
+			return nil, err
+		}
+		return d.ext(n)
+	case 0xca:
+		u, err := d.uint(4)
+		if err != nil {
+			return nil, err
+		}
+		return numberValue(float64(math.Float32frombits(uint32(u)))), nil
+	case 0xcb:
+		u, err := d.uint(8)
+		if err != nil {
+			return nil, err
+		}
+		return numberValue(math.Float64frombits(u)), nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		u, err := d.uint(1 << (c - 0xcc))
+		if err != nil {
+			return nil, err
+		}
+		return json.Number(strconv.FormatUint(u, 10)), nil
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := uint64(1) << (c - 0xd0)
+		u, err := d.uint(size)
+		if err != nil {
+			return nil, err
+		}
+		// Sign-extend
+		shift := 64 - 8*size
+		return json.Number(strconv.FormatInt(int64(u<<shift)>>shift, 10)), nil
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return d.ext(1 << (c - 0xd4))
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.uint(1 << (c - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+		return d.str(n)
+	case 0xdc, 0xdd:
+		n, err := d.uint(2 << (c - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return d.array(n, depth)
+	case 0xde, 0xdf:
+		n, err := d.uint(2 << (c - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return d.object(n, depth)
+	}
+	return nil, fmt.Errorf("msgpack: invalid type 0x%02x", c)
+}
+
+func (d *msgpackDecoder) str(n uint64) (any, error) {
+	b, err := d.read(n)
+	if err != nil {
+		return nil, err
+	}
+	if !utf8.Valid(b) {
+		return nil, errors.New("msgpack: invalid UTF-8 in string")
+	}
+	return string(b), nil
+}
+
+func (d *msgpackDecoder) array(n uint64, depth int) (any, error) {
+	// Each item takes at least one byte
+	items := make([]any, 0, min(n, uint64(len(d.data)-d.pos)))
+	for range n {
+		item, err := d.value(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (d *msgpackDecoder) object(n uint64, depth int) (any, error) {
+	obj := make(map[string]any, min(n, uint64(len(d.data)-d.pos)/2))
+	for range n {
+		key, err := d.value(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		name, err := binaryMapKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("msgpack: %w", err)
+		}
+		if obj[name], err = d.value(depth + 1); err != nil {
+			return nil, err
+		}
+	}
+	return obj, nil
+}
+
+// ext reads the type and the n bytes of data of an extension.
+func (d *msgpackDecoder) ext(n uint64) (any, error) {
+	b, err := d.read(1)
+	if err != nil {
+		return nil, err
+	}
+	typ := int8(b[0])
+	data, err := d.read(n)
+	if err != nil {
+		return nil, err
+	}
+	if typ != -1 {
+		return nil, fmt.Errorf("msgpack: unsupported extension type %d", typ)
+	}
+	switch n {
+	case 4:
+		return timestampValue(time.Unix(int64(binary.BigEndian.Uint32(data)), 0)), nil
+	case 8:
+		u := binary.BigEndian.Uint64(data)
+		return timestampValue(time.Unix(int64(u&(1<<34-1)), int64(u>>34))), nil
+	case 12:
+		return timestampValue(time.Unix(int64(binary.BigEndian.Uint64(data[4:])), int64(binary.BigEndian.Uint32(data)))), nil
+	}
+	return nil, errors.New("msgpack: invalid timestamp")
+}
+
+// MsgpackBodyEncoder encodes a body as MessagePack. Maps are encoded with sorted keys,
+// json.Number values as integers when they are ones, strings that are not valid UTF-8 and []byte
+// as binaries. Values of other types are converted as encoding/json would first.
+func MsgpackBodyEncoder(body any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := encodeMsgpack(&buf, body, 0); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// encodeMsgpackHead writes the smallest head of a string, binary, array or map of length n:
+// its fixed format (if fix is not 0, for n < fixMax), or its 8-bit (if code8 is not 0), 16-bit or 32-bit format.
+func encodeMsgpackHead(buf *bytes.Buffer, fix byte, fixMax int, code8, code16, code32 byte, n int) {
+	switch {
+	case fix != 0 && n < fixMax:
+		buf.WriteByte(fix | byte(n))
+	case code8 != 0 && n <= math.MaxUint8:
+		buf.Write([]byte{code8, byte(n)})
+	case n <= math.MaxUint16:
+		buf.WriteByte(code16)
+		buf.Write(binary.BigEndian.AppendUint16(nil, uint16(n)))
+	default:
+		buf.WriteByte(code32)
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(n)))
+	}
+}
+
+func encodeMsgpackInt(buf *bytes.Buffer, n int64) {
+	switch {
+	case n >= 0:
+		encodeMsgpackUint(buf, uint64(n))
+	case n >= -32:
+		buf.WriteByte(byte(int8(n)))
+	case n >= math.MinInt8:
+		buf.Write([]byte{0xd0, byte(int8(n))})
+	case n >= math.MinInt16:
+		buf.WriteByte(0xd1)
+		buf.Write(binary.BigEndian.AppendUint16(nil, uint16(n)))
+	case n >= math.MinInt32:
+		buf.WriteByte(0xd2)
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(n)))
+	default:
+		buf.WriteByte(0xd3)
+		buf.Write(binary.BigEndian.AppendUint64(nil, uint64(n)))
+	}
+}
+
+func encodeMsgpackUint(buf *bytes.Buffer, n uint64) {
+	switch {
+	case n <= math.MaxInt8:
+		buf.WriteByte(byte(n))
+	case n <= math.MaxUint8:
+		buf.Write([]byte{0xcc, byte(n)})
+	case n <= math.MaxUint16:
+		buf.WriteByte(0xcd)
+		buf.Write(binary.BigEndian.AppendUint16(nil, uint16(n)))
+	case n <= math.MaxUint32:
+		buf.WriteByte(0xce)
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(n)))
+	default:
+		buf.WriteByte(0xcf)
+		buf.Write(binary.BigEndian.AppendUint64(nil, n))
+	}
+}
+
+func encodeMsgpack(buf *bytes.Buffer, v any, depth int) error {
+	if depth > maxBinaryBodyDepth {
+		return errors.New("msgpack: maximum nesting depth exceeded")
+	}
+	if n, ok := toBinaryNumber(v); ok {
+		switch {
+		case n.isUint:
+			encodeMsgpackUint(buf, n.u)
+		case n.isInt:
+			encodeMsgpackInt(buf, n.i)
+		default:
+			buf.WriteByte(0xcb)
+			buf.Write(binary.BigEndian.AppendUint64(nil, math.Float64bits(n.f)))
+		}
+		return nil
+	}
+	switch v := v.(type) {
+	case nil:
+		buf.WriteByte(0xc0)
+	case bool:
+		if v {
+			buf.WriteByte(0xc3)
+		} else {
+			buf.WriteByte(0xc2)
+		}
+	case string:
+		if utf8.ValidString(v) {
+			encodeMsgpackHead(buf, 0xa0, 32, 0xd9, 0xda, 0xdb, len(v))
+		} else {
+			encodeMsgpackHead(buf, 0, 0, 0xc4, 0xc5, 0xc6, len(v))
+		}
+		buf.WriteString(v)
+	case []byte:
+		encodeMsgpackHead(buf, 0, 0, 0xc4, 0xc5, 0xc6, len(v))
+		buf.Write(v)
+	case []any:
+		encodeMsgpackHead(buf, 0x90, 16, 0, 0xdc, 0xdd, len(v))
+		for _, item := range v {
+			if err := encodeMsgpack(buf, item, depth+1); err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		encodeMsgpackHead(buf, 0x80, 16, 0, 0xde, 0xdf, len(v))
+		for _, k := range slices.Sorted(maps.Keys(v)) {
+			encodeMsgpackHead(buf, 0xa0, 32, 0xd9, 0xda, 0xdb, len(k))
+			buf.WriteString(k)
+			if err := encodeMsgpack(buf, v[k], depth+1); err != nil {
+				return err
+			}
+		}
+	default:
+		value, err := binaryBodyValue(v)
+		if err != nil {
+			return err
+		}
+		return encodeMsgpack(buf, value, depth)
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package openapi3filter
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/getkin/kin-openapi/routers"
+)
+
+// Stage is a stage of the validation of requests and responses reported to an Observer.
+type Stage string
+
+const (
+	// StageRouting is the routing of a request by Validator.Middleware.
+	StageRouting Stage = "routing"
+	// StageSecurity is the validation of the security requirements of a request.
+	StageSecurity Stage = "security"
+	// StageParameters is the validation of the parameters of a request, declared or not.
+	StageParameters Stage = "parameters"
+	// StageRequestBodyDecode is the reading and decoding of the body of a request.
+	StageRequestBodyDecode Stage = "request-body-decode"
+	// StageRequestBodyValidation is the validation of the decoded body of a request.
+	StageRequestBodyValidation Stage = "request-body-validation"
+	// StageResponseValidation is the validation of a response, its headers and its body.
+	StageResponseValidation Stage = "response-validation"
+)
+
+// Outcome is the outcome of a Stage.
+type Outcome string
+
+const (
+	OutcomeOK    Outcome = "ok"
+	OutcomeError Outcome = "error"
+)
+
+// Observer is notified of the stages of the validation of requests and responses (see Options.Observer).
+// Observe is called synchronously once each stage is over, possibly concurrently; it must not retain observation.
+type Observer interface {
+	Observe(ctx context.Context, observation *Observation)
+}
+
+// ObserverFunc is an Observer function.
+type ObserverFunc func(ctx context.Context, observation *Observation)
+
+// Observe implements Observer.
+func (f ObserverFunc) Observe(ctx context.Context, observation *Observation) {
+	f(ctx, observation)
+}
+
+// Observers returns an Observer notifying each of observers in turn.
+func Observers(observers ...Observer) Observer {
+	return ObserverFunc(func(ctx context.Context, observation *Observation) {
+		for _, observer := range observers {
+			observer.Observe(ctx, observation)
+		}
+	})
+}
+
+// Observation describes a Stage of the validation of a request or a response.
+type Observation struct {
+	Stage Stage
+
+	// Method, Path and OperationID identify the operation of the route, when one was found.
+	Method      string
+	Path        string
+	OperationID string
+
+	Start    time.Time
+	Duration time.Duration
+
+	// BodySize is the size in bytes of the body read by StageRequestBodyDecode and StageResponseValidation,
+	// and of the decoded body validated by StageRequestBodyValidation.
+	BodySize int
+	// Status is the status of the response, for StageResponseValidation.
+	Status int
+
+	Outcome Outcome
+	// Err is the error the stage failed with, if any.
+	Err error
+	// Codes are the codes of the problem details of Err (see NewProblemDetails), without duplicates.
+	Codes []string
+
+	observer Observer
+}
+
+// Operation returns the method and path of the route, e.g. "GET /pets/{id}", or "" if none was found.
+func (observation *Observation) Operation() string {
+	if observation.Method == "" {
+		return ""
+	}
+	return observation.Method + " " + observation.Path
+}
+
+// startObservation starts the observation of stage, if options has an Observer, and returns nil otherwise.
+// Its methods are safe to call on nil.
+func startObservation(options *Options, stage Stage, route *routers.Route) *Observation {
+	if options.Observer == nil {
+		return nil
+	}
+	observation := &Observation{Stage: stage, Start: time.Now(), observer: options.Observer}
+	observation.setRoute(route)
+	return observation
+}
+
+func (observation *Observation) setRoute(route *routers.Route) {
+	if observation == nil || route == nil {
+		return
+	}
+	observation.Method, observation.Path = route.Method, route.Path
+	if route.Operation != nil {
+		observation.OperationID = route.Operation.OperationID
+	}
+}
+
+func (observation *Observation) setBodySize(size int) {
+	if observation != nil {
+		observation.BodySize = size
+	}
+}
+
+// next ends the observation successfully and starts observing stage with it.
+func (observation *Observation) next(ctx context.Context, stage Stage) {
+	if observation == nil {
+		return
+	}
+	observation.done(ctx, nil)
+	observation.Stage, observation.Start = stage, time.Now()
+	observation.BodySize, observation.Duration = 0, 0
+}
+
+// done ends the observation with err and notifies its observer.
+func (observation *Observation) done(ctx context.Context, err error) {
+	if observation == nil {
+		return
+	}
+	observation.Duration = time.Since(observation.Start)
+	observation.Outcome, observation.Err = OutcomeOK, err
+	if err != nil {
+		observation.Outcome = OutcomeError
+		fallback := http.StatusBadRequest
+		if observation.Stage == StageResponseValidation {
+			fallback = http.StatusInternalServerError
+		}
+		for _, e := range NewProblemDetails(err, fallback).Errors {
+			if e.Code != "" && !slices.Contains(observation.Codes, e.Code) {
+				observation.Codes = append(observation.Codes, e.Code)
+			}
+		}
+	}
+	observation.observer.Observe(ctx, observation)
+}
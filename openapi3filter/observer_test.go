@@ -0,0 +1,151 @@
+package openapi3filter_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+)
+
+func TestObserver(t *testing.T) {
+	const spec = `
+openapi: 3.0.3
+info:
+  title: Pets
+  version: 1.0.0
+paths:
+  /pets:
+    post:
+      operationId: createPet
+      security:
+        - apiKey: []
+      parameters:
+        - name: dryRun
+          in: query
+          schema:
+            type: boolean
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [name]
+              properties:
+                name:
+                  type: string
+      responses:
+        '201':
+          description: created
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [id]
+                properties:
+                  id:
+                    type: integer
+components:
+  securitySchemes:
+    apiKey:
+      type: apiKey
+      in: header
+      name: X-Api-Key
+`
+	ctx := context.Background()
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(spec))
+	require.NoError(t, err)
+	require.NoError(t, doc.Validate(ctx))
+	router, err := gorillamux.NewRouter(doc)
+	require.NoError(t, err)
+
+	var (
+		mu           sync.Mutex
+		observations []openapi3filter.Observation
+	)
+	observer := openapi3filter.ObserverFunc(func(ctx context.Context, observation *openapi3filter.Observation) {
+		mu.Lock()
+		defer mu.Unlock()
+		observations = append(observations, *observation)
+	})
+	stages := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		var stages []string
+		for _, o := range observations {
+			stages = append(stages, string(o.Stage)+" "+string(o.Outcome)+" "+strings.Join(o.Codes, ","))
+		}
+		observations = nil
+		return stages
+	}
+
+	handler := openapi3filter.NewValidator(router,
+		openapi3filter.OnLog(func(context.Context, string, error) {}),
+		openapi3filter.ValidationOptions(openapi3filter.Options{
+			AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+			Observer:           openapi3filter.Observers(observer),
+		}),
+	).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id": "1"}`))
+	}))
+	serve := func(target, body string) {
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	serve("/pets", `{"name": "Tom"}`)
+	require.Equal(t, []string{
+		"routing ok ",
+		"security ok ",
+		"parameters ok ",
+		"request-body-decode ok ",
+		"request-body-validation ok ",
+		"response-validation error schema-type",
+	}, stages())
+
+	serve("/pets?dryRun=maybe", `{}`)
+	require.Equal(t, []string{
+		"routing ok ",
+		"security ok ",
+		"parameters error invalid-format",
+	}, stages())
+
+	serve("/owners", `{}`)
+	require.Equal(t, []string{"routing error route-not-found"}, stages())
+
+	serve("/pets", `{`)
+	require.Equal(t, []string{
+		"routing ok ",
+		"security ok ",
+		"parameters ok ",
+		"request-body-decode error invalid-format",
+	}, stages())
+
+	// Operations, timings and sizes
+	serve("/pets", `{"name": "Tom"}`)
+	observed := observations
+	require.Len(t, observed, 6)
+	for _, o := range observed {
+		require.Equal(t, "createPet", o.OperationID)
+		require.Equal(t, "POST /pets", o.Operation())
+		require.False(t, o.Start.IsZero())
+		require.GreaterOrEqual(t, o.Duration, time.Duration(0))
+	}
+	require.Equal(t, len(`{"name": "Tom"}`), observed[3].BodySize)
+	require.Equal(t, len(`{"name": "Tom"}`), observed[4].BodySize)
+	require.Equal(t, len(`{"id": "1"}`), observed[5].BodySize)
+	require.Equal(t, http.StatusCreated, observed[5].Status)
+	require.Error(t, observed[5].Err)
+}
@@ -28,9 +28,36 @@ type Options struct {
 	// Set RegexCompiler to override the regex implementation
 	RegexCompiler openapi3.RegexCompilerFunc
 
+	// Set CompileSchemas so ValidateRequest and ValidateResponse compile the schemas of each operation
+	// once (see openapi3.Compile) instead of resolving their formats and patterns on every validation.
+	// Compiled schemas are not updated: define global formats (e.g. with openapi3.DefineStringFormatValidator)
+	// and modify the schemas before the first validation.
+	// It has no effect when RegexCompiler or SchemaValidationOptions are set.
+	CompileSchemas bool
+
 	// Set RejectWhenRequestBodyNotSpecified so ValidateRequest fails when request body is present but not defined in the specification
 	RejectWhenRequestBodyNotSpecified bool
 
+	// Set RejectUnacceptableRequests so ValidateRequest fails with a NotAcceptableError when no media type
+	// of the successful (or else default) responses of the operation matches the Accept header of the request
+	RejectUnacceptableRequests bool
+
+	// Set RejectUnacceptableResponses so ValidateResponse fails with a NotAcceptableError when the Content-Type
+	// of the response does not match the Accept header of the request
+	RejectUnacceptableResponses bool
+
+	// Set StrictParameters so ValidateRequest fails on query parameters, headers and cookies the operation
+	// does not declare, and ValidateResponse on response headers, each in the locations it enables
+	StrictParameters *StrictParameters
+
+	// Set Coverage so ValidateRequest and ValidateResponse record the parts of the document
+	// they exercise (see NewCoverage)
+	Coverage *Coverage
+
+	// Set Observer so ValidateRequest, ValidateResponse and Validator.Middleware report
+	// the duration and outcome of each of their stages to it
+	Observer Observer
+
 	// A document with security schemes defined will not pass validation
 	// unless an AuthenticationFunc is defined.
 	// See NoopAuthenticationFunc
@@ -45,6 +72,30 @@ type Options struct {
 	// Additional schema validation options to pass through to schema validation.
 	// Use this to pass document-scoped format validators or other per-validation options.
 	SchemaValidationOptions []openapi3.SchemaValidationOption
+
+	// Set MessageCatalog to localize the reasons of schema and parse errors
+	// in the locale of the context (see ContextWithLocale).
+	// Validator.Middleware then picks the locale matching the Accept-Language header of requests.
+	MessageCatalog *openapi3.MessageCatalog
+
+	// MaxDecodedBodySize caps the size of request and response bodies with a Content-Encoding
+	// once decoded (see RegisterContentEncodingDecoder).
+	// It defaults to DefaultMaxDecodedBodySize; a negative value removes the cap.
+	MaxDecodedBodySize int64
+
+	// MaxBodySize, if positive, is the maximum size in bytes of request and response bodies.
+	// Larger bodies fail validation with a BodyTooLargeError as soon as the limit is reached.
+	// The ExtensionMaxBodySize extension of operations overrides it.
+	MaxBodySize int64
+
+	// MaxBodySizeByContentType overrides MaxBodySize for bodies of some media types,
+	// e.g. "application/json", "image/*" or "*/*".
+	MaxBodySizeByContentType map[string]int64
+
+	// Set LimitBodySizeFromSchema so the sizes of bodies are also limited by their schemas:
+	// by the maxLength of binary and byte strings, and by the maxItems of JSON arrays
+	// (counted while reading them).
+	LimitBodySizeFromSchema bool
 }
 
 // CustomSchemaErrorFunc allows for custom the schema error message.
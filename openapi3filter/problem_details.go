@@ -0,0 +1,319 @@
+package openapi3filter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/routers"
+)
+
+// ProblemDetailsContentType is the media type of ProblemDetails.
+const ProblemDetailsContentType = "application/problem+json"
+
+// ProblemDetails describes the failures of the validation of a request or response
+// as an RFC 9457 problem details object.
+// See https://www.rfc-editor.org/rfc/rfc9457
+type ProblemDetails struct {
+	// A URI reference identifying the problem type, "about:blank" by default.
+	Type string `json:"type" yaml:"type"`
+	// A short, human-readable summary of the problem type: the HTTP status text by default.
+	Title string `json:"title" yaml:"title"`
+	// The HTTP status code of the response.
+	Status int `json:"status" yaml:"status"`
+	// A human-readable explanation specific to this occurrence of the problem.
+	Detail string `json:"detail,omitempty" yaml:"detail,omitempty"`
+	// The URI of the request, if known.
+	Instance string `json:"instance,omitempty" yaml:"instance,omitempty"`
+	// The "errors" extension member, with one entry per failure.
+	Errors []*ProblemError `json:"errors,omitempty" yaml:"errors,omitempty"`
+}
+
+// ProblemError is a failure listed in ProblemDetails.Errors.
+type ProblemError struct {
+	// Code is a stable, kebab-case identifier of the failure, e.g. "parameter-required",
+	// "content-type-unsupported" or "schema-" followed by the failing schema keyword ("schema-pattern").
+	// Errors implementing openapi3.CodedError keep their own code.
+	Code string `json:"code" yaml:"code"`
+	// A human-readable explanation of the failure.
+	Detail string `json:"detail" yaml:"detail"`
+	// In is the location of the failure: "path", "query", "header", "cookie" or "body".
+	In string `json:"in,omitempty" yaml:"in,omitempty"`
+	// Parameter is the name of the offending parameter.
+	Parameter string `json:"parameter,omitempty" yaml:"parameter,omitempty"`
+	// Pointer is the JSON pointer of the offending value within the body or parameter.
+	Pointer string `json:"pointer,omitempty" yaml:"pointer,omitempty"`
+
+	status int
+}
+
+// NewProblemDetails returns the problem details of err, as returned by ValidateRequest, ValidateResponse
+// or a routers.Router, with one entry per error of a MultiError.
+// Errors without a known status (e.g. unknown error types) have status fallback.
+func NewProblemDetails(err error, fallback int) *ProblemDetails {
+	p := &ProblemDetails{Type: "about:blank"}
+	p.add(err, fallback)
+	if len(p.Errors) == 1 {
+		p.Detail = p.Errors[0].Detail
+	} else {
+		p.Detail = fmt.Sprintf("%d errors occurred", len(p.Errors))
+	}
+
+	for i, e := range p.Errors {
+		if i == 0 {
+			p.Status = e.status
+		} else if e.status != p.Status {
+			if p.Status >= 500 || e.status >= 500 {
+				p.Status = http.StatusInternalServerError
+			} else {
+				p.Status = http.StatusBadRequest
+			}
+		}
+	}
+	if p.Status == 0 {
+		p.Status = fallback
+	}
+	p.Title = http.StatusText(p.Status)
+	return p
+}
+
+func (p *ProblemDetails) add(err error, fallback int) {
+	switch e := err.(type) {
+	case openapi3.MultiError:
+		for _, err := range e {
+			p.add(err, fallback)
+		}
+	case *RequestError:
+		if e.Input != nil && e.Input.Request != nil && p.Instance == "" {
+			p.Instance = e.Input.Request.URL.RequestURI()
+		}
+		if me, ok := e.Err.(openapi3.MultiError); ok {
+			for _, err := range me {
+				p.add(&RequestError{Input: e.Input, Parameter: e.Parameter, RequestBody: e.RequestBody, Reason: e.Reason, Err: err}, fallback)
+			}
+			return
+		}
+		p.Errors = append(p.Errors, newRequestProblem(e))
+	case *ResponseError:
+		if in := e.Input; in != nil && in.RequestValidationInput != nil && in.RequestValidationInput.Request != nil && p.Instance == "" {
+			p.Instance = in.RequestValidationInput.Request.URL.RequestURI()
+		}
+		if me, ok := e.Err.(openapi3.MultiError); ok {
+			for _, err := range me {
+				p.add(&ResponseError{Input: e.Input, Reason: e.Reason, Err: err}, fallback)
+			}
+			return
+		}
+		p.Errors = append(p.Errors, newResponseProblem(e))
+	case *routers.RouteError:
+		cErr := convertRouteError(e)
+		code := "route-not-found"
+		if cErr.Status == http.StatusMethodNotAllowed {
+			code = "method-not-allowed"
+		}
+		p.Errors = append(p.Errors, &ProblemError{Code: code, Detail: cErr.Title, status: cErr.Status})
+	case *SecurityRequirementsError:
+		p.Errors = append(p.Errors, &ProblemError{
+			Code:   "security-requirements-unmet",
+			Detail: e.Error(),
+			status: http.StatusUnauthorized,
+		})
+	default:
+		code := "unknown"
+		var coded openapi3.CodedError
+		if errors.As(err, &coded) {
+			code = coded.Code()
+		}
+		p.Errors = append(p.Errors, &ProblemError{Code: code, Detail: err.Error(), status: fallback})
+	}
+}
+
+func newRequestProblem(e *RequestError) *ProblemError {
+	pe := &ProblemError{Detail: e.Error(), status: http.StatusBadRequest}
+	if p := e.Parameter; p != nil {
+		pe.In, pe.Parameter = p.In, p.Name
+	} else if e.RequestBody != nil {
+		pe.In = "body"
+	}
+	if cErr, ok := convertErrors(e, "").(*ValidationError); ok {
+		if cErr.Title != "" {
+			pe.Detail = cErr.Title
+		}
+		if cErr.Status != 0 {
+			pe.status = cErr.Status
+		}
+	}
+
+	var coded openapi3.CodedError
+	var parseErr *ParseError
+	var tooLarge *BodyTooLargeError
+	var notAcceptable *NotAcceptableError
+	var undeclared *UndeclaredParameterError
+	switch {
+	case e.Err == nil && strings.HasPrefix(e.Reason, prefixInvalidCT):
+		pe.Code = "content-type-unsupported"
+		if strings.HasSuffix(e.Reason, `""`) {
+			pe.Code = "content-type-required"
+		}
+	case e.Reason == reasonRequestContentEncoding:
+		pe.Code = contentEncodingProblemCode(e.Err)
+	case errors.As(e.Err, &tooLarge):
+		pe.Code = "body-too-large"
+	case errors.As(e.Err, &notAcceptable):
+		pe.Code = "not-acceptable"
+	case errors.As(e.Err, &undeclared):
+		pe.Code = undeclared.Code()
+		pe.In, pe.Parameter = undeclared.In, undeclared.Name
+	case e.Err == ErrInvalidRequired && e.Parameter != nil:
+		pe.Code = "parameter-required"
+	case e.Err == ErrInvalidRequired:
+		pe.Code = "request-body-required"
+	case e.Err == ErrInvalidEmptyValue:
+		pe.Code = "parameter-empty"
+	case errors.As(e.Err, &parseErr):
+		pe.Code = parseProblemCode(parseErr)
+	case setSchemaProblem(pe, e.Err):
+	case errors.As(e.Err, &coded):
+		pe.Code = coded.Code()
+	default:
+		pe.Code = "request-invalid"
+	}
+	return pe
+}
+
+func newResponseProblem(e *ResponseError) *ProblemError {
+	pe := &ProblemError{Code: "response-invalid", Detail: e.Error(), status: http.StatusInternalServerError}
+	if e.Err != nil && strings.HasPrefix(e.Reason, "response body") {
+		pe.In = "body"
+	}
+	var coded openapi3.CodedError
+	var parseErr *ParseError
+	var tooLarge *BodyTooLargeError
+	var notAcceptable *NotAcceptableError
+	var undeclared *UndeclaredParameterError
+	switch {
+	case e.Reason == reasonResponseContentEncoding:
+		pe.Code = contentEncodingProblemCode(e.Err)
+	case errors.As(e.Err, &tooLarge):
+		pe.Code = "body-too-large"
+	case errors.As(e.Err, &notAcceptable):
+		pe.Code = "not-acceptable"
+	case errors.As(e.Err, &undeclared):
+		pe.Code = undeclared.Code()
+		pe.In, pe.Parameter = undeclared.In, undeclared.Name
+	case errors.As(e.Err, &parseErr):
+		pe.Code = parseProblemCode(parseErr)
+	case setSchemaProblem(pe, e.Err):
+	case errors.As(e.Err, &coded):
+		pe.Code = coded.Code()
+	}
+	return pe
+}
+
+func contentEncodingProblemCode(err error) string {
+	switch {
+	case errors.Is(err, ErrUnsupportedContentEncoding):
+		return "content-encoding-unsupported"
+	case errors.Is(err, ErrDecodedBodyTooLarge):
+		return "body-too-large"
+	}
+	return "content-encoding-invalid"
+}
+
+func parseProblemCode(e *ParseError) string {
+	if e.MessageKind() == openapi3.ErrorKindParseUnsupportedFormat {
+		return "content-type-unsupported"
+	}
+	switch e.Kind {
+	case KindInvalidFormat:
+		return "invalid-format"
+	case KindUnsupportedFormat:
+		return "unsupported-format"
+	default:
+		return "parse-failed"
+	}
+}
+
+// setSchemaProblem sets the code and pointer of pe from err, if it is a schema error.
+func setSchemaProblem(pe *ProblemError, err error) bool {
+	var schemaErr *openapi3.SchemaError
+	if !errors.As(err, &schemaErr) {
+		return false
+	}
+	if ptr := schemaErr.JSONPointer(); len(ptr) != 0 {
+		pe.Pointer = toJSONPointer(ptr)
+	}
+	for {
+		origin, ok := schemaErr.Origin.(*openapi3.SchemaError)
+		if !ok {
+			break
+		}
+		schemaErr = origin
+	}
+	if schemaErr.Reason != "" {
+		pe.Detail = schemaErr.Reason
+	}
+	switch field := schemaErr.SchemaField; field {
+	case "":
+		pe.Code = "schema"
+	case "properties":
+		// Unsupported property
+		pe.Code = "schema-additional-properties"
+	default:
+		// e.g. "schema-max-length"
+		var b strings.Builder
+		b.WriteString("schema-")
+		for _, r := range field {
+			if unicode.IsUpper(r) {
+				b.WriteByte('-')
+			}
+			b.WriteRune(unicode.ToLower(r))
+		}
+		pe.Code = b.String()
+	}
+	return true
+}
+
+// ProblemDetailsEncoder writes errors as RFC 9457 problem details (see NewProblemDetails).
+// Its Encode method is an ErrorEncoder and its ErrFunc method can be passed to OnErr:
+//
+//	enc := &openapi3filter.ProblemDetailsEncoder{}
+//	validator := openapi3filter.NewValidator(router, openapi3filter.OnErr(enc.ErrFunc))
+type ProblemDetailsEncoder struct {
+	// Type, if set, is the "type" of the problems instead of "about:blank".
+	Type string
+	// Title, if set, is the "title" of the problems instead of the HTTP status text.
+	// RFC 9457 expects the title to summarize the problem type, so set it along with Type.
+	Title string
+}
+
+// Encode implements the ErrorEncoder interface for encoding errors as problem details.
+// Errors of unknown types have status 500.
+func (enc *ProblemDetailsEncoder) Encode(ctx context.Context, err error, w http.ResponseWriter) {
+	enc.ErrFunc(ctx, w, http.StatusInternalServerError, ErrCodeOK, err)
+}
+
+// ErrFunc implements ErrFunc for encoding errors as problem details.
+// The status is that of the errors, falling back to status for errors of unknown types.
+func (enc *ProblemDetailsEncoder) ErrFunc(_ context.Context, w http.ResponseWriter, status int, _ ErrCode, err error) {
+	p := NewProblemDetails(err, status)
+	if enc.Type != "" {
+		p.Type = enc.Type
+	}
+	if enc.Title != "" {
+		p.Title = enc.Title
+	}
+	body, marshalErr := json.Marshal(p)
+	if marshalErr != nil {
+		http.Error(w, marshalErr.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set(headerCT, ProblemDetailsContentType)
+	w.WriteHeader(p.Status)
+	_, _ = w.Write(body)
+}
@@ -0,0 +1,208 @@
+package openapi3filter_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+)
+
+func TestProblemDetailsEncoder(t *testing.T) {
+	const spec = `
+openapi: 3.0.3
+info:
+  title: Pets
+  version: 1.0.0
+paths:
+  /pets/{id}:
+    put:
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: integer
+        - name: limit
+          in: query
+          required: true
+          schema:
+            type: integer
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [name]
+              properties:
+                name:
+                  type: string
+                  maxLength: 3
+      responses:
+        '200':
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  id:
+                    type: integer
+`
+	ctx := context.Background()
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(spec))
+	require.NoError(t, err)
+	require.NoError(t, doc.Validate(ctx))
+	router, err := gorillamux.NewRouter(doc)
+	require.NoError(t, err)
+
+	enc := &openapi3filter.ProblemDetailsEncoder{}
+	handler := openapi3filter.NewValidator(router,
+		openapi3filter.OnErr(enc.ErrFunc),
+		openapi3filter.OnLog(func(context.Context, string, error) {}),
+		openapi3filter.Strict(true),
+		openapi3filter.ValidationOptions(openapi3filter.Options{MultiError: true}),
+	).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"id":"abc"}`)
+	}))
+
+	serve := func(method, target, contentType, body string) (*http.Response, string) {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		data, err := io.ReadAll(w.Result().Body)
+		require.NoError(t, err)
+		return w.Result(), string(data)
+	}
+
+	resp, body := serve(http.MethodPut, "/pets/1?limit=x", "application/json", `{"name":"Fido","age":3}`)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	require.Equal(t, "application/problem+json", resp.Header.Get("Content-Type"))
+	require.JSONEq(t, `{
+  "type": "about:blank",
+  "title": "Bad Request",
+  "status": 400,
+  "detail": "2 errors occurred",
+  "instance": "/pets/1?limit=x",
+  "errors": [
+    {
+      "code": "invalid-format",
+      "detail": "an invalid integer",
+      "in": "query",
+      "parameter": "limit"
+    },
+    {
+      "code": "schema-max-length",
+      "detail": "maximum string length is 3",
+      "in": "body",
+      "pointer": "/name"
+    }
+  ]
+}`, body)
+
+	resp, body = serve(http.MethodPut, "/pets/1", "application/json", `{}`)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	require.JSONEq(t, `{
+  "type": "about:blank",
+  "title": "Bad Request",
+  "status": 400,
+  "detail": "2 errors occurred",
+  "instance": "/pets/1",
+  "errors": [
+    {
+      "code": "parameter-required",
+      "detail": "parameter \"limit\" in query is required",
+      "in": "query",
+      "parameter": "limit"
+    },
+    {
+      "code": "schema-required",
+      "detail": "property \"name\" is missing",
+      "in": "body",
+      "pointer": "/name"
+    }
+  ]
+}`, body)
+
+	// A single failure has its own status
+	resp, body = serve(http.MethodPut, "/pets/1?limit=1", "text/plain", `x`)
+	require.Equal(t, http.StatusUnsupportedMediaType, resp.StatusCode)
+	require.JSONEq(t, `{
+  "type": "about:blank",
+  "title": "Unsupported Media Type",
+  "status": 415,
+  "detail": "unsupported content type \"text/plain\"",
+  "instance": "/pets/1?limit=1",
+  "errors": [
+    {
+      "code": "content-type-unsupported",
+      "detail": "unsupported content type \"text/plain\"",
+      "in": "body"
+    }
+  ]
+}`, body)
+
+	resp, body = serve(http.MethodGet, "/pets/1", "application/json", ``)
+	require.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	require.JSONEq(t, `{
+  "type": "about:blank",
+  "title": "Method Not Allowed",
+  "status": 405,
+  "detail": "method not allowed",
+  "errors": [{"code": "method-not-allowed", "detail": "method not allowed"}]
+}`, body)
+
+	resp, body = serve(http.MethodPut, "/pets/1?limit=1", "application/json", `{"name":"Rex"}`)
+	require.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	require.JSONEq(t, `{
+  "type": "about:blank",
+  "title": "Internal Server Error",
+  "status": 500,
+  "detail": "value must be an integer",
+  "instance": "/pets/1?limit=1",
+  "errors": [
+    {
+      "code": "schema-type",
+      "detail": "value must be an integer",
+      "in": "body",
+      "pointer": "/id"
+    }
+  ]
+}`, body)
+
+	// As an ErrorEncoder
+	enc.Type = "https://example.com/problems/validation"
+	w := httptest.NewRecorder()
+	enc.Encode(ctx, errors.New("boom"), w)
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+	require.JSONEq(t, `{
+  "type": "https://example.com/problems/validation",
+  "title": "Internal Server Error",
+  "status": 500,
+  "detail": "boom",
+  "errors": [{"code": "unknown", "detail": "boom"}]
+}`, w.Body.String())
+
+	// The title of the problem type
+	enc.Title = "Validation failed"
+	w = httptest.NewRecorder()
+	enc.Encode(ctx, errors.New("boom"), w)
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+	require.JSONEq(t, `{
+  "type": "https://example.com/problems/validation",
+  "title": "Validation failed",
+  "status": 500,
+  "detail": "boom",
+  "errors": [{"code": "unknown", "detail": "boom"}]
+}`, w.Body.String())
+}
@@ -0,0 +1,611 @@
+package openapi3filter
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// ExtensionProtobufMessage is the schema extension naming the protobuf message type of a body,
+// overriding ProtobufOptions.Message:
+//
+//	requestBody:
+//	  content:
+//	    application/x-protobuf:
+//	      schema:
+//	        $ref: '#/components/schemas/Pet'
+//	        x-protobuf-message: pets.v1.Pet
+const ExtensionProtobufMessage = "x-protobuf-message"
+
+// ProtobufOptions configures the decoder returned by ProtobufDescriptorSet.BodyDecoder.
+type ProtobufOptions struct {
+	// Message is the full name (e.g. "pets.v1.Pet") of the message type of bodies
+	// whose schema has no x-protobuf-message extension.
+	Message string
+
+	// UseProtoNames uses the names of fields of the .proto files as property names,
+	// instead of their JSON names (by default lowerCamelCase).
+	UseProtoNames bool
+
+	// EmitUnpopulated sets the properties of unpopulated fields (except those of oneofs)
+	// to their zero value, or to null for fields with presence (e.g. messages), as gRPC-Gateway does.
+	EmitUnpopulated bool
+}
+
+// BodyDecoder returns a decoder of binary protobuf bodies into their proto3 JSON mapping,
+// as produced by protojson (int64 values and bytes as strings, enums by name, well-known types
+// such as google.protobuf.Timestamp as strings...), so that they are validated against the same schemas
+// as the JSON bodies of a gRPC-Gateway. Unknown fields are ignored.
+//
+// The message type of a body is the one of the x-protobuf-message extension of its schema,
+// or options.Message. The decoder is registered with RegisterBodyDecoder, e.g. for "application/x-protobuf".
+func (set *ProtobufDescriptorSet) BodyDecoder(options ProtobufOptions) (BodyDecoder, error) {
+	if options.Message != "" && !set.HasMessage(options.Message) {
+		return nil, fmt.Errorf("unknown protobuf message type %q", options.Message)
+	}
+	return func(body io.Reader, header http.Header, schema *openapi3.SchemaRef, encFn EncodingFn) (any, error) {
+		name := options.Message
+		if schema != nil && schema.Value != nil {
+			if s, ok := schema.Value.Extensions[ExtensionProtobufMessage].(string); ok {
+				name = s
+			}
+		}
+		message := set.messages[name]
+		if message == nil {
+			if name == "" {
+				return nil, errors.New("protobuf message type of body is not defined")
+			}
+			return nil, fmt.Errorf("unknown protobuf message type %q", name)
+		}
+
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return nil, &ParseError{Kind: KindInvalidFormat, Cause: err}
+		}
+		d := &protobufDecoder{set: set, options: options}
+		value, err := d.message(message, data, 0)
+		if err != nil {
+			return nil, &ParseError{Kind: KindInvalidFormat, Cause: err}
+		}
+		return value, nil
+	}, nil
+}
+
+type protobufDecoder struct {
+	set     *ProtobufDescriptorSet
+	options ProtobufOptions
+}
+
+// protoValue holds the populated fields of a decoded message, by number:
+// values of scalars, *protoValue of messages, []any of repeated fields and map[string]any of maps
+// (values of map entries are nil if missing).
+type protoValue struct {
+	message *protoMessage
+	fields  map[int32]any
+}
+
+// message decodes data, a message, into its JSON value.
+func (d *protobufDecoder) message(message *protoMessage, data []byte, depth int) (any, error) {
+	v := &protoValue{message: message, fields: make(map[int32]any)}
+	if err := d.merge(v, data, depth); err != nil {
+		return nil, err
+	}
+	return d.json(v, depth)
+}
+
+// merge decodes the fields of data into v, as proto.Merge does.
+func (d *protobufDecoder) merge(v *protoValue, data []byte, depth int) error {
+	if depth > maxBinaryBodyDepth {
+		return errors.New("protobuf: maximum nesting depth exceeded")
+	}
+	w := &protoWire{data: data}
+	for len(w.data) > 0 {
+		num, typ, err := w.tag()
+		if err != nil {
+			return err
+		}
+		w.typ = typ
+		f := v.message.byNumber[num]
+		if f == nil || !protoWireTypeMatches(f, typ) {
+			// Unknown fields are ignored
+			if err := w.skip(num, typ, 0); err != nil {
+				return err
+			}
+			continue
+		}
+
+		switch {
+		case f.typ == protoTypeGroup:
+			return fmt.Errorf("protobuf: field %s.%s: groups are not supported", v.message.name, f.name)
+		case f.message != nil && f.message.mapEntry:
+			b, err := w.bytes()
+			if err != nil {
+				return err
+			}
+			if err := d.mergeMapEntry(v, f, b, depth); err != nil {
+				return err
+			}
+		case f.message != nil:
+			b, err := w.bytes()
+			if err != nil {
+				return err
+			}
+			item, _ := v.fields[num].(*protoValue)
+			if f.repeated || item == nil {
+				item = &protoValue{message: f.message, fields: make(map[int32]any)}
+			}
+			if err := d.merge(item, b, depth+1); err != nil {
+				return err
+			}
+			d.setField(v, f, item)
+		case f.repeated && typ == protoWireBytes && f.typ != protoTypeString && f.typ != protoTypeBytes:
+			// Packed scalars
+			b, err := w.bytes()
+			if err != nil {
+				return err
+			}
+			packed := &protoWire{data: b, typ: protoScalarWireType(f.typ)}
+			for len(packed.data) > 0 {
+				item, err := d.scalar(v.message, f, packed)
+				if err != nil {
+					return err
+				}
+				d.setField(v, f, item)
+			}
+			if _, ok := v.fields[num]; !ok {
+				v.fields[num] = []any{}
+			}
+		default:
+			item, err := d.scalar(v.message, f, w)
+			if err != nil {
+				return err
+			}
+			d.setField(v, f, item)
+		}
+	}
+	return nil
+}
+
+// setField sets a value of field f, appending it to repeated fields and clearing the other fields of its oneof.
+func (d *protobufDecoder) setField(v *protoValue, f *protoField, value any) {
+	if f.repeated {
+		items, _ := v.fields[f.number].([]any)
+		v.fields[f.number] = append(items, value)
+		return
+	}
+	if f.oneof >= 0 {
+		for _, other := range v.message.fields {
+			if other.oneof == f.oneof && other != f {
+				delete(v.fields, other.number)
+			}
+		}
+	}
+	v.fields[f.number] = value
+}
+
+func (d *protobufDecoder) mergeMapEntry(v *protoValue, f *protoField, data []byte, depth int) error {
+	entry := &protoValue{message: f.message, fields: make(map[int32]any)}
+	if err := d.merge(entry, data, depth+1); err != nil {
+		return err
+	}
+	keyField := f.message.byNumber[1]
+	if keyField == nil {
+		return fmt.Errorf("protobuf: map entry %s has no key", f.message.name)
+	}
+	key, ok := entry.fields[1]
+	if !ok {
+		key = protoZero(keyField)
+	}
+	entries, _ := v.fields[f.number].(map[string]any)
+	if entries == nil {
+		entries = make(map[string]any)
+		v.fields[f.number] = entries
+	}
+	entries[fmt.Sprint(key)] = entry.fields[2]
+	return nil
+}
+
+// scalar reads a value of field f, of a scalar type.
+func (d *protobufDecoder) scalar(message *protoMessage, f *protoField, w *protoWire) (any, error) {
+	switch f.typ {
+	case protoTypeDouble, protoTypeFixed64, protoTypeSfixed64:
+		u, err := w.fixed64()
+		switch f.typ {
+		case protoTypeDouble:
+			return math.Float64frombits(u), err
+		case protoTypeSfixed64:
+			return int64(u), err
+		}
+		return u, err
+	case protoTypeFloat, protoTypeFixed32, protoTypeSfixed32:
+		u, err := w.fixed32()
+		switch f.typ {
+		case protoTypeFloat:
+			return math.Float32frombits(u), err
+		case protoTypeSfixed32:
+			return int64(int32(u)), err
+		}
+		return uint64(u), err
+	case protoTypeString, protoTypeBytes:
+		b, err := w.bytes()
+		if err != nil {
+			return nil, err
+		}
+		if f.typ == protoTypeBytes {
+			return b, nil
+		}
+		if message.proto3 && !utf8.Valid(b) {
+			// Strings of proto3 fields must be valid UTF-8
+			return nil, fmt.Errorf("protobuf: field %s.%s contains invalid UTF-8", message.name, f.name)
+		}
+		return string(b), nil
+	}
+
+	u, err := w.varint()
+	if err != nil {
+		return nil, err
+	}
+	switch f.typ {
+	case protoTypeInt64:
+		return int64(u), nil
+	case protoTypeInt32, protoTypeEnum:
+		return int64(int32(u)), nil
+	case protoTypeUint32:
+		return uint64(uint32(u)), nil
+	case protoTypeSint32:
+		return int64(int32(uint32(u)>>1) ^ -int32(u&1)), nil
+	case protoTypeSint64:
+		return int64(u>>1) ^ -int64(u&1), nil
+	case protoTypeBool:
+		return u != 0, nil
+	}
+	return u, nil
+}
+
+func protoScalarWireType(typ int32) int {
+	switch typ {
+	case protoTypeDouble, protoTypeFixed64, protoTypeSfixed64:
+		return protoWireFixed64
+	case protoTypeFloat, protoTypeFixed32, protoTypeSfixed32:
+		return protoWireFixed32
+	case protoTypeString, protoTypeBytes, protoTypeMessage:
+		return protoWireBytes
+	case protoTypeGroup:
+		return protoWireStartGroup
+	}
+	return protoWireVarint
+}
+
+// protoWireTypeMatches tells whether a value of wire type typ is one of field f.
+// Other values are unknown fields.
+func protoWireTypeMatches(f *protoField, typ int) bool {
+	expected := protoScalarWireType(f.typ)
+	return typ == expected || (f.repeated && typ == protoWireBytes && expected != protoWireStartGroup)
+}
+
+// protoZero returns the zero value of a field of a scalar type.
+func protoZero(f *protoField) any {
+	switch f.typ {
+	case protoTypeDouble:
+		return float64(0)
+	case protoTypeFloat:
+		return float32(0)
+	case protoTypeBool:
+		return false
+	case protoTypeString:
+		return ""
+	case protoTypeBytes:
+		return []byte{}
+	case protoTypeUint32, protoTypeUint64, protoTypeFixed32, protoTypeFixed64:
+		return uint64(0)
+	}
+	return int64(0)
+}
+
+func protoIsZero(value any) bool {
+	switch v := value.(type) {
+	case float64:
+		return math.Float64bits(v) == 0
+	case float32:
+		return math.Float32bits(v) == 0
+	case bool:
+		return !v
+	case string:
+		return v == ""
+	case []byte:
+		return len(v) == 0
+	case int64:
+		return v == 0
+	case uint64:
+		return v == 0
+	}
+	return false
+}
+
+// json returns the proto3 JSON value of v.
+func (d *protobufDecoder) json(v *protoValue, depth int) (any, error) {
+	if wkt, ok := protoWellKnownTypes[v.message.name]; ok {
+		return wkt(d, v, depth)
+	}
+
+	obj := make(map[string]any, len(v.fields))
+	for _, f := range v.message.fields {
+		name := f.jsonName
+		if d.options.UseProtoNames {
+			name = f.name
+		}
+		value, ok := v.fields[f.number]
+		if ok && !f.presence {
+			switch x := value.(type) {
+			case []any:
+				ok = len(x) > 0
+			case map[string]any:
+				ok = len(x) > 0
+			default:
+				ok = !protoIsZero(x)
+			}
+		}
+		if !ok {
+			if !d.options.EmitUnpopulated || f.oneof >= 0 {
+				continue
+			}
+			switch {
+			case f.message != nil && f.message.mapEntry:
+				obj[name] = map[string]any{}
+				continue
+			case f.repeated:
+				obj[name] = []any{}
+				continue
+			case f.presence:
+				obj[name] = nil
+				continue
+			}
+			value = protoZero(f)
+		}
+		j, err := d.field(f, value, depth)
+		if err != nil {
+			return nil, err
+		}
+		obj[name] = j
+	}
+	return obj, nil
+}
+
+// field returns the JSON value of a field.
+func (d *protobufDecoder) field(f *protoField, value any, depth int) (any, error) {
+	switch x := value.(type) {
+	case []any:
+		items := make([]any, 0, len(x))
+		for _, item := range x {
+			j, err := d.singular(f, item, depth)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, j)
+		}
+		return items, nil
+	case map[string]any:
+		valueField := f.message.byNumber[2]
+		if valueField == nil {
+			return nil, fmt.Errorf("protobuf: map entry %s has no value", f.message.name)
+		}
+		obj := make(map[string]any, len(x))
+		for k, item := range x {
+			if item == nil {
+				if valueField.message != nil {
+					item = &protoValue{message: valueField.message, fields: map[int32]any{}}
+				} else {
+					item = protoZero(valueField)
+				}
+			}
+			j, err := d.singular(valueField, item, depth)
+			if err != nil {
+				return nil, err
+			}
+			obj[k] = j
+		}
+		return obj, nil
+	}
+	return d.singular(f, value, depth)
+}
+
+// singular returns the JSON value of a value of a field that is not repeated.
+func (d *protobufDecoder) singular(f *protoField, value any, depth int) (any, error) {
+	switch x := value.(type) {
+	case *protoValue:
+		return d.json(x, depth+1)
+	case float64:
+		return protoFloatJSON(x, 64), nil
+	case float32:
+		return protoFloatJSON(float64(x), 32), nil
+	case bool, string:
+		return x, nil
+	case []byte:
+		return base64.StdEncoding.EncodeToString(x), nil
+	case int64:
+		switch f.typ {
+		case protoTypeEnum:
+			if f.enum.name == "google.protobuf.NullValue" {
+				return nil, nil
+			}
+			if name, ok := f.enum.values[int32(x)]; ok {
+				return name, nil
+			}
+			return json.Number(strconv.FormatInt(x, 10)), nil
+		case protoTypeInt64, protoTypeSint64, protoTypeSfixed64:
+			return strconv.FormatInt(x, 10), nil
+		}
+		return json.Number(strconv.FormatInt(x, 10)), nil
+	case uint64:
+		if f.typ == protoTypeUint64 || f.typ == protoTypeFixed64 {
+			return strconv.FormatUint(x, 10), nil
+		}
+		return json.Number(strconv.FormatUint(x, 10)), nil
+	}
+	return nil, fmt.Errorf("protobuf: unexpected value of type %T", value)
+}
+
+func protoFloatJSON(f float64, bitSize int) any {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	}
+	return json.Number(strconv.FormatFloat(f, 'g', -1, bitSize))
+}
+
+// protoWellKnownTypes are the JSON mappings of well-known types that are not the one of messages.
+var protoWellKnownTypes map[string]func(d *protobufDecoder, v *protoValue, depth int) (any, error)
+
+func init() {
+	wrapper := func(d *protobufDecoder, v *protoValue, depth int) (any, error) {
+		f := v.message.byNumber[1]
+		if f == nil {
+			return nil, fmt.Errorf("protobuf: invalid %s", v.message.name)
+		}
+		value, ok := v.fields[1]
+		if !ok {
+			value = protoZero(f)
+		}
+		return d.singular(f, value, depth)
+	}
+	protoWellKnownTypes = map[string]func(d *protobufDecoder, v *protoValue, depth int) (any, error){
+		"google.protobuf.Any":         protoAnyJSON,
+		"google.protobuf.BoolValue":   wrapper,
+		"google.protobuf.BytesValue":  wrapper,
+		"google.protobuf.DoubleValue": wrapper,
+		"google.protobuf.Duration":    protoDurationJSON,
+		"google.protobuf.Empty": func(d *protobufDecoder, v *protoValue, depth int) (any, error) {
+			return map[string]any{}, nil
+		},
+		"google.protobuf.FieldMask":   protoFieldMaskJSON,
+		"google.protobuf.FloatValue":  wrapper,
+		"google.protobuf.Int32Value":  wrapper,
+		"google.protobuf.Int64Value":  wrapper,
+		"google.protobuf.ListValue":   protoListValueJSON,
+		"google.protobuf.StringValue": wrapper,
+		"google.protobuf.Struct":      protoStructJSON,
+		"google.protobuf.Timestamp":   protoTimestampJSON,
+		"google.protobuf.UInt32Value": wrapper,
+		"google.protobuf.UInt64Value": wrapper,
+		"google.protobuf.Value":       protoValueJSON,
+	}
+}
+
+func protoAnyJSON(d *protobufDecoder, v *protoValue, depth int) (any, error) {
+	typeURL, _ := v.fields[1].(string)
+	value, _ := v.fields[2].([]byte)
+	if typeURL == "" {
+		if len(value) == 0 {
+			return map[string]any{}, nil
+		}
+		return nil, errors.New("protobuf: google.protobuf.Any has no type URL")
+	}
+	name := typeURL[strings.LastIndexByte(typeURL, '/')+1:]
+	message := d.set.messages[name]
+	if message == nil {
+		return nil, fmt.Errorf("protobuf: unknown message type %q of google.protobuf.Any", typeURL)
+	}
+	j, err := d.message(message, value, depth+1)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := protoWellKnownTypes[name]; ok {
+		return map[string]any{"@type": typeURL, "value": j}, nil
+	}
+	obj := j.(map[string]any)
+	obj["@type"] = typeURL
+	return obj, nil
+}
+
+// protoSecondsNanos returns the seconds and nanos fields of a google.protobuf.Timestamp or Duration.
+func protoSecondsNanos(v *protoValue) (int64, int64) {
+	seconds, _ := v.fields[1].(int64)
+	nanos, _ := v.fields[2].(int64)
+	return seconds, nanos
+}
+
+// protoTrimNanos trims the fractional seconds of s to 0, 3, 6 or 9 digits, as protojson does.
+func protoTrimNanos(s string) string {
+	s = strings.TrimSuffix(s, "000")
+	s = strings.TrimSuffix(s, "000")
+	return strings.TrimSuffix(s, ".000")
+}
+
+func protoTimestampJSON(d *protobufDecoder, v *protoValue, depth int) (any, error) {
+	const minSeconds, maxSeconds = -62135596800, 253402300799 // 0001-01-01 to 9999-12-31
+	seconds, nanos := protoSecondsNanos(v)
+	if seconds < minSeconds || seconds > maxSeconds || nanos < 0 || nanos >= 1e9 {
+		return nil, fmt.Errorf("protobuf: google.protobuf.Timestamp (%d, %d) is out of range", seconds, nanos)
+	}
+	t := time.Unix(seconds, nanos).UTC()
+	return protoTrimNanos(t.Format("2006-01-02T15:04:05.000000000")) + "Z", nil
+}
+
+func protoDurationJSON(d *protobufDecoder, v *protoValue, depth int) (any, error) {
+	const maxSeconds = 315576000000 // 10,000 years
+	seconds, nanos := protoSecondsNanos(v)
+	if seconds < -maxSeconds || seconds > maxSeconds || nanos <= -1e9 || nanos >= 1e9 ||
+		(seconds > 0 && nanos < 0) || (seconds < 0 && nanos > 0) {
+		return nil, fmt.Errorf("protobuf: google.protobuf.Duration (%d, %d) is out of range", seconds, nanos)
+	}
+	sign := ""
+	if seconds < 0 || nanos < 0 {
+		sign, seconds, nanos = "-", -seconds, -nanos
+	}
+	return sign + protoTrimNanos(fmt.Sprintf("%d.%09d", seconds, nanos)) + "s", nil
+}
+
+func protoFieldMaskJSON(d *protobufDecoder, v *protoValue, depth int) (any, error) {
+	paths, _ := v.fields[1].([]any)
+	names := make([]string, 0, len(paths))
+	for _, path := range paths {
+		names = append(names, protoJSONName(path.(string)))
+	}
+	return strings.Join(names, ","), nil
+}
+
+func protoStructJSON(d *protobufDecoder, v *protoValue, depth int) (any, error) {
+	f := v.message.byNumber[1]
+	fields, ok := v.fields[1]
+	if f == nil || !ok {
+		return map[string]any{}, nil
+	}
+	return d.field(f, fields, depth)
+}
+
+func protoListValueJSON(d *protobufDecoder, v *protoValue, depth int) (any, error) {
+	f := v.message.byNumber[1]
+	values, ok := v.fields[1]
+	if f == nil || !ok {
+		return []any{}, nil
+	}
+	return d.field(f, values, depth)
+}
+
+func protoValueJSON(d *protobufDecoder, v *protoValue, depth int) (any, error) {
+	for _, f := range v.message.fields {
+		value, ok := v.fields[f.number]
+		if !ok {
+			continue
+		}
+		if x, ok := value.(float64); ok && (math.IsNaN(x) || math.IsInf(x, 0)) {
+			return nil, fmt.Errorf("protobuf: google.protobuf.Value has invalid number %v", x)
+		}
+		return d.singular(f, value, depth)
+	}
+	return nil, errors.New("protobuf: google.protobuf.Value has no kind")
+}
@@ -0,0 +1,496 @@
+package openapi3filter
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Types of fields (google.protobuf.FieldDescriptorProto.Type).
+const (
+	protoTypeDouble   = 1
+	protoTypeFloat    = 2
+	protoTypeInt64    = 3
+	protoTypeUint64   = 4
+	protoTypeInt32    = 5
+	protoTypeFixed64  = 6
+	protoTypeFixed32  = 7
+	protoTypeBool     = 8
+	protoTypeString   = 9
+	protoTypeGroup    = 10
+	protoTypeMessage  = 11
+	protoTypeBytes    = 12
+	protoTypeUint32   = 13
+	protoTypeEnum     = 14
+	protoTypeSfixed32 = 15
+	protoTypeSfixed64 = 16
+	protoTypeSint32   = 17
+	protoTypeSint64   = 18
+)
+
+// Wire types of the protobuf encoding.
+const (
+	protoWireVarint     = 0
+	protoWireFixed64    = 1
+	protoWireBytes      = 2
+	protoWireStartGroup = 3
+	protoWireEndGroup   = 4
+	protoWireFixed32    = 5
+)
+
+// ProtobufDescriptorSet holds the message and enum types of a protobuf descriptor set,
+// as written by protoc --include_imports --descriptor_set_out (a serialized google.protobuf.FileDescriptorSet).
+// Use BodyDecoder to validate protobuf bodies against OpenAPI schemas.
+type ProtobufDescriptorSet struct {
+	messages map[string]*protoMessage
+	enums    map[string]*protoEnum
+}
+
+type protoMessage struct {
+	name     string
+	fields   []*protoField
+	byNumber map[int32]*protoField
+	mapEntry bool
+	proto3   bool
+}
+
+type protoField struct {
+	name     string
+	jsonName string
+	number   int32
+	typ      int32
+	repeated bool
+	typeName string
+	message  *protoMessage
+	enum     *protoEnum
+	// oneof is the index of the oneof of the field (including the synthetic ones of proto3 optional fields), or -1.
+	oneof int32
+	// presence is true for fields that are populated even when set to their zero value.
+	presence bool
+}
+
+type protoEnum struct {
+	name   string
+	values map[int32]string
+}
+
+// LoadProtobufDescriptorSet reads a protobuf descriptor set file, see ProtobufDescriptorSet.
+func LoadProtobufDescriptorSet(path string) (*ProtobufDescriptorSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseProtobufDescriptorSet(data)
+}
+
+// ParseProtobufDescriptorSet parses a serialized google.protobuf.FileDescriptorSet, see ProtobufDescriptorSet.
+// The types of all fields must be defined in the set.
+func ParseProtobufDescriptorSet(data []byte) (*ProtobufDescriptorSet, error) {
+	set := &ProtobufDescriptorSet{
+		messages: make(map[string]*protoMessage),
+		enums:    make(map[string]*protoEnum),
+	}
+	err := protoFields(data, func(num int32, w *protoWire) error {
+		if num != 1 {
+			return nil
+		}
+		file, err := w.bytes()
+		if err != nil {
+			return err
+		}
+		return set.parseFile(file)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid protobuf descriptor set: %w", err)
+	}
+
+	// Resolve the types of fields
+	for _, message := range set.messages {
+		for _, f := range message.fields {
+			switch f.typ {
+			case protoTypeMessage, protoTypeGroup:
+				if f.message = set.messages[strings.TrimPrefix(f.typeName, ".")]; f.message == nil {
+					return nil, fmt.Errorf("unknown protobuf message type %q of field %s.%s (descriptor sets should be built with --include_imports)", f.typeName, message.name, f.name)
+				}
+				f.presence = f.presence || !f.repeated
+			case protoTypeEnum:
+				if f.enum = set.enums[strings.TrimPrefix(f.typeName, ".")]; f.enum == nil {
+					return nil, fmt.Errorf("unknown protobuf enum type %q of field %s.%s (descriptor sets should be built with --include_imports)", f.typeName, message.name, f.name)
+				}
+			}
+		}
+	}
+	return set, nil
+}
+
+// HasMessage tells whether the set defines the message type of the given full name, e.g. "pets.v1.Pet".
+func (set *ProtobufDescriptorSet) HasMessage(name string) bool {
+	return set.messages[name] != nil
+}
+
+// parseFile parses a google.protobuf.FileDescriptorProto.
+func (set *ProtobufDescriptorSet) parseFile(data []byte) error {
+	var pkg, syntax string
+	var messages, enums [][]byte
+	err := protoFields(data, func(num int32, w *protoWire) (err error) {
+		switch num {
+		case 2:
+			pkg, err = w.string()
+		case 4:
+			var b []byte
+			b, err = w.bytes()
+			messages = append(messages, b)
+		case 5:
+			var b []byte
+			b, err = w.bytes()
+			enums = append(enums, b)
+		case 12:
+			syntax, err = w.string()
+		}
+		return
+	})
+	if err != nil {
+		return err
+	}
+
+	scope := ""
+	if pkg != "" {
+		scope = pkg + "."
+	}
+	for _, b := range messages {
+		if err := set.parseMessage(b, scope, syntax == "proto3"); err != nil {
+			return err
+		}
+	}
+	for _, b := range enums {
+		if err := set.parseEnum(b, scope); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseMessage parses a google.protobuf.DescriptorProto.
+func (set *ProtobufDescriptorSet) parseMessage(data []byte, scope string, proto3 bool) error {
+	message := &protoMessage{byNumber: make(map[int32]*protoField), proto3: proto3}
+	var nested, enums [][]byte
+	err := protoFields(data, func(num int32, w *protoWire) error {
+		var err error
+		switch num {
+		case 1:
+			message.name, err = w.string()
+		case 2:
+			var b []byte
+			if b, err = w.bytes(); err == nil {
+				var f *protoField
+				if f, err = parseProtoField(b, proto3); err == nil {
+					message.fields = append(message.fields, f)
+					message.byNumber[f.number] = f
+				}
+			}
+		case 3:
+			var b []byte
+			b, err = w.bytes()
+			nested = append(nested, b)
+		case 4:
+			var b []byte
+			b, err = w.bytes()
+			enums = append(enums, b)
+		case 7:
+			var b []byte
+			if b, err = w.bytes(); err == nil {
+				// MessageOptions.map_entry
+				err = protoFields(b, func(num int32, w *protoWire) error {
+					if num != 7 {
+						return nil
+					}
+					v, err := w.varint()
+					message.mapEntry = v != 0
+					return err
+				})
+			}
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if message.name == "" {
+		return errors.New("message type without name")
+	}
+
+	message.name = scope + message.name
+	set.messages[message.name] = message
+	for _, b := range nested {
+		if err := set.parseMessage(b, message.name+".", proto3); err != nil {
+			return err
+		}
+	}
+	for _, b := range enums {
+		if err := set.parseEnum(b, message.name+"."); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseProtoField parses a google.protobuf.FieldDescriptorProto.
+func parseProtoField(data []byte, proto3 bool) (*protoField, error) {
+	f := &protoField{oneof: -1}
+	var proto3Optional bool
+	err := protoFields(data, func(num int32, w *protoWire) error {
+		var (
+			v   uint64
+			err error
+		)
+		switch num {
+		case 1:
+			f.name, err = w.string()
+		case 3:
+			v, err = w.varint()
+			f.number = int32(v)
+		case 4:
+			v, err = w.varint()
+			f.repeated = v == 3
+		case 5:
+			v, err = w.varint()
+			f.typ = int32(v)
+		case 6:
+			f.typeName, err = w.string()
+		case 9:
+			v, err = w.varint()
+			f.oneof = int32(v)
+		case 10:
+			f.jsonName, err = w.string()
+		case 17:
+			v, err = w.varint()
+			proto3Optional = v != 0
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if f.name == "" || f.number <= 0 || f.typ < protoTypeDouble || f.typ > protoTypeSint64 {
+		return nil, fmt.Errorf("invalid field %q", f.name)
+	}
+	if f.jsonName == "" {
+		f.jsonName = protoJSONName(f.name)
+	}
+	f.presence = !f.repeated && (!proto3 || proto3Optional || f.oneof >= 0)
+	return f, nil
+}
+
+// parseEnum parses a google.protobuf.EnumDescriptorProto.
+func (set *ProtobufDescriptorSet) parseEnum(data []byte, scope string) error {
+	enum := &protoEnum{values: make(map[int32]string)}
+	err := protoFields(data, func(num int32, w *protoWire) error {
+		switch num {
+		case 1:
+			var err error
+			enum.name, err = w.string()
+			return err
+		case 2:
+			b, err := w.bytes()
+			if err != nil {
+				return err
+			}
+			// EnumValueDescriptorProto
+			var name string
+			var number int32
+			if err := protoFields(b, func(num int32, w *protoWire) error {
+				switch num {
+				case 1:
+					var err error
+					name, err = w.string()
+					return err
+				case 2:
+					v, err := w.varint()
+					number = int32(v)
+					return err
+				}
+				return nil
+			}); err != nil {
+				return err
+			}
+			// The first name of aliased values is used
+			if _, ok := enum.values[number]; !ok {
+				enum.values[number] = name
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if enum.name == "" {
+		return errors.New("enum type without name")
+	}
+	enum.name = scope + enum.name
+	set.enums[enum.name] = enum
+	return nil
+}
+
+// protoJSONName returns the lowerCamelCase JSON name protoc gives to a field.
+func protoJSONName(name string) string {
+	var sb strings.Builder
+	upper := false
+	for _, c := range name {
+		switch {
+		case c == '_':
+			upper = true
+		case upper && 'a' <= c && c <= 'z':
+			sb.WriteRune(c - 'a' + 'A')
+			upper = false
+		default:
+			sb.WriteRune(c)
+			upper = false
+		}
+	}
+	return sb.String()
+}
+
+// protoFields calls f with the number of each field of a message and a reader of its value,
+// skipping the values f does not read.
+func protoFields(data []byte, f func(num int32, w *protoWire) error) error {
+	w := &protoWire{data: data}
+	for len(w.data) > 0 {
+		num, typ, err := w.tag()
+		if err != nil {
+			return err
+		}
+		w.typ = typ
+		before := len(w.data)
+		if err := f(num, w); err != nil {
+			return err
+		}
+		if len(w.data) == before {
+			if err := w.skip(num, typ, 0); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+var errProtoUnexpectedEnd = errors.New("protobuf: unexpected end of data")
+
+// protoWire reads values of the protobuf wire format.
+type protoWire struct {
+	data []byte
+	// typ is the wire type of the value to read.
+	typ int
+}
+
+func (w *protoWire) varint() (uint64, error) {
+	var v uint64
+	for i := 0; i < 10; i++ {
+		if i >= len(w.data) {
+			return 0, errProtoUnexpectedEnd
+		}
+		c := w.data[i]
+		v |= uint64(c&0x7f) << (7 * i)
+		if c < 0x80 {
+			w.data = w.data[i+1:]
+			return v, nil
+		}
+	}
+	return 0, errors.New("protobuf: invalid varint")
+}
+
+func (w *protoWire) fixed32() (uint32, error) {
+	if len(w.data) < 4 {
+		return 0, errProtoUnexpectedEnd
+	}
+	v := uint32(w.data[0]) | uint32(w.data[1])<<8 | uint32(w.data[2])<<16 | uint32(w.data[3])<<24
+	w.data = w.data[4:]
+	return v, nil
+}
+
+func (w *protoWire) fixed64() (uint64, error) {
+	lo, err := w.fixed32()
+	if err != nil {
+		return 0, err
+	}
+	hi, err := w.fixed32()
+	if err != nil {
+		return 0, err
+	}
+	return uint64(hi)<<32 | uint64(lo), nil
+}
+
+func (w *protoWire) bytes() ([]byte, error) {
+	if w.typ != protoWireBytes {
+		return nil, fmt.Errorf("protobuf: unexpected wire type %d", w.typ)
+	}
+	n, err := w.varint()
+	if err != nil {
+		return nil, err
+	}
+	if n > uint64(len(w.data)) {
+		return nil, errProtoUnexpectedEnd
+	}
+	b := w.data[:n]
+	w.data = w.data[n:]
+	return b, nil
+}
+
+func (w *protoWire) string() (string, error) {
+	b, err := w.bytes()
+	return string(b), err
+}
+
+// tag reads the field number and wire type of the next value.
+func (w *protoWire) tag() (int32, int, error) {
+	v, err := w.varint()
+	if err != nil {
+		return 0, 0, err
+	}
+	num, typ := v>>3, int(v&7)
+	if num == 0 || num > 1<<29-1 {
+		return 0, 0, fmt.Errorf("protobuf: invalid field number %d", num)
+	}
+	return int32(num), typ, nil
+}
+
+// skip skips a value of the given wire type, of field num, nested in depth groups.
+func (w *protoWire) skip(num int32, typ int, depth int) error {
+	var err error
+	switch typ {
+	case protoWireVarint:
+		_, err = w.varint()
+	case protoWireFixed64:
+		_, err = w.fixed64()
+	case protoWireFixed32:
+		_, err = w.fixed32()
+	case protoWireBytes:
+		w.typ = typ
+		_, err = w.bytes()
+	case protoWireStartGroup:
+		if depth >= maxBinaryBodyDepth {
+			return errors.New("protobuf: maximum nesting depth exceeded")
+		}
+		for {
+			var (
+				n int32
+				t int
+			)
+			if n, t, err = w.tag(); err != nil {
+				return err
+			}
+			if t == protoWireEndGroup {
+				if n != num {
+					return errors.New("protobuf: mismatched end group")
+				}
+				return nil
+			}
+			if err = w.skip(n, t, depth+1); err != nil {
+				return err
+			}
+		}
+	default:
+		err = fmt.Errorf("protobuf: invalid wire type %d", typ)
+	}
+	return err
+}
@@ -0,0 +1,175 @@
+package openapi3filter_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+)
+
+// The .binpb bodies of testdata/protobuf and their .json mappings were generated with
+// google.golang.org/protobuf (proto.Marshal and protojson.Marshal), from messages of pets.proto and legacy.proto.
+
+func TestProtobufBodyDecoder(t *testing.T) {
+	set, err := openapi3filter.LoadProtobufDescriptorSet("testdata/protobuf/pets.pb")
+	require.NoError(t, err)
+	require.True(t, set.HasMessage("pets.v1.Vet.Visit"))
+	require.False(t, set.HasMessage("pets.v1.Cat"))
+
+	for name, message := range map[string]string{
+		"pet":    "pets.v1.Pet",
+		"vet":    "pets.v1.Vet",
+		"legacy": "pets.v1.LegacyPet",
+	} {
+		body, err := os.ReadFile(filepath.Join("testdata/protobuf", name+".binpb"))
+		require.NoError(t, err)
+		for suffix, options := range map[string]openapi3filter.ProtobufOptions{
+			"":             {Message: message},
+			"_unpopulated": {Message: message, EmitUnpopulated: true},
+			"_proto_names": {Message: message, UseProtoNames: true},
+		} {
+			expected, err := os.ReadFile(filepath.Join("testdata/protobuf", name+suffix+".json"))
+			require.NoError(t, err)
+			decoder, err := set.BodyDecoder(options)
+			require.NoError(t, err)
+			value, err := decoder(bytes.NewReader(body), http.Header{}, nil, nil)
+			require.NoError(t, err, name+suffix)
+			actual, err := json.Marshal(value)
+			require.NoError(t, err)
+			require.JSONEq(t, string(expected), string(actual), name+suffix)
+		}
+	}
+
+	_, err = set.BodyDecoder(openapi3filter.ProtobufOptions{Message: "pets.v1.Cat"})
+	require.EqualError(t, err, `unknown protobuf message type "pets.v1.Cat"`)
+
+	// Descriptor sets must include the types of all fields
+	field := append(protoTagged(1, []byte("toy")), 0x18, 1, 0x28, 11)
+	field = append(field, protoTagged(6, []byte(".toys.Toy"))...)
+	file := append(protoTagged(2, []byte("pets")), protoTagged(4, append(protoTagged(1, []byte("Pet")), protoTagged(2, field)...))...)
+	_, err = openapi3filter.ParseProtobufDescriptorSet(protoTagged(1, file))
+	require.EqualError(t, err, `unknown protobuf message type ".toys.Toy" of field pets.Pet.toy (descriptor sets should be built with --include_imports)`)
+	_, err = openapi3filter.ParseProtobufDescriptorSet([]byte{0x0a, 0x05})
+	require.EqualError(t, err, "invalid protobuf descriptor set: protobuf: unexpected end of data")
+}
+
+// protoTagged returns a length-delimited field of a message, of a number below 16 and a value of less than 128 bytes.
+func protoTagged(num int, value []byte) []byte {
+	return append([]byte{byte(num<<3 | 2), byte(len(value))}, value...)
+}
+
+func TestProtobufBodyValidation(t *testing.T) {
+	const spec = `
+openapi: 3.0.3
+info:
+  title: Pets
+  version: 1.0.0
+paths:
+  /pets:
+    post:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Pet'
+          application/x-protobuf:
+            schema:
+              $ref: '#/components/schemas/Pet'
+      responses:
+        '204':
+          description: ok
+  /toys:
+    post:
+      requestBody:
+        content:
+          application/x-protobuf:
+            schema:
+              x-protobuf-message: pets.v1.Toy
+              type: object
+              required: [name]
+              properties:
+                name:
+                  type: string
+      responses:
+        '204':
+          description: ok
+components:
+  schemas:
+    Pet:
+      type: object
+      required: [name, kind]
+      properties:
+        id:
+          type: string
+          format: int64
+        name:
+          type: string
+          maxLength: 5
+        kind:
+          type: string
+          enum: [KIND_CAT, KIND_DOG]
+        bornAt:
+          type: string
+          format: date-time
+`
+	ctx := context.Background()
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(spec))
+	require.NoError(t, err)
+	require.NoError(t, doc.Validate(ctx))
+	router, err := gorillamux.NewRouter(doc)
+	require.NoError(t, err)
+
+	set, err := openapi3filter.LoadProtobufDescriptorSet("testdata/protobuf/pets.pb")
+	require.NoError(t, err)
+	decoder, err := set.BodyDecoder(openapi3filter.ProtobufOptions{Message: "pets.v1.Pet"})
+	require.NoError(t, err)
+	openapi3filter.RegisterBodyDecoder("application/x-protobuf", decoder)
+	defer openapi3filter.UnregisterBodyDecoder("application/x-protobuf")
+
+	validate := func(path string, body []byte) error {
+		req, err := http.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/x-protobuf")
+		route, pathParams, err := router.FindRoute(req)
+		require.NoError(t, err)
+		return openapi3filter.ValidateRequest(ctx, &openapi3filter.RequestValidationInput{
+			Request:    req,
+			PathParams: pathParams,
+			Route:      route,
+		})
+	}
+
+	// Pet{id: 7, name: "Tom", kind: KIND_CAT, born_at: {seconds: 1}}
+	tom := []byte{0x08, 0x07, 0x12, 0x03, 'T', 'o', 'm', 0x18, 0x01, 0xa2, 0x01, 0x02, 0x08, 0x01}
+	require.NoError(t, validate("/pets", tom))
+
+	// Fields are validated with their JSON mapping
+	body, err := os.ReadFile("testdata/protobuf/pet.binpb")
+	require.NoError(t, err)
+	err = validate("/pets", body)
+	require.ErrorContains(t, err, `Error at "/name": maximum string length is 5`)
+	err = validate("/pets", tom[:7])
+	require.ErrorContains(t, err, `Error at "/kind": property "kind" is missing`)
+
+	// The schema extension selects the message type
+	require.NoError(t, validate("/toys", []byte{0x0a, 0x04, 'b', 'a', 'l', 'l'}))
+	// Unknown fields are ignored
+	err = validate("/toys", []byte{0x10, 0x01})
+	require.ErrorContains(t, err, `property "name" is missing`)
+
+	// Malformed bodies
+	err = validate("/pets", tom[:len(tom)-1])
+	require.ErrorContains(t, err, "failed to decode request body: protobuf: unexpected end of data")
+	err = validate("/pets", []byte{0x12, 0x01, 0xff})
+	require.ErrorContains(t, err, "protobuf: field pets.v1.Pet.name contains invalid UTF-8")
+}
@@ -48,6 +48,9 @@ type ParseError struct {
 	Cause  error
 
 	path []any
+	// message and params are the kind and the parameters of the message of errors built by this package
+	message openapi3.ErrorKind
+	params  openapi3.MessageParams
 }
 
 var _ interface{ Unwrap() error } = ParseError{}
@@ -83,6 +86,23 @@ func (e *ParseError) innerError() string {
 	return strings.Join(msg, ": ")
 }
 
+// MessageKind returns the kind of e, keying its messages in an openapi3.MessageCatalog,
+// or "" if e was not built by this package (e.g. by a custom body decoder).
+func (e *ParseError) MessageKind() openapi3.ErrorKind {
+	return e.message
+}
+
+// MessageParams returns the parameters of the message of e, with its Value,
+// and its Reason as Detail unless its kind has a more specific one.
+func (e *ParseError) MessageParams() openapi3.MessageParams {
+	params := e.params
+	params.Value = e.Value
+	if params.Detail == "" {
+		params.Detail = e.Reason
+	}
+	return params
+}
+
 // RootCause returns a root cause of ParseError.
 func (e *ParseError) RootCause() error {
 	if v, ok := e.Cause.(*ParseError); ok {
@@ -494,9 +514,11 @@ func cutPrefix(raw, prefix string) (string, error) {
 	}
 	if len(raw) < len(prefix) || raw[:len(prefix)] != prefix {
 		return "", &ParseError{
-			Kind:   KindInvalidFormat,
-			Value:  raw,
-			Reason: fmt.Sprintf("a value must be prefixed with %q", prefix),
+			Kind:    KindInvalidFormat,
+			Value:   raw,
+			Reason:  fmt.Sprintf("a value must be prefixed with %q", prefix),
+			message: openapi3.ErrorKindParsePrefix,
+			params:  openapi3.MessageParams{Delimiters: []string{prefix}},
 		}
 	}
 	return raw[len(prefix):], nil
@@ -880,9 +902,11 @@ func propsFromString(src, propDelim, valueDelim string) (map[string]string, erro
 		// to an array with an even number of items.
 		if len(pairs)%2 != 0 {
 			return nil, &ParseError{
-				Kind:   KindInvalidFormat,
-				Value:  src,
-				Reason: fmt.Sprintf("a value must be a list of object's properties in format \"name%svalue\" separated by %s", valueDelim, propDelim),
+				Kind:    KindInvalidFormat,
+				Value:   src,
+				Reason:  fmt.Sprintf("a value must be a list of object's properties in format \"name%svalue\" separated by %s", valueDelim, propDelim),
+				message: openapi3.ErrorKindParseObject,
+				params:  openapi3.MessageParams{Delimiters: []string{valueDelim, propDelim}},
 			}
 		}
 		for i := 0; i < len(pairs)/2; i++ {
@@ -897,9 +921,11 @@ func propsFromString(src, propDelim, valueDelim string) (map[string]string, erro
 		prop := strings.Split(pair, valueDelim)
 		if len(prop) != 2 {
 			return nil, &ParseError{
-				Kind:   KindInvalidFormat,
-				Value:  src,
-				Reason: fmt.Sprintf("a value must be a list of object's properties in format \"name%svalue\" separated by %s", valueDelim, propDelim),
+				Kind:    KindInvalidFormat,
+				Value:   src,
+				Reason:  fmt.Sprintf("a value must be a list of object's properties in format \"name%svalue\" separated by %s", valueDelim, propDelim),
+				message: openapi3.ErrorKindParseObject,
+				params:  openapi3.MessageParams{Delimiters: []string{valueDelim, propDelim}},
 			}
 		}
 		props[prop[0]] = prop[1]
@@ -940,7 +966,7 @@ func makeObject(props map[string]string, schema *openapi3.SchemaRef) (map[string
 		if strings.Contains(value, urlDecoderDelimiter) {
 			// don't support implicit array indexes anymore
 			p := pathFromKeys(keys)
-			return nil, &ParseError{path: p, Kind: KindInvalidFormat, Reason: "array items must be set with indexes"}
+			return nil, &ParseError{path: p, Kind: KindInvalidFormat, Reason: "array items must be set with indexes", message: openapi3.ErrorKindParseArrayIndexes}
 		}
 		deepSet(mobj, keys, value)
 	}
@@ -950,7 +976,7 @@ func makeObject(props map[string]string, schema *openapi3.SchemaRef) (map[string
 	}
 	result, ok := r.(map[string]any)
 	if !ok {
-		return nil, &ParseError{Kind: KindOther, Reason: "invalid param object", Value: result}
+		return nil, &ParseError{Kind: KindOther, Reason: "invalid param object", Value: result, message: openapi3.ErrorKindParseDeepObject}
 	}
 
 	return result, nil
@@ -1016,12 +1042,18 @@ func buildResObj(params map[string]any, parentKeys []string, key string, schema
 		}
 		t, isMap := paramArr.(map[string]any)
 		if !isMap {
-			return nil, &ParseError{path: pathFromKeys(mapKeys), Kind: KindInvalidFormat, Reason: "array items must be set with indexes"}
+			return nil, &ParseError{path: pathFromKeys(mapKeys), Kind: KindInvalidFormat, Reason: "array items must be set with indexes", message: openapi3.ErrorKindParseArrayIndexes}
 		}
 		// intermediate arrays have to be instantiated
 		arr, err := sliceMapToSlice(t)
 		if err != nil {
-			return nil, &ParseError{path: pathFromKeys(mapKeys), Kind: KindInvalidFormat, Reason: fmt.Sprintf("could not convert value map to array: %v", err)}
+			return nil, &ParseError{
+				path:    pathFromKeys(mapKeys),
+				Kind:    KindInvalidFormat,
+				Reason:  fmt.Sprintf("could not convert value map to array: %v", err),
+				message: openapi3.ErrorKindParseArrayConversion,
+				params:  openapi3.MessageParams{Detail: err.Error()},
+			}
 		}
 		resultArr := make([]any /*not 0,*/, len(arr))
 		for i := range arr {
@@ -1080,7 +1112,7 @@ func buildResObj(params map[string]any, parentKeys []string, key string, schema
 		}
 		v, ok := val.(string)
 		if !ok {
-			return nil, &ParseError{path: pathFromKeys(mapKeys), Kind: KindInvalidFormat, Value: val, Reason: "path is not convertible to primitive"}
+			return nil, &ParseError{path: pathFromKeys(mapKeys), Kind: KindInvalidFormat, Value: val, Reason: "path is not convertible to primitive", message: openapi3.ErrorKindParseNotPrimitive}
 		}
 		prim, err := parsePrimitive(v, schema)
 		if err != nil {
@@ -1185,31 +1217,31 @@ func parsePrimitiveCase(raw string, schema *openapi3.SchemaRef, typ string) (any
 		if schema.Value.Format == "int32" {
 			v, err := strconv.ParseInt(raw, 0, 32)
 			if err != nil {
-				return nil, &ParseError{Kind: KindInvalidFormat, Value: raw, Reason: "an invalid " + typ, Cause: err.(*strconv.NumError).Err}
+				return nil, &ParseError{Kind: KindInvalidFormat, Value: raw, Reason: "an invalid " + typ, Cause: err.(*strconv.NumError).Err, message: openapi3.ErrorKindParseInvalidFormat, params: openapi3.MessageParams{Types: []string{typ}}}
 			}
 			return int32(v), nil
 		}
 		v, err := strconv.ParseInt(raw, 0, 64)
 		if err != nil {
-			return nil, &ParseError{Kind: KindInvalidFormat, Value: raw, Reason: "an invalid " + typ, Cause: err.(*strconv.NumError).Err}
+			return nil, &ParseError{Kind: KindInvalidFormat, Value: raw, Reason: "an invalid " + typ, Cause: err.(*strconv.NumError).Err, message: openapi3.ErrorKindParseInvalidFormat, params: openapi3.MessageParams{Types: []string{typ}}}
 		}
 		return v, nil
 	case "number":
 		v, err := strconv.ParseFloat(raw, 64)
 		if err != nil {
-			return nil, &ParseError{Kind: KindInvalidFormat, Value: raw, Reason: "an invalid " + typ, Cause: err.(*strconv.NumError).Err}
+			return nil, &ParseError{Kind: KindInvalidFormat, Value: raw, Reason: "an invalid " + typ, Cause: err.(*strconv.NumError).Err, message: openapi3.ErrorKindParseInvalidFormat, params: openapi3.MessageParams{Types: []string{typ}}}
 		}
 		return v, nil
 	case "boolean":
 		v, err := strconv.ParseBool(raw)
 		if err != nil {
-			return nil, &ParseError{Kind: KindInvalidFormat, Value: raw, Reason: "an invalid " + typ, Cause: err.(*strconv.NumError).Err}
+			return nil, &ParseError{Kind: KindInvalidFormat, Value: raw, Reason: "an invalid " + typ, Cause: err.(*strconv.NumError).Err, message: openapi3.ErrorKindParseInvalidFormat, params: openapi3.MessageParams{Types: []string{typ}}}
 		}
 		return v, nil
 	case "string":
 		return raw, nil
 	default:
-		return nil, &ParseError{Kind: KindOther, Value: raw, Reason: "schema has non primitive type " + typ}
+		return nil, &ParseError{Kind: KindOther, Value: raw, Reason: "schema has non primitive type " + typ, message: openapi3.ErrorKindParseNonPrimitiveType, params: openapi3.MessageParams{Types: []string{typ}}}
 	}
 }
 
@@ -1265,6 +1297,16 @@ const (
 	prefixNotMatchingCT = "not matching content types"
 )
 
+// unsupportedContentTypeError returns the error of a body of mediaType having no decoder or encoder.
+func unsupportedContentTypeError(mediaType string) *ParseError {
+	return &ParseError{
+		Kind:    KindUnsupportedFormat,
+		Reason:  fmt.Sprintf("%s %q", prefixUnsupportedCT, mediaType),
+		message: openapi3.ErrorKindParseUnsupportedFormat,
+		params:  openapi3.MessageParams{MediaType: mediaType},
+	}
+}
+
 func isBinary(schema *openapi3.SchemaRef) bool {
 	if schema == nil || schema.Value == nil {
 		return false
@@ -1350,6 +1392,8 @@ func decodeBody(body io.Reader, header http.Header, schema *openapi3.SchemaRef,
 				mediaType,
 				encodingContentType,
 			),
+			message: openapi3.ErrorKindParseContentTypeMismatch,
+			params:  openapi3.MessageParams{MediaType: mediaType, Allowed: []any{encodingContentType}},
 		}
 	}
 
@@ -1362,10 +1406,7 @@ func decodeBody(body io.Reader, header http.Header, schema *openapi3.SchemaRef,
 			value, err := FileBodyDecoder(body, header, schema, encFn)
 			return mediaType, value, err
 		}
-		return "", nil, &ParseError{
-			Kind:   KindUnsupportedFormat,
-			Reason: fmt.Sprintf("%s %q", prefixUnsupportedCT, mediaType),
-		}
+		return "", nil, unsupportedContentTypeError(mediaType)
 	}
 	value, err := decoder(body, header, schema, encFn)
 	if err != nil {
@@ -1375,14 +1416,18 @@ func decodeBody(body io.Reader, header http.Header, schema *openapi3.SchemaRef,
 }
 
 func init() {
+	RegisterBodyDecoder("application/cbor", CBORBodyDecoder)
 	RegisterBodyDecoder("application/json", JSONBodyDecoder)
 	RegisterBodyDecoder("application/json-patch+json", JSONBodyDecoder)
 	RegisterBodyDecoder("application/merge-patch+json", JSONBodyDecoder)
+	RegisterBodyDecoder("application/msgpack", MsgpackBodyDecoder)
 	RegisterBodyDecoder("application/ld+json", JSONBodyDecoder)
 	RegisterBodyDecoder("application/hal+json", JSONBodyDecoder)
 	RegisterBodyDecoder("application/vnd.api+json", JSONBodyDecoder)
 	RegisterBodyDecoder("application/octet-stream", FileBodyDecoder)
 	RegisterBodyDecoder("application/problem+json", JSONBodyDecoder)
+	RegisterBodyDecoder("application/vnd.msgpack", MsgpackBodyDecoder)
+	RegisterBodyDecoder("application/x-msgpack", MsgpackBodyDecoder)
 	RegisterBodyDecoder("application/x-www-form-urlencoded", UrlencodedBodyDecoder)
 	RegisterBodyDecoder("application/x-yaml", YamlBodyDecoder)
 	RegisterBodyDecoder("application/yaml", YamlBodyDecoder)
@@ -2,6 +2,7 @@ package openapi3filter
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -2019,11 +2020,66 @@ func TestRegisterAndUnregisterBodyDecoder(t *testing.T) {
 
 	_, _, err = decodeBody(body, h, schema, encFn)
 	require.Equal(t, &ParseError{
-		Kind:   KindUnsupportedFormat,
-		Reason: prefixUnsupportedCT + ` "application/csv"`,
+		Kind:    KindUnsupportedFormat,
+		Reason:  prefixUnsupportedCT + ` "application/csv"`,
+		message: openapi3.ErrorKindParseUnsupportedFormat,
+		params:  openapi3.MessageParams{MediaType: "application/csv"},
 	}, err)
 }
 
+func TestParseErrorMessages(t *testing.T) {
+	integerSchema := openapi3.NewIntegerSchema().NewRef()
+	objectSchema := openapi3.NewObjectSchema().NewRef()
+	arraySchema := openapi3.NewArraySchema().WithItems(openapi3.NewStringSchema()).NewRef()
+	must := func(_ any, err error) error {
+		require.Error(t, err)
+		return err
+	}
+	errs := map[openapi3.ErrorKind]error{
+		openapi3.ErrorKindParseInvalidFormat:    must(parsePrimitiveCase("x", integerSchema, "integer")),
+		openapi3.ErrorKindParseNonPrimitiveType: must(parsePrimitiveCase("x", objectSchema, "object")),
+		openapi3.ErrorKindParsePrefix:           must(cutPrefix("x", ";")),
+		openapi3.ErrorKindParseObject:           must(propsFromString("a=1,b", ",", "=")),
+		openapi3.ErrorKindParseArrayIndexes:     must(makeObject(map[string]string{"a": "1" + urlDecoderDelimiter + "2"}, objectSchema)),
+		openapi3.ErrorKindParseArrayConversion:  must(buildResObj(map[string]any{"a": map[string]any{"x": "1"}}, nil, "a", arraySchema)),
+		openapi3.ErrorKindParseNotPrimitive:     must(buildResObj(map[string]any{"a": map[string]any{"x": "1"}}, nil, "a", integerSchema)),
+		openapi3.ErrorKindParseUnsupportedFormat: func() error {
+			_, _, err := decodeBody(strings.NewReader("a,b"), http.Header{headerCT: {"application/x-custom"}}, openapi3.NewStringSchema().NewRef(), nil)
+			return err
+		}(),
+		openapi3.ErrorKindParseContentTypeMismatch: func() error {
+			encFn := func(string) *openapi3.Encoding { return &openapi3.Encoding{ContentType: "image/png"} }
+			_, _, err := decodeBody(strings.NewReader("a"), http.Header{headerCT: {"text/plain"}}, openapi3.NewStringSchema().NewRef(), encFn)
+			return err
+		}(),
+		openapi3.ErrorKindParseDeepObject: &ParseError{Kind: KindOther, Reason: "invalid param object", Value: "x", message: openapi3.ErrorKindParseDeepObject},
+	}
+
+	// The built-in messages are the reasons of parse errors
+	catalog := openapi3.NewMessageCatalog()
+	for kind, err := range errs {
+		var e *ParseError
+		require.ErrorAs(t, err, &e, kind)
+		require.Equal(t, kind, e.MessageKind())
+		require.Equal(t, e.Reason, catalog.Message(openapi3.DefaultLocale, kind, e.MessageParams()), kind)
+	}
+	require.Equal(t, openapi3.MessageParams{Value: "x", Types: []string{"integer"}, Detail: "an invalid integer"},
+		errs[openapi3.ErrorKindParseInvalidFormat].(*ParseError).MessageParams())
+
+	// Localized errors are copies
+	require.NoError(t, catalog.Register("fr", map[openapi3.ErrorKind]string{
+		openapi3.ErrorKindParseInvalidFormat: `{{printf "%q" .Value}} n'est pas de type {{index .Types 0}}`,
+	}))
+	options := &Options{MessageCatalog: catalog}
+	err := &ParseError{path: []any{"a"}, Cause: errs[openapi3.ErrorKindParseInvalidFormat]}
+	localized := localizeParseErrors(ContextWithLocale(context.Background(), "fr"), options, err)
+	require.EqualError(t, localized, `path a: value x: "x" n'est pas de type integer: invalid syntax`)
+	require.EqualError(t, err, `path a: value x: an invalid integer: invalid syntax`)
+	// Errors of custom decoders keep their reasons
+	custom := &ParseError{Kind: KindInvalidFormat, Reason: "not a CSV"}
+	require.EqualError(t, localizeParseErrors(ContextWithLocale(context.Background(), "fr"), options, custom), "not a CSV")
+}
+
 func matchParseError(t *testing.T, got, want error) {
 	t.Helper()
 
@@ -1,19 +1,26 @@
 package openapi3filter
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
 )
 
 func encodeBody(body any, mediaType string) ([]byte, error) {
 	if encoder := RegisteredBodyEncoder(mediaType); encoder != nil {
 		return encoder(body)
 	}
-	return nil, &ParseError{
-		Kind:   KindUnsupportedFormat,
-		Reason: fmt.Sprintf("%s %q", prefixUnsupportedCT, mediaType),
-	}
+	return nil, unsupportedContentTypeError(mediaType)
 }
 
 // BodyEncoder really is an (encoding/json).Marshaler
@@ -21,7 +28,11 @@ type BodyEncoder func(body any) ([]byte, error)
 
 var bodyEncodersM sync.RWMutex
 var bodyEncoders = map[string]BodyEncoder{
-	"application/json": json.Marshal,
+	"application/cbor":        CBORBodyEncoder,
+	"application/json":        json.Marshal,
+	"application/msgpack":     MsgpackBodyEncoder,
+	"application/vnd.msgpack": MsgpackBodyEncoder,
+	"application/x-msgpack":   MsgpackBodyEncoder,
 }
 
 // RegisterBodyEncoder enables package-wide decoding of contentType values
@@ -56,3 +67,380 @@ func RegisteredBodyEncoder(contentType string) BodyEncoder {
 	bodyEncodersM.RUnlock()
 	return mayBE
 }
+
+// EncodedParameter is a parameter serialized by EncodeParameter.
+type EncodedParameter struct {
+	In   string
+	Name string
+
+	// Value is the serialized parameter:
+	//   - in path: the escaped text replacing the parameter's template (e.g. ";id=3,4,5" for style matrix)
+	//   - in query: an escaped query string (e.g. "id=3&id=4&id=5" for style form with explode)
+	//   - in header: the value of the header
+	//   - in cookie: the value of the cookie
+	Value string
+}
+
+// EncodeParameter serializes value as the parameter param of a request,
+// following its style and explode (see openapi3.Parameter.SerializationMethod),
+// or with the body encoder registered for the media type of its content (see RegisterBodyEncoder).
+// It is the counterpart of the decoding done by ValidateRequest.
+//
+// value is converted as encoding/json would first: structs, maps, slices and scalars are accepted.
+// Only style deepObject and content serialize nested arrays or objects.
+// Object properties are serialized in the order of their names.
+//
+// Reserved characters (:/?@!$'()*,;) are left unescaped in query parameters with allowReserved.
+//
+// A nil value serializes to a nil EncodedParameter.
+func EncodeParameter(param *openapi3.Parameter, value any) (*EncodedParameter, error) {
+	value, err := normalizeParameterValue(value)
+	if err != nil {
+		return nil, fmt.Errorf("encoding %s parameter %q: %w", param.In, param.Name, err)
+	}
+	if value == nil {
+		return nil, nil
+	}
+
+	var s string
+	if param.Content != nil {
+		s, err = encodeContentParameter(param, value)
+	} else {
+		s, err = encodeStyledParameter(param, value)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("encoding %s parameter %q: %w", param.In, param.Name, err)
+	}
+	return &EncodedParameter{In: param.In, Name: param.Name, Value: s}, nil
+}
+
+// Apply sets the parameter on req.
+// The path of req.URL must contain the template of a path parameter, e.g. "/pets/{id}".
+// Query parameters are appended to req.URL.RawQuery, headers and cookies are added.
+// Apply does nothing when p is nil.
+func (p *EncodedParameter) Apply(req *http.Request) error {
+	if p == nil {
+		return nil
+	}
+	switch p.In {
+	case openapi3.ParameterInPath:
+		path := req.URL.EscapedPath()
+		expanded := strings.ReplaceAll(path, "{"+p.Name+"}", p.Value)
+		expanded = strings.ReplaceAll(expanded, "%7B"+p.Name+"%7D", p.Value)
+		if expanded == path {
+			return fmt.Errorf("path %q has no template for parameter %q", path, p.Name)
+		}
+		unescaped, err := url.PathUnescape(expanded)
+		if err != nil {
+			return err
+		}
+		req.URL.Path, req.URL.RawPath = unescaped, expanded
+	case openapi3.ParameterInQuery:
+		if p.Value == "" {
+			return nil
+		}
+		if req.URL.RawQuery != "" {
+			req.URL.RawQuery += "&"
+		}
+		req.URL.RawQuery += p.Value
+	case openapi3.ParameterInHeader:
+		req.Header.Add(p.Name, p.Value)
+	case openapi3.ParameterInCookie:
+		req.AddCookie(&http.Cookie{Name: p.Name, Value: p.Value})
+	default:
+		return fmt.Errorf("unsupported parameter's 'in': %s", p.In)
+	}
+	return nil
+}
+
+// normalizeParameterValue converts value to the types encoding/json decodes to,
+// with numbers kept as json.Number.
+func normalizeParameterValue(value any) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var normalized any
+	if err := dec.Decode(&normalized); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
+
+func encodeContentParameter(param *openapi3.Parameter, value any) (string, error) {
+	if len(param.Content) != 1 {
+		return "", fmt.Errorf("multiple content types for parameter %q", param.Name)
+	}
+	var mediaType string
+	for mt := range param.Content {
+		mediaType = mt
+	}
+	data, err := encodeBody(value, mediaType)
+	if err != nil {
+		return "", err
+	}
+	switch param.In {
+	case openapi3.ParameterInPath:
+		return escapeParameterValue(string(data), false), nil
+	case openapi3.ParameterInQuery:
+		return escapeParameterValue(param.Name, false) + "=" + escapeParameterValue(string(data), param.AllowReserved), nil
+	default:
+		return string(data), nil
+	}
+}
+
+func encodeStyledParameter(param *openapi3.Parameter, value any) (string, error) {
+	sm, err := param.SerializationMethod()
+	if err != nil {
+		return "", err
+	}
+
+	switch param.In {
+	case openapi3.ParameterInPath:
+		return encodePathParameter(param.Name, sm, value)
+	case openapi3.ParameterInQuery:
+		return encodeQueryParameter(param.Name, sm, param.AllowReserved, value)
+	case openapi3.ParameterInHeader:
+		if sm.Style != "simple" {
+			return "", invalidSerializationMethodErr(sm)
+		}
+		return encodeSimpleValue(sm, value, func(s string) string { return s })
+	case openapi3.ParameterInCookie:
+		if sm.Style != "form" {
+			return "", invalidSerializationMethodErr(sm)
+		}
+		if _, ok := value.([]any); ok && sm.Explode {
+			return "", invalidSerializationMethodErr(sm)
+		}
+		if _, ok := value.(map[string]any); ok && sm.Explode {
+			return "", invalidSerializationMethodErr(sm)
+		}
+		return encodeSimpleValue(&openapi3.SerializationMethod{Style: "simple"}, value, func(s string) string { return s })
+	default:
+		return "", fmt.Errorf("unsupported parameter's 'in': %s", param.In)
+	}
+}
+
+// encodeSimpleValue serializes value following style simple, escaping names and values with escape.
+func encodeSimpleValue(sm *openapi3.SerializationMethod, value any, escape func(string) string) (string, error) {
+	switch v := value.(type) {
+	case []any:
+		items, err := primitiveItems(v, escape)
+		if err != nil {
+			return "", err
+		}
+		return strings.Join(items, ","), nil
+	case map[string]any:
+		pairs, err := primitiveProperties(v, escape)
+		if err != nil {
+			return "", err
+		}
+		if sm.Explode {
+			return joinProperties(pairs, "=", ","), nil
+		}
+		return joinProperties(pairs, ",", ","), nil
+	default:
+		s, err := formatPrimitive(v)
+		return escape(s), err
+	}
+}
+
+func encodePathParameter(name string, sm *openapi3.SerializationMethod, value any) (string, error) {
+	escape := func(s string) string { return escapeParameterValue(s, false) }
+	switch sm.Style {
+	case "simple":
+		return encodeSimpleValue(sm, value, escape)
+	case "label", "matrix":
+	default:
+		return "", invalidSerializationMethodErr(sm)
+	}
+
+	prefix := "."
+	if sm.Style == "matrix" {
+		prefix = ";" + escape(name) + "="
+	}
+	switch v := value.(type) {
+	case []any:
+		items, err := primitiveItems(v, escape)
+		if err != nil {
+			return "", err
+		}
+		delim := ","
+		if sm.Explode {
+			delim = prefix
+		}
+		return prefix + strings.Join(items, delim), nil
+	case map[string]any:
+		pairs, err := primitiveProperties(v, escape)
+		if err != nil {
+			return "", err
+		}
+		switch {
+		case !sm.Explode:
+			return prefix + joinProperties(pairs, ",", ","), nil
+		case sm.Style == "label":
+			return "." + joinProperties(pairs, "=", "."), nil
+		default:
+			return ";" + joinProperties(pairs, "=", ";"), nil
+		}
+	default:
+		s, err := formatPrimitive(v)
+		return prefix + escape(s), err
+	}
+}
+
+func encodeQueryParameter(name string, sm *openapi3.SerializationMethod, allowReserved bool, value any) (string, error) {
+	escape := func(s string) string { return escapeParameterValue(s, allowReserved) }
+	key := escapeParameterValue(name, false)
+
+	if sm.Style == "deepObject" {
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return "", invalidSerializationMethodErr(sm)
+		}
+		var pairs []string
+		encodeDeepObject(key, obj, escape, &pairs)
+		return strings.Join(pairs, "&"), nil
+	}
+
+	var delim string
+	switch sm.Style {
+	case "form":
+		delim = ","
+	case "spaceDelimited":
+		delim = "%20"
+	case "pipeDelimited":
+		delim = "%7C"
+	default:
+		return "", invalidSerializationMethodErr(sm)
+	}
+
+	switch v := value.(type) {
+	case []any:
+		items, err := primitiveItems(v, escape)
+		if err != nil {
+			return "", err
+		}
+		if !sm.Explode {
+			return key + "=" + strings.Join(items, delim), nil
+		}
+		for i, item := range items {
+			items[i] = key + "=" + item
+		}
+		return strings.Join(items, "&"), nil
+	case map[string]any:
+		if sm.Style != "form" {
+			return "", invalidSerializationMethodErr(sm)
+		}
+		pairs, err := primitiveProperties(v, escape)
+		if err != nil {
+			return "", err
+		}
+		if sm.Explode {
+			return joinProperties(pairs, "=", "&"), nil
+		}
+		return key + "=" + joinProperties(pairs, ",", ","), nil
+	default:
+		s, err := formatPrimitive(v)
+		return key + "=" + escape(s), err
+	}
+}
+
+// encodeDeepObject appends the pairs key[prop]=value of obj to pairs, recursively.
+// Array items are indexed: key[prop][0]=value.
+func encodeDeepObject(key string, value any, escape func(string) string, pairs *[]string) {
+	switch v := value.(type) {
+	case map[string]any:
+		for _, name := range slices.Sorted(maps.Keys(v)) {
+			encodeDeepObject(key+"["+escapeParameterValue(name, false)+"]", v[name], escape, pairs)
+		}
+	case []any:
+		for i, item := range v {
+			encodeDeepObject(key+"["+strconv.Itoa(i)+"]", item, escape, pairs)
+		}
+	default:
+		s, _ := formatPrimitive(v)
+		*pairs = append(*pairs, key+"="+escape(s))
+	}
+}
+
+func primitiveItems(items []any, escape func(string) string) ([]string, error) {
+	ss := make([]string, 0, len(items))
+	for _, item := range items {
+		s, err := formatPrimitive(item)
+		if err != nil {
+			return nil, err
+		}
+		ss = append(ss, escape(s))
+	}
+	return ss, nil
+}
+
+// primitiveProperties returns the escaped names and values of the properties of obj, sorted by name.
+func primitiveProperties(obj map[string]any, escape func(string) string) ([][2]string, error) {
+	pairs := make([][2]string, 0, len(obj))
+	for _, name := range slices.Sorted(maps.Keys(obj)) {
+		s, err := formatPrimitive(obj[name])
+		if err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, [2]string{escape(name), escape(s)})
+	}
+	return pairs, nil
+}
+
+func joinProperties(pairs [][2]string, valueDelim, propDelim string) string {
+	var sb strings.Builder
+	for i, pair := range pairs {
+		if i > 0 {
+			sb.WriteString(propDelim)
+		}
+		sb.WriteString(pair[0])
+		sb.WriteString(valueDelim)
+		sb.WriteString(pair[1])
+	}
+	return sb.String()
+}
+
+// formatPrimitive returns the text of a normalized primitive value.
+func formatPrimitive(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	default:
+		return "", errors.New("nested arrays and objects require style deepObject or content")
+	}
+}
+
+// escapeParameterValue percent-encodes s, leaving unreserved characters (RFC 3986)
+// and, if allowReserved, the reserved characters that keep their meaning in a query string.
+func escapeParameterValue(s string, allowReserved bool) string {
+	const hex = "0123456789ABCDEF"
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9',
+			c == '-', c == '.', c == '_', c == '~':
+			sb.WriteByte(c)
+		case allowReserved && strings.IndexByte(":/?@!$'()*,;", c) >= 0:
+			sb.WriteByte(c)
+		default:
+			sb.WriteByte('%')
+			sb.WriteByte(hex[c>>4])
+			sb.WriteByte(hex[c&15])
+		}
+	}
+	return sb.String()
+}
@@ -1,11 +1,17 @@
 package openapi3filter
 
 import (
+	"encoding/json"
 	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/getkin/kin-openapi/openapi3"
 )
 
 func TestRegisterAndUnregisterBodyEncoder(t *testing.T) {
@@ -33,7 +39,290 @@ func TestRegisterAndUnregisterBodyEncoder(t *testing.T) {
 
 	_, err = encodeBody(body, contentType)
 	require.Equal(t, &ParseError{
-		Kind:   KindUnsupportedFormat,
-		Reason: prefixUnsupportedCT + ` "text/csv"`,
+		Kind:    KindUnsupportedFormat,
+		Reason:  prefixUnsupportedCT + ` "text/csv"`,
+		message: openapi3.ErrorKindParseUnsupportedFormat,
+		params:  openapi3.MessageParams{MediaType: "text/csv"},
 	}, err)
 }
+
+func TestEncodeParameter(t *testing.T) {
+	primitive := "blue"
+	array := []string{"blue", "black", "brown"}
+	object := map[string]int{"R": 100, "G": 200, "B": 150}
+
+	type testCase struct {
+		name  string
+		param *openapi3.Parameter
+		value any
+		want  string
+		err   string
+	}
+	param := func(in, style string, explode *bool) *openapi3.Parameter {
+		return &openapi3.Parameter{Name: "color", In: in, Style: style, Explode: explode}
+	}
+	tests := []testCase{
+		{name: "path simple primitive", param: param("path", "simple", noExplode), value: primitive, want: "blue"},
+		{name: "path simple array", param: param("path", "simple", noExplode), value: array, want: "blue,black,brown"},
+		{name: "path simple object", param: param("path", "simple", noExplode), value: object, want: "B,150,G,200,R,100"},
+		{name: "path simple explode object", param: param("path", "simple", explode), value: object, want: "B=150,G=200,R=100"},
+		{name: "path label primitive", param: param("path", "label", noExplode), value: primitive, want: ".blue"},
+		{name: "path label array", param: param("path", "label", noExplode), value: array, want: ".blue,black,brown"},
+		{name: "path label explode array", param: param("path", "label", explode), value: array, want: ".blue.black.brown"},
+		{name: "path label object", param: param("path", "label", noExplode), value: object, want: ".B,150,G,200,R,100"},
+		{name: "path label explode object", param: param("path", "label", explode), value: object, want: ".B=150.G=200.R=100"},
+		{name: "path matrix primitive", param: param("path", "matrix", noExplode), value: primitive, want: ";color=blue"},
+		{name: "path matrix array", param: param("path", "matrix", noExplode), value: array, want: ";color=blue,black,brown"},
+		{name: "path matrix explode array", param: param("path", "matrix", explode), value: array, want: ";color=blue;color=black;color=brown"},
+		{name: "path matrix object", param: param("path", "matrix", noExplode), value: object, want: ";color=B,150,G,200,R,100"},
+		{name: "path matrix explode object", param: param("path", "matrix", explode), value: object, want: ";B=150;G=200;R=100"},
+		{name: "path escaped", param: param("path", "simple", noExplode), value: "a/b c,d", want: "a%2Fb%20c%2Cd"},
+		{name: "path form", param: param("path", "form", noExplode), value: primitive, err: `encoding path parameter "color": invalid serialization method: style="form", explode=false`},
+
+		{name: "query form primitive", param: param("query", "", nil), value: primitive, want: "color=blue"},
+		{name: "query form array", param: param("query", "form", noExplode), value: array, want: "color=blue,black,brown"},
+		{name: "query form explode array", param: param("query", "form", explode), value: array, want: "color=blue&color=black&color=brown"},
+		{name: "query form object", param: param("query", "form", noExplode), value: object, want: "color=B,150,G,200,R,100"},
+		{name: "query form explode object", param: param("query", "form", explode), value: object, want: "B=150&G=200&R=100"},
+		{name: "query spaceDelimited array", param: param("query", "spaceDelimited", noExplode), value: array, want: "color=blue%20black%20brown"},
+		{name: "query pipeDelimited array", param: param("query", "pipeDelimited", noExplode), value: array, want: "color=blue%7Cblack%7Cbrown"},
+		{name: "query pipeDelimited object", param: param("query", "pipeDelimited", noExplode), value: object, err: `encoding query parameter "color": invalid serialization method: style="pipeDelimited", explode=false`},
+		{name: "query deepObject", param: param("query", "deepObject", explode), value: object, want: "color[B]=150&color[G]=200&color[R]=100"},
+		{
+			name:  "query deepObject nested",
+			param: param("query", "deepObject", explode),
+			value: map[string]any{"name": "x", "tags": []string{"a", "b"}, "size": map[string]float64{"w": 1.5}},
+			want:  "color[name]=x&color[size][w]=1.5&color[tags][0]=a&color[tags][1]=b",
+		},
+		{name: "query deepObject primitive", param: param("query", "deepObject", explode), value: primitive, err: `encoding query parameter "color": invalid serialization method: style="deepObject", explode=true`},
+		{name: "query escaped", param: param("query", "form", explode), value: "a/b c&d=e#f", want: "color=a%2Fb%20c%26d%3De%23f"},
+		{
+			name:  "query allowReserved",
+			param: &openapi3.Parameter{Name: "color", In: "query", AllowReserved: true},
+			value: "a/b:c?d@e,f;g c&d=e#f[0]+",
+			want:  "color=a/b:c?d@e,f;g%20c%26d%3De%23f%5B0%5D%2B",
+		},
+		{name: "query nested", param: param("query", "form", explode), value: [][]string{{"a"}}, err: `encoding query parameter "color": nested arrays and objects require style deepObject or content`},
+
+		{name: "header primitive", param: param("header", "", nil), value: 5, want: "5"},
+		{name: "header array", param: param("header", "simple", noExplode), value: []any{true, 1.5, "x"}, want: "true,1.5,x"},
+		{name: "header explode object", param: param("header", "simple", explode), value: object, want: "B=150,G=200,R=100"},
+
+		{name: "cookie primitive", param: param("cookie", "", nil), value: primitive, want: "blue"},
+		{name: "cookie array", param: param("cookie", "form", noExplode), value: array, want: "blue,black,brown"},
+		{name: "cookie explode array", param: param("cookie", "form", explode), value: array, err: `encoding cookie parameter "color": invalid serialization method: style="form", explode=true`},
+
+		{
+			name: "query content",
+			param: &openapi3.Parameter{Name: "color", In: "query", Content: openapi3.Content{
+				"application/json": &openapi3.MediaType{Schema: objectSchema},
+			}},
+			value: object,
+			want:  "color=%7B%22B%22%3A150%2C%22G%22%3A200%2C%22R%22%3A100%7D",
+		},
+		{
+			name: "header content",
+			param: &openapi3.Parameter{Name: "color", In: "header", Content: openapi3.Content{
+				"application/json": &openapi3.MediaType{Schema: objectSchema},
+			}},
+			value: object,
+			want:  `{"B":150,"G":200,"R":100}`,
+		},
+		{
+			name: "unsupported content",
+			param: &openapi3.Parameter{Name: "color", In: "header", Content: openapi3.Content{
+				"application/xml": &openapi3.MediaType{Schema: objectSchema},
+			}},
+			value: object,
+			err:   `encoding header parameter "color": unsupported content type "application/xml"`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := EncodeParameter(tc.param, tc.value)
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, &EncodedParameter{In: tc.param.In, Name: "color", Value: tc.want}, got)
+		})
+	}
+
+	got, err := EncodeParameter(param("query", "form", explode), (*int)(nil))
+	require.NoError(t, err)
+	require.Nil(t, got)
+}
+
+func TestEncodedParameterApply(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "http://example.com/pets/{id}/toys?sort=asc", nil)
+	require.NoError(t, err)
+
+	for _, p := range []struct {
+		param *openapi3.Parameter
+		value any
+	}{
+		{&openapi3.Parameter{Name: "id", In: "path", Style: "matrix"}, []int{1, 2}},
+		{&openapi3.Parameter{Name: "color", In: "query", Style: "deepObject", Explode: explode}, map[string]string{"R": "a b"}},
+		{&openapi3.Parameter{Name: "X-Rate", In: "header"}, 1.5},
+		{&openapi3.Parameter{Name: "session", In: "cookie"}, "abc"},
+		{&openapi3.Parameter{Name: "limit", In: "query"}, nil},
+	} {
+		encoded, err := EncodeParameter(p.param, p.value)
+		require.NoError(t, err)
+		require.NoError(t, encoded.Apply(req))
+	}
+
+	require.Equal(t, "http://example.com/pets/;id=1,2/toys?sort=asc&color[R]=a%20b", req.URL.String())
+	require.Equal(t, "/pets/;id=1,2/toys", req.URL.Path)
+	require.Equal(t, "1.5", req.Header.Get("X-Rate"))
+	cookie, err := req.Cookie("session")
+	require.NoError(t, err)
+	require.Equal(t, "abc", cookie.Value)
+
+	err = (&EncodedParameter{In: "path", Name: "missing", Value: "x"}).Apply(req)
+	require.EqualError(t, err, `path "/pets/;id=1,2/toys" has no template for parameter "missing"`)
+}
+
+// TestEncodeParameterRoundTrip checks that random values encoded by EncodeParameter
+// decode back to themselves, for every style supported by the decoders.
+func TestEncodeParameterRoundTrip(t *testing.T) {
+	const (
+		// Characters decoded unambiguously by every style
+		safeAlphabet = "abcXYZ0189-_~"
+		// Characters of query primitives, which are unescaped before decoding
+		richAlphabet = safeAlphabet + " .,;:/?#[]@!$&'()*+=%|\"é"
+	)
+
+	type method struct {
+		in      string
+		style   string
+		explode *bool
+	}
+	var methods []method
+	for _, style := range []string{"simple", "label", "matrix"} {
+		methods = append(methods, method{"path", style, noExplode}, method{"path", style, explode})
+	}
+	for _, style := range []string{"form", "spaceDelimited", "pipeDelimited", "deepObject"} {
+		methods = append(methods, method{"query", style, noExplode}, method{"query", style, explode})
+	}
+	methods = append(methods,
+		method{"header", "simple", noExplode}, method{"header", "simple", explode},
+		method{"cookie", "form", noExplode}, method{"cookie", "form", explode},
+		method{"query", "content", nil}, method{"header", "content", nil},
+	)
+	supports := func(m method, kind string) bool {
+		switch {
+		case m.style == "content":
+			return true
+		case m.style == "deepObject":
+			return kind == "object"
+		case m.style == "spaceDelimited", m.style == "pipeDelimited":
+			return kind == "array"
+		case m.in == "cookie":
+			return kind == "primitive" || !*m.explode
+		}
+		return true
+	}
+
+	rnd := rand.New(rand.NewPCG(1, 2))
+	primitiveSchemas := []*openapi3.SchemaRef{integerSchema, numberSchema, booleanSchema, stringSchema}
+	// randValue returns a value of a primitive schema, as decoded.
+	randValue := func(schema *openapi3.SchemaRef, alphabet string) any {
+		switch {
+		case schema.Value.Type.Is("integer"):
+			return rnd.Int64N(2000) - 1000
+		case schema.Value.Type.Is("number"):
+			return float64(rnd.IntN(20000)-10000) / 8
+		case schema.Value.Type.Is("boolean"):
+			return rnd.IntN(2) == 0
+		default:
+			runes := []rune(alphabet)
+			s := make([]rune, 1+rnd.IntN(8))
+			for i := range s {
+				s[i] = runes[rnd.IntN(len(runes))]
+			}
+			return string(s)
+		}
+	}
+
+	for i := range 1000 {
+		m := methods[rnd.IntN(len(methods))]
+		kind := []string{"primitive", "array", "object"}[rnd.IntN(3)]
+		if !supports(m, kind) {
+			continue
+		}
+		schemas := primitiveSchemas
+		if m.style == "label" {
+			// Style label delimits values with dots, so decimal numbers are ambiguous
+			schemas = []*openapi3.SchemaRef{integerSchema, booleanSchema, stringSchema}
+		}
+
+		var schema *openapi3.SchemaRef
+		var value any
+		switch kind {
+		case "primitive":
+			alphabet := safeAlphabet
+			if m.in == "query" || m.style == "content" {
+				alphabet = richAlphabet
+			}
+			schema = schemas[rnd.IntN(len(schemas))]
+			value = randValue(schema, alphabet)
+		case "array":
+			itemSchema := schemas[rnd.IntN(len(schemas))]
+			var items []any
+			for range 1 + rnd.IntN(4) {
+				items = append(items, randValue(itemSchema, safeAlphabet))
+			}
+			schema = &openapi3.SchemaRef{Value: openapi3.NewArraySchema().WithItems(itemSchema.Value)}
+			value = items
+		case "object":
+			obj := openapi3.NewObjectSchema()
+			props := make(map[string]any)
+			for j := range 1 + rnd.IntN(4) {
+				propSchema := schemas[rnd.IntN(len(schemas))]
+				name := fmt.Sprintf("p%d", j)
+				obj.WithPropertyRef(name, propSchema)
+				props[name] = randValue(propSchema, safeAlphabet)
+			}
+			schema = &openapi3.SchemaRef{Value: obj}
+			value = props
+		}
+
+		param := &openapi3.Parameter{Name: "param", In: m.in, Style: m.style, Explode: m.explode, Schema: schema}
+		if m.style == "content" {
+			param.Style, param.Schema = "", nil
+			param.Content = openapi3.NewContentWithJSONSchemaRef(schema)
+		}
+		name := fmt.Sprintf("#%d %s style=%s explode=%v value=%#v", i, m.in, m.style, m.explode != nil && *m.explode, value)
+
+		encoded, err := EncodeParameter(param, value)
+		require.NoError(t, err, name)
+
+		req := httptest.NewRequest(http.MethodGet, "http://example.com/{param}", nil)
+		require.NoError(t, encoded.Apply(req), name)
+		input := &RequestValidationInput{Request: req}
+		if m.in == "path" {
+			// Routers provide path parameters as they appear in the request path
+			input.PathParams = map[string]string{"param": encoded.Value}
+		}
+
+		var decoded any
+		var found bool
+		if param.Content != nil {
+			// Numbers of JSON content decode to float64
+			data, err := json.Marshal(value)
+			require.NoError(t, err)
+			value = nil
+			require.NoError(t, json.Unmarshal(data, &value))
+
+			decoded, _, found, err = decodeContentParameter(param, input)
+			require.NoError(t, err, name)
+		} else {
+			decoded, found, err = decodeStyledParameter(param, input)
+		}
+		require.NoError(t, err, name)
+		require.True(t, found, name)
+		require.Equal(t, value, decoded, name)
+	}
+}
@@ -0,0 +1,215 @@
+package openapi3filter
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// RequestBuilder builds the requests of the operations of a document,
+// serializing their parameters with EncodeParameter and their bodies
+// with the registered body encoders (see RegisterBodyEncoder).
+type RequestBuilder struct {
+	doc        *openapi3.T
+	baseURL    string
+	serverVars map[string]string
+	operations map[string]builderOperation
+}
+
+type builderOperation struct {
+	method    string
+	path      string
+	pathItem  *openapi3.PathItem
+	operation *openapi3.Operation
+}
+
+// RequestBuilderOption defines an option that may be specified when creating a
+// RequestBuilder.
+type RequestBuilderOption func(*RequestBuilder)
+
+// BaseURL sets the URL requests are sent to, instead of the URL of the first server
+// of the operation, path or document.
+func BaseURL(baseURL string) RequestBuilderOption {
+	return func(b *RequestBuilder) {
+		b.baseURL = baseURL
+	}
+}
+
+// ServerVariables sets values of server variables, overriding their defaults.
+func ServerVariables(vars map[string]string) RequestBuilderOption {
+	return func(b *RequestBuilder) {
+		b.serverVars = vars
+	}
+}
+
+// NewRequestBuilder returns a RequestBuilder for the operations of doc.
+// It fails if operationIds are not unique.
+func NewRequestBuilder(doc *openapi3.T, options ...RequestBuilderOption) (*RequestBuilder, error) {
+	b := &RequestBuilder{
+		doc:        doc,
+		operations: make(map[string]builderOperation),
+	}
+	for i := range options {
+		options[i](b)
+	}
+
+	for _, path := range doc.Paths.InMatchingOrder() {
+		pathItem := doc.Paths.Value(path)
+		operations := pathItem.Operations()
+		for _, method := range slices.Sorted(maps.Keys(operations)) {
+			operation := operations[method]
+			if operation.OperationID == "" {
+				continue
+			}
+			if op, ok := b.operations[operation.OperationID]; ok {
+				return nil, fmt.Errorf("operationId %q is used by %s %s and %s %s", operation.OperationID, op.method, op.path, method, path)
+			}
+			b.operations[operation.OperationID] = builderOperation{
+				method:    method,
+				path:      path,
+				pathItem:  pathItem,
+				operation: operation,
+			}
+		}
+	}
+	return b, nil
+}
+
+// NewRequest returns a request of the operation operationID.
+//
+// params holds the values of the parameters of the operation, keyed by name.
+// A key "{in}:{name}" (e.g. "header:X-Id") selects the parameter of one location,
+// for operations declaring parameters of the same name in several locations.
+// body, if not nil, is encoded with the media type application/json if the operation accepts it,
+// or with the first media type of its request body otherwise.
+func (b *RequestBuilder) NewRequest(ctx context.Context, operationID string, params map[string]any, body any) (*http.Request, error) {
+	op, ok := b.operations[operationID]
+	if !ok {
+		return nil, fmt.Errorf("no operation has operationId %q", operationID)
+	}
+
+	baseURL, err := b.serverURL(op)
+	if err != nil {
+		return nil, err
+	}
+
+	var bodyData []byte
+	var contentType string
+	if requestBody := op.operation.RequestBody; requestBody != nil && requestBody.Value != nil {
+		if body == nil && requestBody.Value.Required {
+			return nil, fmt.Errorf("operation %q requires a request body", operationID)
+		}
+		if body != nil {
+			content := requestBody.Value.Content
+			contentType = "application/json"
+			if content.Get(contentType) == nil {
+				if len(content) == 0 {
+					return nil, fmt.Errorf("request body of operation %q has no content", operationID)
+				}
+				contentType = slices.Sorted(maps.Keys(content))[0]
+			}
+			if bodyData, err = encodeBody(body, contentType); err != nil {
+				return nil, err
+			}
+		}
+	} else if body != nil {
+		return nil, fmt.Errorf("operation %q has no request body", operationID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, strings.ToUpper(op.method), strings.TrimSuffix(baseURL, "/")+op.path, bytes.NewReader(bodyData))
+	if err != nil {
+		return nil, err
+	}
+	if bodyData == nil {
+		req.Body, req.GetBody, req.ContentLength = http.NoBody, nil, 0
+	} else {
+		req.Header.Set(headerCT, contentType)
+	}
+
+	used := make(map[string]struct{}, len(params))
+	for _, parameter := range operationParameters(op) {
+		key := parameter.In + ":" + parameter.Name
+		value, ok := params[key]
+		if !ok {
+			key = parameter.Name
+			value, ok = params[key]
+		}
+		if !ok || value == nil {
+			if parameter.Required {
+				return nil, fmt.Errorf("missing required %s parameter %q", parameter.In, parameter.Name)
+			}
+			continue
+		}
+		used[key] = struct{}{}
+
+		encoded, err := EncodeParameter(parameter, value)
+		if err != nil {
+			return nil, err
+		}
+		if err := encoded.Apply(req); err != nil {
+			return nil, err
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(params)) {
+		if _, ok := used[key]; !ok && params[key] != nil {
+			return nil, fmt.Errorf("operation %q has no parameter %q", operationID, key)
+		}
+	}
+	return req, nil
+}
+
+// serverURL returns the URL of the first server of the operation, its path or the document,
+// with the values of its variables.
+func (b *RequestBuilder) serverURL(op builderOperation) (string, error) {
+	if b.baseURL != "" {
+		return b.baseURL, nil
+	}
+
+	var operationServers openapi3.Servers
+	if op.operation.Servers != nil {
+		operationServers = *op.operation.Servers
+	}
+	var server *openapi3.Server
+	for _, servers := range []openapi3.Servers{operationServers, op.pathItem.Servers, b.doc.Servers} {
+		if len(servers) != 0 {
+			server = servers[0]
+			break
+		}
+	}
+	if server == nil {
+		return "/", nil
+	}
+
+	serverURL := server.URL
+	for name, variable := range server.Variables {
+		value, ok := b.serverVars[name]
+		if !ok {
+			value = variable.Default
+		} else if len(variable.Enum) != 0 && !slices.Contains(variable.Enum, value) {
+			return "", fmt.Errorf("value %q of server variable %q is not one of the allowed values", value, name)
+		}
+		serverURL = strings.ReplaceAll(serverURL, "{"+name+"}", value)
+	}
+	return serverURL, nil
+}
+
+// operationParameters returns the parameters of the operation and those of its path it does not override.
+func operationParameters(op builderOperation) []*openapi3.Parameter {
+	var parameters []*openapi3.Parameter
+	for _, parameterRef := range op.pathItem.Parameters {
+		parameter := parameterRef.Value
+		if op.operation.Parameters.GetByInAndName(parameter.In, parameter.Name) == nil {
+			parameters = append(parameters, parameter)
+		}
+	}
+	for _, parameterRef := range op.operation.Parameters {
+		parameters = append(parameters, parameterRef.Value)
+	}
+	return parameters
+}
@@ -0,0 +1,88 @@
+package openapi3filter_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+)
+
+func TestRequestBuilder(t *testing.T) {
+	doc := loadClientSpec(t)
+	ctx := context.Background()
+
+	builder, err := openapi3filter.NewRequestBuilder(doc)
+	require.NoError(t, err)
+
+	req, err := builder.NewRequest(ctx, "getPet", map[string]any{
+		"id":      42,
+		"fields":  []string{"name", "tag"},
+		"session": "abc",
+	}, nil)
+	require.NoError(t, err)
+	require.Equal(t, http.MethodGet, req.Method)
+	require.Equal(t, "https://eu.example.com/v1/pets/42?fields=name,tag", req.URL.String())
+	require.Equal(t, "session=abc", req.Header.Get("Cookie"))
+	require.Equal(t, http.NoBody, req.Body)
+
+	req, err = builder.NewRequest(ctx, "createPet", map[string]any{"header:X-Trace-Id": "t1"}, map[string]any{"name": "Rex"})
+	require.NoError(t, err)
+	require.Equal(t, "https://eu.example.com/v1/pets", req.URL.String())
+	require.Equal(t, "t1", req.Header.Get("X-Trace-Id"))
+	require.Equal(t, "application/json", req.Header.Get("Content-Type"))
+	body, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"name": "Rex"}`, string(body))
+
+	// Built requests route to their operation and pass validation
+	router, err := gorillamux.NewRouter(doc)
+	require.NoError(t, err)
+	req, err = builder.NewRequest(ctx, "getPet", map[string]any{"id": 7, "fields": []string{"name"}}, nil)
+	require.NoError(t, err)
+	route, pathParams, err := router.FindRoute(req)
+	require.NoError(t, err)
+	require.Equal(t, "getPet", route.Operation.OperationID)
+	err = openapi3filter.ValidateRequest(ctx, &openapi3filter.RequestValidationInput{
+		Request:    req,
+		PathParams: pathParams,
+		Route:      route,
+	})
+	require.NoError(t, err)
+
+	_, err = builder.NewRequest(ctx, "getPet", nil, nil)
+	require.EqualError(t, err, `missing required path parameter "id"`)
+
+	_, err = builder.NewRequest(ctx, "getPet", map[string]any{"id": 1, "limit": 10}, nil)
+	require.EqualError(t, err, `operation "getPet" has no parameter "limit"`)
+
+	_, err = builder.NewRequest(ctx, "createPet", nil, nil)
+	require.EqualError(t, err, `operation "createPet" requires a request body`)
+
+	_, err = builder.NewRequest(ctx, "getPet", map[string]any{"id": 1}, "body")
+	require.EqualError(t, err, `operation "getPet" has no request body`)
+
+	_, err = builder.NewRequest(ctx, "deletePet", nil, nil)
+	require.EqualError(t, err, `no operation has operationId "deletePet"`)
+
+	builder, err = openapi3filter.NewRequestBuilder(doc, openapi3filter.ServerVariables(map[string]string{"region": "us"}))
+	require.NoError(t, err)
+	req, err = builder.NewRequest(ctx, "getPet", map[string]any{"id": 1}, nil)
+	require.NoError(t, err)
+	require.Equal(t, "https://us.example.com/v1/pets/1", req.URL.String())
+
+	builder, err = openapi3filter.NewRequestBuilder(doc, openapi3filter.ServerVariables(map[string]string{"region": "asia"}))
+	require.NoError(t, err)
+	_, err = builder.NewRequest(ctx, "getPet", map[string]any{"id": 1}, nil)
+	require.EqualError(t, err, `value "asia" of server variable "region" is not one of the allowed values`)
+
+	builder, err = openapi3filter.NewRequestBuilder(doc, openapi3filter.BaseURL("http://localhost:8080/"))
+	require.NoError(t, err)
+	req, err = builder.NewRequest(ctx, "getPet", map[string]any{"id": 1}, nil)
+	require.NoError(t, err)
+	require.Equal(t, "http://localhost:8080/pets/1", req.URL.String())
+}
@@ -0,0 +1,71 @@
+// Package slogobserver logs the stages of the validation of requests and responses with log/slog,
+// as an openapi3filter.Observer:
+//
+//	options := &openapi3filter.Options{Observer: slogobserver.New(slog.Default())}
+package slogobserver
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/getkin/kin-openapi/openapi3filter"
+)
+
+// Message is the message of the records logged by an Observer.
+const Message = "openapi3filter"
+
+// Observer logs a record per stage of the validation of requests and responses, with the attributes
+// "stage", "operation", "operationId", "duration", "outcome" and, when they apply,
+// "status", "bodySize", "codes" and "error".
+type Observer struct {
+	logger *slog.Logger
+
+	// Level is the level of the records of successful stages, slog.LevelDebug by default.
+	Level slog.Level
+	// ErrorLevel is the level of the records of failed stages, slog.LevelWarn by default.
+	ErrorLevel slog.Level
+}
+
+var _ openapi3filter.Observer = (*Observer)(nil)
+
+// New returns an Observer logging with logger.
+func New(logger *slog.Logger) *Observer {
+	return &Observer{logger: logger, Level: slog.LevelDebug, ErrorLevel: slog.LevelWarn}
+}
+
+// Observe implements openapi3filter.Observer.
+func (o *Observer) Observe(ctx context.Context, observation *openapi3filter.Observation) {
+	level := o.Level
+	if observation.Outcome == openapi3filter.OutcomeError {
+		level = o.ErrorLevel
+	}
+	if !o.logger.Enabled(ctx, level) {
+		return
+	}
+
+	attrs := make([]slog.Attr, 0, 9)
+	attrs = append(attrs, slog.String("stage", string(observation.Stage)))
+	if operation := observation.Operation(); operation != "" {
+		attrs = append(attrs, slog.String("operation", operation))
+	}
+	if observation.OperationID != "" {
+		attrs = append(attrs, slog.String("operationId", observation.OperationID))
+	}
+	attrs = append(attrs,
+		slog.Duration("duration", observation.Duration),
+		slog.String("outcome", string(observation.Outcome)),
+	)
+	if observation.Status != 0 {
+		attrs = append(attrs, slog.Int("status", observation.Status))
+	}
+	if observation.BodySize != 0 {
+		attrs = append(attrs, slog.Int("bodySize", observation.BodySize))
+	}
+	if len(observation.Codes) != 0 {
+		attrs = append(attrs, slog.Any("codes", observation.Codes))
+	}
+	if observation.Err != nil {
+		attrs = append(attrs, slog.String("error", observation.Err.Error()))
+	}
+	o.logger.LogAttrs(ctx, level, Message, attrs...)
+}
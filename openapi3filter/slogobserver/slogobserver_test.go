@@ -0,0 +1,49 @@
+package slogobserver_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/openapi3filter/slogobserver"
+)
+
+func TestObserver(t *testing.T) {
+	ctx := context.Background()
+	var buf bytes.Buffer
+	removeTime := func(groups []string, a slog.Attr) slog.Attr {
+		if a.Key == slog.TimeKey {
+			return slog.Attr{}
+		}
+		return a
+	}
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo, ReplaceAttr: removeTime}))
+	observer := slogobserver.New(logger)
+
+	// Successful stages are logged at debug level by default
+	observer.Observe(ctx, &openapi3filter.Observation{Stage: openapi3filter.StageSecurity, Outcome: openapi3filter.OutcomeOK})
+	require.Empty(t, buf.String())
+
+	observer.Observe(ctx, &openapi3filter.Observation{
+		Stage:       openapi3filter.StageRequestBodyValidation,
+		Method:      "POST",
+		Path:        "/pets",
+		OperationID: "createPet",
+		Duration:    1500,
+		BodySize:    15,
+		Outcome:     openapi3filter.OutcomeError,
+		Err:         errors.New("invalid body"),
+		Codes:       []string{"schema-required"},
+	})
+	require.Equal(t, `level=WARN msg=openapi3filter stage=request-body-validation operation="POST /pets" operationId=createPet duration=1.5µs outcome=error bodySize=15 codes=[schema-required] error="invalid body"`+"\n", buf.String())
+
+	buf.Reset()
+	observer.Level = slog.LevelInfo
+	observer.Observe(ctx, &openapi3filter.Observation{Stage: openapi3filter.StageResponseValidation, Status: 204, Outcome: openapi3filter.OutcomeOK})
+	require.Equal(t, "level=INFO msg=openapi3filter stage=response-validation duration=0s outcome=ok status=204\n", buf.String())
+}
@@ -0,0 +1,281 @@
+package openapi3filter
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// StrictParameters configures the rejection of the parameters an operation does not declare
+// (see Options.StrictParameters).
+//
+// Allowed names ending with "*" allow all the names starting with what precedes it, e.g. "X-B3-*".
+// Header names are case-insensitive.
+type StrictParameters struct {
+	// Set Query, Header and Cookie so ValidateRequest fails on query parameters, headers and cookies
+	// that are not declared by the operation, its path item or its apiKey security schemes
+	Query, Header, Cookie bool
+
+	// Set ResponseHeader so ValidateResponse fails on headers not declared by the response
+	ResponseHeader bool
+
+	// AllowedQueryParameters and AllowedCookies are undeclared names accepted anyway.
+	AllowedQueryParameters, AllowedCookies []string
+
+	// AllowedHeaders are undeclared request headers accepted anyway, in addition to DefaultAllowedRequestHeaders.
+	AllowedHeaders []string
+
+	// AllowedResponseHeaders are undeclared response headers accepted anyway, in addition to DefaultAllowedResponseHeaders.
+	AllowedResponseHeaders []string
+}
+
+// DefaultAllowedRequestHeaders are the standard request headers StrictParameters accepts when undeclared:
+// content negotiation, authorization, caching, proxies and tracing headers.
+var DefaultAllowedRequestHeaders = []string{
+	"Accept", "Accept-Charset", "Accept-Encoding", "Accept-Language",
+	"Authorization", "Proxy-Authorization",
+	"Cache-Control", "Connection", "Content-Encoding", "Content-Length", "Content-Type", "Cookie", "Expect", "Host",
+	"If-Match", "If-Modified-Since", "If-None-Match", "If-Range", "If-Unmodified-Since", "Range",
+	"Keep-Alive", "Origin", "Pragma", "Referer", "TE", "Trailer", "Transfer-Encoding", "Upgrade", "User-Agent", "Via",
+	"Access-Control-Request-*", "Sec-*",
+	"Forwarded", "X-Forwarded-*", "X-Real-Ip", "X-Request-Id", "X-Correlation-Id",
+	"Traceparent", "Tracestate", "Baggage", "B3", "X-B3-*", "Uber-Trace-Id", "X-Amzn-Trace-Id", "X-Cloud-Trace-Context",
+}
+
+// DefaultAllowedResponseHeaders are the standard response headers StrictParameters accepts when undeclared.
+var DefaultAllowedResponseHeaders = []string{
+	"Accept-Ranges", "Access-Control-*", "Age", "Allow", "Alt-Svc",
+	"Cache-Control", "Connection", "Content-Disposition", "Content-Encoding", "Content-Language", "Content-Length",
+	"Content-Range", "Content-Security-Policy", "Content-Type", "Date", "ETag", "Expires", "Keep-Alive", "Last-Modified",
+	"Location", "Pragma", "Retry-After", "Server", "Set-Cookie", "Strict-Transport-Security", "Trailer",
+	"Transfer-Encoding", "Vary", "Via", "WWW-Authenticate", "X-Content-Type-Options", "X-Frame-Options",
+	"X-Request-Id", "X-Correlation-Id", "Traceparent", "Tracestate", "Server-Timing",
+}
+
+var _ openapi3.CodedError = &UndeclaredParameterError{}
+
+// UndeclaredParameterError is returned by ValidateRequest (wrapped in a RequestError) and ValidateResponse
+// (wrapped in a ResponseError) with Options.StrictParameters, for each parameter that is not declared.
+type UndeclaredParameterError struct {
+	// In is the location of the parameter: "query", "header" or "cookie".
+	In string
+	// Name is the name of the parameter.
+	Name string
+	// Suggestion is the declared name closest to Name, if any is close enough.
+	Suggestion string
+}
+
+func (err *UndeclaredParameterError) Error() string {
+	what := "header"
+	switch err.In {
+	case openapi3.ParameterInQuery:
+		what = "query parameter"
+	case openapi3.ParameterInCookie:
+		what = "cookie"
+	}
+	msg := fmt.Sprintf("%s %q is not declared", what, err.Name)
+	if err.Suggestion != "" {
+		msg += fmt.Sprintf(", did you mean %q?", err.Suggestion)
+	}
+	return msg
+}
+
+// Code returns the code of the error in problem details.
+func (err *UndeclaredParameterError) Code() string { return "parameter-undeclared" }
+
+// validateStrictParameters returns an UndeclaredParameterError for each query parameter, header and cookie
+// of the request that operation does not declare.
+func validateStrictParameters(input *RequestValidationInput, strict *StrictParameters, options *Options) []error {
+	route := input.Route
+	operation := route.Operation
+	declared := map[string]*strictNames{
+		openapi3.ParameterInQuery:  {allowed: strict.AllowedQueryParameters},
+		openapi3.ParameterInHeader: {allowed: slices.Concat(DefaultAllowedRequestHeaders, strict.AllowedHeaders), fold: true},
+		openapi3.ParameterInCookie: {allowed: strict.AllowedCookies},
+	}
+	declare := func(parameter *openapi3.Parameter) {
+		names := declared[parameter.In]
+		if names == nil {
+			return
+		}
+		names.names = append(names.names, parameter.Name)
+		if parameter.In != openapi3.ParameterInQuery || parameter.Schema == nil || parameter.Schema.Value == nil {
+			return
+		}
+		// Object query parameters spread their properties
+		sm, err := parameter.SerializationMethod()
+		if err != nil {
+			return
+		}
+		schema := parameter.Schema.Value
+		switch {
+		case sm.Style == openapi3.SerializationDeepObject:
+			names.prefixes = append(names.prefixes, parameter.Name+"[")
+		case sm.Style == openapi3.SerializationForm && sm.Explode && schema.Type.Is(openapi3.TypeObject):
+			for property := range schema.Properties {
+				names.names = append(names.names, property)
+			}
+			if ap := schema.AdditionalProperties; (ap.Has != nil && *ap.Has) || ap.Schema != nil {
+				names.any = true
+			}
+		}
+	}
+	for _, parameterRef := range route.PathItem.Parameters {
+		declare(parameterRef.Value)
+	}
+	for _, parameterRef := range operation.Parameters {
+		declare(parameterRef.Value)
+	}
+	security := operation.Security
+	if security == nil {
+		security = &route.Spec.Security
+	}
+	if route.Spec.Components != nil {
+		for _, requirement := range *security {
+			for name := range requirement {
+				if scheme := route.Spec.Components.SecuritySchemes[name]; scheme != nil && scheme.Value != nil && scheme.Value.Type == "apiKey" {
+					declare(&openapi3.Parameter{In: scheme.Value.In, Name: scheme.Value.Name})
+				}
+			}
+		}
+	}
+
+	var errs []error
+	check := func(in string, names []string) {
+		slices.Sort(names)
+		for _, name := range slices.Compact(names) {
+			if err := declared[in].check(in, name); err != nil {
+				errs = append(errs, &RequestError{Input: input, Err: err})
+			}
+		}
+	}
+	if strict.Query && !options.ExcludeRequestQueryParams {
+		var names []string
+		for name := range input.GetQueryParams() {
+			names = append(names, name)
+		}
+		check(openapi3.ParameterInQuery, names)
+	}
+	if strict.Header {
+		var names []string
+		for name := range input.Request.Header {
+			names = append(names, name)
+		}
+		check(openapi3.ParameterInHeader, names)
+	}
+	if strict.Cookie {
+		var names []string
+		for _, cookie := range input.Request.Cookies() {
+			names = append(names, cookie.Name)
+		}
+		check(openapi3.ParameterInCookie, names)
+	}
+	return errs
+}
+
+// validateStrictResponseHeaders returns an error for the first header of the response
+// that response does not declare, or an openapi3.MultiError of one error per such header with MultiError.
+func validateStrictResponseHeaders(input *ResponseValidationInput, response *openapi3.Response) error {
+	options := input.Options
+	strict := options.StrictParameters
+	names := &strictNames{allowed: slices.Concat(DefaultAllowedResponseHeaders, strict.AllowedResponseHeaders), fold: true}
+	for name := range response.Headers {
+		names.names = append(names.names, name)
+	}
+	headers := make([]string, 0, len(input.Header))
+	for name := range input.Header {
+		headers = append(headers, name)
+	}
+	slices.Sort(headers)
+	var me openapi3.MultiError
+	for _, name := range headers {
+		if err := names.check(openapi3.ParameterInHeader, name); err != nil {
+			if !options.MultiError {
+				return &ResponseError{Input: input, Err: err}
+			}
+			me = append(me, &ResponseError{Input: input, Err: err})
+		}
+	}
+	if len(me) > 0 {
+		return me
+	}
+	return nil
+}
+
+// strictNames are the declared and allowed names of a location.
+type strictNames struct {
+	names    []string
+	prefixes []string
+	allowed  []string
+	any      bool
+	fold     bool
+}
+
+func (s *strictNames) check(in, name string) *UndeclaredParameterError {
+	if s.any {
+		return nil
+	}
+	equal := func(a, b string) bool { return a == b || (s.fold && strings.EqualFold(a, b)) }
+	hasPrefix := func(name, prefix string) bool {
+		return len(name) >= len(prefix) && equal(name[:len(prefix)], prefix)
+	}
+	for _, declared := range s.names {
+		if equal(name, declared) {
+			return nil
+		}
+	}
+	for _, prefix := range s.prefixes {
+		if hasPrefix(name, prefix) {
+			return nil
+		}
+	}
+	for _, allowed := range s.allowed {
+		if prefix, ok := strings.CutSuffix(allowed, "*"); (ok && hasPrefix(name, prefix)) || equal(name, allowed) {
+			return nil
+		}
+	}
+	return &UndeclaredParameterError{In: in, Name: name, Suggestion: s.closest(name)}
+}
+
+// closest returns the declared name at the smallest edit distance of name,
+// if it is at most a third of the length of name (and at least 1).
+func (s *strictNames) closest(name string) string {
+	fold := func(v string) string {
+		if s.fold {
+			return strings.ToLower(v)
+		}
+		return v
+	}
+	best, bestDistance := "", max(1, len(name)/3)+1
+	names := slices.Clone(s.names)
+	slices.Sort(names)
+	for _, declared := range names {
+		if d := editDistance(fold(name), fold(declared)); d < bestDistance {
+			best, bestDistance = declared, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	row := make([]int, len(rb)+1)
+	for j := range row {
+		row[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		prev := row[0]
+		row[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			prev, row[j] = row[j], min(row[j]+1, row[j-1]+1, prev+cost)
+		}
+	}
+	return row[len(rb)]
+}
@@ -0,0 +1,219 @@
+package openapi3filter_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+)
+
+func TestStrictParameters(t *testing.T) {
+	const spec = `
+openapi: 3.0.3
+info:
+  title: Items
+  version: 1.0.0
+security:
+  - apiKey: []
+paths:
+  /items:
+    parameters:
+      - name: X-Tenant
+        in: header
+        schema:
+          type: string
+    get:
+      parameters:
+        - name: limit
+          in: query
+          schema:
+            type: integer
+        - name: offset
+          in: query
+          schema:
+            type: integer
+        - name: filter
+          in: query
+          style: deepObject
+          schema:
+            type: object
+            properties:
+              name:
+                type: string
+        - name: session
+          in: cookie
+          schema:
+            type: string
+      responses:
+        '200':
+          description: ok
+          headers:
+            X-Rate-Limit:
+              schema:
+                type: integer
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  type: string
+  /search:
+    get:
+      security: []
+      parameters:
+        - name: criteria
+          in: query
+          schema:
+            type: object
+            properties:
+              color:
+                type: string
+              size:
+                type: string
+      responses:
+        '200':
+          description: ok
+components:
+  securitySchemes:
+    apiKey:
+      type: apiKey
+      in: query
+      name: api_key
+`
+	ctx := context.Background()
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(spec))
+	require.NoError(t, err)
+	require.NoError(t, doc.Validate(ctx))
+	router, err := gorillamux.NewRouter(doc)
+	require.NoError(t, err)
+
+	strict := &openapi3filter.StrictParameters{Query: true, Header: true, Cookie: true, ResponseHeader: true}
+	request := func(url string, header http.Header, options *openapi3filter.Options) *openapi3filter.RequestValidationInput {
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		require.NoError(t, err)
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		route, pathParams, err := router.FindRoute(req)
+		require.NoError(t, err)
+		if options == nil {
+			options = &openapi3filter.Options{StrictParameters: strict, AuthenticationFunc: openapi3filter.NoopAuthenticationFunc}
+		}
+		return &openapi3filter.RequestValidationInput{
+			Request:    req,
+			PathParams: pathParams,
+			Route:      route,
+			Options:    options,
+		}
+	}
+	validate := func(url string, header http.Header) error {
+		return openapi3filter.ValidateRequest(ctx, request(url, header, nil))
+	}
+
+	// Declared parameters, apiKey security schemes and standard headers
+	require.NoError(t, validate("/items?api_key=k&limit=10&offset=5&filter[name]=a", http.Header{
+		"Accept":        {"application/json"},
+		"Authorization": {"Bearer t"},
+		"Traceparent":   {"00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"},
+		"X-B3-Traceid":  {"0af7651916cd43dd"},
+		"X-Tenant":      {"acme"},
+		"Cookie":        {"session=s"},
+	}))
+	// Properties of exploded form objects
+	require.NoError(t, validate("/search?color=red&size=m", nil))
+
+	// Typos
+	err = validate("/items?api_key=k&limt=10", nil)
+	require.EqualError(t, err, `query parameter "limt" is not declared, did you mean "limit"?`)
+	var undeclared *openapi3filter.UndeclaredParameterError
+	require.ErrorAs(t, err, &undeclared)
+	require.Equal(t, &openapi3filter.UndeclaredParameterError{In: "query", Name: "limt", Suggestion: "limit"}, undeclared)
+	p := openapi3filter.NewProblemDetails(err, http.StatusBadRequest)
+	require.Equal(t, http.StatusBadRequest, p.Status)
+	require.Equal(t, "parameter-undeclared", p.Errors[0].Code)
+	require.Equal(t, "query", p.Errors[0].In)
+	require.Equal(t, "limt", p.Errors[0].Parameter)
+
+	err = validate("/items?api_key=k", http.Header{"X-Tennant": {"acme"}})
+	require.EqualError(t, err, `header "X-Tennant" is not declared, did you mean "X-Tenant"?`)
+	err = validate("/items?api_key=k", http.Header{"Cookie": {"sesion=s"}})
+	require.EqualError(t, err, `cookie "sesion" is not declared, did you mean "session"?`)
+	err = validate("/search?colour=red&weight=3", nil)
+	require.EqualError(t, err, `query parameter "colour" is not declared, did you mean "color"?`)
+	err = validate("/search?zzz=1", nil)
+	require.EqualError(t, err, `query parameter "zzz" is not declared`)
+
+	// All of them with MultiError
+	input := request("/items?api_key=k&limt=10&ofset=5", http.Header{"X-Debug": {"1"}}, &openapi3filter.Options{
+		StrictParameters:   strict,
+		MultiError:         true,
+		AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+	})
+	err = openapi3filter.ValidateRequest(ctx, input)
+	var me openapi3.MultiError
+	require.True(t, errors.As(err, &me))
+	require.Len(t, me, 3)
+	require.EqualError(t, me[0], `query parameter "limt" is not declared, did you mean "limit"?`)
+	require.EqualError(t, me[1], `query parameter "ofset" is not declared, did you mean "offset"?`)
+	require.EqualError(t, me[2], `header "X-Debug" is not declared`)
+
+	// Locations and allowlists
+	options := &openapi3filter.Options{
+		StrictParameters: &openapi3filter.StrictParameters{
+			Query:                  true,
+			AllowedQueryParameters: []string{"utm_*", "debug"},
+		},
+		AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+	}
+	require.NoError(t, openapi3filter.ValidateRequest(ctx, request("/items?api_key=k&utm_source=x&debug=1", http.Header{"X-Debug": {"1"}}, options)))
+	err = openapi3filter.ValidateRequest(ctx, request("/items?api_key=k&utm=x", nil, options))
+	require.EqualError(t, err, `query parameter "utm" is not declared`)
+	options.StrictParameters = &openapi3filter.StrictParameters{Header: true, AllowedHeaders: []string{"x-debug"}}
+	require.NoError(t, openapi3filter.ValidateRequest(ctx, request("/items?api_key=k&utm=x", http.Header{"X-Debug": {"1"}}, options)))
+
+	// Responses
+	response := func(header http.Header, options *openapi3filter.Options) error {
+		input := &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: request("/items", nil, nil),
+			Status:                 http.StatusOK,
+			Header:                 header,
+			Options:                options,
+		}
+		input.SetBodyBytes([]byte(`[]`))
+		return openapi3filter.ValidateResponse(ctx, input)
+	}
+	header := http.Header{
+		"Content-Type":   {"application/json"},
+		"Date":           {"Mon, 19 Oct 2026 10:00:00 GMT"},
+		"X-Rate-Limit":   {"10"},
+		"X-Ratelimit":    {"10"},
+		"Content-Length": {"2"},
+	}
+	require.NoError(t, response(header, nil))
+	require.NoError(t, response(header, &openapi3filter.Options{StrictParameters: &openapi3filter.StrictParameters{Query: true}}))
+	err = response(header, &openapi3filter.Options{StrictParameters: strict})
+	require.EqualError(t, err, `header "X-Ratelimit" is not declared, did you mean "X-Rate-Limit"?`)
+	p = openapi3filter.NewProblemDetails(err, http.StatusInternalServerError)
+	require.Equal(t, http.StatusInternalServerError, p.Status)
+	require.Equal(t, "parameter-undeclared", p.Errors[0].Code)
+	require.Equal(t, "X-Ratelimit", p.Errors[0].Parameter)
+	require.NoError(t, response(header, &openapi3filter.Options{StrictParameters: &openapi3filter.StrictParameters{
+		ResponseHeader:         true,
+		AllowedResponseHeaders: []string{"X-Ratelimit"},
+	}}))
+	header.Set("X-Debug", "1")
+	err = response(header, &openapi3filter.Options{StrictParameters: strict, MultiError: true})
+	require.True(t, errors.As(err, &me))
+	require.Len(t, me, 2)
+	require.EqualError(t, me[0], `header "X-Debug" is not declared`)
+	require.EqualError(t, me[1], `header "X-Ratelimit" is not declared, did you mean "X-Rate-Limit"?`)
+	var responseErr *openapi3filter.ResponseError
+	require.ErrorAs(t, me[1], &responseErr)
+	require.Equal(t, &openapi3filter.UndeclaredParameterError{In: "header", Name: "X-Ratelimit", Suggestion: "X-Rate-Limit"}, responseErr.Err)
+}
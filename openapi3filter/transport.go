@@ -0,0 +1,229 @@
+package openapi3filter
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/getkin/kin-openapi/routers"
+)
+
+// ReportMode defines how a Transport reports validation errors.
+type ReportMode int
+
+const (
+	// ReportFail makes RoundTrip return a *TransportError. It is the default.
+	// Invalid requests are not sent.
+	ReportFail ReportMode = iota
+	// ReportLog logs validation errors and lets requests and responses through.
+	ReportLog
+	// ReportCount only counts validation errors (see Transport.Stats).
+	ReportCount
+)
+
+// Transport is an http.RoundTripper validating the requests it sends
+// and the responses it receives against the operations a router finds for them.
+//
+// Bodies are buffered: the request body is sent and the response body is returned intact.
+type Transport struct {
+	base    http.RoundTripper
+	router  routers.Router
+	mode    ReportMode
+	logFunc LogFunc
+	options Options
+
+	requests         atomic.Int64
+	unrouted         atomic.Int64
+	invalidRequests  atomic.Int64
+	invalidResponses atomic.Int64
+}
+
+var _ http.RoundTripper = &Transport{}
+
+// TransportOption defines an option that may be specified when creating a
+// Transport.
+type TransportOption func(*Transport)
+
+// BaseTransport sets the http.RoundTripper sending requests. It defaults to http.DefaultTransport.
+func BaseTransport(base http.RoundTripper) TransportOption {
+	return func(t *Transport) {
+		t.base = base
+	}
+}
+
+// Reporting sets how validation errors are reported. It defaults to ReportFail.
+func Reporting(mode ReportMode) TransportOption {
+	return func(t *Transport) {
+		t.mode = mode
+	}
+}
+
+// TransportOnLog provides a callback that handles logging in ReportLog mode.
+// It defaults to log.Printf.
+func TransportOnLog(f LogFunc) TransportOption {
+	return func(t *Transport) {
+		t.logFunc = f
+	}
+}
+
+// TransportValidationOptions sets request/response validation options on the transport.
+func TransportValidationOptions(options Options) TransportOption {
+	return func(t *Transport) {
+		t.options = options
+	}
+}
+
+// NewTransport returns a Transport validating requests and their responses
+// against the operations found by router.
+func NewTransport(router routers.Router, options ...TransportOption) *Transport {
+	t := &Transport{
+		base:   http.DefaultTransport,
+		router: router,
+		logFunc: func(_ context.Context, message string, err error) {
+			log.Printf("%s: %v", message, err)
+		},
+	}
+	for i := range options {
+		options[i](t)
+	}
+	return t
+}
+
+// TransportStats counts the requests a Transport went through.
+type TransportStats struct {
+	// Requests is the number of requests RoundTrip was called with.
+	Requests int64
+	// Unrouted is the number of requests matching no operation.
+	Unrouted int64
+	// InvalidRequests is the number of requests that failed validation.
+	InvalidRequests int64
+	// InvalidResponses is the number of responses that failed validation.
+	InvalidResponses int64
+}
+
+// Stats returns the counts of requests and validation errors so far.
+func (t *Transport) Stats() TransportStats {
+	return TransportStats{
+		Requests:         t.requests.Load(),
+		Unrouted:         t.unrouted.Load(),
+		InvalidRequests:  t.invalidRequests.Load(),
+		InvalidResponses: t.invalidResponses.Load(),
+	}
+}
+
+// TransportError is returned by Transport.RoundTrip in ReportFail mode.
+type TransportError struct {
+	// Code is ErrCodeCannotFindRoute, ErrCodeRequestInvalid or ErrCodeResponseInvalid.
+	Code ErrCode
+	// Response is the response that failed validation, with its body intact.
+	// It is nil unless Code is ErrCodeResponseInvalid.
+	Response *http.Response
+	Err      error
+}
+
+var _ interface{ Unwrap() error } = &TransportError{}
+
+func (e *TransportError) Error() string {
+	switch e.Code {
+	case ErrCodeCannotFindRoute:
+		return "cannot find route: " + e.Err.Error()
+	case ErrCodeRequestInvalid:
+		return "invalid request: " + e.Err.Error()
+	default:
+		return "invalid response: " + e.Err.Error()
+	}
+}
+
+func (e *TransportError) Unwrap() error { return e.Err }
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests.Add(1)
+	ctx := req.Context()
+
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	// newRequest returns a copy of req with its own reader of body
+	newRequest := func() *http.Request {
+		r := req.Clone(ctx)
+		if body != nil {
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			r.GetBody = func() (io.ReadCloser, error) {
+				return io.NopCloser(bytes.NewReader(body)), nil
+			}
+		}
+		return r
+	}
+
+	route, pathParams, err := t.router.FindRoute(req)
+	if err != nil {
+		t.unrouted.Add(1)
+		if err := t.report(ctx, ErrCodeCannotFindRoute, fmt.Sprintf("failed to find route for %s %s", req.Method, req.URL), err, nil); err != nil {
+			return nil, err
+		}
+		return t.base.RoundTrip(newRequest())
+	}
+
+	// Validate a copy: ValidateRequest may set defaults
+	requestValidationInput := &RequestValidationInput{
+		Request:    newRequest(),
+		PathParams: pathParams,
+		Route:      route,
+		Options:    &t.options,
+	}
+	if err := ValidateRequest(ctx, requestValidationInput); err != nil {
+		t.invalidRequests.Add(1)
+		if err := t.report(ctx, ErrCodeRequestInvalid, "invalid request", err, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := t.base.RoundTrip(newRequest())
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+
+	responseValidationInput := &ResponseValidationInput{
+		RequestValidationInput: requestValidationInput,
+		Status:                 resp.StatusCode,
+		Header:                 resp.Header,
+		Options:                &t.options,
+	}
+	responseValidationInput.SetBodyBytes(data)
+	if err := ValidateResponse(ctx, responseValidationInput); err != nil {
+		t.invalidResponses.Add(1)
+		if err := t.report(ctx, ErrCodeResponseInvalid, "invalid response", err, resp); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// report returns the error RoundTrip fails with in ReportFail mode, or nil.
+func (t *Transport) report(ctx context.Context, code ErrCode, message string, err error, resp *http.Response) error {
+	switch t.mode {
+	case ReportFail:
+		return &TransportError{Code: code, Response: resp, Err: err}
+	case ReportLog:
+		t.logFunc(ctx, message, err)
+	}
+	return nil
+}
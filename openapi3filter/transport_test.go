@@ -0,0 +1,214 @@
+package openapi3filter_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+)
+
+const clientSpec = `
+openapi: 3.0.0
+info:
+  title: Pets
+  version: '1.0'
+servers:
+- url: https://{region}.example.com/v1
+  variables:
+    region:
+      default: eu
+      enum: [eu, us]
+paths:
+  /pets:
+    post:
+      operationId: createPet
+      parameters:
+      - name: X-Trace-Id
+        in: header
+        schema: {type: string}
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [name]
+              properties:
+                name: {type: string}
+      responses:
+        '201':
+          description: Created
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [id]
+                properties:
+                  id: {type: integer}
+  /pets/{id}:
+    parameters:
+    - name: id
+      in: path
+      required: true
+      schema: {type: integer}
+    get:
+      operationId: getPet
+      parameters:
+      - name: fields
+        in: query
+        style: form
+        explode: false
+        schema:
+          type: array
+          items: {type: string}
+      - name: session
+        in: cookie
+        schema: {type: string}
+      responses:
+        '200':
+          description: OK
+`
+
+func loadClientSpec(t *testing.T) *openapi3.T {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData([]byte(clientSpec))
+	require.NoError(t, err)
+	err = doc.Validate(loader.Context)
+	require.NoError(t, err)
+	return doc
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+// serve returns a base transport answering requests with h, without network.
+func serve(h http.HandlerFunc) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		rec := httptest.NewRecorder()
+		h(rec, req)
+		return rec.Result(), nil
+	})
+}
+
+func TestTransport(t *testing.T) {
+	doc := loadClientSpec(t)
+	router, err := gorillamux.NewRouter(doc)
+	require.NoError(t, err)
+
+	var sent []string
+	base := serve(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		sent = append(sent, string(body))
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		if strings.Contains(string(body), "bad") {
+			_, _ = w.Write([]byte(`{"id": "bad"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id": 1}`))
+	})
+
+	post := func(t *testing.T, client *http.Client, body string) (*http.Response, error) {
+		req, err := http.NewRequest(http.MethodPost, "https://eu.example.com/v1/pets", strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		return client.Do(req)
+	}
+
+	t.Run("fail", func(t *testing.T) {
+		sent = nil
+		transport := openapi3filter.NewTransport(router, openapi3filter.BaseTransport(base))
+		client := &http.Client{Transport: transport}
+
+		resp, err := post(t, client, `{"name": "Rex"}`)
+		require.NoError(t, err)
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"id": 1}`, string(data))
+		require.Equal(t, []string{`{"name": "Rex"}`}, sent)
+
+		_, err = post(t, client, `{}`)
+		var transportErr *openapi3filter.TransportError
+		require.ErrorAs(t, err, &transportErr)
+		require.Equal(t, openapi3filter.ErrCodeRequestInvalid, int(transportErr.Code))
+		require.ErrorContains(t, err, `invalid request: request body has an error: doesn't match schema: Error at "/name": property "name" is missing`)
+		require.Len(t, sent, 1, "invalid requests are not sent")
+
+		_, err = post(t, client, `{"name": "bad"}`)
+		require.ErrorAs(t, err, &transportErr)
+		require.Equal(t, openapi3filter.ErrCodeResponseInvalid, int(transportErr.Code))
+		data, err = io.ReadAll(transportErr.Response.Body)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"id": "bad"}`, string(data))
+
+		resp, err = client.Get("https://eu.example.com/v1/owners")
+		require.ErrorAs(t, err, &transportErr)
+		require.Equal(t, openapi3filter.ErrCodeCannotFindRoute, int(transportErr.Code))
+		require.Nil(t, resp)
+
+		require.Equal(t, openapi3filter.TransportStats{
+			Requests:         4,
+			Unrouted:         1,
+			InvalidRequests:  1,
+			InvalidResponses: 1,
+		}, transport.Stats())
+	})
+
+	t.Run("log", func(t *testing.T) {
+		sent = nil
+		var logged []string
+		transport := openapi3filter.NewTransport(router,
+			openapi3filter.BaseTransport(base),
+			openapi3filter.Reporting(openapi3filter.ReportLog),
+			openapi3filter.TransportOnLog(func(_ context.Context, message string, err error) {
+				logged = append(logged, message)
+			}),
+		)
+		client := &http.Client{Transport: transport}
+
+		resp, err := post(t, client, `{"bad": true}`)
+		require.NoError(t, err)
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"id": "bad"}`, string(data))
+		require.Equal(t, []string{`{"bad": true}`}, sent, "the body is sent intact")
+		require.Equal(t, []string{"invalid request", "invalid response"}, logged)
+	})
+
+	t.Run("count", func(t *testing.T) {
+		transport := openapi3filter.NewTransport(router,
+			openapi3filter.BaseTransport(base),
+			openapi3filter.Reporting(openapi3filter.ReportCount),
+		)
+		client := &http.Client{Transport: transport}
+
+		for _, body := range []string{`{"name": "Rex"}`, `{}`, `{"name": "bad"}`} {
+			resp, err := post(t, client, body)
+			require.NoError(t, err)
+			require.Equal(t, http.StatusCreated, resp.StatusCode)
+		}
+		require.Equal(t, openapi3filter.TransportStats{
+			Requests:         3,
+			InvalidRequests:  1,
+			InvalidResponses: 1,
+		}, transport.Stats())
+	})
+
+	t.Run("base error", func(t *testing.T) {
+		transport := openapi3filter.NewTransport(router, openapi3filter.BaseTransport(roundTripperFunc(func(*http.Request) (*http.Response, error) {
+			return nil, errors.New("connection refused")
+		})))
+		_, err := post(t, &http.Client{Transport: transport}, `{"name": "Rex"}`)
+		require.ErrorContains(t, err, "connection refused")
+	})
+}
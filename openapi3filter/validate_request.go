@@ -28,6 +28,11 @@ var ErrInvalidEmptyValue = errors.New("empty value is not allowed")
 // loaded OpenAPIv3 spec. If the input does not match the OpenAPIv3 spec, a
 // non-nil error will be returned.
 //
+// When input.Parameters is set, the decoded values of the parameters are stored in it
+// (see DecodedParameters). They are not stored on the context of the request: only
+// Validator.Middleware passes them on that way to the handler it wraps (see ParametersFromContext
+// and BindParameters). Other callers read input.Parameters, or use ContextWithParameters.
+//
 // Note: One can tune the behavior of uniqueItems: true verification
 // by registering a custom function with openapi3.RegisterArrayUniqueItemsChecker
 func ValidateRequest(ctx context.Context, input *RequestValidationInput) error {
@@ -39,8 +44,8 @@ func ValidateRequest(ctx context.Context, input *RequestValidationInput) error {
 	}
 	route := input.Route
 	operation := route.Operation
-	operationParameters := operation.Parameters
-	pathItemParameters := route.PathItem.Parameters
+
+	options.Coverage.recordRequest(route)
 
 	// Security
 	security := operation.Security
@@ -50,7 +55,10 @@ func ValidateRequest(ctx context.Context, input *RequestValidationInput) error {
 		security = &route.Spec.Security
 	}
 	if security != nil {
-		if err := ValidateSecurityRequirements(ctx, input, *security); err != nil {
+		observation := startObservation(options, StageSecurity, route)
+		err := ValidateSecurityRequirements(ctx, input, *security)
+		observation.done(ctx, err)
+		if err != nil {
 			if !options.MultiError {
 				return err
 			}
@@ -58,29 +66,26 @@ func ValidateRequest(ctx context.Context, input *RequestValidationInput) error {
 		}
 	}
 
-	// For each parameter of the PathItem
-	for _, parameterRef := range pathItemParameters {
-		parameter := parameterRef.Value
-		if operationParameters != nil {
-			if override := operationParameters.GetByInAndName(parameter.In, parameter.Name); override != nil {
-				continue
-			}
+	// Fail early on request bodies larger than their limit, without reading them
+	bodyTooLarge := false
+	if !options.ExcludeRequestBody && input.Request.Body != nil && input.Request.Body != http.NoBody {
+		var requestBody *openapi3.RequestBody
+		if operation.RequestBody != nil {
+			requestBody = operation.RequestBody.Value
 		}
-
-		if err := ValidateParameter(ctx, input, parameter); err != nil {
+		if err := requestBodyLimit(input, options, requestBody).checkContentLength(input.Request.ContentLength); err != nil {
+			err = &RequestError{Input: input, RequestBody: requestBody, Err: err}
 			if !options.MultiError {
 				return err
 			}
 			me = append(me, err)
+			bodyTooLarge = true
 		}
 	}
 
-	// For each parameter of the Operation
-	for _, parameter := range operationParameters {
-		if options.ExcludeRequestQueryParams && parameter.Value.In == openapi3.ParameterInQuery {
-			continue
-		}
-		if err := ValidateParameter(ctx, input, parameter.Value); err != nil {
+	// Content negotiation
+	if options.RejectUnacceptableRequests {
+		if err := validateAccept(input, operation); err != nil {
 			if !options.MultiError {
 				return err
 			}
@@ -88,9 +93,27 @@ func ValidateRequest(ctx context.Context, input *RequestValidationInput) error {
 		}
 	}
 
+	// Parameters
+	observation := startObservation(options, StageParameters, route)
+	errs := validateRequestParameters(ctx, input, options)
+	switch len(errs) {
+	case 0:
+		observation.done(ctx, nil)
+	case 1:
+		observation.done(ctx, errs[0])
+	default:
+		observation.done(ctx, openapi3.MultiError(errs))
+	}
+	if len(errs) != 0 {
+		if !options.MultiError {
+			return errs[0]
+		}
+		me = append(me, errs...)
+	}
+
 	// RequestBody
 	requestBody := operation.RequestBody
-	if !options.ExcludeRequestBody {
+	if !options.ExcludeRequestBody && !bodyTooLarge {
 		// Validate specification request body if present
 		if requestBody != nil {
 			if err := ValidateRequestBody(ctx, input, requestBody.Value); err != nil {
@@ -120,6 +143,54 @@ func ValidateRequest(ctx context.Context, input *RequestValidationInput) error {
 	return nil
 }
 
+// validateRequestParameters validates the parameters of the operation of input,
+// then the undeclared ones, and returns the first error, or all of them with options.MultiError.
+func validateRequestParameters(ctx context.Context, input *RequestValidationInput, options *Options) []error {
+	var errs []error
+	operationParameters := input.Route.Operation.Parameters
+
+	// For each parameter of the PathItem
+	for _, parameterRef := range input.Route.PathItem.Parameters {
+		parameter := parameterRef.Value
+		if operationParameters != nil {
+			if override := operationParameters.GetByInAndName(parameter.In, parameter.Name); override != nil {
+				continue
+			}
+		}
+
+		if err := ValidateParameter(ctx, input, parameter); err != nil {
+			if !options.MultiError {
+				return []error{err}
+			}
+			errs = append(errs, err)
+		}
+	}
+
+	// For each parameter of the Operation
+	for _, parameter := range operationParameters {
+		if options.ExcludeRequestQueryParams && parameter.Value.In == openapi3.ParameterInQuery {
+			continue
+		}
+		if err := ValidateParameter(ctx, input, parameter.Value); err != nil {
+			if !options.MultiError {
+				return []error{err}
+			}
+			errs = append(errs, err)
+		}
+	}
+
+	// Undeclared parameters
+	if options.StrictParameters != nil {
+		if strictErrs := validateStrictParameters(input, options.StrictParameters, options); len(strictErrs) != 0 {
+			if !options.MultiError {
+				return strictErrs[:1]
+			}
+			errs = append(errs, strictErrs...)
+		}
+	}
+	return errs
+}
+
 // appendToQueryValues adds to query parameters each value in the provided slice
 func appendToQueryValues[T any](q url.Values, parameterName string, v []T) {
 	for _, i := range v {
@@ -154,6 +225,7 @@ func populateDefaultQueryParameters(q url.Values, parameterName string, value an
 // The function returns RequestError with ErrInvalidRequired cause when a value of a required parameter is not defined.
 // The function returns RequestError with ErrInvalidEmptyValue cause when a value of a required parameter is not defined.
 // The function returns RequestError with a openapi3.SchemaError cause when a value is invalid by JSON schema.
+// Valid values are recorded in input.Parameters, when set.
 func ValidateParameter(ctx context.Context, input *RequestValidationInput, parameter *openapi3.Parameter) error {
 	if parameter.Schema == nil && parameter.Content == nil {
 		// We have no schema for the parameter. Assume that everything passes
@@ -175,11 +247,11 @@ func ValidateParameter(ctx context.Context, input *RequestValidationInput, param
 	// Validation will ensure that we either have content or schema.
 	if parameter.Content != nil {
 		if value, schema, found, err = decodeContentParameter(parameter, input); err != nil {
-			return &RequestError{Input: input, Parameter: parameter, Err: err}
+			return &RequestError{Input: input, Parameter: parameter, Err: localizeParseErrors(ctx, options, err)}
 		}
 	} else {
 		if value, found, err = decodeStyledParameter(parameter, input); err != nil {
-			return &RequestError{Input: input, Parameter: parameter, Err: err}
+			return &RequestError{Input: input, Parameter: parameter, Err: localizeParseErrors(ctx, options, err)}
 		}
 		schema = parameter.Schema.Value
 	}
@@ -220,6 +292,9 @@ func ValidateParameter(ctx context.Context, input *RequestValidationInput, param
 	if parameter.Required && !found {
 		return &RequestError{Input: input, Parameter: parameter, Reason: ErrInvalidRequired.Error(), Err: ErrInvalidRequired}
 	}
+	if found {
+		options.Coverage.recordParameter(input.Route, parameter)
+	}
 
 	if isNilValue(value) {
 		if !parameter.AllowEmptyValue && found {
@@ -230,10 +305,12 @@ func ValidateParameter(ctx context.Context, input *RequestValidationInput, param
 	// #1096 keeps empty strings as ""; with allowEmptyValue skip schema checks
 	// (format, pattern, ...) like we used to when the value was nil.
 	if s, ok := value.(string); ok && s == "" && parameter.AllowEmptyValue {
+		input.setParameter(parameter, value)
 		return nil
 	}
 	if schema == nil {
 		// A parameter's schema is not defined so skip validation of a parameter's value.
+		input.setParameter(parameter, value)
 		return nil
 	}
 
@@ -247,9 +324,12 @@ func ValidateParameter(ctx context.Context, input *RequestValidationInput, param
 	if input.Route != nil && input.Route.Spec.IsOpenAPI31OrLater() {
 		opts = append(opts, openapi3.EnableJSONSchema2020())
 	}
-	if err = schema.VisitJSON(value, opts...); err != nil {
+	opts = append(opts, localizeOptions(ctx, options)...)
+	opts = append(opts, coverageOptions(options)...)
+	if err = visitJSON(input.Route, options, schema, value, opts); err != nil {
 		return &RequestError{Input: input, Parameter: parameter, Err: err}
 	}
+	input.setParameter(parameter, value)
 	return nil
 }
 
@@ -260,20 +340,30 @@ const prefixInvalidCT = "header Content-Type has unexpected value"
 // The function returns RequestError with ErrInvalidRequired cause when a value is required but not defined.
 // The function returns RequestError with a openapi3.SchemaError cause when a value is invalid by JSON schema.
 func ValidateRequestBody(ctx context.Context, input *RequestValidationInput, requestBody *openapi3.RequestBody) error {
-	var (
-		req  = input.Request
-		data []byte
-	)
-
 	options := input.Options
 	if options == nil {
 		options = &Options{}
 	}
+	observation := startObservation(options, StageRequestBodyDecode, input.Route)
+	err := validateRequestBody(ctx, input, requestBody, options, observation)
+	observation.done(ctx, err)
+	return err
+}
+
+// validateRequestBody validates the body of a request, observing its decoding then its validation.
+func validateRequestBody(ctx context.Context, input *RequestValidationInput, requestBody *openapi3.RequestBody, options *Options, observation *Observation) error {
+	var (
+		req  = input.Request
+		data []byte
+	)
 
 	if req.Body != http.NoBody && req.Body != nil {
 		defer req.Body.Close()
 		var err error
-		if data, err = io.ReadAll(req.Body); err != nil {
+		if data, err = requestBodyLimit(input, options, requestBody).read(req.Body, len(contentEncodings(req.Header)) == 0); err != nil {
+			if _, ok := err.(*BodyTooLargeError); ok {
+				return &RequestError{Input: input, RequestBody: requestBody, Err: err}
+			}
 			return &RequestError{
 				Input:       input,
 				RequestBody: requestBody,
@@ -296,8 +386,19 @@ func ValidateRequestBody(ctx context.Context, input *RequestValidationInput, req
 			req.Body, _ = req.GetBody() // no error return
 		}
 	}
+	observation.setBodySize(len(data))
+
+	body, encoded, err := decodeContentEncoding(data, req.Header, options)
+	if err != nil {
+		return &RequestError{
+			Input:       input,
+			RequestBody: requestBody,
+			Reason:      reasonRequestContentEncoding,
+			Err:         err,
+		}
+	}
 
-	if len(data) == 0 {
+	if len(body) == 0 {
 		if requestBody.Required {
 			return &RequestError{Input: input, RequestBody: requestBody, Err: ErrInvalidRequired}
 		}
@@ -320,21 +421,25 @@ func ValidateRequestBody(ctx context.Context, input *RequestValidationInput, req
 		}
 	}
 
+	options.Coverage.recordRequestBody(input.Route, content, contentType)
+
 	if contentType.Schema == nil {
 		// A JSON schema that describes the received data is not declared, so skip validation.
 		return nil
 	}
 
 	encFn := func(name string) *openapi3.Encoding { return contentType.Encoding[name] }
-	mediaType, value, err := decodeBody(bytes.NewReader(data), req.Header, contentType.Schema, encFn)
+	mediaType, value, err := decodeBody(bytes.NewReader(body), req.Header, contentType.Schema, encFn)
 	if err != nil {
 		return &RequestError{
 			Input:       input,
 			RequestBody: requestBody,
 			Reason:      "failed to decode request body",
-			Err:         err,
+			Err:         localizeParseErrors(ctx, options, err),
 		}
 	}
+	observation.next(ctx, StageRequestBodyValidation)
+	observation.setBodySize(len(body))
 
 	defaultsSet := false
 	var opts []openapi3.SchemaValidationOption
@@ -359,9 +464,11 @@ func ValidateRequestBody(ctx context.Context, input *RequestValidationInput, req
 	if input.Route != nil && input.Route.Spec.IsOpenAPI31OrLater() {
 		opts = append(opts, openapi3.EnableJSONSchema2020())
 	}
+	opts = append(opts, localizeOptions(ctx, options)...)
+	opts = append(opts, coverageOptions(options)...)
 
 	// Validate JSON with the schema
-	if err := contentType.Schema.Value.VisitJSON(value, opts...); err != nil {
+	if err := visitJSON(input.Route, options, contentType.Schema.Value, value, opts); err != nil {
 		schemaId := getSchemaIdentifier(contentType.Schema)
 		schemaId = prependSpaceIfNeeded(schemaId)
 		return &RequestError{
@@ -372,9 +479,10 @@ func ValidateRequestBody(ctx context.Context, input *RequestValidationInput, req
 		}
 	}
 
-	if defaultsSet {
+	// Content-encoded bodies are left as they were
+	if defaultsSet && !encoded {
 		var err error
-		if data, err = encodeBody(value, mediaType); err != nil {
+		if data, err = encodeBody(restoreByteStrings(mediaType, body, value), mediaType); err != nil {
 			return &RequestError{
 				Input:       input,
 				RequestBody: requestBody,
@@ -399,13 +507,23 @@ func ValidateRequestBody(ctx context.Context, input *RequestValidationInput, req
 // ValidateSecurityRequirements goes through multiple OpenAPI 3 security
 // requirements in order and returns nil on the first valid requirement.
 // If no requirement is met, errors are returned in order.
+//
+// An AuthenticationFunc may replace input.Request with a shallow copy carrying values
+// in its context: the context of the request is restored when the requirement is not met.
 func ValidateSecurityRequirements(ctx context.Context, input *RequestValidationInput, srs openapi3.SecurityRequirements) error {
 	if len(srs) == 0 {
 		return nil
 	}
 	var errs []error
 	for _, sr := range srs {
+		var reqCtx context.Context
+		if input.Request != nil {
+			reqCtx = input.Request.Context()
+		}
 		if err := validateSecurityRequirement(ctx, input, sr); err != nil {
+			if input.Request != nil && reqCtx != nil && input.Request.Context() != reqCtx {
+				input.Request = input.Request.WithContext(reqCtx)
+			}
 			if len(errs) == 0 {
 				errs = make([]error, 0, len(srs))
 			}
@@ -449,8 +567,12 @@ func validateSecurityRequirement(ctx context.Context, input *RequestValidationIn
 	if input.Request != nil && input.Request.Body != http.NoBody && input.Request.Body != nil {
 		defer input.Request.Body.Close()
 
+		var requestBody *openapi3.RequestBody
+		if input.Route != nil && input.Route.Operation != nil && input.Route.Operation.RequestBody != nil {
+			requestBody = input.Route.Operation.RequestBody.Value
+		}
 		var err error
-		if data, err = io.ReadAll(input.Request.Body); err != nil {
+		if data, err = requestBodyLimit(input, options, requestBody).read(input.Request.Body, false); err != nil {
 			return &RequestError{
 				Input:  input,
 				Reason: "reading failed",
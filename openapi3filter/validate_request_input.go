@@ -26,6 +26,9 @@ type RequestValidationInput struct {
 	Route        *routers.Route
 	Options      *Options
 	ParamDecoder ContentParameterDecoder
+
+	// Parameters, when set, receives the parameter values decoded by ValidateParameter.
+	Parameters *DecodedParameters
 }
 
 func (input *RequestValidationInput) GetQueryParams() url.Values {
@@ -36,3 +39,9 @@ func (input *RequestValidationInput) GetQueryParams() url.Values {
 	}
 	return q
 }
+
+func (input *RequestValidationInput) setParameter(parameter *openapi3.Parameter, value any) {
+	if input.Parameters != nil {
+		input.Parameters.set(parameter, value)
+	}
+}
@@ -39,6 +39,24 @@ func ValidateResponse(ctx context.Context, input *ResponseValidationInput) error
 	if options == nil {
 		options = &Options{}
 	}
+	observation := startObservation(options, StageResponseValidation, route)
+	if observation != nil {
+		observation.Status = status
+	}
+	err := validateResponse(ctx, input, options, observation)
+	observation.done(ctx, err)
+	return err
+}
+
+func validateResponse(ctx context.Context, input *ResponseValidationInput, options *Options, observation *Observation) error {
+	status := input.Status
+	route := input.RequestValidationInput.Route
+
+	if options.RejectUnacceptableResponses {
+		if err := validateResponseAccept(input); err != nil {
+			return err
+		}
+	}
 
 	// Find input for the current status
 	responses := route.Operation.Responses
@@ -60,6 +78,13 @@ func ValidateResponse(ctx context.Context, input *ResponseValidationInput) error
 	if response == nil {
 		return &ResponseError{Input: input, Reason: "response has not been resolved"}
 	}
+	options.Coverage.recordResponse(route, status, nil, nil)
+
+	if options.StrictParameters != nil && options.StrictParameters.ResponseHeader {
+		if err := validateStrictResponseHeaders(input, response); err != nil {
+			return err
+		}
+	}
 
 	var opts []openapi3.SchemaValidationOption
 	if options.MultiError {
@@ -76,6 +101,8 @@ func ValidateResponse(ctx context.Context, input *ResponseValidationInput) error
 	if route.Spec.IsOpenAPI31OrLater() {
 		opts = append(opts, openapi3.EnableJSONSchema2020())
 	}
+	opts = append(opts, localizeOptions(ctx, options)...)
+	opts = append(opts, coverageOptions(options)...)
 
 	headers := make([]string, 0, len(response.Headers))
 	for k := range response.Headers {
@@ -111,6 +138,8 @@ func ValidateResponse(ctx context.Context, input *ResponseValidationInput) error
 		}
 	}
 
+	options.Coverage.recordResponse(route, status, content, contentType)
+
 	if contentType.Schema == nil {
 		// An operation does not contains a validation schema for responses with this status code.
 		return nil
@@ -124,11 +153,18 @@ func ValidateResponse(ctx context.Context, input *ResponseValidationInput) error
 	// Ensure that this doesn't happen.
 	input.Body = nil
 
+	// Read all, up to the limit
+	limit := newBodyLimit(options, route.Operation, parseMediaType(inputMIME), contentType)
+	data, err := limit.read(body, len(contentEncodings(input.Header)) == 0)
+	if _, ok := err.(*BodyTooLargeError); ok {
+		// Put the data and the unread rest back into the response.
+		input.Body = &readCloser{Reader: io.MultiReader(bytes.NewReader(data), body), Closer: body}
+		return &ResponseError{Input: input, Reason: "response body is too large", Err: err}
+	}
+
 	// Ensure we close the reader
 	defer body.Close()
 
-	// Read all
-	data, err := io.ReadAll(body)
 	if err != nil {
 		return &ResponseError{
 			Input:  input,
@@ -139,6 +175,15 @@ func ValidateResponse(ctx context.Context, input *ResponseValidationInput) error
 
 	// Put the data back into the response.
 	input.SetBodyBytes(data)
+	observation.setBodySize(len(data))
+
+	if data, _, err = decodeContentEncoding(data, input.Header, options); err != nil {
+		return &ResponseError{
+			Input:  input,
+			Reason: reasonResponseContentEncoding,
+			Err:    err,
+		}
+	}
 
 	encFn := func(name string) *openapi3.Encoding { return contentType.Encoding[name] }
 	_, value, err := decodeBody(bytes.NewBuffer(data), input.Header, contentType.Schema, encFn)
@@ -146,12 +191,12 @@ func ValidateResponse(ctx context.Context, input *ResponseValidationInput) error
 		return &ResponseError{
 			Input:  input,
 			Reason: "failed to decode response body",
-			Err:    err,
+			Err:    localizeParseErrors(ctx, options, err),
 		}
 	}
 
 	// Validate data with the schema.
-	if err := contentType.Schema.Value.VisitJSON(value, append(opts, openapi3.VisitAsResponse())...); err != nil {
+	if err := visitJSON(route, options, contentType.Schema.Value, value, append(opts, openapi3.VisitAsResponse())); err != nil {
 		schemaId := getSchemaIdentifier(contentType.Schema)
 		schemaId = prependSpaceIfNeeded(schemaId)
 		return &ResponseError{
@@ -187,7 +232,11 @@ func validateResponseHeader(headerName string, headerRef *openapi3.HeaderRef, in
 	}
 
 	if found {
-		if err = headerRef.Value.Schema.Value.VisitJSON(decodedValue, opts...); err != nil {
+		options := input.Options
+		if options == nil {
+			options = &Options{}
+		}
+		if err = visitJSON(input.RequestValidationInput.Route, options, headerRef.Value.Schema.Value, decodedValue, opts); err != nil {
 			return &ResponseError{
 				Input:  input,
 				Reason: fmt.Sprintf("response header %q doesn't match schema", headerName),
@@ -225,3 +274,8 @@ func prependSpaceIfNeeded(value string) string {
 	}
 	return value
 }
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
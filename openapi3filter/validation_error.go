@@ -3,6 +3,8 @@ package openapi3filter
 import (
 	"bytes"
 	"strconv"
+
+	"github.com/getkin/kin-openapi/openapi3"
 )
 
 // ValidationError struct provides granular error information
@@ -21,6 +23,8 @@ type ValidationError struct {
 	Detail string `json:"detail,omitempty" yaml:"detail,omitempty"`
 	// An object containing references to the source of the error
 	Source *ValidationErrorSource `json:"source,omitempty" yaml:"source,omitempty"`
+	// The schema validation result in a JSON Schema output format (see ValidationErrorEncoder.OutputFormat)
+	Output *openapi3.OutputUnit `json:"output,omitempty" yaml:"output,omitempty"`
 }
 
 // ValidationErrorSource struct
@@ -2,6 +2,7 @@ package openapi3filter
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -13,15 +14,28 @@ import (
 // ValidationErrorEncoder wraps a base ErrorEncoder to handle ValidationErrors
 type ValidationErrorEncoder struct {
 	Encoder ErrorEncoder
+
+	// OutputFormat, if set, adds to ValidationErrors of schema errors their Output in that format.
+	OutputFormat openapi3.OutputFormat
 }
 
 // Encode implements the ErrorEncoder interface for encoding ValidationErrors
 func (enc *ValidationErrorEncoder) Encode(ctx context.Context, err error, w http.ResponseWriter) {
-	enc.Encoder(ctx, ConvertErrors(err), w)
+	enc.Encoder(ctx, convertErrors(err, enc.OutputFormat), w)
 }
 
 // ConvertErrors converts all errors to the appropriate error format.
 func ConvertErrors(err error) error {
+	return convertErrors(err, "")
+}
+
+// ConvertErrorsWithOutput converts all errors to the appropriate error format,
+// adding to ValidationErrors of schema errors their Output in format.
+func ConvertErrorsWithOutput(err error, format openapi3.OutputFormat) error {
+	return convertErrors(err, format)
+}
+
+func convertErrors(err error, format openapi3.OutputFormat) error {
 	if e, ok := err.(*routers.RouteError); ok {
 		return convertRouteError(e)
 	}
@@ -34,6 +48,12 @@ func ConvertErrors(err error) error {
 	var cErr *ValidationError
 	if e.Err == nil {
 		cErr = convertBasicRequestError(e)
+	} else if e.Reason == reasonRequestContentEncoding {
+		cErr = convertContentEncodingError(e)
+	} else if innerErr, ok := e.Err.(*BodyTooLargeError); ok {
+		cErr = &ValidationError{Status: http.StatusRequestEntityTooLarge, Title: innerErr.Error()}
+	} else if innerErr, ok := e.Err.(*NotAcceptableError); ok {
+		cErr = &ValidationError{Status: http.StatusNotAcceptable, Title: innerErr.Error()}
 	} else if e.Err == ErrInvalidRequired {
 		cErr = convertErrInvalidRequired(e)
 	} else if e.Err == ErrInvalidEmptyValue {
@@ -42,6 +62,9 @@ func ConvertErrors(err error) error {
 		cErr = convertParseError(e, innerErr)
 	} else if innerErr, ok := e.Err.(*openapi3.SchemaError); ok {
 		cErr = convertSchemaError(e, innerErr)
+		if schema := requestErrorSchema(e); schema != nil && format != "" {
+			cErr.Output = schema.Output(e.Err, format)
+		}
 	}
 
 	if cErr != nil {
@@ -77,6 +100,20 @@ func convertBasicRequestError(e *RequestError) *ValidationError {
 	}
 }
 
+func convertContentEncodingError(e *RequestError) *ValidationError {
+	status := http.StatusBadRequest
+	switch {
+	case errors.Is(e.Err, ErrUnsupportedContentEncoding):
+		status = http.StatusUnsupportedMediaType
+	case errors.Is(e.Err, ErrDecodedBodyTooLarge):
+		status = http.StatusRequestEntityTooLarge
+	}
+	return &ValidationError{
+		Status: status,
+		Title:  e.Err.Error(),
+	}
+}
+
 func convertErrInvalidRequired(e *RequestError) *ValidationError {
 	if e.Err == ErrInvalidRequired && e.Parameter != nil {
 		return &ValidationError{
@@ -189,6 +226,25 @@ func convertSchemaError(e *RequestError, innerErr *openapi3.SchemaError) *Valida
 	return cErr
 }
 
+// requestErrorSchema returns the schema of the parameter or request body of e.
+func requestErrorSchema(e *RequestError) *openapi3.Schema {
+	var schema *openapi3.SchemaRef
+	if p := e.Parameter; p != nil {
+		schema = p.Schema
+		for _, mt := range p.Content {
+			schema = mt.Schema
+		}
+	} else if e.RequestBody != nil && e.Input != nil && e.Input.Request != nil {
+		if mt := e.RequestBody.Content.Get(e.Input.Request.Header.Get(headerCT)); mt != nil {
+			schema = mt.Schema
+		}
+	}
+	if schema == nil {
+		return nil
+	}
+	return schema.Value
+}
+
 func toJSONPointer(reversePath []string) string {
 	return "/" + strings.Join(reversePath, "/")
 }
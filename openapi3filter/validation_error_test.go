@@ -798,6 +798,26 @@ func TestValidationHandler_ServeHTTP(t *testing.T) {
 		require.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
 		require.Equal(t, "[422][][] value must be an array [source pointer=/photoUrls]", string(body))
 	})
+
+	t.Run("adds output of schema errors", func(t *testing.T) {
+		r := newPetstoreRequest(t, http.MethodPost, "/pet", bytes.NewBufferString(`{"name":"Bahama","photoUrls":"http://cat"}`))
+
+		handler := &testHandler{}
+		mockEncoder := &mockErrorEncoder{}
+		encoder := &ValidationErrorEncoder{Encoder: mockEncoder.Encode, OutputFormat: openapi3.OutputBasic}
+		runTest_ServeHTTP(t, handler, encoder.Encode, r)
+
+		require.True(t, mockEncoder.Called)
+		validationErr, ok := mockEncoder.Err.(*ValidationError)
+		require.True(t, ok)
+		require.Equal(t, &openapi3.OutputUnit{
+			Errors: []*openapi3.OutputUnit{{
+				KeywordLocation:  "/properties/photoUrls/type",
+				InstanceLocation: "/photoUrls",
+				Error:            "value must be an array",
+			}},
+		}, validationErr.Output)
+	})
 }
 
 func TestValidationHandler_Middleware(t *testing.T) {
@@ -0,0 +1,704 @@
+package openapi3fuzz
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/openapi3sample"
+	"github.com/getkin/kin-openapi/routers"
+)
+
+// Option allows tweaking Generator behavior
+type Option func(*generatorOpt)
+
+type generatorOpt struct {
+	seed     uint64
+	attempts int
+	baseURL  string
+	faults   []Fault
+	options  openapi3filter.Options
+}
+
+// Seed sets the seed of the generated values. It defaults to 0.
+func Seed(seed uint64) Option {
+	return func(x *generatorOpt) { x.seed = seed }
+}
+
+// BaseURL sets the URL requests are sent to.
+// It defaults to the URL of the first server of the document, with the default values of its variables,
+// on host localhost if the URL is relative.
+func BaseURL(baseURL string) Option {
+	return func(x *generatorOpt) { x.baseURL = baseURL }
+}
+
+// Faults sets the faults of the generated cases, besides valid cases. It defaults to all faults.
+func Faults(faults ...Fault) Option {
+	return func(x *generatorOpt) { x.faults = faults }
+}
+
+// ValidationOptions sets the options valid cases are checked with (see Validate).
+// Security requirements are satisfied by openapi3filter.NoopAuthenticationFunc
+// unless options.AuthenticationFunc is set.
+func ValidationOptions(options openapi3filter.Options) Option {
+	return func(x *generatorOpt) { x.options = options }
+}
+
+// Generator generates the cases of the operations of a document.
+type Generator struct {
+	router routers.Router
+	opts   generatorOpt
+}
+
+// NewGenerator returns a Generator of cases routed by router.
+func NewGenerator(router routers.Router, opts ...Option) *Generator {
+	x := &generatorOpt{
+		attempts: 16,
+		faults:   []Fault{WrongType, MissingRequired, PatternMismatch, OutOfRange, BadSerialization},
+	}
+	for _, f := range opts {
+		f(x)
+	}
+	if x.options.AuthenticationFunc == nil {
+		x.options.AuthenticationFunc = openapi3filter.NoopAuthenticationFunc
+	}
+	return &Generator{router: router, opts: *x}
+}
+
+// Cases returns the cases of the operations of doc, ordered by path and method.
+//
+// Each operation has a valid request and, for each fault, a request breaking one constraint
+// of a parameter or of the request body. Each of its responses with a status code and JSON content
+// has a valid response and, for each fault, a response whose body breaks one constraint.
+//
+// Valid cases are checked with Validate: an error is returned if no valid request or response
+// of an operation could be generated, e.g. if its request body has no media type with a body encoder
+// (see openapi3filter.RegisterBodyEncoder).
+func (g *Generator) Cases(doc *openapi3.T) ([]*Case, error) {
+	var cases []*Case
+	for _, path := range slices.Sorted(maps.Keys(doc.Paths.Map())) {
+		pathItem := doc.Paths.Value(path)
+		operations := pathItem.Operations()
+		for _, method := range slices.Sorted(maps.Keys(operations)) {
+			op := &operation{
+				doc:       doc,
+				method:    method,
+				path:      path,
+				pathItem:  pathItem,
+				operation: operations[method],
+			}
+			opCases, err := g.operationCases(op)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", method, path, err)
+			}
+			cases = append(cases, opCases...)
+		}
+	}
+	return cases, nil
+}
+
+type operation struct {
+	doc       *openapi3.T
+	method    string
+	path      string
+	pathItem  *openapi3.PathItem
+	operation *openapi3.Operation
+}
+
+// message is a request or a response under construction.
+type message struct {
+	params []paramValue
+	// body is encoded with contentType, unless rawBody is set
+	body         any
+	bodySchema   *openapi3.SchemaRef
+	bodyRequired bool
+	hasBody      bool
+	rawBody      []byte
+	contentType  string
+}
+
+type paramValue struct {
+	param *openapi3.Parameter
+	value any
+	// raw, if set, is applied instead of the encoded value
+	raw  *openapi3filter.EncodedParameter
+	omit bool
+}
+
+func (m *message) clone() *message {
+	c := *m
+	c.params = slices.Clone(m.params)
+	return &c
+}
+
+func (g *Generator) operationCases(op *operation) ([]*Case, error) {
+	var visitOptions []openapi3.SchemaValidationOption
+	if op.doc.IsOpenAPI31OrLater() {
+		visitOptions = append(visitOptions, openapi3.EnableJSONSchema2020())
+	}
+
+	var valid *message
+	var request []byte
+	var lastErr error
+	for i := range uint64(g.opts.attempts) {
+		sampler := openapi3sample.NewGenerator(
+			openapi3sample.Seed(g.opts.seed+i),
+			openapi3sample.UseDirection(openapi3sample.RequestDirection),
+			openapi3sample.SchemaValidationOptions(visitOptions...),
+		)
+		m, err := g.validRequest(op, sampler)
+		if err != nil {
+			return nil, err
+		}
+		if request, lastErr = g.writeRequest(op, m); lastErr != nil {
+			return nil, lastErr
+		}
+		if lastErr = g.validate(op, request, nil); lastErr == nil {
+			valid = m
+			break
+		}
+	}
+	if valid == nil {
+		return nil, fmt.Errorf("no valid request: %w", lastErr)
+	}
+
+	newCase := func(name string, expect Expectation, request, response []byte) *Case {
+		return &Case{
+			Name:        op.method + " " + op.path + " " + name,
+			Method:      op.method,
+			Path:        op.path,
+			OperationID: op.operation.OperationID,
+			Expect:      expect,
+			Request:     request,
+			Response:    response,
+		}
+	}
+	cases := []*Case{newCase("valid request", Expectation{}, request, nil)}
+
+	for _, fault := range g.requestFaults(valid, visitOptions) {
+		data, err := g.writeRequest(op, fault.message)
+		if err != nil {
+			return nil, err
+		}
+		cases = append(cases, newCase(fault.name, fault.expect, data, nil))
+	}
+
+	responses, err := g.responseCases(op, request, visitOptions)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range responses {
+		cases = append(cases, newCase(c.name, c.expect, request, c.data))
+	}
+	return cases, nil
+}
+
+// validate checks a valid case.
+func (g *Generator) validate(op *operation, request, response []byte) error {
+	req, err := ReadRequest(request)
+	if err != nil {
+		return err
+	}
+	route, _, err := g.router.FindRoute(req)
+	if err != nil {
+		return err
+	}
+	if route.Operation != op.operation {
+		return fmt.Errorf("request is routed to %s %s", route.Method, route.Path)
+	}
+	return Validate(context.Background(), g.router, request, response, &g.opts.options)
+}
+
+// validRequest returns a request of op with all its parameters, and its body if it has one.
+func (g *Generator) validRequest(op *operation, sampler *openapi3sample.Generator) (*message, error) {
+	m := &message{}
+	for _, param := range operationParameters(op) {
+		schema := parameterSchema(param)
+		if schema == nil {
+			continue
+		}
+		value, err := sampler.Generate(schema)
+		if err != nil {
+			return nil, fmt.Errorf("%s parameter %q: %w", param.In, param.Name, err)
+		}
+		m.params = append(m.params, paramValue{param: param, value: value})
+	}
+
+	requestBody := op.operation.RequestBody
+	if requestBody == nil || requestBody.Value == nil {
+		return m, nil
+	}
+	contentType, media := encodableMedia(requestBody.Value.Content)
+	if media == nil {
+		if requestBody.Value.Required {
+			return nil, fmt.Errorf("request body has no media type with a body encoder")
+		}
+		return m, nil
+	}
+	m.contentType, m.hasBody = contentType, true
+	m.bodySchema, m.bodyRequired = media.Schema, requestBody.Value.Required
+	if media.Schema != nil {
+		value, err := sampler.Generate(media.Schema)
+		if err != nil {
+			return nil, fmt.Errorf("request body: %w", err)
+		}
+		m.body = value
+	}
+	return m, nil
+}
+
+type faultyMessage struct {
+	name    string
+	expect  Expectation
+	message *message
+}
+
+// faultSet collects the faulty variants of a message.
+type faultSet struct {
+	faults   []Fault
+	messages []faultyMessage
+}
+
+func (fs *faultSet) add(name string, fault Fault, expect Expectation, m *message) {
+	if slices.Contains(fs.faults, fault) {
+		expect.Fault = fault
+		fs.messages = append(fs.messages, faultyMessage{name: name + " " + string(fault), expect: expect, message: m})
+	}
+}
+
+// requestFaults returns the variants of valid breaking one constraint.
+func (g *Generator) requestFaults(valid *message, visitOptions []openapi3.SchemaValidationOption) []faultyMessage {
+	fs := &faultSet{faults: g.opts.faults}
+	requestOptions := append(slices.Clone(visitOptions), openapi3.VisitAsRequest())
+	for i, pv := range valid.params {
+		param := pv.param
+		name := param.In + ":" + param.Name
+		expect := Expectation{In: param.In, Name: param.Name}
+		withParam := func(p paramValue) *message {
+			m := valid.clone()
+			m.params[i] = p
+			return m
+		}
+		parseError := expect
+		parseError.ParseError, parseError.ParseErrorKind = true, openapi3filter.KindInvalidFormat
+
+		if param.Required && param.In != openapi3.ParameterInPath {
+			e := expect
+			e.Err = openapi3filter.ErrInvalidRequired
+			fs.add(name, MissingRequired, e, withParam(paramValue{param: param, omit: true}))
+		}
+
+		if param.Content != nil {
+			// Content parameters are set verbatim: a lone brace is not a JSON value
+			if contentType, _ := encodableMedia(param.Content); isJSON(contentType) {
+				if raw, err := rawParameter(param, "{"); err == nil {
+					fs.add(name, BadSerialization, expect, withParam(paramValue{param: param, raw: raw}))
+				}
+			}
+			continue
+		}
+		schema := param.Schema.Value
+
+		// Non-string primitives, and arrays of them, fail to parse
+		primitive, value := schema, any("x")
+		if schema.Type.Is(openapi3.TypeArray) && schema.Items != nil && schema.Items.Value != nil {
+			primitive, value = schema.Items.Value, []any{"x"}
+		}
+		if primitive.Type.Is(openapi3.TypeInteger) || primitive.Type.Is(openapi3.TypeNumber) || primitive.Type.Is(openapi3.TypeBoolean) {
+			if raw, err := rawParameter(param, value); err == nil {
+				fs.add(name, WrongType, parseError, withParam(paramValue{param: param, raw: raw}))
+			}
+		}
+
+		// Objects without explode are lists of names and values: an odd count cannot be decoded
+		if sm, err := param.SerializationMethod(); err == nil && schema.Type.Is(openapi3.TypeObject) && !sm.Explode && sm.Style != "deepObject" {
+			if raw, err := rawParameter(param, []any{"x"}); err == nil {
+				fs.add(name, BadSerialization, parseError, withParam(paramValue{param: param, raw: raw}))
+			}
+		}
+
+		// Parameter values of any type serialize to strings: wrong types are the parse errors above
+		schemaFaults := slices.DeleteFunc(slices.Clone(g.opts.faults), func(f Fault) bool { return f == WrongType })
+		for _, mutation := range mutate(schema, pv.value, schemaFaults, requestOptions) {
+			switch v := mutation.value.(type) {
+			case map[string]any:
+				if len(v) == 0 {
+					// Empty objects serialize to empty values
+					continue
+				}
+			}
+			e := expect
+			e.SchemaField, e.Pointer = mutation.schemaField, mutation.pointer
+			fs.add(pointerName(name, mutation.pointer), mutation.fault, e, withParam(paramValue{param: param, value: mutation.value}))
+		}
+	}
+
+	if valid.hasBody {
+		if valid.bodyRequired {
+			m := valid.clone()
+			m.hasBody = false
+			fs.add("request body", MissingRequired, Expectation{In: InBody, Err: openapi3filter.ErrInvalidRequired}, m)
+		}
+		g.bodyFaults(fs, "request body", valid, requestOptions)
+	}
+	return fs.messages
+}
+
+// bodyFaults adds the variants of m whose JSON body breaks one constraint.
+func (g *Generator) bodyFaults(fs *faultSet, name string, m *message, opts []openapi3.SchemaValidationOption) {
+	if !isJSON(m.contentType) {
+		return
+	}
+
+	malformed := m.clone()
+	malformed.rawBody = []byte("{")
+	fs.add(name, BadSerialization, Expectation{In: InBody, ParseError: true, ParseErrorKind: openapi3filter.KindInvalidFormat}, malformed)
+
+	if m.bodySchema == nil || m.bodySchema.Value == nil {
+		return
+	}
+	for _, mutation := range mutate(m.bodySchema.Value, m.body, g.opts.faults, opts) {
+		mutated := m.clone()
+		mutated.body = mutation.value
+		fs.add(pointerName(name, mutation.pointer), mutation.fault, Expectation{In: InBody, SchemaField: mutation.schemaField, Pointer: mutation.pointer}, mutated)
+	}
+}
+
+// pointerName returns name followed by the JSON pointer of a value within it, if any.
+func pointerName(name string, pointer []string) string {
+	if len(pointer) == 0 {
+		return name
+	}
+	return name + "/" + strings.Join(pointer, "/")
+}
+
+type responseCase struct {
+	name   string
+	expect Expectation
+	data   []byte
+}
+
+// skippedStatuses are not validated by openapi3filter.ValidateResponse.
+var skippedStatuses = []int{http.StatusMovedPermanently, http.StatusNotModified, http.StatusTemporaryRedirect, http.StatusPermanentRedirect}
+
+// responseCases returns the cases of the responses of op to the valid request.
+func (g *Generator) responseCases(op *operation, request []byte, visitOptions []openapi3.SchemaValidationOption) ([]responseCase, error) {
+	responses := op.operation.Responses
+	if responses == nil {
+		return nil, nil
+	}
+	responseOptions := append(slices.Clone(visitOptions), openapi3.VisitAsResponse())
+
+	var cases []responseCase
+	for _, key := range slices.Sorted(maps.Keys(responses.Map())) {
+		response := responses.Value(key).Value
+		status := responseStatus(responses, key)
+		if response == nil || status == 0 {
+			continue
+		}
+
+		var valid *message
+		var data []byte
+		var lastErr error
+		for i := range uint64(g.opts.attempts) {
+			sampler := openapi3sample.NewGenerator(
+				openapi3sample.Seed(g.opts.seed+i),
+				openapi3sample.UseDirection(openapi3sample.ResponseDirection),
+				openapi3sample.SchemaValidationOptions(visitOptions...),
+			)
+			m, err := validResponse(response, sampler)
+			if err != nil {
+				return nil, fmt.Errorf("response %s: %w", key, err)
+			}
+			if m == nil {
+				break
+			}
+			if data, lastErr = writeResponse(status, m); lastErr != nil {
+				return nil, lastErr
+			}
+			if lastErr = g.validate(op, request, data); lastErr == nil {
+				valid = m
+				break
+			}
+		}
+		if valid == nil {
+			if lastErr == nil {
+				// No content with a body encoder
+				continue
+			}
+			return nil, fmt.Errorf("no valid response %s: %w", key, lastErr)
+		}
+		name := key + " response"
+		cases = append(cases, responseCase{name: "valid " + name, data: data})
+		if op.method == http.MethodHead {
+			// Responses to HEAD requests are not validated
+			continue
+		}
+
+		fs := &faultSet{faults: g.opts.faults}
+		g.bodyFaults(fs, name+" body", valid, responseOptions)
+		for _, fault := range fs.messages {
+			data, err := writeResponse(status, fault.message)
+			if err != nil {
+				return nil, err
+			}
+			cases = append(cases, responseCase{name: fault.name, expect: fault.expect, data: data})
+		}
+	}
+	return cases, nil
+}
+
+// responseStatus returns a status code of the response key that ValidateResponse checks against it, or 0.
+func responseStatus(responses *openapi3.Responses, key string) int {
+	declared := func(status int) bool {
+		return slices.Contains(skippedStatuses, status) ||
+			responses.Value(strconv.Itoa(status)) != nil ||
+			responses.Value(strconv.Itoa(status/100)+"XX") != nil
+	}
+	if status, err := strconv.Atoi(key); err == nil {
+		if status < 200 || slices.Contains(skippedStatuses, status) {
+			return 0
+		}
+		return status
+	}
+	if len(key) == 3 && key[0] >= '2' && key[0] <= '5' && strings.HasSuffix(key, "XX") {
+		// A status of the range not declared by itself
+		first := int(key[0]-'0') * 100
+		for status := first; status < first+100; status++ {
+			if !slices.Contains(skippedStatuses, status) && responses.Value(strconv.Itoa(status)) == nil {
+				return status
+			}
+		}
+		return 0
+	}
+	if key != "default" {
+		return 0
+	}
+	// A status declared by no other response, preferably a server error
+	if !declared(http.StatusInternalServerError) {
+		return http.StatusInternalServerError
+	}
+	for status := 200; status < 600; status++ {
+		if !declared(status) {
+			return status
+		}
+	}
+	return 0
+}
+
+// validResponse returns a response with its headers and body, or nil if its content has no media type with a body encoder.
+func validResponse(response *openapi3.Response, sampler *openapi3sample.Generator) (*message, error) {
+	m := &message{}
+	for _, name := range slices.Sorted(maps.Keys(response.Headers)) {
+		header := response.Headers[name]
+		if header == nil || header.Value == nil || http.CanonicalHeaderKey(name) == "Content-Type" {
+			continue
+		}
+		param := header.Value.Parameter
+		param.Name, param.In = name, openapi3.ParameterInHeader
+		schema := parameterSchema(&param)
+		if schema == nil {
+			continue
+		}
+		value, err := sampler.Generate(schema)
+		if err != nil {
+			return nil, fmt.Errorf("header %q: %w", name, err)
+		}
+		m.params = append(m.params, paramValue{param: &param, value: value})
+	}
+
+	if len(response.Content) == 0 {
+		return m, nil
+	}
+	contentType, media := encodableMedia(response.Content)
+	if media == nil {
+		return nil, nil
+	}
+	m.contentType, m.hasBody, m.bodySchema = contentType, true, media.Schema
+	if media.Schema != nil {
+		value, err := sampler.Generate(media.Schema)
+		if err != nil {
+			return nil, fmt.Errorf("body: %w", err)
+		}
+		m.body = value
+	}
+	return m, nil
+}
+
+// encodedBody returns the body of m.
+func (m *message) encodedBody() ([]byte, error) {
+	if m.rawBody != nil {
+		return m.rawBody, nil
+	}
+	if encoder := openapi3filter.RegisteredBodyEncoder(m.contentType); encoder != nil {
+		return encoder(m.body)
+	}
+	return json.Marshal(m.body)
+}
+
+// writeRequest returns the serialized request m of op.
+func (g *Generator) writeRequest(op *operation, m *message) ([]byte, error) {
+	var body []byte
+	if m.hasBody {
+		var err error
+		if body, err = m.encodedBody(); err != nil {
+			return nil, err
+		}
+	}
+	req, err := http.NewRequest(strings.ToUpper(op.method), strings.TrimSuffix(g.baseURL(op.doc), "/")+op.path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if m.hasBody {
+		req.Header.Set("Content-Type", m.contentType)
+	} else {
+		req.Body, req.ContentLength = http.NoBody, 0
+	}
+	for _, p := range m.params {
+		if p.omit {
+			continue
+		}
+		encoded := p.raw
+		if encoded == nil {
+			if encoded, err = openapi3filter.EncodeParameter(p.param, p.value); err != nil {
+				return nil, err
+			}
+		}
+		if err := encoded.Apply(req); err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := req.Write(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeResponse returns the serialized response m with the given status.
+func writeResponse(status int, m *message) ([]byte, error) {
+	resp := &http.Response{
+		StatusCode: status,
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     make(http.Header),
+		Body:       http.NoBody,
+	}
+	if m.hasBody {
+		body, err := m.encodedBody()
+		if err != nil {
+			return nil, err
+		}
+		resp.Header.Set("Content-Type", m.contentType)
+		resp.Body, resp.ContentLength = io.NopCloser(bytes.NewReader(body)), int64(len(body))
+	}
+	for _, p := range m.params {
+		if p.omit {
+			continue
+		}
+		encoded, err := openapi3filter.EncodeParameter(p.param, p.value)
+		if err != nil {
+			return nil, err
+		}
+		if encoded != nil {
+			resp.Header.Add(p.param.Name, encoded.Value)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := resp.Write(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// baseURL returns the URL requests are sent to.
+func (g *Generator) baseURL(doc *openapi3.T) string {
+	if g.opts.baseURL != "" {
+		return g.opts.baseURL
+	}
+	if len(doc.Servers) == 0 {
+		return "http://localhost"
+	}
+	server := doc.Servers[0]
+	serverURL := server.URL
+	for name, variable := range server.Variables {
+		serverURL = strings.ReplaceAll(serverURL, "{"+name+"}", variable.Default)
+	}
+	if strings.HasPrefix(serverURL, "/") {
+		serverURL = "http://localhost" + serverURL
+	}
+	return serverURL
+}
+
+// operationParameters returns the parameters of the operation and those of its path it does not override.
+func operationParameters(op *operation) []*openapi3.Parameter {
+	var parameters []*openapi3.Parameter
+	for _, parameterRef := range op.pathItem.Parameters {
+		parameter := parameterRef.Value
+		if op.operation.Parameters.GetByInAndName(parameter.In, parameter.Name) == nil {
+			parameters = append(parameters, parameter)
+		}
+	}
+	for _, parameterRef := range op.operation.Parameters {
+		parameters = append(parameters, parameterRef.Value)
+	}
+	return parameters
+}
+
+// parameterSchema returns the schema of param, or of its content.
+func parameterSchema(param *openapi3.Parameter) *openapi3.SchemaRef {
+	if param.Schema != nil {
+		return param.Schema
+	}
+	for _, media := range param.Content {
+		if media != nil {
+			return media.Schema
+		}
+	}
+	return nil
+}
+
+// rawParameter returns param serialized as if its value were value: strings, or an array of strings.
+func rawParameter(param *openapi3.Parameter, value any) (*openapi3filter.EncodedParameter, error) {
+	p := *param
+	p.Content = nil
+	if _, ok := value.([]any); ok {
+		p.Schema = openapi3.NewArraySchema().WithItems(openapi3.NewStringSchema()).NewRef()
+	} else {
+		p.Schema = openapi3.NewStringSchema().NewRef()
+	}
+	return openapi3filter.EncodeParameter(&p, value)
+}
+
+// encodableMedia returns the JSON media type of content, or the first media type with a body encoder.
+func encodableMedia(content openapi3.Content) (string, *openapi3.MediaType) {
+	if media := content.Get("application/json"); media != nil {
+		return "application/json", media
+	}
+	for _, contentType := range slices.Sorted(maps.Keys(content)) {
+		if strings.Contains(contentType, "*") {
+			continue
+		}
+		if isJSON(contentType) || openapi3filter.RegisteredBodyEncoder(contentType) != nil {
+			return contentType, content[contentType]
+		}
+	}
+	return "", nil
+}
+
+func isJSON(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.TrimSpace(mediaType)
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
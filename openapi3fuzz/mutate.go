@@ -0,0 +1,158 @@
+package openapi3fuzz
+
+import (
+	"maps"
+	"slices"
+	"strconv"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// mutation is a value breaking exactly one constraint of a schema.
+type mutation struct {
+	fault       Fault
+	value       any
+	schemaField string
+	pointer     []string
+}
+
+// schemaFields are the fields of the schema errors of each fault.
+var schemaFields = map[Fault][]string{
+	WrongType:       {"type"},
+	MissingRequired: {"required"},
+	PatternMismatch: {"pattern"},
+	OutOfRange:      {"minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum"},
+}
+
+// patternBreakers are tried in turn as strings not matching patterns.
+var patternBreakers = []string{"~", "!", "0", "A", "a", "~~~~~~~~~~~~~~~~~~~~"}
+
+// mutate returns, for each of faults, the first mutation of value breaking exactly one constraint of schema
+// with that fault, walking value depth first.
+func mutate(schema *openapi3.Schema, value any, faults []Fault, opts []openapi3.SchemaValidationOption) []mutation {
+	opts = append(slices.Clone(opts), openapi3.MultiErrors())
+	found := make(map[Fault]bool)
+	var mutations []mutation
+
+	try := func(fault Fault, mutated any) bool {
+		if found[fault] || !slices.Contains(faults, fault) {
+			return found[fault]
+		}
+		errs := flattenErrors(schema.VisitJSON(mutated, opts...))
+		if len(errs) != 1 {
+			return false
+		}
+		schemaError, ok := errs[0].(*openapi3.SchemaError)
+		if !ok || !slices.Contains(schemaFields[fault], schemaError.SchemaField) {
+			return false
+		}
+		found[fault] = true
+		mutations = append(mutations, mutation{
+			fault:       fault,
+			value:       mutated,
+			schemaField: schemaError.SchemaField,
+			pointer:     schemaError.JSONPointer(),
+		})
+		return true
+	}
+
+	var walk func(node any, path []string, inObject bool)
+	walk = func(node any, path []string, inObject bool) {
+		if inObject {
+			try(MissingRequired, without(value, path))
+		}
+		switch v := node.(type) {
+		case nil:
+		case string:
+			try(WrongType, with(value, path, true))
+			for _, s := range patternBreakers {
+				if s != v && try(PatternMismatch, with(value, path, s)) {
+					break
+				}
+			}
+		case int64:
+			try(WrongType, with(value, path, "x"))
+			for _, n := range []int64{v + 1e9, v - 1e9} {
+				if try(OutOfRange, with(value, path, n)) {
+					break
+				}
+			}
+		case float64:
+			try(WrongType, with(value, path, "x"))
+			for _, n := range []float64{v + 1e9, v - 1e9} {
+				if try(OutOfRange, with(value, path, n)) {
+					break
+				}
+			}
+		case bool:
+			try(WrongType, with(value, path, "x"))
+		case map[string]any:
+			try(WrongType, with(value, path, "x"))
+			for _, key := range slices.Sorted(maps.Keys(v)) {
+				walk(v[key], append(slices.Clip(path), key), true)
+			}
+		case []any:
+			try(WrongType, with(value, path, "x"))
+			for i, item := range v {
+				walk(item, append(slices.Clip(path), strconv.Itoa(i)), false)
+			}
+		}
+	}
+	walk(value, nil, false)
+	return mutations
+}
+
+// flattenErrors returns the errors of nested openapi3.MultiError values.
+func flattenErrors(err error) []error {
+	if err == nil {
+		return nil
+	}
+	me, ok := err.(openapi3.MultiError)
+	if !ok {
+		return []error{err}
+	}
+	var errs []error
+	for _, e := range me {
+		errs = append(errs, flattenErrors(e)...)
+	}
+	return errs
+}
+
+// with returns a copy of value with the value at path replaced by leaf.
+func with(value any, path []string, leaf any) any {
+	if len(path) == 0 {
+		return leaf
+	}
+	switch v := value.(type) {
+	case map[string]any:
+		c := maps.Clone(v)
+		c[path[0]] = with(v[path[0]], path[1:], leaf)
+		return c
+	case []any:
+		i, _ := strconv.Atoi(path[0])
+		c := slices.Clone(v)
+		c[i] = with(v[i], path[1:], leaf)
+		return c
+	}
+	return value
+}
+
+// without returns a copy of value without the property at path.
+func without(value any, path []string) any {
+	switch v := value.(type) {
+	case map[string]any:
+		c := maps.Clone(v)
+		if len(path) == 1 {
+			delete(c, path[0])
+		} else {
+			c[path[0]] = without(v[path[0]], path[1:])
+		}
+		return c
+	case []any:
+		i, _ := strconv.Atoi(path[0])
+		c := slices.Clone(v)
+		c[i] = without(v[i], path[1:])
+		return c
+	}
+	return value
+}
@@ -0,0 +1,148 @@
+// Package openapi3fuzz derives fuzzing corpora from the operations of an OpenAPI document.
+//
+// A Generator builds, for each operation, a valid request and valid responses,
+// and "almost valid" variants of them breaking exactly one constraint:
+// a wrong type, a missing required value, a pattern mismatch, an out-of-range number
+// or a bad serialization. Each Case is labelled with the error validating it must return
+// (see Case.Check).
+//
+// Cases are serialized HTTP/1.1 messages, which seed native Go fuzzing:
+//
+//	func FuzzValidate(f *testing.F) {
+//		cases, err := openapi3fuzz.NewGenerator(router).Cases(doc)
+//		if err != nil {
+//			f.Fatal(err)
+//		}
+//		openapi3fuzz.AddSeeds(f, cases)
+//		f.Fuzz(func(t *testing.T, request, response []byte) {
+//			_ = openapi3fuzz.Validate(context.Background(), router, request, response, nil)
+//		})
+//	}
+package openapi3fuzz
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+)
+
+// Fault is the constraint a Case breaks.
+type Fault string
+
+const (
+	// Valid cases break no constraint.
+	Valid Fault = ""
+	// WrongType cases have a value of the wrong type.
+	WrongType Fault = "wrong type"
+	// MissingRequired cases lack a required parameter, body or property.
+	MissingRequired Fault = "missing required"
+	// PatternMismatch cases have a string not matching its pattern.
+	PatternMismatch Fault = "pattern mismatch"
+	// OutOfRange cases have a number out of its minimum or maximum.
+	OutOfRange Fault = "out of range"
+	// BadSerialization cases have a parameter or body that cannot be decoded.
+	BadSerialization Fault = "bad serialization"
+)
+
+// InBody is the location of faults of request and response bodies.
+const InBody = "body"
+
+// Expectation describes the error validating a Case returns.
+type Expectation struct {
+	Fault Fault
+	// In is the location of the fault: a parameter location (e.g. openapi3.ParameterInQuery) or InBody.
+	In string
+	// Name is the name of the faulty parameter.
+	Name string
+	// SchemaField is the SchemaField of the expected *openapi3.SchemaError, e.g. "pattern".
+	SchemaField string
+	// Pointer is the JSON pointer of the faulty value (see openapi3.SchemaError.JSONPointer).
+	Pointer []string
+	// ParseError reports whether an *openapi3filter.ParseError of kind ParseErrorKind is expected.
+	ParseError     bool
+	ParseErrorKind openapi3filter.ParseErrorKind
+	// Err is the expected cause, e.g. openapi3filter.ErrInvalidRequired.
+	Err error
+}
+
+// Case is a request, and optionally a response, of an operation.
+type Case struct {
+	// Name identifies the case, e.g. "GET /pets/{id} query:limit out of range".
+	Name        string
+	Method      string
+	Path        string
+	OperationID string
+	Expect      Expectation
+	// Request is the serialized HTTP/1.1 request (see ReadRequest).
+	Request []byte
+	// Response is the serialized HTTP/1.1 response of cases about responses, or nil (see ReadResponse).
+	// The request of these cases is valid.
+	Response []byte
+}
+
+// Check returns an error if err, as returned by Validate for the case, is not the expected error.
+// Validation must not use the openapi3filter.Options.MultiError option.
+func (c *Case) Check(err error) error {
+	e := c.Expect
+	if e.Fault == Valid {
+		if err != nil {
+			return fmt.Errorf("%s: expected no error, got: %w", c.Name, err)
+		}
+		return nil
+	}
+	if err == nil {
+		return fmt.Errorf("%s: expected an error, got none", c.Name)
+	}
+	mismatch := func(format string, args ...any) error {
+		return fmt.Errorf("%s: expected %s, got: %w", c.Name, fmt.Sprintf(format, args...), err)
+	}
+
+	if c.Response == nil {
+		var requestError *openapi3filter.RequestError
+		if !errors.As(err, &requestError) {
+			return mismatch("a request error")
+		}
+		if e.In == InBody {
+			if requestError.RequestBody == nil {
+				return mismatch("an error of the request body")
+			}
+		} else if p := requestError.Parameter; p == nil || p.In != e.In || p.Name != e.Name {
+			return mismatch("an error of %s parameter %q", e.In, e.Name)
+		}
+	} else {
+		var responseError *openapi3filter.ResponseError
+		if !errors.As(err, &responseError) {
+			return mismatch("a response error")
+		}
+	}
+
+	if e.Err != nil && !errors.Is(err, e.Err) {
+		return mismatch("%q", e.Err)
+	}
+	if e.SchemaField != "" {
+		var schemaError *openapi3.SchemaError
+		if !errors.As(err, &schemaError) || schemaError.SchemaField != e.SchemaField {
+			return mismatch("a schema error of %q", e.SchemaField)
+		}
+		if e.Pointer != nil && !slices.Equal(schemaError.JSONPointer(), e.Pointer) {
+			return mismatch("a schema error at %q", "/"+strings.Join(e.Pointer, "/"))
+		}
+	}
+	if e.ParseError {
+		var parseError *openapi3filter.ParseError
+		if errors.As(err, &parseError) {
+			// The kind of the root cause
+			for inner, ok := parseError.Cause.(*openapi3filter.ParseError); ok; inner, ok = parseError.Cause.(*openapi3filter.ParseError) {
+				parseError = inner
+			}
+		}
+		if parseError == nil || parseError.Kind != e.ParseErrorKind {
+			return mismatch("a parse error of kind %d", e.ParseErrorKind)
+		}
+	}
+	return nil
+}
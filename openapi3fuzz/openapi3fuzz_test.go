@@ -0,0 +1,257 @@
+package openapi3fuzz_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3fuzz"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+)
+
+const spec = `
+openapi: 3.0.3
+info:
+  title: Pets
+  version: 1.0.0
+servers:
+  - url: http://pets.example.com/v1
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      parameters:
+        - name: limit
+          in: query
+          schema:
+            type: integer
+            minimum: 1
+            maximum: 100
+        - name: tags
+          in: query
+          schema:
+            type: array
+            items:
+              type: integer
+        - name: filter
+          in: query
+          required: true
+          explode: false
+          schema:
+            type: object
+            required: [kind]
+            properties:
+              kind:
+                type: string
+                pattern: '^[a-z]+$'
+        - name: where
+          in: query
+          content:
+            application/json:
+              schema:
+                type: object
+        - name: X-Request-Id
+          in: header
+          required: true
+          schema:
+            type: string
+            pattern: '^[0-9a-f]{8}$'
+        - name: session
+          in: cookie
+          schema:
+            type: boolean
+      responses:
+        '200':
+          description: pets
+          headers:
+            X-Total:
+              required: true
+              schema:
+                type: integer
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: '#/components/schemas/Pet'
+        '4XX':
+          description: client error
+          content:
+            application/problem+json:
+              schema:
+                $ref: '#/components/schemas/Problem'
+        default:
+          description: error
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Problem'
+    post:
+      operationId: createPet
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Pet'
+      responses:
+        '201':
+          description: created
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Pet'
+  /pets/{id}:
+    parameters:
+      - name: id
+        in: path
+        required: true
+        schema:
+          type: integer
+          minimum: 1
+    delete:
+      operationId: deletePet
+      responses:
+        '204':
+          description: deleted
+components:
+  schemas:
+    Pet:
+      type: object
+      required: [id, name, age]
+      properties:
+        id:
+          type: integer
+          readOnly: true
+        name:
+          type: string
+          pattern: '^[A-Z][a-z]+$'
+        age:
+          type: integer
+          minimum: 0
+          maximum: 30
+    Problem:
+      type: object
+      required: [title]
+      properties:
+        title:
+          type: string
+`
+
+func newRouter(t testing.TB) (*openapi3.T, routers.Router) {
+	t.Helper()
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(spec))
+	require.NoError(t, err)
+	require.NoError(t, doc.Validate(context.Background()))
+	router, err := gorillamux.NewRouter(doc)
+	require.NoError(t, err)
+	return doc, router
+}
+
+func TestCases(t *testing.T) {
+	doc, router := newRouter(t)
+	cases, err := openapi3fuzz.NewGenerator(router, openapi3fuzz.Seed(42)).Cases(doc)
+	require.NoError(t, err)
+
+	names := make(map[string]bool, len(cases))
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			err := openapi3fuzz.Validate(context.Background(), router, c.Request, c.Response, nil)
+			require.NoError(t, c.Check(err))
+		})
+		require.False(t, names[c.Name], c.Name)
+		names[c.Name] = true
+	}
+
+	for _, name := range []string{
+		"GET /pets valid request",
+		"GET /pets query:limit wrong type",
+		"GET /pets query:limit out of range",
+		"GET /pets query:tags wrong type",
+		"GET /pets query:filter missing required",
+		"GET /pets query:filter bad serialization",
+		"GET /pets query:filter/kind pattern mismatch",
+		"GET /pets query:where bad serialization",
+		"GET /pets header:X-Request-Id missing required",
+		"GET /pets header:X-Request-Id pattern mismatch",
+		"GET /pets cookie:session wrong type",
+		"GET /pets valid 200 response",
+		"GET /pets 200 response body wrong type",
+		"GET /pets 200 response body/0/age missing required",
+		"GET /pets 200 response body/0/name pattern mismatch",
+		"GET /pets 200 response body/0/age out of range",
+		"GET /pets 200 response body bad serialization",
+		"GET /pets valid 4XX response",
+		"GET /pets valid default response",
+		"POST /pets valid request",
+		"POST /pets request body missing required",
+		"POST /pets request body bad serialization",
+		"POST /pets request body wrong type",
+		"POST /pets request body/name pattern mismatch",
+		"POST /pets request body/age out of range",
+		"POST /pets valid 201 response",
+		"DELETE /pets/{id} valid request",
+		"DELETE /pets/{id} path:id wrong type",
+		"DELETE /pets/{id} path:id out of range",
+		"DELETE /pets/{id} valid 204 response",
+	} {
+		require.True(t, names[name], name)
+	}
+}
+
+func TestCasesDeterministic(t *testing.T) {
+	doc, router := newRouter(t)
+	a, err := openapi3fuzz.NewGenerator(router, openapi3fuzz.Seed(1)).Cases(doc)
+	require.NoError(t, err)
+	b, err := openapi3fuzz.NewGenerator(router, openapi3fuzz.Seed(1)).Cases(doc)
+	require.NoError(t, err)
+	require.Equal(t, a, b)
+}
+
+func TestFaults(t *testing.T) {
+	doc, router := newRouter(t)
+	cases, err := openapi3fuzz.NewGenerator(router, openapi3fuzz.Faults(openapi3fuzz.OutOfRange)).Cases(doc)
+	require.NoError(t, err)
+	for _, c := range cases {
+		require.Contains(t, []openapi3fuzz.Fault{openapi3fuzz.Valid, openapi3fuzz.OutOfRange}, c.Expect.Fault)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	doc, router := newRouter(t)
+	cases, err := openapi3fuzz.NewGenerator(router).Cases(doc)
+	require.NoError(t, err)
+
+	var valid, faulty *openapi3fuzz.Case
+	for _, c := range cases {
+		switch c.Name {
+		case "POST /pets valid request":
+			valid = c
+		case "POST /pets request body/name pattern mismatch":
+			faulty = c
+		}
+	}
+	require.NotNil(t, valid)
+	require.NotNil(t, faulty)
+	require.Equal(t, []string{"name"}, faulty.Expect.Pointer)
+
+	err = openapi3fuzz.Validate(context.Background(), router, faulty.Request, nil, nil)
+	require.Error(t, valid.Check(err))
+	require.NoError(t, faulty.Check(err))
+	require.Error(t, faulty.Check(nil))
+}
+
+func FuzzValidate(f *testing.F) {
+	doc, router := newRouter(f)
+	cases, err := openapi3fuzz.NewGenerator(router).Cases(doc)
+	require.NoError(f, err)
+	openapi3fuzz.AddSeeds(f, cases)
+
+	f.Fuzz(func(t *testing.T, request, response []byte) {
+		// Validation must not panic
+		_ = openapi3fuzz.Validate(context.Background(), router, request, response, nil)
+	})
+}
@@ -0,0 +1,82 @@
+package openapi3fuzz
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+)
+
+// Seeder is implemented by *testing.F.
+type Seeder interface {
+	Add(args ...any)
+}
+
+// AddSeeds adds the request and response of each case to the seed corpus of f.
+// The fuzz target takes them as two []byte arguments: func(t *testing.T, request, response []byte).
+func AddSeeds(f Seeder, cases []*Case) {
+	for _, c := range cases {
+		f.Add(c.Request, c.Response)
+	}
+}
+
+// ReadRequest parses a serialized HTTP/1.1 request, as a server receives it.
+func ReadRequest(data []byte) (*http.Request, error) {
+	return http.ReadRequest(bufio.NewReader(bytes.NewReader(data)))
+}
+
+// ReadResponse parses a serialized HTTP/1.1 response to req.
+func ReadResponse(data []byte, req *http.Request) (*http.Response, error) {
+	return http.ReadResponse(bufio.NewReader(bytes.NewReader(data)), req)
+}
+
+// Validate routes and validates a serialized request and, if response is not nil, its serialized response.
+// options may be nil: security requirements are then satisfied by openapi3filter.NoopAuthenticationFunc.
+func Validate(ctx context.Context, router routers.Router, request, response []byte, options *openapi3filter.Options) error {
+	if options == nil {
+		options = &openapi3filter.Options{AuthenticationFunc: openapi3filter.NoopAuthenticationFunc}
+	}
+
+	req, err := ReadRequest(request)
+	if err != nil {
+		return err
+	}
+	route, pathParams, err := router.FindRoute(req)
+	if err != nil {
+		return err
+	}
+	requestValidationInput := &openapi3filter.RequestValidationInput{
+		Request:    req,
+		PathParams: pathParams,
+		Route:      route,
+		Options:    options,
+	}
+	if err := openapi3filter.ValidateRequest(ctx, requestValidationInput); err != nil {
+		return err
+	}
+	if response == nil {
+		return nil
+	}
+
+	resp, err := ReadResponse(response, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	responseValidationInput := &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: requestValidationInput,
+		Status:                 resp.StatusCode,
+		Header:                 resp.Header,
+		Options:                options,
+	}
+	responseValidationInput.SetBodyBytes(data)
+	return openapi3filter.ValidateResponse(ctx, responseValidationInput)
+}
@@ -0,0 +1,235 @@
+package openapi3har
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// HAR is an HTTP Archive, as exported by browsers and proxies.
+// See http://www.softwareishard.com/blog/har-12-spec/
+type HAR struct {
+	Log *Log `json:"log"`
+}
+
+// Log is the root of a HAR file.
+type Log struct {
+	Version string   `json:"version"`
+	Creator *Creator `json:"creator,omitempty"`
+	Entries []*Entry `json:"entries"`
+	Comment string   `json:"comment,omitempty"`
+}
+
+// Creator is the application that created a HAR file.
+type Creator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Entry is an exchange of a request and a response.
+type Entry struct {
+	StartedDateTime string    `json:"startedDateTime"`
+	Time            float64   `json:"time"`
+	Request         *Request  `json:"request"`
+	Response        *Response `json:"response"`
+	Comment         string    `json:"comment,omitempty"`
+}
+
+// Request is a recorded request.
+type Request struct {
+	Method      string       `json:"method"`
+	URL         string       `json:"url"`
+	HTTPVersion string       `json:"httpVersion"`
+	Cookies     []*Cookie    `json:"cookies"`
+	Headers     []*NameValue `json:"headers"`
+	QueryString []*NameValue `json:"queryString"`
+	PostData    *PostData    `json:"postData,omitempty"`
+	HeadersSize int64        `json:"headersSize"`
+	BodySize    int64        `json:"bodySize"`
+	Comment     string       `json:"comment,omitempty"`
+}
+
+// Response is a recorded response.
+// Its status is 0 when none was received (e.g. for aborted requests).
+type Response struct {
+	Status      int          `json:"status"`
+	StatusText  string       `json:"statusText"`
+	HTTPVersion string       `json:"httpVersion"`
+	Cookies     []*Cookie    `json:"cookies"`
+	Headers     []*NameValue `json:"headers"`
+	Content     *Content     `json:"content"`
+	RedirectURL string       `json:"redirectURL"`
+	HeadersSize int64        `json:"headersSize"`
+	BodySize    int64        `json:"bodySize"`
+	Comment     string       `json:"comment,omitempty"`
+}
+
+// Cookie is a cookie of a request or a response.
+type Cookie struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Path     string `json:"path,omitempty"`
+	Domain   string `json:"domain,omitempty"`
+	Expires  string `json:"expires,omitempty"`
+	HTTPOnly bool   `json:"httpOnly,omitempty"`
+	Secure   bool   `json:"secure,omitempty"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+// NameValue is a header or a query parameter.
+type NameValue struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	Comment string `json:"comment,omitempty"`
+}
+
+// PostData is the body of a request.
+type PostData struct {
+	MimeType string   `json:"mimeType"`
+	Params   []*Param `json:"params,omitempty"`
+	Text     string   `json:"text"`
+	Comment  string   `json:"comment,omitempty"`
+}
+
+// Param is a parameter of a form body.
+type Param struct {
+	Name        string `json:"name"`
+	Value       string `json:"value,omitempty"`
+	FileName    string `json:"fileName,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+	Comment     string `json:"comment,omitempty"`
+}
+
+// Content is the body of a response, as decoded from its Content-Encoding.
+type Content struct {
+	Size        int64  `json:"size"`
+	Compression int64  `json:"compression,omitempty"`
+	MimeType    string `json:"mimeType"`
+	Text        string `json:"text,omitempty"`
+	// Encoding is "base64" for binary bodies.
+	Encoding string `json:"encoding,omitempty"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+// Load reads a HAR file from r.
+func Load(r io.Reader) (*HAR, error) {
+	var har HAR
+	if err := json.NewDecoder(r).Decode(&har); err != nil {
+		return nil, fmt.Errorf("invalid HAR file: %w", err)
+	}
+	if har.Log == nil {
+		return nil, errors.New("invalid HAR file: missing log")
+	}
+	return &har, nil
+}
+
+// LoadFile reads the HAR file at path.
+func LoadFile(path string) (*HAR, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Load(f)
+}
+
+// HTTPRequest rebuilds the request of the entry.
+func (entry *Entry) HTTPRequest() (*http.Request, error) {
+	r := entry.Request
+	if r == nil {
+		return nil, errors.New("entry has no request")
+	}
+	var body []byte
+	if data := r.PostData; data != nil {
+		body = []byte(data.Text)
+		if data.Text == "" && len(data.Params) != 0 {
+			if mediaType, _, _ := mime.ParseMediaType(data.MimeType); mediaType == "application/x-www-form-urlencoded" {
+				values := make(url.Values, len(data.Params))
+				for _, param := range data.Params {
+					values.Add(param.Name, param.Value)
+				}
+				body = []byte(values.Encode())
+			}
+		}
+	}
+	req, err := http.NewRequest(r.Method, r.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) == 0 {
+		req.Body, req.GetBody = http.NoBody, nil
+	}
+	setHeaders(req.Header, r.Headers)
+	if host := req.Header.Get("Host"); host != "" {
+		req.Host = host
+		req.Header.Del("Host")
+	}
+	if r.PostData != nil && req.Header.Get("Content-Type") == "" && r.PostData.MimeType != "" {
+		req.Header.Set("Content-Type", r.PostData.MimeType)
+	}
+	if req.Header.Get("Cookie") == "" {
+		for _, cookie := range r.Cookies {
+			req.AddCookie(&http.Cookie{Name: cookie.Name, Value: cookie.Value})
+		}
+	}
+	return req, nil
+}
+
+// HTTPResponse rebuilds the response of the entry to req.
+// It returns nil when the entry has no response.
+//
+// Bodies are recorded decoded, so the Content-Encoding and Content-Length headers of responses are dropped.
+func (entry *Entry) HTTPResponse(req *http.Request) (*http.Response, error) {
+	r := entry.Response
+	if r == nil || r.Status == 0 {
+		return nil, nil
+	}
+	var body []byte
+	if content := r.Content; content != nil {
+		switch content.Encoding {
+		case "":
+			body = []byte(content.Text)
+		case "base64":
+			var err error
+			if body, err = base64.StdEncoding.DecodeString(content.Text); err != nil {
+				return nil, fmt.Errorf("invalid base64 response body: %w", err)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported encoding %q of response body", content.Encoding)
+		}
+	}
+	resp := &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.Status, r.StatusText),
+		StatusCode:    r.Status,
+		Header:        make(http.Header, len(r.Headers)),
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+	setHeaders(resp.Header, r.Headers)
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.Header.Del("Transfer-Encoding")
+	if resp.Header.Get("Content-Type") == "" && r.Content != nil && r.Content.MimeType != "" && len(body) != 0 {
+		resp.Header.Set("Content-Type", r.Content.MimeType)
+	}
+	return resp, nil
+}
+
+func setHeaders(header http.Header, headers []*NameValue) {
+	for _, h := range headers {
+		// Skip HTTP/2 pseudo-headers (e.g. ":authority")
+		if strings.HasPrefix(h.Name, ":") {
+			continue
+		}
+		header.Add(h.Name, h.Value)
+	}
+}
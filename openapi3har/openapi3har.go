@@ -0,0 +1,331 @@
+// Package openapi3har validates traffic recorded in HAR files (HTTP Archive 1.2) against an OpenAPI document,
+// offline, e.g. traffic exported from the developer tools of browsers or from proxies.
+//
+// Each entry is rebuilt as an *http.Request and *http.Response, routed with a routers.Router,
+// and checked with openapi3filter.ValidateRequest and openapi3filter.ValidateResponse:
+//
+//	archive, err := openapi3har.LoadFile("traffic.har")
+//	if err != nil {
+//		panic(err)
+//	}
+//	report := openapi3har.NewValidator(router).Validate(ctx, archive)
+//	if err := report.WriteText(os.Stdout); err != nil {
+//		panic(err)
+//	}
+//
+// The Report lists the violations of each entry, the requests that matched no route
+// and aggregates per operation.
+package openapi3har
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+)
+
+// Validator validates the entries of HAR files.
+type Validator struct {
+	router  routers.Router
+	options openapi3filter.Options
+	filter  func(*Entry) bool
+}
+
+// ValidatorOption defines an option that may be specified when creating a Validator.
+type ValidatorOption func(*Validator)
+
+// ValidationOptions sets request/response validation options on the validator.
+// Security requirements are satisfied by openapi3filter.NoopAuthenticationFunc
+// unless options.AuthenticationFunc is set.
+func ValidationOptions(options openapi3filter.Options) ValidatorOption {
+	return func(v *Validator) {
+		v.options = options
+	}
+}
+
+// EntryFilter sets a function selecting the entries to validate, e.g. to skip the static assets
+// of a HAR file exported by a browser. Other entries are left out of reports.
+func EntryFilter(filter func(*Entry) bool) ValidatorOption {
+	return func(v *Validator) {
+		v.filter = filter
+	}
+}
+
+// NewValidator returns a Validator of the entries routed by router.
+func NewValidator(router routers.Router, options ...ValidatorOption) *Validator {
+	v := &Validator{router: router}
+	for _, option := range options {
+		option(v)
+	}
+	if v.options.AuthenticationFunc == nil {
+		v.options.AuthenticationFunc = openapi3filter.NoopAuthenticationFunc
+	}
+	return v
+}
+
+// Report is the result of the validation of a HAR file.
+type Report struct {
+	// Entries are the results of the validated entries, in the order of the file.
+	Entries []*EntryResult `json:"entries"`
+	// Unmatched are the results of the entries whose request matched no route.
+	Unmatched []*EntryResult `json:"unmatched"`
+	// Operations aggregates the results of the matched entries per operation,
+	// sorted by path then method.
+	Operations []*OperationReport `json:"operations"`
+}
+
+// Valid reports whether all the entries matched a route and have no violations.
+func (report *Report) Valid() bool {
+	for _, result := range report.Entries {
+		if !result.Valid() {
+			return false
+		}
+	}
+	return true
+}
+
+// EntryResult is the result of the validation of an entry.
+type EntryResult struct {
+	// Index is the index of the entry in the HAR file.
+	Index  int    `json:"index"`
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	// Status is the status of the response, 0 if there is none.
+	Status int `json:"status,omitempty"`
+
+	// Route is the route matched by the request, nil if none did.
+	Route *routers.Route `json:"-"`
+	// Operation is the method and path of Route, e.g. "GET /pets/{id}".
+	Operation string `json:"operation,omitempty"`
+
+	// Err is the error rebuilding or routing the entry, if any.
+	Err error `json:"-"`
+	// Error is the message of Err.
+	Error string `json:"error,omitempty"`
+
+	// RequestErr and ResponseErr are the errors returned by
+	// openapi3filter.ValidateRequest and openapi3filter.ValidateResponse.
+	RequestErr  error `json:"-"`
+	ResponseErr error `json:"-"`
+	// Violations lists RequestErr and ResponseErr, one per error of a MultiError.
+	Violations []*Violation `json:"violations,omitempty"`
+}
+
+// Valid reports whether the entry matched a route and has no violations.
+func (result *EntryResult) Valid() bool {
+	return result.Err == nil && len(result.Violations) == 0
+}
+
+// Violation is a failure of the validation of a request or a response.
+type Violation struct {
+	// Response is set for violations of the response.
+	Response bool `json:"response,omitempty"`
+	*openapi3filter.ProblemError
+}
+
+// OperationReport aggregates the results of the entries of an operation.
+type OperationReport struct {
+	Method      string `json:"method"`
+	Path        string `json:"path"`
+	OperationID string `json:"operationId,omitempty"`
+	// Entries is the number of entries of the operation.
+	Entries int `json:"entries"`
+	// InvalidRequests and InvalidResponses are the number of entries failing validation.
+	InvalidRequests  int `json:"invalidRequests"`
+	InvalidResponses int `json:"invalidResponses"`
+	// Statuses counts the entries per response status.
+	Statuses map[int]int `json:"statuses,omitempty"`
+}
+
+// Validate validates the entries of har.
+func (v *Validator) Validate(ctx context.Context, har *HAR) *Report {
+	report := &Report{Entries: []*EntryResult{}, Unmatched: []*EntryResult{}, Operations: []*OperationReport{}}
+	operations := make(map[string]*OperationReport)
+	for i, entry := range har.Log.Entries {
+		if v.filter != nil && !v.filter(entry) {
+			continue
+		}
+		result := v.validateEntry(ctx, i, entry)
+		report.Entries = append(report.Entries, result)
+		if result.Route == nil {
+			if result.unmatched() {
+				report.Unmatched = append(report.Unmatched, result)
+			}
+			continue
+		}
+
+		op := operations[result.Operation]
+		if op == nil {
+			op = &OperationReport{Method: result.Route.Method, Path: result.Route.Path, Statuses: make(map[int]int)}
+			if result.Route.Operation != nil {
+				op.OperationID = result.Route.Operation.OperationID
+			}
+			operations[result.Operation] = op
+			report.Operations = append(report.Operations, op)
+		}
+		op.Entries++
+		if result.RequestErr != nil {
+			op.InvalidRequests++
+		}
+		if result.ResponseErr != nil {
+			op.InvalidResponses++
+		}
+		if result.Status != 0 {
+			op.Statuses[result.Status]++
+		}
+	}
+	slices.SortFunc(report.Operations, func(a, b *OperationReport) int {
+		return cmp.Or(cmp.Compare(a.Path, b.Path), cmp.Compare(a.Method, b.Method))
+	})
+	return report
+}
+
+func (v *Validator) validateEntry(ctx context.Context, index int, entry *Entry) *EntryResult {
+	result := &EntryResult{Index: index}
+	if r := entry.Request; r != nil {
+		result.Method, result.URL = r.Method, r.URL
+	}
+	fail := func(err error) *EntryResult {
+		result.Err, result.Error = err, err.Error()
+		return result
+	}
+
+	req, err := entry.HTTPRequest()
+	if err != nil {
+		return fail(err)
+	}
+	resp, err := entry.HTTPResponse(req)
+	if err != nil {
+		return fail(err)
+	}
+	if resp != nil {
+		result.Status = resp.StatusCode
+	}
+	route, pathParams, err := v.router.FindRoute(req)
+	if err != nil {
+		return fail(err)
+	}
+	result.Route = route
+	result.Operation = route.Method + " " + route.Path
+
+	options := v.options
+	requestValidationInput := &openapi3filter.RequestValidationInput{
+		Request:    req,
+		PathParams: pathParams,
+		Route:      route,
+		Options:    &options,
+	}
+	if err := openapi3filter.ValidateRequest(ctx, requestValidationInput); err != nil {
+		result.RequestErr = err
+		result.addViolations(err, false)
+	}
+	if resp == nil {
+		return result
+	}
+	responseValidationInput := &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: requestValidationInput,
+		Status:                 resp.StatusCode,
+		Header:                 resp.Header,
+		Body:                   resp.Body,
+		Options:                &options,
+	}
+	if err := openapi3filter.ValidateResponse(ctx, responseValidationInput); err != nil {
+		result.ResponseErr = err
+		result.addViolations(err, true)
+	}
+	return result
+}
+
+// unmatched reports whether the request of the entry matched no route.
+func (result *EntryResult) unmatched() bool {
+	var routeErr *routers.RouteError
+	return errors.As(result.Err, &routeErr)
+}
+
+func (result *EntryResult) addViolations(err error, response bool) {
+	fallback := http.StatusBadRequest
+	if response {
+		fallback = http.StatusInternalServerError
+	}
+	for _, pe := range openapi3filter.NewProblemDetails(err, fallback).Errors {
+		result.Violations = append(result.Violations, &Violation{Response: response, ProblemError: pe})
+	}
+}
+
+// WriteText writes a human-readable summary of the report to w:
+// the violations of each invalid entry, the unmatched requests and the aggregates per operation.
+func (report *Report) WriteText(w io.Writer) error {
+	ew := &errWriter{w: w}
+	invalid := 0
+	for _, result := range report.Entries {
+		if len(result.Violations) == 0 && (result.Err == nil || result.unmatched()) {
+			continue
+		}
+		invalid++
+		ew.printf("entry %d: %s %s", result.Index, result.Method, result.URL)
+		if result.Status != 0 {
+			ew.printf(" -> %d", result.Status)
+		}
+		if result.Operation != "" {
+			ew.printf(" (%s)", result.Operation)
+		}
+		ew.printf("\n")
+		if result.Err != nil {
+			ew.printf("  error: %s\n", result.Error)
+		}
+		for _, violation := range result.Violations {
+			where := "request"
+			if violation.Response {
+				where = "response"
+			}
+			if violation.In != "" {
+				where += " " + violation.In
+			}
+			if violation.Parameter != "" {
+				where += fmt.Sprintf(" %q", violation.Parameter)
+			}
+			if violation.Pointer != "" {
+				where += " " + violation.Pointer
+			}
+			ew.printf("  %s: %s (%s)\n", where, violation.Detail, violation.Code)
+		}
+	}
+
+	if len(report.Unmatched) != 0 {
+		ew.printf("unmatched requests:\n")
+		for _, result := range report.Unmatched {
+			ew.printf("  entry %d: %s %s: %s\n", result.Index, result.Method, result.URL, result.Error)
+		}
+	}
+
+	if len(report.Operations) != 0 {
+		ew.printf("operations:\n")
+		for _, op := range report.Operations {
+			ew.printf("  %s %s", op.Method, op.Path)
+			if op.OperationID != "" {
+				ew.printf(" (%s)", op.OperationID)
+			}
+			ew.printf(": %d entries, %d invalid requests, %d invalid responses\n", op.Entries, op.InvalidRequests, op.InvalidResponses)
+		}
+	}
+
+	ew.printf("%d entries, %d invalid, %d unmatched\n", len(report.Entries), invalid, len(report.Unmatched))
+	return ew.err
+}
+
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (ew *errWriter) printf(format string, args ...any) {
+	if ew.err == nil {
+		_, ew.err = fmt.Fprintf(ew.w, format, args...)
+	}
+}
@@ -0,0 +1,227 @@
+package openapi3har_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/openapi3har"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+)
+
+const spec = `
+openapi: 3.0.3
+info:
+  title: Pets
+  version: 1.0.0
+servers:
+  - url: https://api.example.com/v1
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      parameters:
+        - name: limit
+          in: query
+          schema:
+            type: integer
+            maximum: 100
+      responses:
+        '200':
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: '#/components/schemas/Pet'
+    post:
+      operationId: createPet
+      requestBody:
+        required: true
+        content:
+          application/x-www-form-urlencoded:
+            schema:
+              type: object
+              required: [name]
+              properties:
+                name:
+                  type: string
+      responses:
+        '201':
+          description: created
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Pet'
+  /pets/{id}:
+    get:
+      operationId: getPet
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: integer
+      responses:
+        '200':
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Pet'
+components:
+  schemas:
+    Pet:
+      type: object
+      required: [id, name]
+      properties:
+        id:
+          type: integer
+        name:
+          type: string
+`
+
+func TestValidator(t *testing.T) {
+	ctx := context.Background()
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(spec))
+	require.NoError(t, err)
+	require.NoError(t, doc.Validate(ctx))
+	router, err := gorillamux.NewRouter(doc)
+	require.NoError(t, err)
+
+	har, err := openapi3har.LoadFile("testdata/traffic.har")
+	require.NoError(t, err)
+	require.Len(t, har.Log.Entries, 6)
+
+	report := openapi3har.NewValidator(router).Validate(ctx, har)
+	require.False(t, report.Valid())
+	require.Len(t, report.Entries, 6)
+
+	// Valid entries: HTTP/2 headers, decoded bodies, form params, base64 bodies and aborted requests
+	for _, i := range []int{0, 2, 3} {
+		require.True(t, report.Entries[i].Valid(), "entry %d: %+v", i, report.Entries[i])
+	}
+	require.Equal(t, "POST /pets", report.Entries[2].Operation)
+	require.Equal(t, 201, report.Entries[2].Status)
+	require.Equal(t, 0, report.Entries[3].Status)
+
+	// Violations of requests and responses
+	result := report.Entries[1]
+	require.Len(t, result.Violations, 2)
+	require.False(t, result.Violations[0].Response)
+	require.Equal(t, "schema-maximum", result.Violations[0].Code)
+	require.Equal(t, "limit", result.Violations[0].Parameter)
+	require.True(t, result.Violations[1].Response)
+	require.Equal(t, "schema-type", result.Violations[1].Code)
+	var requestErr *openapi3filter.RequestError
+	require.ErrorAs(t, result.RequestErr, &requestErr)
+	var responseErr *openapi3filter.ResponseError
+	require.ErrorAs(t, result.ResponseErr, &responseErr)
+
+	// Unmatched requests
+	require.Len(t, report.Unmatched, 2)
+	require.Equal(t, "https://api.example.com/v1/owners", report.Unmatched[0].URL)
+	require.Equal(t, "no matching operation was found", report.Unmatched[0].Error)
+	require.Equal(t, "https://api.example.com/favicon.ico", report.Unmatched[1].URL)
+
+	// Aggregates per operation
+	require.Len(t, report.Operations, 3)
+	require.Equal(t, &openapi3har.OperationReport{
+		Method:           http.MethodGet,
+		Path:             "/pets",
+		OperationID:      "listPets",
+		Entries:          2,
+		InvalidRequests:  1,
+		InvalidResponses: 1,
+		Statuses:         map[int]int{200: 2},
+	}, report.Operations[0])
+	require.Equal(t, "createPet", report.Operations[1].OperationID)
+	require.Equal(t, "getPet", report.Operations[2].OperationID)
+	require.Empty(t, report.Operations[2].Statuses)
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteText(&buf))
+	require.Equal(t, `entry 1: GET https://api.example.com/v1/pets?limit=1000 -> 200 (GET /pets)
+  request query "limit": number must be at most 100 (schema-maximum)
+  response body /0/id: value must be an integer (schema-type)
+unmatched requests:
+  entry 4: GET https://api.example.com/v1/owners: no matching operation was found
+  entry 5: GET https://api.example.com/favicon.ico: no matching operation was found
+operations:
+  GET /pets (listPets): 2 entries, 1 invalid requests, 1 invalid responses
+  POST /pets (createPet): 1 entries, 0 invalid requests, 0 invalid responses
+  GET /pets/{id} (getPet): 1 entries, 0 invalid requests, 0 invalid responses
+6 entries, 1 invalid, 2 unmatched
+`, buf.String())
+
+	data, err := json.Marshal(report)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `{"response":true,"code":"schema-type","detail":`)
+
+	// Options and filters
+	report = openapi3har.NewValidator(router,
+		openapi3har.ValidationOptions(openapi3filter.Options{MultiError: true, ExcludeResponseBody: true}),
+		openapi3har.EntryFilter(func(entry *openapi3har.Entry) bool {
+			return strings.HasPrefix(entry.Request.URL, "https://api.example.com/v1/")
+		}),
+	).Validate(ctx, har)
+	require.Len(t, report.Entries, 5)
+	require.Len(t, report.Unmatched, 1)
+	require.Len(t, report.Entries[1].Violations, 1)
+}
+
+func TestEntry(t *testing.T) {
+	entry := &openapi3har.Entry{
+		Request: &openapi3har.Request{
+			Method: http.MethodPost,
+			URL:    "http://localhost:8080/pets?x=1",
+			Headers: []*openapi3har.NameValue{
+				{Name: "Host", Value: "api.example.com"},
+				{Name: "content-type", Value: "application/json"},
+			},
+			Cookies:  []*openapi3har.Cookie{{Name: "session", Value: "s"}},
+			PostData: &openapi3har.PostData{MimeType: "application/json", Text: `{"name":"Tom"}`},
+		},
+		Response: &openapi3har.Response{
+			Status:     200,
+			StatusText: "OK",
+			Headers:    []*openapi3har.NameValue{{Name: "Content-Encoding", Value: "br"}},
+			Content:    &openapi3har.Content{MimeType: "text/plain", Text: "b2s=", Encoding: "base64"},
+		},
+	}
+	req, err := entry.HTTPRequest()
+	require.NoError(t, err)
+	require.Equal(t, "api.example.com", req.Host)
+	require.Equal(t, "1", req.URL.Query().Get("x"))
+	require.Equal(t, "application/json", req.Header.Get("Content-Type"))
+	require.Equal(t, "session=s", req.Header.Get("Cookie"))
+	body, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	require.Equal(t, `{"name":"Tom"}`, string(body))
+
+	resp, err := entry.HTTPResponse(req)
+	require.NoError(t, err)
+	require.Equal(t, 200, resp.StatusCode)
+	require.Equal(t, http.Header{"Content-Type": {"text/plain"}}, resp.Header)
+	body, err = io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, "ok", string(body))
+
+	entry.Response.Content.Encoding = "gzip"
+	_, err = entry.HTTPResponse(req)
+	require.EqualError(t, err, `unsupported encoding "gzip" of response body`)
+
+	_, err = openapi3har.Load(strings.NewReader(`{}`))
+	require.EqualError(t, err, "invalid HAR file: missing log")
+	_, err = openapi3har.Load(strings.NewReader(`{"log":`))
+	require.EqualError(t, err, "invalid HAR file: unexpected EOF")
+}
@@ -0,0 +1,417 @@
+// Package openapi3mock serves mock responses for the operations of an OpenAPI document.
+//
+// Requests are routed with a routers.Router and validated with openapi3filter.ValidateRequest.
+// Responses come from the examples of the document, or are synthesized from their schemas,
+// and are checked with openapi3filter.ValidateResponse before they are sent.
+//
+// Clients pick the status code and the named example of a response with the Prefer header
+// (e.g. "Prefer: code=404, example=notFound") or the query parameters __code and __example.
+package openapi3mock
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"mime"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+)
+
+// Server is an http.Handler answering requests with mock responses.
+type Server struct {
+	router       routers.Router
+	options      openapi3filter.Options
+	errorEncoder openapi3filter.ErrorEncoder
+}
+
+var _ http.Handler = &Server{}
+
+// ServerOption defines an option that may be specified when creating a Server.
+type ServerOption func(*Server)
+
+// ValidationOptions sets request/response validation options on the server.
+// Security requirements are satisfied by openapi3filter.NoopAuthenticationFunc
+// unless options.AuthenticationFunc is set.
+func ValidationOptions(options openapi3filter.Options) ServerOption {
+	return func(s *Server) {
+		s.options = options
+	}
+}
+
+// ErrorEncoder sets the ErrorEncoder answering requests that cannot be mocked:
+// unknown routes (404 or 405), invalid requests (400), unavailable responses or examples (400),
+// unacceptable media types (406) and invalid mock responses (500).
+// It defaults to openapi3filter.DefaultErrorEncoder.
+func ErrorEncoder(enc openapi3filter.ErrorEncoder) ServerOption {
+	return func(s *Server) {
+		s.errorEncoder = enc
+	}
+}
+
+// NewServer returns a Server mocking the operations routed by router.
+func NewServer(router routers.Router, options ...ServerOption) *Server {
+	s := &Server{
+		router:       router,
+		errorEncoder: openapi3filter.DefaultErrorEncoder,
+	}
+	for i := range options {
+		options[i](s)
+	}
+	if s.options.AuthenticationFunc == nil {
+		s.options.AuthenticationFunc = openapi3filter.NoopAuthenticationFunc
+	}
+	return s
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	route, pathParams, err := s.router.FindRoute(r)
+	if err != nil {
+		s.errorEncoder(ctx, openapi3filter.ConvertErrors(err), w)
+		return
+	}
+
+	input := &openapi3filter.RequestValidationInput{
+		Request:    r,
+		PathParams: pathParams,
+		Route:      route,
+		Options:    &s.options,
+	}
+	if err := openapi3filter.ValidateRequest(ctx, input); err != nil {
+		err = openapi3filter.ConvertErrors(err)
+		if _, ok := err.(*openapi3filter.ValidationError); !ok {
+			err = &openapi3filter.ValidationError{Status: http.StatusBadRequest, Title: err.Error()}
+		}
+		s.errorEncoder(ctx, err, w)
+		return
+	}
+
+	mock, err := newMockResponse(route.Operation, parsePreferences(r), r.Header.Get("Accept"))
+	if err != nil {
+		s.errorEncoder(ctx, err, w)
+		return
+	}
+
+	responseValidationInput := &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: input,
+		Status:                 mock.status,
+		Header:                 mock.header,
+		Options:                &s.options,
+	}
+	responseValidationInput.SetBodyBytes(mock.body)
+	if err := openapi3filter.ValidateResponse(ctx, responseValidationInput); err != nil {
+		s.errorEncoder(ctx, &openapi3filter.ValidationError{
+			Status: http.StatusInternalServerError,
+			Title:  "mock response is invalid",
+			Detail: err.Error(),
+		}, w)
+		return
+	}
+
+	maps.Copy(w.Header(), mock.header)
+	w.WriteHeader(mock.status)
+	if r.Method != http.MethodHead {
+		_, _ = w.Write(mock.body)
+	}
+}
+
+// preferences are the choices of a client, from the Prefer header or the query.
+type preferences struct {
+	code    string
+	example string
+}
+
+// parsePreferences reads preferences "code" and "example" from the Prefer header (RFC 7240)
+// and the query parameters __code and __example, which take precedence.
+func parsePreferences(r *http.Request) preferences {
+	var prefs preferences
+	for _, header := range r.Header.Values("Prefer") {
+		for _, pref := range strings.Split(header, ",") {
+			pref, _, _ = strings.Cut(pref, ";")
+			name, value, _ := strings.Cut(strings.TrimSpace(pref), "=")
+			value = strings.Trim(strings.TrimSpace(value), `"`)
+			switch strings.ToLower(strings.TrimSpace(name)) {
+			case "code":
+				prefs.code = value
+			case "example":
+				prefs.example = value
+			}
+		}
+	}
+	query := r.URL.Query()
+	if code := query.Get("__code"); code != "" {
+		prefs.code = code
+	}
+	if example := query.Get("__example"); example != "" {
+		prefs.example = example
+	}
+	return prefs
+}
+
+type mockResponse struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+func newMockResponse(operation *openapi3.Operation, prefs preferences, accept string) (*mockResponse, error) {
+	status, response, err := selectResponse(operation.Responses, prefs)
+	if err != nil {
+		return nil, err
+	}
+
+	mock := &mockResponse{status: status, header: make(http.Header)}
+	for _, name := range slices.Sorted(maps.Keys(response.Headers)) {
+		header := response.Headers[name].Value
+		if header == nil || http.CanonicalHeaderKey(name) == "Content-Type" {
+			continue
+		}
+		value := exampleValue(header.Example, header.Examples, "")
+		if value == nil && header.Schema != nil {
+			value = synthesize(header.Schema)
+		}
+		param := header.Parameter
+		param.Name, param.In = name, openapi3.ParameterInHeader
+		encoded, err := openapi3filter.EncodeParameter(&param, value)
+		if err != nil {
+			return nil, &openapi3filter.ValidationError{
+				Status: http.StatusInternalServerError,
+				Title:  fmt.Sprintf("cannot mock response header %q", name),
+				Detail: err.Error(),
+			}
+		}
+		if encoded != nil {
+			mock.header.Set(name, encoded.Value)
+		}
+	}
+
+	if len(response.Content) == 0 {
+		if prefs.example != "" {
+			return nil, &openapi3filter.ValidationError{
+				Status: http.StatusBadRequest,
+				Title:  fmt.Sprintf("response %d has no example %q", status, prefs.example),
+			}
+		}
+		return mock, nil
+	}
+
+	contentType, mediaType, err := negotiate(response.Content, accept, prefs.example)
+	if err != nil {
+		return nil, err
+	}
+	var value any
+	if prefs.example != "" {
+		if value = exampleValue(nil, mediaType.Examples, prefs.example); value == nil {
+			return nil, &openapi3filter.ValidationError{
+				Status: http.StatusBadRequest,
+				Title:  fmt.Sprintf("response %d has no example %q", status, prefs.example),
+			}
+		}
+	} else if value = exampleValue(mediaType.Example, mediaType.Examples, ""); value == nil && mediaType.Schema != nil {
+		value = synthesize(mediaType.Schema)
+	}
+
+	if mock.body, err = encodeBody(value, contentType); err != nil {
+		return nil, &openapi3filter.ValidationError{
+			Status: http.StatusInternalServerError,
+			Title:  fmt.Sprintf("cannot mock response body of type %q", contentType),
+			Detail: err.Error(),
+		}
+	}
+	mock.header.Set("Content-Type", contentType)
+	return mock, nil
+}
+
+// selectResponse returns the response of the preferred status code or of the response
+// having the preferred example, or the first success response otherwise.
+func selectResponse(responses *openapi3.Responses, prefs preferences) (int, *openapi3.Response, error) {
+	codes := slices.Sorted(maps.Keys(responses.Map()))
+	if responses.Len() == 0 {
+		return 0, nil, &openapi3filter.ValidationError{Status: http.StatusNotImplemented, Title: "operation has no responses"}
+	}
+
+	if prefs.code != "" {
+		status, err := strconv.Atoi(prefs.code)
+		if err != nil || status < 100 || status > 599 {
+			return 0, nil, &openapi3filter.ValidationError{
+				Status: http.StatusBadRequest,
+				Title:  fmt.Sprintf("invalid preferred status code %q", prefs.code),
+			}
+		}
+		responseRef := responses.Status(status)
+		if responseRef == nil {
+			responseRef = responses.Default()
+		}
+		if responseRef == nil || responseRef.Value == nil {
+			return 0, nil, &openapi3filter.ValidationError{
+				Status: http.StatusBadRequest,
+				Title:  fmt.Sprintf("operation has no response %d", status),
+			}
+		}
+		return status, responseRef.Value, nil
+	}
+
+	if prefs.example != "" {
+		for _, code := range codes {
+			response := responses.Value(code).Value
+			for _, mediaType := range response.Content {
+				if _, ok := mediaType.Examples[prefs.example]; ok {
+					return statusOf(code), response, nil
+				}
+			}
+		}
+	}
+
+	for _, code := range codes {
+		if strings.HasPrefix(code, "2") {
+			return statusOf(code), responses.Value(code).Value, nil
+		}
+	}
+	if responseRef := responses.Default(); responseRef != nil {
+		return http.StatusOK, responseRef.Value, nil
+	}
+	return statusOf(codes[0]), responses.Value(codes[0]).Value, nil
+}
+
+// statusOf returns the status code of a key of responses: "404", "4XX" or "default".
+func statusOf(code string) int {
+	if len(code) == 3 && (code[1] == 'X' || code[1] == 'x') {
+		code = code[:1] + "00"
+	}
+	if status, err := strconv.Atoi(code); err == nil {
+		return status
+	}
+	return http.StatusOK
+}
+
+// negotiate returns the first media type of content accepted by the client,
+// preferring the one having the preferred example, then application/json.
+func negotiate(content openapi3.Content, accept, example string) (string, *openapi3.MediaType, error) {
+	candidates := slices.Sorted(maps.Keys(content))
+	slices.SortStableFunc(candidates, func(a, b string) int {
+		rank := func(mt string) int {
+			if _, ok := content[mt].Examples[example]; ok && example != "" {
+				return 0
+			}
+			if mt == "application/json" {
+				return 1
+			}
+			return 2
+		}
+		return rank(a) - rank(b)
+	})
+
+	for _, accepted := range parseAccept(accept) {
+		for _, candidate := range candidates {
+			if matchMediaType(accepted, candidate) {
+				contentType := candidate
+				if strings.Contains(contentType, "*") {
+					contentType = "application/octet-stream"
+					if matchMediaType(candidate, "application/json") {
+						contentType = "application/json"
+					}
+				}
+				return contentType, content[candidate], nil
+			}
+		}
+	}
+	return "", nil, &openapi3filter.ValidationError{
+		Status: http.StatusNotAcceptable,
+		Title:  fmt.Sprintf("no response media type is acceptable: %s", strings.Join(candidates, ", ")),
+	}
+}
+
+// parseAccept returns the media ranges of an Accept header, by decreasing quality.
+func parseAccept(accept string) []string {
+	if strings.TrimSpace(accept) == "" {
+		return []string{"*/*"}
+	}
+	type mediaRange struct {
+		value string
+		q     float64
+	}
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		value, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if s, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(s, 64); err != nil {
+				continue
+			}
+		}
+		if q > 0 {
+			ranges = append(ranges, mediaRange{value: value, q: q})
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].q > ranges[j].q })
+	values := make([]string, 0, len(ranges))
+	for _, r := range ranges {
+		values = append(values, r.value)
+	}
+	return values
+}
+
+// matchMediaType reports whether media types a and b match, either may be a range such as "text/*".
+func matchMediaType(a, b string) bool {
+	if a == "*/*" || b == "*/*" {
+		return true
+	}
+	aType, aSub, _ := strings.Cut(a, "/")
+	bType, bSub, _ := strings.Cut(b, "/")
+	return strings.EqualFold(aType, bType) && (aSub == "*" || bSub == "*" || strings.EqualFold(aSub, bSub))
+}
+
+// exampleValue returns the named example, or the example if any, or the first of the examples.
+func exampleValue(example any, examples openapi3.Examples, name string) any {
+	if name != "" {
+		if ref := examples[name]; ref != nil && ref.Value != nil {
+			return ref.Value.Value
+		}
+		return nil
+	}
+	if example != nil {
+		return example
+	}
+	for _, name := range slices.Sorted(maps.Keys(examples)) {
+		if ref := examples[name]; ref != nil && ref.Value != nil && ref.Value.Value != nil {
+			return ref.Value.Value
+		}
+	}
+	return nil
+}
+
+// encodeBody encodes value with the body encoder registered for contentType,
+// as JSON for +json media types, or as text.
+func encodeBody(value any, contentType string) ([]byte, error) {
+	encoder := openapi3filter.RegisteredBodyEncoder(contentType)
+	if encoder == nil && strings.HasSuffix(contentType, "+json") {
+		encoder = json.Marshal
+	}
+	if encoder != nil {
+		return encoder(value)
+	}
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []byte(v), nil
+	case []byte:
+		return v, nil
+	default:
+		var buf bytes.Buffer
+		_, err := fmt.Fprint(&buf, v)
+		return buf.Bytes(), err
+	}
+}
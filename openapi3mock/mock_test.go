@@ -0,0 +1,237 @@
+package openapi3mock_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3mock"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+)
+
+const spec = `
+openapi: 3.0.0
+info:
+  title: Pets
+  version: '1.0'
+paths:
+  /pets:
+    get:
+      parameters:
+      - name: limit
+        in: query
+        schema: {type: integer, maximum: 100}
+      responses:
+        '200':
+          description: OK
+          headers:
+            X-Rate-Limit:
+              required: true
+              schema: {type: integer, minimum: 10}
+          content:
+            application/json:
+              schema:
+                type: array
+                minItems: 2
+                uniqueItems: true
+                items: {$ref: '#/components/schemas/Pet'}
+  /pets/{id}:
+    get:
+      parameters:
+      - name: id
+        in: path
+        required: true
+        schema: {type: integer}
+      responses:
+        '200':
+          description: OK
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Pet'}
+              examples:
+                cat:
+                  value: {id: 1, name: Tom, tag: cat}
+                dog:
+                  value: {id: 2, name: Rex, tag: dog}
+            text/plain:
+              schema: {type: string}
+              example: Tom
+        '404':
+          description: Not found
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [message]
+                properties:
+                  message: {type: string}
+              examples:
+                notFound:
+                  value: {message: no such pet}
+                wrong:
+                  value: {message: 42}
+components:
+  schemas:
+    Pet:
+      type: object
+      required: [id, name]
+      properties:
+        id: {type: integer, format: int64, minimum: 1}
+        name: {type: string, minLength: 3}
+        tag: {type: string, enum: [cat, dog]}
+        born: {type: string, format: date}
+`
+
+func newServer(t *testing.T) *openapi3mock.Server {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData([]byte(spec))
+	require.NoError(t, err)
+	// The document has an invalid example on purpose
+	require.NoError(t, doc.Validate(loader.Context, openapi3.DisableExamplesValidation()))
+	router, err := gorillamux.NewRouter(doc)
+	require.NoError(t, err)
+	return openapi3mock.NewServer(router)
+}
+
+func TestServer(t *testing.T) {
+	server := newServer(t)
+
+	for _, tc := range []struct {
+		name        string
+		target      string
+		header      http.Header
+		status      int
+		contentType string
+		body        string
+		rateLimit   string
+	}{
+		{
+			name:        "synthesized from schema",
+			target:      "/pets",
+			status:      http.StatusOK,
+			contentType: "application/json",
+			body:        `[{"born":"2006-01-02","id":1,"name":"string","tag":"cat"},{"born":"2006-01-02","id":2,"name":"stringx","tag":"dog"}]`,
+			rateLimit:   "10",
+		},
+		{
+			name:        "first example",
+			target:      "/pets/1",
+			status:      http.StatusOK,
+			contentType: "application/json",
+			body:        `{"id":1,"name":"Tom","tag":"cat"}`,
+		},
+		{
+			name:        "named example in header",
+			target:      "/pets/1",
+			header:      http.Header{"Prefer": {"example=dog"}},
+			status:      http.StatusOK,
+			contentType: "application/json",
+			body:        `{"id":2,"name":"Rex","tag":"dog"}`,
+		},
+		{
+			name:        "code and example in header",
+			target:      "/pets/1",
+			header:      http.Header{"Prefer": {`code=404, example="notFound"`}},
+			status:      http.StatusNotFound,
+			contentType: "application/json",
+			body:        `{"message":"no such pet"}`,
+		},
+		{
+			name:        "example selects its response",
+			target:      "/pets/1?__example=notFound",
+			status:      http.StatusNotFound,
+			contentType: "application/json",
+			body:        `{"message":"no such pet"}`,
+		},
+		{
+			name:        "code in query",
+			target:      "/pets/1?__code=404",
+			status:      http.StatusNotFound,
+			contentType: "application/json",
+			body:        `{"message":"no such pet"}`,
+		},
+		{
+			name:        "accept",
+			target:      "/pets/1",
+			header:      http.Header{"Accept": {"application/xml, text/*;q=0.5"}},
+			status:      http.StatusOK,
+			contentType: "text/plain",
+			body:        `Tom`,
+		},
+		{
+			name:        "unknown route",
+			target:      "/owners",
+			status:      http.StatusNotFound,
+			contentType: "text/plain; charset=utf-8",
+			body:        "[404][][] no matching operation was found ",
+		},
+		{
+			name:        "invalid request",
+			target:      "/pets?limit=1000",
+			status:      http.StatusBadRequest,
+			contentType: "text/plain; charset=utf-8",
+			body:        "[400][][] number must be at most 100 [source parameter=limit]",
+		},
+		{
+			name:        "undefined code",
+			target:      "/pets/1",
+			header:      http.Header{"Prefer": {"code=500"}},
+			status:      http.StatusBadRequest,
+			contentType: "text/plain; charset=utf-8",
+			body:        "[400][][] operation has no response 500 ",
+		},
+		{
+			name:        "undefined example",
+			target:      "/pets/1",
+			header:      http.Header{"Prefer": {"example=bird"}},
+			status:      http.StatusBadRequest,
+			contentType: "text/plain; charset=utf-8",
+			body:        `[400][][] response 200 has no example "bird" `,
+		},
+		{
+			name:        "not acceptable",
+			target:      "/pets/1",
+			header:      http.Header{"Accept": {"application/xml"}},
+			status:      http.StatusNotAcceptable,
+			contentType: "text/plain; charset=utf-8",
+			body:        "[406][][] no response media type is acceptable: application/json, text/plain ",
+		},
+		{
+			name:        "invalid example",
+			target:      "/pets/1",
+			header:      http.Header{"Prefer": {"code=404, example=wrong"}},
+			status:      http.StatusInternalServerError,
+			contentType: "text/plain; charset=utf-8",
+			body: `[500][][] mock response is invalid | response body doesn't match schema: Error at "/message": value must be a string
+Schema:
+  {
+    "type": "string"
+  }
+
+Value:
+  42
+ `,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.target, nil)
+			for k, v := range tc.header {
+				req.Header[k] = v
+			}
+			rec := httptest.NewRecorder()
+			server.ServeHTTP(rec, req)
+
+			resp := rec.Result()
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.Equal(t, tc.status, resp.StatusCode, string(body))
+			require.Equal(t, tc.contentType, resp.Header.Get("Content-Type"))
+			require.Equal(t, tc.body, string(body))
+			require.Equal(t, tc.rateLimit, resp.Header.Get("X-Rate-Limit"))
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package openapi3mock
+
+import (
+	"maps"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// maxSynthesizeDepth stops synthesizing recursive schemas.
+const maxSynthesizeDepth = 8
+
+// synthesize returns a value of schema: its example, default, const or first enumerated value,
+// or a value made of its required properties and bounds otherwise.
+func synthesize(schema *openapi3.SchemaRef) any {
+	return synthesizeValue(schema, 0, 0)
+}
+
+// synthesizeValue returns the variant-th value of schema: values of unique array items differ by their variant.
+func synthesizeValue(schemaRef *openapi3.SchemaRef, variant, depth int) any {
+	if schemaRef == nil || schemaRef.Value == nil || depth > maxSynthesizeDepth {
+		return nil
+	}
+	schema := schemaRef.Value
+
+	switch {
+	case schema.Const != nil:
+		return schema.Const
+	case len(schema.Enum) != 0:
+		return schema.Enum[variant%len(schema.Enum)]
+	case variant == 0 && schema.Example != nil:
+		return schema.Example
+	case variant == 0 && len(schema.Examples) != 0:
+		return schema.Examples[0]
+	case variant == 0 && schema.Default != nil:
+		return schema.Default
+	}
+
+	if len(schema.AllOf) != 0 {
+		merged := make(map[string]any)
+		for _, sub := range schema.AllOf {
+			value := synthesizeValue(sub, variant, depth+1)
+			obj, ok := value.(map[string]any)
+			if !ok {
+				return value
+			}
+			maps.Copy(merged, obj)
+		}
+		if obj, ok := synthesizeType(schema, variant, depth).(map[string]any); ok {
+			maps.Copy(merged, obj)
+		}
+		return merged
+	}
+	if len(schema.OneOf) != 0 {
+		return synthesizeValue(schema.OneOf[0], variant, depth+1)
+	}
+	if len(schema.AnyOf) != 0 {
+		return synthesizeValue(schema.AnyOf[0], variant, depth+1)
+	}
+	return synthesizeType(schema, variant, depth)
+}
+
+func synthesizeType(schema *openapi3.Schema, variant, depth int) any {
+	// Schemas without a type synthesize objects
+	typ := openapi3.TypeObject
+	if schema.Type != nil {
+		typ = openapi3.TypeNull
+		for _, t := range schema.Type.Slice() {
+			if t != openapi3.TypeNull {
+				typ = t
+				break
+			}
+		}
+	}
+
+	switch typ {
+	case openapi3.TypeObject:
+		obj := make(map[string]any)
+		for _, name := range slices.Sorted(maps.Keys(schema.Properties)) {
+			prop := schema.Properties[name]
+			if prop.Value != nil && prop.Value.WriteOnly {
+				continue
+			}
+			if value := synthesizeValue(prop, variant, depth+1); value != nil || slices.Contains(schema.Required, name) {
+				obj[name] = value
+			}
+		}
+		return obj
+	case openapi3.TypeArray:
+		n := max(schema.MinItems, 1)
+		if schema.MaxItems != nil {
+			n = min(n, *schema.MaxItems)
+		}
+		items := make([]any, 0, n)
+		for i := range int(n) {
+			v := variant
+			if schema.UniqueItems {
+				v += i
+			}
+			items = append(items, synthesizeValue(schema.Items, v, depth+1))
+		}
+		return items
+	case openapi3.TypeString:
+		return synthesizeString(schema, variant)
+	case openapi3.TypeInteger:
+		return math.Round(synthesizeNumber(schema, variant, 1))
+	case openapi3.TypeNumber:
+		return synthesizeNumber(schema, variant, 0.5)
+	case openapi3.TypeBoolean:
+		return variant%2 == 0
+	default:
+		return nil
+	}
+}
+
+// synthesizeNumber returns the smallest number within the bounds of schema, plus variant steps.
+func synthesizeNumber(schema *openapi3.Schema, variant int, step float64) float64 {
+	if schema.MultipleOf != nil && *schema.MultipleOf > 0 {
+		step = *schema.MultipleOf
+	}
+	n := 0.0
+	switch {
+	case schema.ExclusiveMin.Value != nil:
+		n = (math.Floor(*schema.ExclusiveMin.Value/step) + 1) * step
+	case schema.Min != nil:
+		n = math.Ceil(*schema.Min/step) * step
+		if schema.ExclusiveMin.IsTrue() && n == *schema.Min {
+			n += step
+		}
+	case schema.Max != nil && *schema.Max < 0:
+		n = math.Floor(*schema.Max/step) * step
+		if schema.ExclusiveMax.IsTrue() && n == *schema.Max {
+			n -= step
+		}
+	case schema.ExclusiveMax.Value != nil && *schema.ExclusiveMax.Value <= 0:
+		n = (math.Ceil(*schema.ExclusiveMax.Value/step) - 1) * step
+	}
+	return n + float64(variant)*step
+}
+
+var formatExamples = map[string]string{
+	"date":      "2006-01-02",
+	"date-time": "2006-01-02T15:04:05Z",
+	"time":      "15:04:05Z",
+	"email":     "user@example.com",
+	"hostname":  "example.com",
+	"ipv4":      "192.0.2.1",
+	"ipv6":      "2001:db8::1",
+	"uri":       "https://example.com",
+	"url":       "https://example.com",
+	"uuid":      "01234567-89ab-cdef-0123-456789abcdef",
+	"byte":      "c3RyaW5n",
+}
+
+// synthesizeString returns an example of the format of schema, or a string within its length bounds.
+func synthesizeString(schema *openapi3.Schema, variant int) string {
+	s, ok := formatExamples[schema.Format]
+	if !ok {
+		s = "string" + strings.Repeat("x", variant)
+	}
+	if n := int(schema.MinLength); len(s) < n {
+		s += strings.Repeat("x", n-len(s))
+	}
+	if schema.MaxLength != nil && uint64(len(s)) > *schema.MaxLength {
+		s = s[:*schema.MaxLength]
+	}
+	return s
+}
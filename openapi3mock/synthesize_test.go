@@ -0,0 +1,42 @@
+package openapi3mock
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestSynthesize(t *testing.T) {
+	for _, tc := range []struct {
+		schema string
+		want   any
+	}{
+		{`{"type": "integer", "minimum": 3, "exclusiveMinimum": true}`, 4.0},
+		{`{"type": "integer", "maximum": -3}`, -3.0},
+		{`{"type": "number", "minimum": 1.1, "multipleOf": 0.5}`, 1.5},
+		{`{"type": "string", "minLength": 8, "maxLength": 10}`, "stringxx"},
+		{`{"type": "string", "format": "uuid"}`, "01234567-89ab-cdef-0123-456789abcdef"},
+		{`{"type": "string", "default": "abc"}`, "abc"},
+		{`{"type": "boolean"}`, true},
+		{`{"type": ["null", "string"], "enum": ["a", "b", null]}`, "a"},
+		{`{"type": "array", "minItems": 3, "uniqueItems": true, "items": {"type": "integer"}}`, []any{0.0, 1.0, 2.0}},
+		{`{"type": "array", "maxItems": 0, "items": {"type": "integer"}}`, []any{}},
+		{
+			`{"allOf": [{"type": "object", "required": ["a"], "properties": {"a": {"type": "string"}}}, {"properties": {"b": {"type": "integer", "writeOnly": true}}}]}`,
+			map[string]any{"a": "string"},
+		},
+		{`{"oneOf": [{"type": "integer"}, {"type": "string"}]}`, 0.0},
+		{`{}`, map[string]any{}},
+	} {
+		t.Run(tc.schema, func(t *testing.T) {
+			var schema openapi3.Schema
+			require.NoError(t, json.Unmarshal([]byte(tc.schema), &schema))
+			got := synthesize(openapi3.NewSchemaRef("", &schema))
+			require.Equal(t, tc.want, got)
+			require.NoError(t, schema.VisitJSON(got))
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package openapi3sample
+
+import (
+	"slices"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// flatten returns a schema with the constraints of schema and of its allOf subschemas.
+// Constraints that cannot be merged (e.g. two patterns) are taken from the first schema:
+// values violating the others fail validation and are regenerated.
+func flatten(schema *openapi3.Schema) *openapi3.Schema {
+	return flattenSeen(schema, make(map[*openapi3.Schema]struct{}))
+}
+
+func flattenSeen(schema *openapi3.Schema, seen map[*openapi3.Schema]struct{}) *openapi3.Schema {
+	merged := *schema
+	merged.AllOf = nil
+	if _, ok := seen[schema]; ok {
+		return &merged
+	}
+	seen[schema] = struct{}{}
+	defer delete(seen, schema)
+
+	for _, sub := range schema.AllOf {
+		if sub == nil || sub.Value == nil {
+			continue
+		}
+		merge(&merged, flattenSeen(sub.Value, seen))
+	}
+	return &merged
+}
+
+// merge adds the constraints of s to dst.
+func merge(dst, s *openapi3.Schema) {
+	switch {
+	case s.Type == nil || len(s.Type.Slice()) == 0:
+	case dst.Type == nil || len(dst.Type.Slice()) == 0:
+		dst.Type = s.Type
+	default:
+		var types openapi3.Types
+		for _, t := range dst.Type.Slice() {
+			switch {
+			case s.Type.Includes(t):
+			case t == openapi3.TypeInteger && s.Type.Includes(openapi3.TypeNumber):
+			case t == openapi3.TypeNumber && s.Type.Includes(openapi3.TypeInteger):
+				t = openapi3.TypeInteger
+			default:
+				continue
+			}
+			types = append(types, t)
+		}
+		dst.Type = &types
+	}
+	if dst.Format == "" {
+		dst.Format = s.Format
+	}
+	if dst.Const == nil {
+		dst.Const = s.Const
+	}
+	if len(dst.Enum) == 0 {
+		dst.Enum = s.Enum
+	} else if len(s.Enum) != 0 {
+		dst.Enum = slices.DeleteFunc(slices.Clone(dst.Enum), func(v any) bool {
+			return s.VisitJSON(v) != nil
+		})
+		if len(dst.Enum) == 0 {
+			dst.Enum = s.Enum
+		}
+	}
+	dst.Nullable = dst.Nullable && s.Nullable
+	dst.ReadOnly = dst.ReadOnly || s.ReadOnly
+	dst.WriteOnly = dst.WriteOnly || s.WriteOnly
+	if len(dst.OneOf) == 0 {
+		dst.OneOf = s.OneOf
+	}
+	if len(dst.AnyOf) == 0 {
+		dst.AnyOf = s.AnyOf
+	}
+	if dst.Discriminator == nil {
+		dst.Discriminator = s.Discriminator
+	}
+
+	// Numbers
+	if s.Min != nil && (dst.Min == nil || *s.Min > *dst.Min) {
+		dst.Min = s.Min
+		dst.ExclusiveMin.Bool = s.ExclusiveMin.Bool
+	}
+	if v := s.ExclusiveMin.Value; v != nil && (dst.ExclusiveMin.Value == nil || *v > *dst.ExclusiveMin.Value) {
+		dst.ExclusiveMin.Value = v
+	}
+	if s.Max != nil && (dst.Max == nil || *s.Max < *dst.Max) {
+		dst.Max = s.Max
+		dst.ExclusiveMax.Bool = s.ExclusiveMax.Bool
+	}
+	if v := s.ExclusiveMax.Value; v != nil && (dst.ExclusiveMax.Value == nil || *v < *dst.ExclusiveMax.Value) {
+		dst.ExclusiveMax.Value = v
+	}
+	if dst.MultipleOf == nil {
+		dst.MultipleOf = s.MultipleOf
+	}
+
+	// Strings
+	dst.MinLength = max(dst.MinLength, s.MinLength)
+	dst.MaxLength = minPtr(dst.MaxLength, s.MaxLength)
+	if dst.Pattern == "" {
+		dst.Pattern = s.Pattern
+	}
+
+	// Arrays
+	dst.MinItems = max(dst.MinItems, s.MinItems)
+	dst.MaxItems = minPtr(dst.MaxItems, s.MaxItems)
+	dst.UniqueItems = dst.UniqueItems || s.UniqueItems
+	dst.Items = mergeRefs(dst.Items, s.Items)
+	if len(dst.PrefixItems) == 0 {
+		dst.PrefixItems = s.PrefixItems
+	}
+	if dst.Contains == nil {
+		dst.Contains, dst.MinContains = s.Contains, s.MinContains
+	}
+
+	// Objects
+	dst.MinProps = max(dst.MinProps, s.MinProps)
+	dst.MaxProps = minPtr(dst.MaxProps, s.MaxProps)
+	for _, name := range s.Required {
+		if !slices.Contains(dst.Required, name) {
+			dst.Required = append(slices.Clip(dst.Required), name)
+		}
+	}
+	if len(s.Properties) != 0 {
+		properties := make(openapi3.Schemas, len(dst.Properties)+len(s.Properties))
+		for name, prop := range dst.Properties {
+			properties[name] = prop
+		}
+		for name, prop := range s.Properties {
+			properties[name] = mergeRefs(properties[name], prop)
+		}
+		dst.Properties = properties
+	}
+	if has := s.AdditionalProperties.Has; has != nil && !*has {
+		dst.AdditionalProperties.Has = has
+	}
+	dst.AdditionalProperties.Schema = mergeRefs(dst.AdditionalProperties.Schema, s.AdditionalProperties.Schema)
+	if dst.PatternProperties == nil {
+		dst.PatternProperties = s.PatternProperties
+	}
+	if dst.PropertyNames == nil {
+		dst.PropertyNames = s.PropertyNames
+	}
+	if dst.DependentRequired == nil {
+		dst.DependentRequired = s.DependentRequired
+	}
+}
+
+// mergeRefs returns a schema satisfying both a and b.
+func mergeRefs(a, b *openapi3.SchemaRef) *openapi3.SchemaRef {
+	switch {
+	case a == nil:
+		return b
+	case b == nil || a == b:
+		return a
+	}
+	return openapi3.NewSchemaRef("", &openapi3.Schema{AllOf: openapi3.SchemaRefs{a, b}})
+}
+
+func minPtr(a, b *uint64) *uint64 {
+	if a == nil || b != nil && *b < *a {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,675 @@
+// Package openapi3sample generates sample values of OpenAPI schemas.
+//
+// Generated values satisfy their schema: every value is checked with Schema.VisitJSON
+// before it is returned. Output is deterministic for a given seed.
+package openapi3sample
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Mode selects the kind of values a Generator produces.
+type Mode int
+
+const (
+	// Random generates random values within the constraints of schemas. It is the default.
+	Random Mode = iota
+	// Minimal generates the smallest values: only required properties, the fewest array items,
+	// the shortest strings, the lowest numbers and the first enumerated values.
+	Minimal
+	// Maximal generates the largest values: all properties, the most array items,
+	// the longest strings, the highest numbers and the last enumerated values.
+	// Unbounded lengths are capped (see MaxItems and MaxLength).
+	Maximal
+)
+
+// Direction selects the properties generated for objects with readOnly or writeOnly properties.
+type Direction int
+
+const (
+	// AnyDirection generates readOnly and writeOnly properties. It is the default.
+	AnyDirection Direction = iota
+	// RequestDirection skips readOnly properties.
+	RequestDirection
+	// ResponseDirection skips writeOnly properties.
+	ResponseDirection
+)
+
+// GenerationError is returned when no value satisfying a schema could be generated.
+type GenerationError struct {
+	Schema *openapi3.Schema
+	// Err is the validation error of the last attempt.
+	Err error
+}
+
+func (err *GenerationError) Error() string {
+	return "cannot generate a value of the schema: " + err.Err.Error()
+}
+
+func (err *GenerationError) Unwrap() error { return err.Err }
+
+// Option allows tweaking Generator behavior
+type Option func(*generatorOpt)
+
+type generatorOpt struct {
+	seed           uint64
+	mode           Mode
+	direction      Direction
+	preferExamples bool
+	maxDepth       int
+	maxItems       int
+	maxLength      int
+	attempts       int
+	visitOptions   []openapi3.SchemaValidationOption
+}
+
+// Seed sets the seed of the pseudo-random values. It defaults to 0.
+func Seed(seed uint64) Option {
+	return func(x *generatorOpt) { x.seed = seed }
+}
+
+// UseMode sets the kind of values generated. It defaults to Random.
+func UseMode(mode Mode) Option {
+	return func(x *generatorOpt) { x.mode = mode }
+}
+
+// UseDirection selects whether readOnly or writeOnly properties are generated.
+// Values are validated as requests or responses accordingly.
+func UseDirection(direction Direction) Option {
+	return func(x *generatorOpt) { x.direction = direction }
+}
+
+// PreferExamples makes the generator use the example, examples or default of schemas, when they are valid.
+func PreferExamples() Option {
+	return func(x *generatorOpt) { x.preferExamples = true }
+}
+
+// MaxDepth sets the depth from which only required properties and the fewest items are generated,
+// which stops recursive schemas. It defaults to 5.
+func MaxDepth(depth int) Option {
+	return func(x *generatorOpt) { x.maxDepth = depth }
+}
+
+// MaxItems caps the number of items of arrays and additional properties of objects
+// without a maximum. It defaults to 3.
+func MaxItems(n int) Option {
+	return func(x *generatorOpt) { x.maxItems = n }
+}
+
+// MaxLength caps the length of strings without a maxLength. It defaults to 16.
+func MaxLength(n int) Option {
+	return func(x *generatorOpt) { x.maxLength = n }
+}
+
+// SchemaValidationOptions sets options used to validate generated values,
+// e.g. openapi3.EnableJSONSchema2020() for OpenAPI 3.1 documents.
+func SchemaValidationOptions(opts ...openapi3.SchemaValidationOption) Option {
+	return func(x *generatorOpt) { x.visitOptions = opts }
+}
+
+// Generator generates sample values of schemas.
+// A Generator is not safe for concurrent use.
+type Generator struct {
+	opts generatorOpt
+	rnd  *rand.Rand
+	// attempt counts failed attempts at generating the current value
+	attempt int
+	// random forces the Random mode while it is positive, e.g. to vary unique array items
+	random int
+}
+
+// NewGenerator returns a Generator.
+func NewGenerator(opts ...Option) *Generator {
+	x := &generatorOpt{
+		maxDepth:  5,
+		maxItems:  3,
+		maxLength: 16,
+		attempts:  32,
+	}
+	for _, f := range opts {
+		f(x)
+	}
+	return &Generator{
+		opts: *x,
+		rnd:  rand.New(rand.NewPCG(x.seed, 0x6b696e2d6f70656e)),
+	}
+}
+
+// Generate returns a sample value of schema.
+func Generate(schema *openapi3.SchemaRef, opts ...Option) (any, error) {
+	return NewGenerator(opts...).Generate(schema)
+}
+
+// Generate returns a sample value of schema: nil, bool, int64, float64, string, []any or map[string]any.
+// Values that fail validation are regenerated: a *GenerationError is returned after several failed attempts.
+func (g *Generator) Generate(schemaRef *openapi3.SchemaRef) (any, error) {
+	if schemaRef == nil || schemaRef.Value == nil {
+		return nil, errors.New("schema is not resolved")
+	}
+	schema := schemaRef.Value
+
+	visitOptions := slices.Clone(g.opts.visitOptions)
+	switch g.opts.direction {
+	case RequestDirection:
+		visitOptions = append(visitOptions, openapi3.VisitAsRequest())
+	case ResponseDirection:
+		visitOptions = append(visitOptions, openapi3.VisitAsResponse())
+	}
+
+	var err error
+	for g.attempt = 0; g.attempt < g.opts.attempts; g.attempt++ {
+		value := g.generate(schema, 0)
+		if err = schema.VisitJSON(value, visitOptions...); err == nil {
+			return value, nil
+		}
+	}
+	return nil, &GenerationError{Schema: schema, Err: err}
+}
+
+// mode returns the mode of the current attempt: after a failed attempt, values are random.
+func (g *Generator) mode() Mode {
+	if g.attempt > 0 || g.random > 0 {
+		return Random
+	}
+	return g.opts.mode
+}
+
+func (g *Generator) generate(schema *openapi3.Schema, depth int) any {
+	if schema == nil {
+		return map[string]any{}
+	}
+	if depth > 4*g.opts.maxDepth {
+		// Required properties of recursive schemas: the value fails validation
+		return nil
+	}
+
+	switch {
+	case schema.Const != nil:
+		return schema.Const
+	case len(schema.Enum) != 0:
+		return g.pick(schema.Enum)
+	case g.opts.preferExamples && g.attempt == 0:
+		for _, example := range append([]any{schema.Example, schema.Default}, schema.Examples...) {
+			if example != nil && schema.VisitJSON(example, g.opts.visitOptions...) == nil {
+				return example
+			}
+		}
+	}
+
+	if len(schema.AllOf) != 0 {
+		return g.generate(flatten(schema), depth)
+	}
+	if branches := append(slices.Clone(schema.OneOf), schema.AnyOf...); len(branches) != 0 {
+		i := g.attempt % len(branches)
+		if g.mode() == Random && g.attempt == 0 {
+			i = g.rnd.IntN(len(branches))
+		}
+		branch := branches[i]
+		parent := *schema
+		parent.OneOf, parent.AnyOf = nil, nil
+		merged := flatten(&openapi3.Schema{AllOf: openapi3.SchemaRefs{openapi3.NewSchemaRef("", &parent), branch}})
+		// Branches referring back to their parent (e.g. with allOf) must not select a branch again
+		if slices.Equal(merged.OneOf, schema.OneOf) {
+			merged.OneOf = nil
+		}
+		if slices.Equal(merged.AnyOf, schema.AnyOf) {
+			merged.AnyOf = nil
+		}
+		value := g.generate(merged, depth+1)
+		if obj, ok := value.(map[string]any); ok && schema.Discriminator != nil {
+			if name := discriminatorValue(schema.Discriminator, branch); name != "" {
+				obj[schema.Discriminator.PropertyName] = name
+			}
+		}
+		return value
+	}
+
+	switch typ := g.pickType(schema); typ {
+	case openapi3.TypeNull:
+		return nil
+	case openapi3.TypeBoolean:
+		switch g.mode() {
+		case Minimal:
+			return false
+		case Maximal:
+			return true
+		default:
+			return g.rnd.IntN(2) == 0
+		}
+	case openapi3.TypeInteger:
+		return g.integer(schema)
+	case openapi3.TypeNumber:
+		return g.number(schema)
+	case openapi3.TypeString:
+		return g.string(schema)
+	case openapi3.TypeArray:
+		return g.array(schema, depth)
+	default:
+		return g.object(schema, depth)
+	}
+}
+
+// pick returns the first, last or a random value, depending on the mode.
+func (g *Generator) pick(values []any) any {
+	switch g.mode() {
+	case Minimal:
+		return values[0]
+	case Maximal:
+		return values[len(values)-1]
+	default:
+		return values[g.rnd.IntN(len(values))]
+	}
+}
+
+// pickType returns the type of the generated value, inferred from the keywords of schema when it has no type.
+func (g *Generator) pickType(schema *openapi3.Schema) string {
+	var types []string
+	if schema.Type != nil {
+		types = schema.Type.Slice()
+	}
+	if len(types) == 0 {
+		switch {
+		case len(schema.Properties) != 0, len(schema.Required) != 0, schema.AdditionalProperties.Schema != nil:
+			return openapi3.TypeObject
+		case schema.Items != nil, len(schema.PrefixItems) != 0:
+			return openapi3.TypeArray
+		case schema.Min != nil, schema.Max != nil, schema.MultipleOf != nil:
+			return openapi3.TypeNumber
+		case schema.Pattern != "", schema.Format != "", schema.MinLength != 0, schema.MaxLength != nil:
+			return openapi3.TypeString
+		}
+		return openapi3.TypeObject
+	}
+
+	nonNull := slices.DeleteFunc(slices.Clone(types), func(t string) bool { return t == openapi3.TypeNull })
+	if len(nonNull) == 0 {
+		return openapi3.TypeNull
+	}
+	if g.mode() == Random {
+		if (schema.Nullable || len(nonNull) != len(types)) && g.rnd.IntN(8) == 0 {
+			return openapi3.TypeNull
+		}
+		return nonNull[g.rnd.IntN(len(nonNull))]
+	}
+	return nonNull[0]
+}
+
+func (g *Generator) object(schema *openapi3.Schema, depth int) map[string]any {
+	obj := make(map[string]any)
+	maxProps := math.MaxInt
+	if schema.MaxProps != nil {
+		maxProps = int(*schema.MaxProps)
+	}
+
+	skip := func(prop *openapi3.SchemaRef) bool {
+		if prop == nil || prop.Value == nil {
+			return false
+		}
+		return g.opts.direction == RequestDirection && prop.Value.ReadOnly ||
+			g.opts.direction == ResponseDirection && prop.Value.WriteOnly
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(schema.Properties)) {
+		prop := schema.Properties[name]
+		if skip(prop) {
+			continue
+		}
+		required := slices.Contains(schema.Required, name)
+		if !required {
+			if depth >= g.opts.maxDepth || len(obj) >= maxProps {
+				continue
+			}
+			switch g.mode() {
+			case Minimal:
+				continue
+			case Random:
+				if g.rnd.IntN(2) == 0 {
+					continue
+				}
+			}
+		}
+		obj[name] = g.generate(prop.Value, depth+1)
+	}
+	// Required properties without a schema
+	for _, name := range schema.Required {
+		if _, ok := obj[name]; !ok && schema.Properties[name] == nil {
+			obj[name] = g.generate(additionalPropertiesSchema(schema), depth+1)
+		}
+	}
+	for name, deps := range schema.DependentRequired {
+		if _, ok := obj[name]; ok {
+			for _, dep := range deps {
+				if _, ok := obj[dep]; !ok {
+					obj[dep] = g.generate(propertySchema(schema, dep), depth+1)
+				}
+			}
+		}
+	}
+
+	if !allowsAdditionalProperties(schema) {
+		return obj
+	}
+	extra := 0
+	if schema.AdditionalProperties.Schema != nil && depth < g.opts.maxDepth {
+		switch g.mode() {
+		case Maximal:
+			extra = g.opts.maxItems
+		case Random:
+			extra = g.rnd.IntN(g.opts.maxItems + 1)
+		}
+	}
+	extra = max(extra, int(schema.MinProps)-len(obj))
+	extra = min(extra, maxProps-len(obj))
+	for i := 0; extra > 0 && i < extra+g.opts.maxItems; i++ {
+		name := g.propertyName(schema, i)
+		if _, ok := obj[name]; ok {
+			continue
+		}
+		obj[name] = g.generate(additionalPropertiesSchema(schema), depth+1)
+		extra--
+	}
+	return obj
+}
+
+// propertyName returns the name of the i-th additional property.
+func (g *Generator) propertyName(schema *openapi3.Schema, i int) string {
+	if schema.PropertyNames != nil && schema.PropertyNames.Value != nil {
+		if name, ok := g.generate(schema.PropertyNames.Value, g.opts.maxDepth).(string); ok {
+			return name
+		}
+	}
+	return fmt.Sprintf("property%d", i+1)
+}
+
+func allowsAdditionalProperties(schema *openapi3.Schema) bool {
+	return schema.AdditionalProperties.Has == nil || *schema.AdditionalProperties.Has || schema.AdditionalProperties.Schema != nil
+}
+
+func additionalPropertiesSchema(schema *openapi3.Schema) *openapi3.Schema {
+	if s := schema.AdditionalProperties.Schema; s != nil {
+		return s.Value
+	}
+	return &openapi3.Schema{Type: &openapi3.Types{openapi3.TypeString}}
+}
+
+func propertySchema(schema *openapi3.Schema, name string) *openapi3.Schema {
+	if prop := schema.Properties[name]; prop != nil {
+		return prop.Value
+	}
+	return additionalPropertiesSchema(schema)
+}
+
+func (g *Generator) array(schema *openapi3.Schema, depth int) []any {
+	lo := int(schema.MinItems)
+	hi := max(lo, g.opts.maxItems)
+	if schema.MaxItems != nil {
+		hi = max(lo, int(*schema.MaxItems))
+	}
+	n := lo
+	if depth < g.opts.maxDepth {
+		switch g.mode() {
+		case Maximal:
+			n = hi
+		case Random:
+			n = lo + g.rnd.IntN(min(hi, lo+g.opts.maxItems)-lo+1)
+		}
+	}
+	if n == 0 {
+		return []any{}
+	}
+
+	var items *openapi3.Schema
+	if schema.Items != nil {
+		items = schema.Items.Value
+	}
+	minContains := 0
+	if schema.Contains != nil {
+		minContains = 1
+		if schema.MinContains != nil {
+			minContains = int(*schema.MinContains)
+		}
+	}
+
+	values := make([]any, 0, n)
+	seen := make(map[string]struct{}, n)
+	retries := 0
+	for len(values) < n && retries < 8*n {
+		itemSchema := items
+		switch {
+		case len(values) < len(schema.PrefixItems):
+			itemSchema = schema.PrefixItems[len(values)].Value
+		case len(values) < len(schema.PrefixItems)+minContains:
+			itemSchema = schema.Contains.Value
+		}
+		value := g.generate(itemSchema, depth+1)
+		if schema.UniqueItems {
+			key, _ := json.Marshal(value)
+			if _, ok := seen[string(key)]; ok {
+				// Vary the next values
+				g.random++
+				retries++
+				continue
+			}
+			seen[string(key)] = struct{}{}
+		}
+		values = append(values, value)
+	}
+	g.random -= retries
+	return values
+}
+
+func (g *Generator) string(schema *openapi3.Schema) string {
+	lo := int(schema.MinLength)
+	hi := max(lo, g.opts.maxLength)
+	if schema.MaxLength != nil {
+		// Unsatisfiable bounds fail validation
+		hi = max(lo, int(*schema.MaxLength))
+	}
+
+	if s, ok := g.format(schema.Format); ok {
+		return s
+	}
+	if schema.Pattern != "" {
+		for range 16 {
+			s, err := g.matchPattern(schema.Pattern, hi)
+			if err != nil {
+				break
+			}
+			if n := len([]rune(s)); n >= lo && (schema.MaxLength == nil || n <= hi) {
+				return s
+			}
+		}
+	}
+
+	n := lo
+	switch g.mode() {
+	case Maximal:
+		n = hi
+	case Random:
+		n = lo + g.rnd.IntN(min(hi, lo+g.opts.maxLength)-lo+1)
+	}
+	const letters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	var sb strings.Builder
+	for range n {
+		c := byte('a')
+		if g.mode() == Random {
+			c = letters[g.rnd.IntN(len(letters))]
+		} else if g.mode() == Maximal {
+			c = 'z'
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String()
+}
+
+// bounds returns the inclusive bounds of numbers of schema, if any.
+func bounds(schema *openapi3.Schema) (lo, hi float64, hasLo, hasHi bool, exclusiveLo, exclusiveHi bool) {
+	if schema.Min != nil {
+		lo, hasLo, exclusiveLo = *schema.Min, true, schema.ExclusiveMin.IsTrue()
+	}
+	if v := schema.ExclusiveMin.Value; v != nil && (!hasLo || *v >= lo) {
+		lo, hasLo, exclusiveLo = *v, true, true
+	}
+	if schema.Max != nil {
+		hi, hasHi, exclusiveHi = *schema.Max, true, schema.ExclusiveMax.IsTrue()
+	}
+	if v := schema.ExclusiveMax.Value; v != nil && (!hasHi || *v <= hi) {
+		hi, hasHi, exclusiveHi = *v, true, true
+	}
+	return
+}
+
+func (g *Generator) integer(schema *openapi3.Schema) int64 {
+	lo, hi := int64(math.MinInt64), int64(math.MaxInt64)
+	if schema.Format == "int32" {
+		lo, hi = math.MinInt32, math.MaxInt32
+	}
+	// Keep unbounded integers exact as JSON numbers
+	lo, hi = max(lo, -(1<<53-1)), min(hi, 1<<53-1)
+
+	flo, fhi, hasLo, hasHi, exclusiveLo, exclusiveHi := bounds(schema)
+	if hasLo {
+		l := math.Ceil(flo)
+		if exclusiveLo && l == flo {
+			l++
+		}
+		lo = max(lo, int64(l))
+	}
+	if hasHi {
+		h := math.Floor(fhi)
+		if exclusiveHi && h == fhi {
+			h--
+		}
+		hi = min(hi, int64(h))
+	}
+
+	step := int64(1)
+	if schema.MultipleOf != nil && *schema.MultipleOf >= 1 && *schema.MultipleOf == math.Trunc(*schema.MultipleOf) {
+		step = int64(*schema.MultipleOf)
+	}
+	first := ceilDiv(lo, step) * step
+	last := floorDiv(hi, step) * step
+	if first > last {
+		return first
+	}
+
+	switch g.mode() {
+	case Minimal:
+		if !hasLo && first <= 0 && last >= 0 {
+			return 0
+		}
+		return first
+	case Maximal:
+		return last
+	}
+	// Random: small values around zero or the bounds
+	a, b := first, last
+	if !hasLo {
+		a = max(first, min(last, 0)-1000*step)
+	}
+	if !hasHi {
+		b = min(last, max(a, 0)+1000*step)
+	}
+	return a + g.rnd.Int64N((b-a)/step+1)*step
+}
+
+func ceilDiv(a, b int64) int64 {
+	q := a / b
+	if a%b != 0 && a > 0 {
+		q++
+	}
+	return q
+}
+
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if a%b != 0 && a < 0 {
+		q--
+	}
+	return q
+}
+
+func (g *Generator) number(schema *openapi3.Schema) float64 {
+	lo, hi, hasLo, hasHi, exclusiveLo, exclusiveHi := bounds(schema)
+	if exclusiveLo {
+		lo = math.Nextafter(lo, math.Inf(1))
+	}
+	if exclusiveHi {
+		hi = math.Nextafter(hi, math.Inf(-1))
+	}
+	if !hasLo {
+		lo = -math.MaxFloat64
+	}
+	if !hasHi {
+		hi = math.MaxFloat64
+	}
+
+	if m := schema.MultipleOf; m != nil && *m > 0 {
+		first, last := math.Ceil(lo / *m), math.Floor(hi / *m)
+		k := first
+		switch g.mode() {
+		case Minimal:
+			if !hasLo {
+				k = math.Min(math.Max(0, first), last)
+			}
+		case Maximal:
+			k = last
+			if !hasHi {
+				k = math.Max(first, 1e6)
+			}
+		default:
+			a, b := first, last
+			if !hasLo {
+				a = math.Max(first, math.Min(last, 0)-1000)
+			}
+			if !hasHi {
+				b = math.Min(last, math.Max(a, 0)+1000)
+			}
+			k = a + math.Floor(g.rnd.Float64()*(b-a+1))
+		}
+		return k * *m
+	}
+
+	switch g.mode() {
+	case Minimal:
+		if !hasLo {
+			return math.Min(0, hi)
+		}
+		return lo
+	case Maximal:
+		if !hasHi {
+			return math.Max(1e6, lo)
+		}
+		return hi
+	}
+	a, b := lo, hi
+	if !hasLo {
+		a = math.Min(hi, 0) - 1000
+	}
+	if !hasHi {
+		b = math.Max(a, 0) + 1000
+	}
+	return a + g.rnd.Float64()*(b-a)
+}
+
+// discriminatorValue returns the value of the discriminator property selecting branch.
+func discriminatorValue(discriminator *openapi3.Discriminator, branch *openapi3.SchemaRef) string {
+	if branch.Ref == "" {
+		return ""
+	}
+	for _, name := range slices.Sorted(maps.Keys(discriminator.Mapping)) {
+		if mapping := discriminator.Mapping[name]; mapping.Ref == branch.Ref || strings.HasSuffix(branch.Ref, "/"+mapping.Ref) {
+			return name
+		}
+	}
+	return branch.Ref[strings.LastIndexByte(branch.Ref, '/')+1:]
+}
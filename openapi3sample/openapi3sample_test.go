@@ -0,0 +1,264 @@
+package openapi3sample_test
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3sample"
+)
+
+const spec = `
+openapi: 3.0.3
+info:
+  title: Samples
+  version: 1.0.0
+paths: {}
+components:
+  schemas:
+    Pet:
+      type: object
+      required: [id, name, kind]
+      properties:
+        id:
+          type: integer
+          format: int64
+          minimum: 1
+          readOnly: true
+        name:
+          type: string
+          minLength: 2
+          maxLength: 8
+        kind:
+          type: string
+          enum: [cat, dog]
+        tags:
+          type: array
+          maxItems: 4
+          uniqueItems: true
+          items:
+            type: string
+            pattern: '^[a-z]{3}-[0-9]{2}$'
+        password:
+          type: string
+          writeOnly: true
+        born:
+          type: string
+          format: date
+        weight:
+          type: number
+          exclusiveMinimum: true
+          minimum: 0
+          maximum: 100
+          multipleOf: 0.5
+    Cat:
+      allOf:
+        - $ref: '#/components/schemas/Pet'
+        - type: object
+          required: [lives]
+          properties:
+            lives:
+              type: integer
+              minimum: 1
+              maximum: 9
+    Dog:
+      allOf:
+        - $ref: '#/components/schemas/Pet'
+        - type: object
+          required: [good]
+          properties:
+            good:
+              type: boolean
+    AnyPet:
+      oneOf:
+        - $ref: '#/components/schemas/Cat'
+        - $ref: '#/components/schemas/Dog'
+      discriminator:
+        propertyName: kind
+        mapping:
+          cat: '#/components/schemas/Cat'
+          dog: '#/components/schemas/Dog'
+    Node:
+      type: object
+      required: [value]
+      properties:
+        value:
+          type: string
+        children:
+          type: array
+          items:
+            $ref: '#/components/schemas/Node'
+`
+
+func loadSchemas(t *testing.T) openapi3.Schemas {
+	t.Helper()
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData([]byte(spec))
+	require.NoError(t, err)
+	require.NoError(t, doc.Validate(context.Background()))
+	return doc.Components.Schemas
+}
+
+func TestGenerateModes(t *testing.T) {
+	schemas := loadSchemas(t)
+
+	value, err := openapi3sample.Generate(schemas["Pet"], openapi3sample.UseMode(openapi3sample.Minimal))
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"id": int64(1), "name": "aa", "kind": "cat"}, value)
+
+	value, err = openapi3sample.Generate(schemas["Pet"], openapi3sample.UseMode(openapi3sample.Maximal))
+	require.NoError(t, err)
+	pet := value.(map[string]any)
+	require.Equal(t, "zzzzzzzz", pet["name"])
+	require.Equal(t, "dog", pet["kind"])
+	require.Equal(t, 100.0, pet["weight"])
+	require.Len(t, pet["tags"], 4)
+	require.Contains(t, pet, "password")
+}
+
+func TestGenerateDeterministic(t *testing.T) {
+	schemas := loadSchemas(t)
+	for seed := range uint64(10) {
+		a, err := openapi3sample.Generate(schemas["AnyPet"], openapi3sample.Seed(seed))
+		require.NoError(t, err)
+		b, err := openapi3sample.Generate(schemas["AnyPet"], openapi3sample.Seed(seed))
+		require.NoError(t, err)
+		require.Equal(t, a, b)
+	}
+
+	a, err := openapi3sample.Generate(schemas["Pet"], openapi3sample.Seed(1))
+	require.NoError(t, err)
+	b, err := openapi3sample.Generate(schemas["Pet"], openapi3sample.Seed(2))
+	require.NoError(t, err)
+	require.NotEqual(t, a, b)
+}
+
+func TestGenerateDirections(t *testing.T) {
+	schemas := loadSchemas(t)
+	for seed := range uint64(20) {
+		value, err := openapi3sample.Generate(schemas["Pet"],
+			openapi3sample.Seed(seed),
+			openapi3sample.UseMode(openapi3sample.Maximal),
+			openapi3sample.UseDirection(openapi3sample.RequestDirection))
+		require.NoError(t, err)
+		require.NotContains(t, value, "id")
+		require.Contains(t, value, "password")
+
+		value, err = openapi3sample.Generate(schemas["Pet"],
+			openapi3sample.Seed(seed),
+			openapi3sample.UseDirection(openapi3sample.ResponseDirection))
+		require.NoError(t, err)
+		require.Contains(t, value, "id")
+		require.NotContains(t, value, "password")
+	}
+}
+
+func TestGenerateDiscriminator(t *testing.T) {
+	schemas := loadSchemas(t)
+	kinds := make(map[string]bool)
+	for seed := range uint64(20) {
+		value, err := openapi3sample.Generate(schemas["AnyPet"], openapi3sample.Seed(seed))
+		require.NoError(t, err)
+		pet := value.(map[string]any)
+		switch kind := pet["kind"]; kind {
+		case "cat":
+			require.Contains(t, pet, "lives")
+		case "dog":
+			require.Contains(t, pet, "good")
+		default:
+			t.Fatalf("unexpected kind %v", kind)
+		}
+		kinds[pet["kind"].(string)] = true
+	}
+	require.Len(t, kinds, 2)
+}
+
+func TestGenerateRecursive(t *testing.T) {
+	schemas := loadSchemas(t)
+	_, err := openapi3sample.Generate(schemas["Node"], openapi3sample.UseMode(openapi3sample.Maximal), openapi3sample.MaxDepth(3))
+	require.NoError(t, err)
+}
+
+func TestGenerateStrings(t *testing.T) {
+	for _, format := range []string{"date", "date-time", "time", "email", "hostname", "ipv4", "ipv6", "uri", "uuid", "byte"} {
+		t.Run(format, func(t *testing.T) {
+			schema := openapi3.NewStringSchema().WithFormat(format)
+			for seed := range uint64(10) {
+				value, err := openapi3sample.Generate(schema.NewRef(), openapi3sample.Seed(seed), openapi3sample.SchemaValidationOptions(openapi3.EnableFormatValidation()))
+				require.NoError(t, err)
+				require.NotEmpty(t, value)
+			}
+		})
+	}
+
+	for _, pattern := range []string{
+		`^[a-z]{3}-[0-9]{2}$`,
+		`^(foo|bar)+\d?$`,
+		`^\w+@\w+\.(com|org)$`,
+		`[^a-z]x*`,
+		`^.{4}$`,
+	} {
+		t.Run(pattern, func(t *testing.T) {
+			re := regexp.MustCompile(pattern)
+			schema := openapi3.NewStringSchema().WithPattern(pattern)
+			for seed := range uint64(10) {
+				value, err := openapi3sample.Generate(schema.NewRef(), openapi3sample.Seed(seed))
+				require.NoError(t, err)
+				require.Regexp(t, re, value)
+			}
+		})
+	}
+}
+
+func TestGenerateNumbers(t *testing.T) {
+	for _, tt := range []struct {
+		schema   *openapi3.Schema
+		min, max any
+	}{
+		{schema: openapi3.NewIntegerSchema().WithMin(3).WithMax(10).WithExclusiveMin(true), min: int64(4), max: int64(10)},
+		{schema: openapi3.NewInt32Schema(), min: int64(0), max: int64(2147483647)},
+		{schema: &openapi3.Schema{Type: &openapi3.Types{openapi3.TypeInteger}, MultipleOf: openapi3.Float64Ptr(7), Min: openapi3.Float64Ptr(-20), Max: openapi3.Float64Ptr(20)}, min: int64(-14), max: int64(14)},
+		{schema: openapi3.NewFloat64Schema().WithMin(-1.5).WithMax(-0.5), min: -1.5, max: -0.5},
+	} {
+		t.Run(fmt.Sprint(tt.min, tt.max), func(t *testing.T) {
+			value, err := openapi3sample.Generate(tt.schema.NewRef(), openapi3sample.UseMode(openapi3sample.Minimal))
+			require.NoError(t, err)
+			require.Equal(t, tt.min, value)
+
+			value, err = openapi3sample.Generate(tt.schema.NewRef(), openapi3sample.UseMode(openapi3sample.Maximal))
+			require.NoError(t, err)
+			require.Equal(t, tt.max, value)
+
+			for seed := range uint64(20) {
+				_, err = openapi3sample.Generate(tt.schema.NewRef(), openapi3sample.Seed(seed))
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestGeneratePreferExamples(t *testing.T) {
+	schema := openapi3.NewStringSchema().WithMaxLength(3)
+	schema.Example = "abc"
+	value, err := openapi3sample.Generate(schema.NewRef(), openapi3sample.PreferExamples())
+	require.NoError(t, err)
+	require.Equal(t, "abc", value)
+
+	// Invalid examples are not used
+	schema.Example = "abcd"
+	value, err = openapi3sample.Generate(schema.NewRef(), openapi3sample.PreferExamples())
+	require.NoError(t, err)
+	require.NotEqual(t, "abcd", value)
+}
+
+func TestGenerateUnsatisfiable(t *testing.T) {
+	schema := openapi3.NewStringSchema().WithMinLength(4).WithMaxLength(2)
+	_, err := openapi3sample.Generate(schema.NewRef())
+	var generationError *openapi3sample.GenerationError
+	require.ErrorAs(t, err, &generationError)
+	require.Same(t, schema, generationError.Schema)
+}
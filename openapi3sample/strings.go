@@ -0,0 +1,159 @@
+package openapi3sample
+
+import (
+	"encoding/base64"
+	"fmt"
+	"regexp/syntax"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// format returns a string of the given format, if the format is known.
+func (g *Generator) format(format string) (string, bool) {
+	random := g.mode() == Random
+	n := func(k int) int {
+		if random {
+			return g.rnd.IntN(k)
+		}
+		return 0
+	}
+	t := time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)
+	if random {
+		t = time.Date(1970+n(100), time.January, 1, 0, 0, 0, 0, time.UTC).
+			Add(time.Duration(n(365*24*3600)) * time.Second)
+	}
+
+	switch format {
+	case "date":
+		return t.Format(time.DateOnly), true
+	case "date-time":
+		return t.Format(time.RFC3339), true
+	case "time":
+		return t.Format("15:04:05Z"), true
+	case "email":
+		return fmt.Sprintf("user%d@example.com", n(1000)), true
+	case "hostname":
+		return fmt.Sprintf("host%d.example.com", n(1000)), true
+	case "ipv4":
+		return fmt.Sprintf("192.0.2.%d", 1+n(254)), true
+	case "ipv6":
+		return fmt.Sprintf("2001:db8::%x", 1+n(0xfffe)), true
+	case "uri", "url":
+		return fmt.Sprintf("https://example.com/%d", n(1000)), true
+	case "uri-reference":
+		return fmt.Sprintf("/resources/%d", n(1000)), true
+	case "uuid":
+		var b [16]byte
+		for i := range b {
+			b[i] = byte(n(256))
+		}
+		// Version 4, variant RFC 4122
+		b[6] = b[6]&0x0f | 0x40
+		b[8] = b[8]&0x3f | 0x80
+		return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), true
+	case "byte":
+		b := make([]byte, 1+n(16))
+		for i := range b {
+			b[i] = byte('a' + n(26))
+		}
+		return base64.StdEncoding.EncodeToString(b), true
+	}
+	return "", false
+}
+
+// matchPattern returns a string matching the regular expression pattern.
+// Repetitions are capped so that strings are no longer than maxLength, when possible.
+func (g *Generator) matchPattern(pattern string, maxLength int) (string, error) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	if err := g.writeMatch(&sb, re.Simplify(), min(maxLength, 8)); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+func (g *Generator) writeMatch(sb *strings.Builder, re *syntax.Regexp, maxRepeat int) error {
+	switch re.Op {
+	case syntax.OpNoMatch:
+		return fmt.Errorf("pattern %q matches no string", re.String())
+	case syntax.OpLiteral:
+		sb.WriteString(string(re.Rune))
+	case syntax.OpCharClass:
+		sb.WriteRune(g.classRune(re.Rune))
+	case syntax.OpAnyCharNotNL, syntax.OpAnyChar:
+		sb.WriteRune(g.classRune([]rune{'a', 'z'}))
+	case syntax.OpCapture:
+		return g.writeMatch(sb, re.Sub[0], maxRepeat)
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		lo, hi := re.Min, re.Max
+		switch re.Op {
+		case syntax.OpStar:
+			lo, hi = 0, -1
+		case syntax.OpPlus:
+			lo, hi = 1, -1
+		case syntax.OpQuest:
+			lo, hi = 0, 1
+		}
+		if hi < 0 || hi > max(lo, maxRepeat) {
+			hi = max(lo, maxRepeat)
+		}
+		n := lo
+		switch g.mode() {
+		case Maximal:
+			n = hi
+		case Random:
+			n = lo + g.rnd.IntN(hi-lo+1)
+		}
+		for range n {
+			if err := g.writeMatch(sb, re.Sub[0], maxRepeat); err != nil {
+				return err
+			}
+		}
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if err := g.writeMatch(sb, sub, maxRepeat); err != nil {
+				return err
+			}
+		}
+	case syntax.OpAlternate:
+		i := 0
+		switch g.mode() {
+		case Maximal:
+			i = len(re.Sub) - 1
+		case Random:
+			i = g.rnd.IntN(len(re.Sub))
+		}
+		return g.writeMatch(sb, re.Sub[i], maxRepeat)
+	}
+	// Anchors, word boundaries and empty matches write nothing
+	return nil
+}
+
+// classRune returns a rune of the ranges of a character class, preferring printable ASCII runes.
+func (g *Generator) classRune(ranges []rune) rune {
+	var printable []rune
+	for i := 0; i+1 < len(ranges); i += 2 {
+		for r := max(ranges[i], ' '+1); r <= min(ranges[i+1], '~'); r++ {
+			printable = append(printable, r)
+		}
+	}
+	if len(printable) == 0 {
+		for i := 0; i+1 < len(ranges); i += 2 {
+			if unicode.IsPrint(ranges[i]) {
+				return ranges[i]
+			}
+		}
+		return ranges[0]
+	}
+	switch g.mode() {
+	case Minimal:
+		return printable[0]
+	case Maximal:
+		return printable[len(printable)-1]
+	}
+	return printable[g.rnd.IntN(len(printable))]
+}
@@ -0,0 +1,117 @@
+package openapi3sample_test
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3sample"
+)
+
+// TestGenerateTestdata checks that values generated for the schemas of the test documents of the repository validate.
+func TestGenerateTestdata(t *testing.T) {
+	var files []string
+	for _, pattern := range []string{"../openapi3/testdata/*.yml", "../openapi3/testdata/*.yaml", "../openapi3/testdata/*.json", "../openapi3filter/testdata/*.yaml"} {
+		matches, err := filepath.Glob(pattern)
+		require.NoError(t, err)
+		files = append(files, matches...)
+	}
+	require.NotEmpty(t, files)
+
+	tested := 0
+	for _, file := range files {
+		loader := openapi3.NewLoader()
+		loader.IsExternalRefsAllowed = true
+		doc, err := loader.LoadFromFile(file)
+		if err != nil || doc.Validate(context.Background()) != nil {
+			// Documents testing loading or validation errors
+			continue
+		}
+
+		var visitOptions []openapi3.SchemaValidationOption
+		if doc.IsOpenAPI31OrLater() {
+			visitOptions = append(visitOptions, openapi3.EnableJSONSchema2020())
+		}
+
+		schemas := documentSchemas(doc)
+		for _, name := range slices.Sorted(maps.Keys(schemas)) {
+			schema := schemas[name]
+			t.Run(filepath.Base(file)+"/"+name, func(t *testing.T) {
+				for _, direction := range []openapi3sample.Direction{openapi3sample.AnyDirection, openapi3sample.RequestDirection, openapi3sample.ResponseDirection} {
+					for i, mode := range []openapi3sample.Mode{openapi3sample.Minimal, openapi3sample.Maximal, openapi3sample.Random, openapi3sample.Random, openapi3sample.Random} {
+						opts := []openapi3sample.Option{
+							openapi3sample.Seed(uint64(i)),
+							openapi3sample.UseMode(mode),
+							openapi3sample.UseDirection(direction),
+							openapi3sample.SchemaValidationOptions(visitOptions...),
+						}
+						value, err := openapi3sample.Generate(schema, opts...)
+						require.NoError(t, err, "direction %d, mode %d, seed %d", direction, mode, i)
+
+						validationOptions := slices.Clone(visitOptions)
+						switch direction {
+						case openapi3sample.RequestDirection:
+							validationOptions = append(validationOptions, openapi3.VisitAsRequest())
+						case openapi3sample.ResponseDirection:
+							validationOptions = append(validationOptions, openapi3.VisitAsResponse())
+						}
+						require.NoError(t, schema.Value.VisitJSON(value, validationOptions...))
+					}
+				}
+			})
+			tested++
+		}
+	}
+	require.Greater(t, tested, 50)
+}
+
+// documentSchemas returns the schemas of the components, parameters and bodies of doc.
+func documentSchemas(doc *openapi3.T) map[string]*openapi3.SchemaRef {
+	schemas := make(map[string]*openapi3.SchemaRef)
+	add := func(name string, schema *openapi3.SchemaRef) {
+		if schema != nil && schema.Value != nil {
+			schemas[name] = schema
+		}
+	}
+	addContent := func(name string, content openapi3.Content) {
+		for mediaType, media := range content {
+			if media == nil {
+				continue
+			}
+			add(name+"/"+mediaType, media.Schema)
+		}
+	}
+
+	if doc.Components != nil {
+		for name, schema := range doc.Components.Schemas {
+			add("components/"+name, schema)
+		}
+	}
+	for path, pathItem := range doc.Paths.Map() {
+		for method, operation := range pathItem.Operations() {
+			prefix := method + " " + path
+			for _, parameter := range append(slices.Clone(pathItem.Parameters), operation.Parameters...) {
+				if parameter.Value != nil {
+					add(fmt.Sprintf("%s/%s:%s", prefix, parameter.Value.In, parameter.Value.Name), parameter.Value.Schema)
+				}
+			}
+			if operation.RequestBody != nil && operation.RequestBody.Value != nil {
+				addContent(prefix+"/request", operation.RequestBody.Value.Content)
+			}
+			if operation.Responses != nil {
+				for status, response := range operation.Responses.Map() {
+					if response.Value != nil {
+						addContent(prefix+"/"+status, response.Value.Content)
+					}
+				}
+			}
+		}
+	}
+	return schemas
+}
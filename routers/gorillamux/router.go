@@ -7,9 +7,9 @@
 package gorillamux
 
 import (
+	"maps"
 	"net/http"
 	"net/url"
-	"regexp"
 	"slices"
 	"strings"
 
@@ -17,6 +17,7 @@ import (
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/internal/serverurl"
 )
 
 var _ routers.Router = &Router{}
@@ -41,8 +42,6 @@ type srv struct {
 	varsUpdater varsf
 }
 
-var singleVariableMatcher = regexp.MustCompile(`^\{([^{}]+)\}$`)
-
 // TODO: Handle/HandlerFunc + ServeHTTP (When there is a match, the route variables can be retrieved calling mux.Vars(request))
 
 // NewRouter creates a gorilla/mux router.
@@ -112,6 +111,7 @@ func (r *Router) FindRoute(req *http.Request) (*routers.Route, map[string]string
 				f(vars)
 			}
 			route := *r.routes[i]
+			route.ResolveServer(req, vars)
 			route.Method = req.Method
 			route.Operation = route.Spec.Paths.Value(route.Path).GetOperation(route.Method)
 			return &route, vars, nil
@@ -129,38 +129,19 @@ func (r *Router) FindRoute(req *http.Request) (*routers.Route, map[string]string
 func makeServers(in openapi3.Servers) ([]srv, error) {
 	servers := make([]srv, 0, len(in))
 	for _, server := range in {
-		serverURL := server.URL
-		if submatch := singleVariableMatcher.FindStringSubmatch(serverURL); submatch != nil {
-			sVar := submatch[1]
-			sVal := server.Variables[sVar].Default
-			serverURL = strings.ReplaceAll(serverURL, "{"+sVar+"}", sVal)
-			var varsUpdater varsf
-			if lhs := strings.TrimSuffix(serverURL, server.Variables[sVar].Default); lhs != "" {
-				varsUpdater = func(vars map[string]string) { vars[sVar] = lhs }
-			}
-			svr, err := newSrv(serverURL, server, varsUpdater)
-			if err != nil {
-				return nil, err
-			}
-
-			servers = append(servers, svr)
-			continue
-		}
-
 		// If a variable represents the port "http://domain.tld:{port}/bla"
 		// then url.Parse() cannot parse "http://domain.tld:`bEncode({port})`/bla"
 		// and mux is not able to set the {port} variable
 		// So we just use the default value for this variable.
 		// See https://github.com/getkin/kin-openapi/issues/367
+		serverURL, defaults, err := serverurl.Default(server)
+		if err != nil {
+			return nil, err
+		}
 		var varsUpdater varsf
-		if lhs := strings.Index(serverURL, ":{"); lhs > 0 {
-			rest := serverURL[lhs+len(":{"):]
-			rhs := strings.Index(rest, "}")
-			portVariable := rest[:rhs]
-			portValue := server.Variables[portVariable].Default
-			serverURL = strings.ReplaceAll(serverURL, "{"+portVariable+"}", portValue)
+		if defaults != nil {
 			varsUpdater = func(vars map[string]string) {
-				vars[portVariable] = portValue
+				maps.Copy(vars, defaults)
 			}
 		}
 
@@ -266,6 +266,27 @@ func TestServerPath(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestInvalidServerURL(t *testing.T) {
+	helloGET := &openapi3.Operation{Responses: openapi3.NewResponses(openapi3.WithStatus(200, &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("OK")}))}
+	for _, tc := range []struct {
+		url string
+		err string
+	}{
+		{url: "{url}", err: `server "{url}": missing variable "url"`},
+		{url: "http://example.com:{port}/path", err: `server "http://example.com:{port}/path": missing variable "port"`},
+		{url: "http://example.com:{port/path", err: `server "http://example.com:{port/path": unclosed '{' at offset 19`},
+		{url: "http://example.com}/path", err: `server "http://example.com}/path": unexpected '}' at offset 18`},
+	} {
+		t.Run(tc.url, func(t *testing.T) {
+			_, err := NewRouter(&openapi3.T{
+				Servers: openapi3.Servers{{URL: tc.url}},
+				Paths:   openapi3.NewPaths(openapi3.WithPath("/hello", &openapi3.PathItem{Get: helloGET})),
+			})
+			require.EqualError(t, err, tc.err)
+		})
+	}
+}
+
 func TestServerOverrideAtPathLevel(t *testing.T) {
 	helloGET := &openapi3.Operation{Responses: openapi3.NewResponses(openapi3.WithStatus(200, &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("OK")}))}
 	doc := &openapi3.T{
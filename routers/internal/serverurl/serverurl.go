@@ -0,0 +1,71 @@
+// Package serverurl holds the parsing of server URLs the routers share.
+package serverurl
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+var singleVariableMatcher = regexp.MustCompile(`^\{([^{}]+)\}$`)
+
+// Default returns the URL of server with the variables routers do not match replaced by their default value:
+// a variable making up the whole URL, or else one for the port number (see issue #367).
+// vars holds the value of the port variable, if any.
+//
+// It fails if the braces of the URL are unbalanced, or if such a variable is not declared.
+func Default(server *openapi3.Server) (serverURL string, vars map[string]string, err error) {
+	serverURL = server.URL
+	if err = checkBraces(serverURL); err != nil {
+		return "", nil, fmt.Errorf("server %q: %w", server.URL, err)
+	}
+
+	if submatch := singleVariableMatcher.FindStringSubmatch(serverURL); submatch != nil {
+		v := server.Variables[submatch[1]]
+		if v == nil {
+			return "", nil, fmt.Errorf("server %q: missing variable %q", server.URL, submatch[1])
+		}
+		return v.Default, nil, nil
+	}
+
+	// A variable representing the port cannot be matched: use its default value.
+	if lhs := strings.Index(serverURL, ":{"); lhs > 0 {
+		rest := serverURL[lhs+len(":{"):]
+		portVariable := rest[:strings.IndexByte(rest, '}')]
+		v := server.Variables[portVariable]
+		if v == nil {
+			return "", nil, fmt.Errorf("server %q: missing variable %q", server.URL, portVariable)
+		}
+		serverURL = strings.ReplaceAll(serverURL, "{"+portVariable+"}", v.Default)
+		vars = map[string]string{portVariable: v.Default}
+	}
+	return serverURL, vars, nil
+}
+
+// checkBraces fails unless each "{" of s is closed by a "}" before the next "{".
+func checkBraces(s string) error {
+	open := -1
+	for i, c := range s {
+		switch c {
+		case '{':
+			if open >= 0 {
+				return fmt.Errorf("unexpected '{' at offset %d", i)
+			}
+			open = i
+		case '}':
+			if open < 0 {
+				return fmt.Errorf("unexpected '}' at offset %d", i)
+			}
+			if i == open+1 {
+				return fmt.Errorf("empty variable name at offset %d", open)
+			}
+			open = -1
+		}
+	}
+	if open >= 0 {
+		return fmt.Errorf("unclosed '{' at offset %d", open)
+	}
+	return nil
+}
@@ -14,6 +14,8 @@ import (
 	"github.com/getkin/kin-openapi/routers"
 	"github.com/getkin/kin-openapi/routers/gorillamux"
 	"github.com/getkin/kin-openapi/routers/legacy"
+	"github.com/getkin/kin-openapi/routers/radix"
+	"github.com/getkin/kin-openapi/routers/stdmux"
 )
 
 func TestIssue356(t *testing.T) {
@@ -71,8 +73,16 @@ paths:
 			return gorillamux.NewRouter(doc)
 		}
 
-		for i, newRouter := range []func(*openapi3.T, ...openapi3.ValidationOption) (routers.Router, error){gorillamuxNewRouterWrapped, legacy.NewRouter} {
-			t.Logf("using NewRouter from %s", map[int]string{0: "gorillamux", 1: "legacy"}[i])
+		stdmuxNewRouterWrapped := func(doc *openapi3.T, opts ...openapi3.ValidationOption) (routers.Router, error) {
+			return stdmux.NewRouter(doc)
+		}
+
+		radixNewRouterWrapped := func(doc *openapi3.T, opts ...openapi3.ValidationOption) (routers.Router, error) {
+			return radix.NewRouter(doc)
+		}
+
+		for i, newRouter := range []func(*openapi3.T, ...openapi3.ValidationOption) (routers.Router, error){gorillamuxNewRouterWrapped, legacy.NewRouter, stdmuxNewRouterWrapped, radixNewRouterWrapped} {
+			t.Logf("using NewRouter from %s", map[int]string{0: "gorillamux", 1: "legacy", 2: "stdmux", 3: "radix"}[i])
 			router, err := newRouter(doc)
 			require.NoError(t, err)
 
@@ -160,5 +160,12 @@ func (router *Router) FindRoute(req *http.Request) (*routers.Route, map[string]s
 		key := strings.TrimSuffix(paramKeys[i], "*")
 		pathParams[key] = value
 	}
-	return route, pathParams, nil
+
+	if route == nil {
+		return nil, pathParams, nil
+	}
+	matched := *route
+	matched.Server = server
+	matched.ResolveServer(req, pathParams)
+	return &matched, pathParams, nil
 }
@@ -0,0 +1,366 @@
+// Package radix implements a router.
+//
+// It compiles paths into one compressed radix tree per method:
+// * it provides somewhat granular errors: "path not found", "method not allowed".
+// * it handles templated segments (e.g. /books/{id}.json or /files/{name}.{ext})
+// * it handles a trailing catch-all path pattern (e.g. /params/{x}/{y}/{z:.*})
+// * static segments take precedence over templated ones, whatever the order of paths.
+//
+// Matching a route does not allocate, except for the returned copy of the route and path parameters.
+package radix
+
+import (
+	"cmp"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/routers"
+)
+
+var _ routers.Router = &Router{}
+
+// Router helps link http.Request.s and an OpenAPIv3 spec
+type Router struct {
+	trees map[string]*node
+}
+
+// entry is a route reachable through a leaf of a tree, along with the server checks it requires.
+type entry struct {
+	route   *routers.Route
+	server  *srv
+	names   []string
+	hasVars bool
+}
+
+type state struct {
+	host   string
+	scheme string
+	values []string
+}
+
+var statePool = sync.Pool{New: func() any { return &state{values: make([]string, 0, 8)} }}
+
+// NewRouter creates a radix tree router.
+// Assumes spec is .Validate()d
+// Note that a variable for the port number MUST have a default value and only this value will match as the port (see issue #367).
+//
+// Path parameters are nil when the matched route has no variables.
+func NewRouter(doc *openapi3.T) (routers.Router, error) {
+	docServers, err := makeServers(doc.Servers)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Router{trees: make(map[string]*node)}
+	for _, path := range doc.Paths.InMatchingOrder() {
+		pathItem := doc.Paths.Value(path)
+		servers := docServers
+		if len(pathItem.Servers) > 0 {
+			if servers, err = makeServers(pathItem.Servers); err != nil {
+				return nil, err
+			}
+		}
+
+		operations := pathItem.Operations()
+		methods := make([]string, 0, len(operations))
+		for method := range operations {
+			methods = append(methods, method)
+		}
+		slices.Sort(methods)
+
+		for _, s := range servers {
+			tokens, err := tokenize(s.base + path)
+			if err != nil {
+				return nil, err
+			}
+			var names []string
+			for _, tok := range tokens {
+				names = append(names, tok.tpl.names()...)
+			}
+			if s.host != nil {
+				names = append(names, s.host.names()...)
+			}
+			for _, method := range methods {
+				root := r.trees[method]
+				if root == nil {
+					root = &node{}
+					r.trees[method] = root
+				}
+				root.insert(tokens, &entry{
+					route: &routers.Route{
+						Spec:      doc,
+						Server:    s.server,
+						Path:      path,
+						PathItem:  pathItem,
+						Method:    method,
+						Operation: operations[method],
+					},
+					server:  s,
+					names:   names,
+					hasVars: len(names) != 0 || len(s.vars) != 0,
+				})
+			}
+		}
+	}
+	return r, nil
+}
+
+// FindRoute extracts the route and parameters of an http.Request
+func (r *Router) FindRoute(req *http.Request) (*routers.Route, map[string]string, error) {
+	st := statePool.Get().(*state)
+	defer func() {
+		clear(st.values)
+		st.values = st.values[:0]
+		statePool.Put(st)
+	}()
+
+	st.host = req.Host
+	if req.URL.IsAbs() {
+		st.host = req.URL.Host
+	}
+	st.scheme = req.URL.Scheme
+	if st.scheme == "" {
+		if req.TLS == nil {
+			st.scheme = "http"
+		} else {
+			st.scheme = "https"
+		}
+	}
+	path := req.URL.EscapedPath()
+
+	if root := r.trees[req.Method]; root != nil {
+		if e := root.match(path, st); e != nil {
+			route := *e.route
+			params := e.params(st.values)
+			route.ResolveServer(req, params)
+			return &route, params, nil
+		}
+	}
+	for method, root := range r.trees {
+		if method == req.Method {
+			continue
+		}
+		st.values = st.values[:0]
+		if root.match(path, st) != nil {
+			return nil, nil, routers.ErrMethodNotAllowed
+		}
+	}
+	return nil, nil, routers.ErrPathNotFound
+}
+
+func (e *entry) params(values []string) map[string]string {
+	if !e.hasVars {
+		return nil
+	}
+	params := make(map[string]string, len(e.names)+len(e.server.vars))
+	for i, name := range e.names {
+		params[name] = values[i]
+	}
+	for name, value := range e.server.vars {
+		params[name] = value
+	}
+	return params
+}
+
+type nodeKind uint8
+
+const (
+	staticNode nodeKind = iota
+	templateNode
+	catchAllNode
+)
+
+type node struct {
+	kind nodeKind
+	// prefix is the static path of a staticNode, the source of a template otherwise
+	prefix string
+	tpl    template
+
+	indices  string
+	static   []*node
+	params   []*node
+	catchAll *node
+
+	entries []*entry
+}
+
+// token is either a run of static characters or a templated path segment
+type token struct {
+	kind nodeKind
+	text string
+	tpl  template
+}
+
+func (n *node) insert(tokens []token, e *entry) {
+	if len(tokens) == 0 {
+		n.entries = append(n.entries, e)
+		return
+	}
+	tok := tokens[0]
+	switch tok.kind {
+	case staticNode:
+		n.insertStatic(tok.text, tokens[1:], e)
+	case catchAllNode:
+		if n.catchAll == nil {
+			n.catchAll = &node{kind: catchAllNode, prefix: tok.text, tpl: tok.tpl}
+		}
+		n.catchAll.insert(tokens[1:], e)
+	default:
+		for _, child := range n.params {
+			if child.prefix == tok.text {
+				child.insert(tokens[1:], e)
+				return
+			}
+		}
+		child := &node{kind: templateNode, prefix: tok.text, tpl: tok.tpl}
+		n.params = append(n.params, child)
+		// Try the most literal templates first: "{id}.json" before "{id}"
+		slices.SortStableFunc(n.params, func(a, b *node) int {
+			return cmp.Compare(b.tpl.literalLen(), a.tpl.literalLen())
+		})
+		child.insert(tokens[1:], e)
+	}
+}
+
+func (n *node) insertStatic(text string, rest []token, e *entry) {
+	i := strings.IndexByte(n.indices, text[0])
+	if i < 0 {
+		child := &node{prefix: text}
+		n.indices += text[:1]
+		n.static = append(n.static, child)
+		child.insert(rest, e)
+		return
+	}
+
+	child := n.static[i]
+	common := commonPrefixLen(child.prefix, text)
+	if common < len(child.prefix) {
+		// Split the child at the end of the common prefix
+		split := &node{
+			prefix:   child.prefix[common:],
+			indices:  child.indices,
+			static:   child.static,
+			params:   child.params,
+			catchAll: child.catchAll,
+			entries:  child.entries,
+		}
+		*child = node{
+			prefix:  child.prefix[:common],
+			indices: split.prefix[:1],
+			static:  []*node{split},
+		}
+	}
+	if common == len(text) {
+		child.insert(rest, e)
+		return
+	}
+	child.insertStatic(text[common:], rest, e)
+}
+
+func commonPrefixLen(a, b string) int {
+	n := min(len(a), len(b))
+	for i := range n {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	return n
+}
+
+// match returns the first entry matching path, backtracking from static
+// children to templated ones. Variable values are appended to st.values.
+func (n *node) match(path string, st *state) *entry {
+	switch n.kind {
+	case staticNode:
+		if !strings.HasPrefix(path, n.prefix) {
+			return nil
+		}
+		path = path[len(n.prefix):]
+	case templateNode:
+		end := strings.IndexByte(path, '/')
+		if end < 0 {
+			end = len(path)
+		}
+		if !n.tpl.match(path[:end], st) {
+			return nil
+		}
+		path = path[end:]
+	case catchAllNode:
+		if !n.tpl.matchValue(0, path) {
+			return nil
+		}
+		st.values = append(st.values, path)
+		path = ""
+	}
+
+	if path == "" {
+		for _, e := range n.entries {
+			if e.server.match(st) {
+				return e
+			}
+		}
+	}
+
+	mark := len(st.values)
+	if path != "" {
+		if i := strings.IndexByte(n.indices, path[0]); i >= 0 {
+			if e := n.static[i].match(path, st); e != nil {
+				return e
+			}
+			st.values = st.values[:mark]
+		}
+	}
+	for _, child := range n.params {
+		if e := child.match(path, st); e != nil {
+			return e
+		}
+		st.values = st.values[:mark]
+	}
+	if n.catchAll != nil {
+		if e := n.catchAll.match(path, st); e != nil {
+			return e
+		}
+		st.values = st.values[:mark]
+	}
+	return nil
+}
+
+// tokenize splits a path into static runs and templated segments
+func tokenize(path string) ([]token, error) {
+	if !strings.HasPrefix(path, "/") {
+		return nil, &routers.RouteError{Reason: "path " + path + " does not start with a slash"}
+	}
+
+	var tokens []token
+	static := ""
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if i > 0 {
+			static += "/"
+		}
+		if !strings.Contains(segment, "{") {
+			static += segment
+			continue
+		}
+		if static != "" {
+			tokens = append(tokens, token{kind: staticNode, text: static})
+			static = ""
+		}
+		tpl, err := parseTemplate(segment, 0)
+		if err != nil {
+			return nil, err
+		}
+		kind := templateNode
+		if i == len(segments)-1 && len(tpl) == 1 && tpl[0].isVar && tpl[0].pattern == ".*" {
+			kind = catchAllNode
+		}
+		tokens = append(tokens, token{kind: kind, text: segment, tpl: tpl})
+	}
+	if static != "" {
+		tokens = append(tokens, token{kind: staticNode, text: static})
+	}
+	return tokens, nil
+}
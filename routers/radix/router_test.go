@@ -0,0 +1,509 @@
+package radix
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/getkin/kin-openapi/routers/legacy"
+)
+
+func TestRouter(t *testing.T) {
+	helloCONNECT := &openapi3.Operation{Responses: openapi3.NewResponses(openapi3.WithStatus(200, &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("OK")}))}
+	helloDELETE := &openapi3.Operation{Responses: openapi3.NewResponses(openapi3.WithStatus(200, &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("OK")}))}
+	helloGET := &openapi3.Operation{Responses: openapi3.NewResponses(openapi3.WithStatus(200, &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("OK")}))}
+	helloHEAD := &openapi3.Operation{Responses: openapi3.NewResponses(openapi3.WithStatus(200, &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("OK")}))}
+	helloOPTIONS := &openapi3.Operation{Responses: openapi3.NewResponses(openapi3.WithStatus(200, &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("OK")}))}
+	helloPATCH := &openapi3.Operation{Responses: openapi3.NewResponses(openapi3.WithStatus(200, &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("OK")}))}
+	helloPOST := &openapi3.Operation{Responses: openapi3.NewResponses(openapi3.WithStatus(200, &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("OK")}))}
+	helloPUT := &openapi3.Operation{Responses: openapi3.NewResponses(openapi3.WithStatus(200, &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("OK")}))}
+	helloTRACE := &openapi3.Operation{Responses: openapi3.NewResponses(openapi3.WithStatus(200, &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("OK")}))}
+	paramsGET := &openapi3.Operation{Responses: openapi3.NewResponses(openapi3.WithStatus(200, &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("OK")}))}
+	booksPOST := &openapi3.Operation{Responses: openapi3.NewResponses(openapi3.WithStatus(200, &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("OK")}))}
+	partialGET := &openapi3.Operation{Responses: openapi3.NewResponses(openapi3.WithStatus(200, &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("OK")}))}
+	doc := &openapi3.T{
+		OpenAPI: "3.0.0",
+		Info: &openapi3.Info{
+			Title:   "MyAPI",
+			Version: "0.1",
+		},
+		Paths: openapi3.NewPaths(
+			openapi3.WithPath("/hello", &openapi3.PathItem{
+				Connect: helloCONNECT,
+				Delete:  helloDELETE,
+				Get:     helloGET,
+				Head:    helloHEAD,
+				Options: helloOPTIONS,
+				Patch:   helloPATCH,
+				Post:    helloPOST,
+				Put:     helloPUT,
+				Trace:   helloTRACE,
+			}),
+			openapi3.WithPath("/onlyGET", &openapi3.PathItem{
+				Get: helloGET,
+			}),
+			openapi3.WithPath("/params/{x}/{y}/{z:.*}", &openapi3.PathItem{
+				Get: paramsGET,
+				Parameters: openapi3.Parameters{
+					&openapi3.ParameterRef{Value: openapi3.NewPathParameter("x").WithSchema(openapi3.NewStringSchema())},
+					&openapi3.ParameterRef{Value: openapi3.NewPathParameter("y").WithSchema(openapi3.NewFloat64Schema())},
+					&openapi3.ParameterRef{Value: openapi3.NewPathParameter("z").WithSchema(openapi3.NewIntegerSchema())},
+				},
+			}),
+			openapi3.WithPath("/books/{bookid}", &openapi3.PathItem{
+				Get: paramsGET,
+				Parameters: openapi3.Parameters{
+					&openapi3.ParameterRef{Value: openapi3.NewPathParameter("bookid").WithSchema(openapi3.NewStringSchema())},
+				},
+			}),
+			openapi3.WithPath("/books/{bookid}.json", &openapi3.PathItem{
+				Post: booksPOST,
+				Parameters: openapi3.Parameters{
+					&openapi3.ParameterRef{Value: openapi3.NewPathParameter("bookid2").WithSchema(openapi3.NewStringSchema())},
+				},
+			}),
+			openapi3.WithPath("/partial", &openapi3.PathItem{
+				Get: partialGET,
+			}),
+		),
+	}
+
+	expect := func(r routers.Router, method string, uri string, operation *openapi3.Operation, params map[string]string) {
+		t.Helper()
+		req, err := http.NewRequest(method, uri, nil)
+		require.NoError(t, err)
+		route, pathParams, err := r.FindRoute(req)
+		if err != nil {
+			if operation == nil {
+				pathItem := doc.Paths.Value(uri)
+				if pathItem == nil {
+					if err.Error() != routers.ErrPathNotFound.Error() {
+						t.Fatalf("'%s %s': should have returned %q, but it returned an error: %v", method, uri, routers.ErrPathNotFound, err)
+					}
+					return
+				}
+				if pathItem.GetOperation(method) == nil {
+					if err.Error() != routers.ErrMethodNotAllowed.Error() {
+						t.Fatalf("'%s %s': should have returned %q, but it returned an error: %v", method, uri, routers.ErrMethodNotAllowed, err)
+					}
+				}
+			} else {
+				t.Fatalf("'%s %s': should have returned an operation, but it returned an error: %v", method, uri, err)
+			}
+		}
+		if operation == nil && err == nil {
+			t.Fatalf("'%s %s': should have failed, but returned\nroute = %+v\npathParams = %+v", method, uri, route, pathParams)
+		}
+		if route == nil {
+			return
+		}
+		if route.Operation != operation {
+			t.Fatalf("'%s %s': Returned wrong operation (%v)",
+				method, uri, route.Operation)
+		}
+		if len(params) == 0 {
+			if len(pathParams) != 0 {
+				t.Fatalf("'%s %s': should return no path arguments, but found %+v", method, uri, pathParams)
+			}
+		} else {
+			names := make([]string, 0, len(params))
+			for name := range params {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				expected := params[name]
+				actual, exists := pathParams[name]
+				if !exists {
+					t.Fatalf("'%s %s': path parameter %q should be %q, but it's not defined.", method, uri, name, expected)
+				}
+				if actual != expected {
+					t.Fatalf("'%s %s': path parameter %q should be %q, but it's %q", method, uri, name, expected, actual)
+				}
+			}
+		}
+	}
+
+	err := doc.Validate(context.Background())
+	require.NoError(t, err)
+	r, err := NewRouter(doc)
+	require.NoError(t, err)
+
+	expect(r, http.MethodGet, "/not_existing", nil, nil)
+	expect(r, http.MethodDelete, "/hello", helloDELETE, nil)
+	expect(r, http.MethodGet, "/hello", helloGET, nil)
+	expect(r, http.MethodHead, "/hello", helloHEAD, nil)
+	expect(r, http.MethodPatch, "/hello", helloPATCH, nil)
+	expect(r, http.MethodPost, "/hello", helloPOST, nil)
+	expect(r, http.MethodPut, "/hello", helloPUT, nil)
+	expect(r, http.MethodGet, "/params/a/b/", paramsGET, map[string]string{
+		"x": "a",
+		"y": "b",
+		"z": "",
+	})
+	expect(r, http.MethodGet, "/params/a/b/c%2Fd", paramsGET, map[string]string{
+		"x": "a",
+		"y": "b",
+		"z": "c%2Fd",
+	})
+	expect(r, http.MethodGet, "/books/War.and.Peace", paramsGET, map[string]string{
+		"bookid": "War.and.Peace",
+	})
+	expect(r, http.MethodPost, "/books/War.and.Peace.json", booksPOST, map[string]string{
+		"bookid": "War.and.Peace",
+	})
+	expect(r, http.MethodPost, "/partial", nil, nil)
+
+	doc.Servers = []*openapi3.Server{
+		{URL: "https://www.example.com/api/v1"},
+		{URL: "{scheme}://{d0}.{d1}.com/api/v1/", Variables: map[string]*openapi3.ServerVariable{
+			"d0":     {Default: "www"},
+			"d1":     {Default: "example", Enum: []string{"example"}},
+			"scheme": {Default: "https", Enum: []string{"https", "http"}},
+		}},
+		{URL: "http://127.0.0.1:{port}/api/v1", Variables: map[string]*openapi3.ServerVariable{
+			"port": {Default: "8000"},
+		}},
+	}
+	err = doc.Validate(context.Background())
+	require.NoError(t, err)
+	r, err = NewRouter(doc)
+	require.NoError(t, err)
+	expect(r, http.MethodGet, "/hello", nil, nil)
+	expect(r, http.MethodGet, "/api/v1/hello", nil, nil)
+	expect(r, http.MethodGet, "www.example.com/api/v1/hello", nil, nil)
+	expect(r, http.MethodGet, "https:///api/v1/hello", nil, nil)
+	expect(r, http.MethodGet, "https://www.example.com/hello", nil, nil)
+	expect(r, http.MethodGet, "https://www.example.com/api/v1/hello", helloGET, nil)
+	expect(r, http.MethodGet, "https://domain0.domain1.com/api/v1/hello", helloGET, map[string]string{
+		"d0": "domain0",
+		"d1": "domain1",
+		// "scheme": "https", TODO: https://github.com/gorilla/mux/issues/624
+	})
+	expect(r, http.MethodGet, "http://127.0.0.1:8000/api/v1/hello", helloGET, map[string]string{
+		"port": "8000",
+	})
+
+	doc.Servers = []*openapi3.Server{
+		{URL: "{server}", Variables: map[string]*openapi3.ServerVariable{
+			"server": {Default: "/api/v1"},
+		}},
+	}
+	err = doc.Validate(context.Background())
+	require.NoError(t, err)
+	r, err = NewRouter(doc)
+	require.NoError(t, err)
+	expect(r, http.MethodGet, "https://myserver/api/v1/hello", helloGET, nil)
+
+	{
+		uri := "https://www.example.com/api/v1/onlyGET"
+		expect(r, http.MethodGet, uri, helloGET, nil)
+		req, err := http.NewRequest(http.MethodDelete, uri, nil)
+		require.NoError(t, err)
+		require.NotNil(t, req)
+		route, pathParams, err := r.FindRoute(req)
+		require.EqualError(t, err, routers.ErrMethodNotAllowed.Error())
+		require.Nil(t, route)
+		require.Nil(t, pathParams)
+	}
+}
+
+func TestServerPath(t *testing.T) {
+	server := &openapi3.Server{URL: "http://example.com"}
+	err := server.Validate(context.Background())
+	require.NoError(t, err)
+
+	helloGET := &openapi3.Operation{Responses: openapi3.NewResponses(openapi3.WithStatus(200, &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("OK")}))}
+	for _, tc := range []struct {
+		server *openapi3.Server
+		url    string
+	}{
+		{server: server, url: "http://example.com/hello"},
+		{server: &openapi3.Server{URL: "http://example.com/"}, url: "http://example.com/hello"},
+		{server: &openapi3.Server{URL: "http://example.com/path"}, url: "http://example.com/path/hello"},
+		{
+			server: &openapi3.Server{URL: "{scheme}://localhost", Variables: map[string]*openapi3.ServerVariable{"scheme": {Default: "https"}}},
+			url:    "https://localhost/hello",
+		},
+		{
+			server: &openapi3.Server{URL: "{url}", Variables: map[string]*openapi3.ServerVariable{"url": {Default: "http://example.com/path"}}},
+			url:    "http://example.com/path/hello",
+		},
+		{
+			server: &openapi3.Server{URL: "http://example.com:{port}/path", Variables: map[string]*openapi3.ServerVariable{"port": {Default: "8088"}}},
+			url:    "http://example.com:8088/path/hello",
+		},
+		{
+			server: &openapi3.Server{URL: "{server}", Variables: map[string]*openapi3.ServerVariable{"server": {Default: "/"}}},
+			url:    "http://example.com/hello",
+		},
+		{server: &openapi3.Server{URL: "/"}, url: "http://example.com/hello"},
+	} {
+		t.Run(tc.server.URL, func(t *testing.T) {
+			router, err := NewRouter(&openapi3.T{
+				Servers: openapi3.Servers{tc.server},
+				Paths:   openapi3.NewPaths(openapi3.WithPath("/hello", &openapi3.PathItem{Get: helloGET})),
+			})
+			require.NoError(t, err)
+			req, err := http.NewRequest(http.MethodGet, tc.url, nil)
+			require.NoError(t, err)
+			route, _, err := router.FindRoute(req)
+			require.NoError(t, err)
+			require.Equal(t, "/hello", route.Path)
+		})
+	}
+}
+
+func TestInvalidServerURL(t *testing.T) {
+	helloGET := &openapi3.Operation{Responses: openapi3.NewResponses(openapi3.WithStatus(200, &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("OK")}))}
+	for _, tc := range []struct {
+		url string
+		err string
+	}{
+		{url: "{url}", err: `server "{url}": missing variable "url"`},
+		{url: "http://example.com:{port}/path", err: `server "http://example.com:{port}/path": missing variable "port"`},
+		{url: "http://example.com:{port/path", err: `server "http://example.com:{port/path": unclosed '{' at offset 19`},
+		{url: "http://example.com}/path", err: `server "http://example.com}/path": unexpected '}' at offset 18`},
+	} {
+		t.Run(tc.url, func(t *testing.T) {
+			_, err := NewRouter(&openapi3.T{
+				Servers: openapi3.Servers{{URL: tc.url}},
+				Paths:   openapi3.NewPaths(openapi3.WithPath("/hello", &openapi3.PathItem{Get: helloGET})),
+			})
+			require.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestServerOverrideAtPathLevel(t *testing.T) {
+	helloGET := &openapi3.Operation{Responses: openapi3.NewResponses(openapi3.WithStatus(200, &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("OK")}))}
+	doc := &openapi3.T{
+		OpenAPI: "3.0.0",
+		Info: &openapi3.Info{
+			Title:   "rel",
+			Version: "1",
+		},
+		Servers: openapi3.Servers{
+			&openapi3.Server{
+				URL: "https://example.com",
+			},
+		},
+		Paths: openapi3.NewPaths(
+			openapi3.WithPath("/hello", &openapi3.PathItem{
+				Servers: openapi3.Servers{
+					&openapi3.Server{
+						URL: "https://another.com",
+					},
+				},
+				Get: helloGET,
+			}),
+		),
+	}
+	err := doc.Validate(context.Background())
+	require.NoError(t, err)
+	router, err := NewRouter(doc)
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodGet, "https://another.com/hello", nil)
+	require.NoError(t, err)
+	route, _, err := router.FindRoute(req)
+	require.NoError(t, err)
+	require.Equal(t, "/hello", route.Path)
+
+	req, err = http.NewRequest(http.MethodGet, "https://example.com/hello", nil)
+	require.NoError(t, err)
+	route, _, err = router.FindRoute(req)
+	require.Nil(t, route)
+	require.Error(t, err)
+}
+
+func TestRelativeURL(t *testing.T) {
+	helloGET := &openapi3.Operation{Responses: openapi3.NewResponses(openapi3.WithStatus(200, &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("OK")}))}
+	doc := &openapi3.T{
+		OpenAPI: "3.0.0",
+		Info: &openapi3.Info{
+			Title:   "rel",
+			Version: "1",
+		},
+		Servers: openapi3.Servers{
+			&openapi3.Server{
+				URL: "/api/v1",
+			},
+		},
+		Paths: openapi3.NewPaths(
+			openapi3.WithPath("/hello", &openapi3.PathItem{
+				Get: helloGET,
+			}),
+		),
+	}
+	err := doc.Validate(context.Background())
+	require.NoError(t, err)
+	router, err := NewRouter(doc)
+	require.NoError(t, err)
+	req, err := http.NewRequest(http.MethodGet, "https://example.com/api/v1/hello", nil)
+	require.NoError(t, err)
+	route, _, err := router.FindRoute(req)
+	require.NoError(t, err)
+	require.Equal(t, "/hello", route.Path)
+}
+
+func TestTemplatedSegments(t *testing.T) {
+	okResponses := openapi3.NewResponses(openapi3.WithStatus(200, &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("OK")}))
+	fileGET := &openapi3.Operation{Responses: okResponses}
+	latestGET := &openapi3.Operation{Responses: okResponses}
+	reportGET := &openapi3.Operation{Responses: okResponses}
+	itemGET := &openapi3.Operation{Responses: okResponses}
+	doc := &openapi3.T{
+		OpenAPI: "3.0.0",
+		Info:    &openapi3.Info{Title: "MyAPI", Version: "0.1"},
+		Servers: openapi3.Servers{{URL: "https://{tenant}.example.com/{version}", Variables: map[string]*openapi3.ServerVariable{
+			"tenant":  {Default: "www"},
+			"version": {Default: "v1"},
+		}}},
+		Paths: openapi3.NewPaths(
+			openapi3.WithPath("/files/{name}.{ext}", &openapi3.PathItem{Get: fileGET}),
+			openapi3.WithPath("/files/latest", &openapi3.PathItem{Get: latestGET}),
+			openapi3.WithPath("/files/report-{id}.pdf", &openapi3.PathItem{Get: reportGET}),
+			openapi3.WithPath("/items/{id:[0-9]+}", &openapi3.PathItem{Get: itemGET}),
+		),
+	}
+	r, err := NewRouter(doc)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		method, uri string
+		operation   *openapi3.Operation
+		params      map[string]string
+		err         error
+	}{
+		{http.MethodGet, "https://acme.example.com/v2/files/archive.tar.gz", fileGET, map[string]string{"tenant": "acme", "version": "v2", "name": "archive.tar", "ext": "gz"}, nil},
+		{http.MethodGet, "https://acme.example.com/v2/files/latest", latestGET, map[string]string{"tenant": "acme", "version": "v2"}, nil},
+		{http.MethodGet, "https://acme.example.com/v2/files/report-42.pdf", reportGET, map[string]string{"tenant": "acme", "version": "v2", "id": "42"}, nil},
+		{http.MethodGet, "https://acme.example.com/v2/files/report", nil, nil, routers.ErrPathNotFound},
+		{http.MethodGet, "https://acme.example.com/v2/items/42", itemGET, map[string]string{"tenant": "acme", "version": "v2", "id": "42"}, nil},
+		{http.MethodGet, "https://acme.example.com/v2/items/abc", nil, nil, routers.ErrPathNotFound},
+		{http.MethodPost, "https://acme.example.com/v2/items/42", nil, nil, routers.ErrMethodNotAllowed},
+		{http.MethodGet, "https://acme.other.com/v2/items/42", nil, nil, routers.ErrPathNotFound},
+		{http.MethodGet, "http://acme.example.com/v2/items/42", nil, nil, routers.ErrPathNotFound},
+	} {
+		t.Run(tc.method+" "+tc.uri, func(t *testing.T) {
+			req, err := http.NewRequest(tc.method, tc.uri, nil)
+			require.NoError(t, err)
+			route, pathParams, err := r.FindRoute(req)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				require.Nil(t, route)
+				return
+			}
+			require.NoError(t, err)
+			require.Same(t, tc.operation, route.Operation)
+			require.Equal(t, tc.params, pathParams)
+		})
+	}
+}
+
+func TestFindRouteAllocations(t *testing.T) {
+	doc := benchmarkDoc(100)
+	r, err := NewRouter(doc)
+	require.NoError(t, err)
+
+	static, err := http.NewRequest(http.MethodGet, "https://example.com/api/v1/resources42/status", nil)
+	require.NoError(t, err)
+	allocs := testing.AllocsPerRun(100, func() {
+		route, _, err := r.FindRoute(static)
+		require.NoError(t, err)
+		require.NotNil(t, route)
+	})
+	// The returned copy of the route and its server variables
+	require.Equal(t, 2.0, allocs)
+
+	// Every call returns its own copy of the route
+	route1, _, err := r.FindRoute(static)
+	require.NoError(t, err)
+	route2, _, err := r.FindRoute(static)
+	require.NoError(t, err)
+	require.NotSame(t, route1, route2)
+	require.Equal(t, route1, route2)
+
+	notFound, err := http.NewRequest(http.MethodGet, "https://example.com/api/v1/nope", nil)
+	require.NoError(t, err)
+	allocs = testing.AllocsPerRun(100, func() {
+		_, _, err := r.FindRoute(notFound)
+		require.ErrorIs(t, err, routers.ErrPathNotFound)
+	})
+	require.Zero(t, allocs)
+}
+
+// benchmarkDoc describes n resources, each with a collection, an item and a status path.
+func benchmarkDoc(n int) *openapi3.T {
+	okResponses := openapi3.NewResponses(openapi3.WithStatus(200, &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("OK")}))
+	paths := openapi3.NewPaths()
+	idParam := &openapi3.ParameterRef{Value: openapi3.NewPathParameter("id").WithSchema(openapi3.NewStringSchema())}
+	for i := range n {
+		resource := fmt.Sprintf("/resources%d", i)
+		paths.Set(resource, &openapi3.PathItem{
+			Get:  &openapi3.Operation{Responses: okResponses},
+			Post: &openapi3.Operation{Responses: okResponses},
+		})
+		paths.Set(resource+"/{id}", &openapi3.PathItem{
+			Parameters: openapi3.Parameters{idParam},
+			Get:        &openapi3.Operation{Responses: okResponses},
+			Put:        &openapi3.Operation{Responses: okResponses},
+			Delete:     &openapi3.Operation{Responses: okResponses},
+		})
+		paths.Set(resource+"/status", &openapi3.PathItem{
+			Get: &openapi3.Operation{Responses: okResponses},
+		})
+	}
+	return &openapi3.T{
+		OpenAPI: "3.0.0",
+		Info:    &openapi3.Info{Title: "MyAPI", Version: "0.1"},
+		Servers: openapi3.Servers{{URL: "https://example.com/api/v1"}},
+		Paths:   paths,
+	}
+}
+
+func BenchmarkFindRoute(b *testing.B) {
+	doc := benchmarkDoc(700) // about 2k operations
+	require.NoError(b, doc.Validate(context.Background()))
+
+	gorillamuxRouter, err := gorillamux.NewRouter(doc)
+	require.NoError(b, err)
+	legacyRouter, err := legacy.NewRouter(doc)
+	require.NoError(b, err)
+	radixRouter, err := NewRouter(doc)
+	require.NoError(b, err)
+
+	for _, router := range []struct {
+		name string
+		r    routers.Router
+	}{
+		{"radix", radixRouter},
+		{"gorillamux", gorillamuxRouter},
+		{"legacy", legacyRouter},
+	} {
+		for _, request := range []struct {
+			name, method, uri string
+		}{
+			{"static", http.MethodGet, "https://example.com/api/v1/resources350/status"},
+			{"param", http.MethodPut, "https://example.com/api/v1/resources699/1234"},
+			{"not_found", http.MethodGet, "https://example.com/api/v1/nope"},
+		} {
+			req, err := http.NewRequest(request.method, request.uri, nil)
+			require.NoError(b, err)
+			b.Run(router.name+"/"+request.name, func(b *testing.B) {
+				b.ReportAllocs()
+				for b.Loop() {
+					_, _, _ = router.r.FindRoute(req)
+				}
+			})
+		}
+	}
+}
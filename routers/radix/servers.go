@@ -0,0 +1,106 @@
+package radix
+
+import (
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/routers/internal/serverurl"
+)
+
+type srv struct {
+	schemes  []string
+	host     template
+	withPort bool
+	base     string
+	server   *openapi3.Server
+	vars     map[string]string
+}
+
+var variableMatcher = regexp.MustCompile(`\{([^{}]+)\}`)
+
+func makeServers(in openapi3.Servers) ([]*srv, error) {
+	servers := make([]*srv, 0, len(in))
+	for _, server := range in {
+		serverURL, vars, err := serverurl.Default(server)
+		if err != nil {
+			return nil, err
+		}
+		svr, err := newSrv(serverURL, server, vars)
+		if err != nil {
+			return nil, err
+		}
+		servers = append(servers, svr)
+	}
+	if len(servers) == 0 {
+		servers = append(servers, &srv{})
+	}
+	return servers, nil
+}
+
+func newSrv(serverURL string, server *openapi3.Server, vars map[string]string) (*srv, error) {
+	if _, err := url.Parse(variableMatcher.ReplaceAllString(serverURL, "x")); err != nil {
+		return nil, err
+	}
+	svr := &srv{server: server, vars: vars}
+
+	path := serverURL
+	if scheme, rest, ok := strings.Cut(serverURL, "://"); ok {
+		svr.schemes = expandVariables(scheme, server)
+		host := rest
+		path = ""
+		if i := strings.IndexByte(rest, '/'); i >= 0 {
+			host, path = rest[:i], rest[i:]
+		}
+		if host != "" {
+			tpl, err := parseTemplate(host, '.')
+			if err != nil {
+				return nil, err
+			}
+			svr.host = tpl
+			svr.withPort = strings.Contains(host, ":")
+		}
+	}
+	svr.base = strings.TrimSuffix(path, "/")
+	return svr, nil
+}
+
+// expandVariables lists the values s can take given the default and enumerated values of its variables.
+func expandVariables(s string, server *openapi3.Server) []string {
+	values := []string{s}
+	for _, submatch := range variableMatcher.FindAllStringSubmatch(s, -1) {
+		v := server.Variables[submatch[1]]
+		if v == nil {
+			continue
+		}
+		options := append([]string{v.Default}, v.Enum...)
+		var expanded []string
+		for _, value := range values {
+			for _, option := range options {
+				expanded = append(expanded, strings.ReplaceAll(value, submatch[0], option))
+			}
+		}
+		values = expanded
+	}
+	slices.Sort(values)
+	return slices.Compact(values)
+}
+
+// match checks the scheme and host of the request, appending host variables to st.values.
+func (svr *srv) match(st *state) bool {
+	if svr.schemes != nil && !slices.Contains(svr.schemes, st.scheme) {
+		return false
+	}
+	if svr.host == nil {
+		return true
+	}
+	host := st.host
+	if !svr.withPort {
+		if i := strings.LastIndexByte(host, ':'); i >= 0 && !strings.Contains(host[i:], "]") {
+			host = host[:i]
+		}
+	}
+	return svr.host.match(host, st)
+}
@@ -0,0 +1,109 @@
+package radix
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// template is a sequence of literals and variables such as "{name}.{ext}".
+// A variable matches at least one character, none of which is sep,
+// or the values accepted by its regular expression (e.g. "{id:[0-9]+}").
+type template []part
+
+type part struct {
+	literal string
+	isVar   bool
+	name    string
+	pattern string
+	re      *regexp.Regexp
+	sep     byte
+}
+
+func parseTemplate(s string, sep byte) (template, error) {
+	var tpl template
+	for s != "" {
+		open := strings.IndexByte(s, '{')
+		if open < 0 {
+			tpl = append(tpl, part{literal: s})
+			break
+		}
+		if open > 0 {
+			tpl = append(tpl, part{literal: s[:open]})
+		}
+		end := strings.IndexByte(s[open:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("unbalanced braces in %q", s)
+		}
+		name, pattern, _ := strings.Cut(s[open+1:open+end], ":")
+		if name == "" {
+			return nil, fmt.Errorf("missing variable name in %q", s)
+		}
+		p := part{isVar: true, name: name, pattern: pattern, sep: sep}
+		if pattern != "" && pattern != ".*" {
+			re, err := regexp.Compile("^(?:" + pattern + ")$")
+			if err != nil {
+				return nil, err
+			}
+			p.re = re
+		}
+		tpl = append(tpl, p)
+		s = s[open+end+1:]
+	}
+	return tpl, nil
+}
+
+func (tpl template) names() []string {
+	var names []string
+	for _, p := range tpl {
+		if p.isVar {
+			names = append(names, p.name)
+		}
+	}
+	return names
+}
+
+func (tpl template) literalLen() int {
+	n := 0
+	for _, p := range tpl {
+		n += len(p.literal)
+	}
+	return n
+}
+
+// match appends the values of the variables of tpl to st.values if s matches tpl.
+// Variables are greedy: "{name}.{ext}" matches "a.b.c" with name "a.b" and ext "c".
+func (tpl template) match(s string, st *state) bool {
+	if len(tpl) == 0 {
+		return s == ""
+	}
+	p := tpl[0]
+	if !p.isVar {
+		return strings.HasPrefix(s, p.literal) && tpl[1:].match(s[len(p.literal):], st)
+	}
+
+	end := len(s)
+	if p.sep != 0 {
+		if i := strings.IndexByte(s, p.sep); i >= 0 {
+			end = i
+		}
+	}
+	mark := len(st.values)
+	for ; end > 0; end-- {
+		if !tpl.matchValue(0, s[:end]) {
+			continue
+		}
+		st.values = append(st.values, s[:end])
+		if tpl[1:].match(s[end:], st) {
+			return true
+		}
+		st.values = st.values[:mark]
+	}
+	return false
+}
+
+// matchValue checks the value of the i-th part against its regular expression, if any.
+func (tpl template) matchValue(i int, value string) bool {
+	re := tpl[i].re
+	return re == nil || re.MatchString(value)
+}
@@ -0,0 +1,133 @@
+package routers
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// ResolveServer sets the ServerURL, ServerVariables and RelativePath of route
+// given the values vars of the variables of route.Server matched by req.
+// Variables absent from vars take the value matching the request's scheme, if any, or their default value.
+// Values are not checked against the values enumerated by their variables (see ValidateServerVariables).
+//
+// Router implementations call it once route.Server is known.
+func (route *Route) ResolveServer(req *http.Request, vars map[string]string) {
+	path := req.URL.EscapedPath()
+	server := route.Server
+	if server == nil {
+		route.ServerURL, route.ServerVariables, route.RelativePath = "", nil, path
+		return
+	}
+
+	scheme, _, hasScheme := strings.Cut(server.URL, "://")
+	var schemeVars map[string]string
+	if hasScheme && strings.Contains(scheme, "{") {
+		schemeVars = matchScheme(scheme, server, requestScheme(req))
+	}
+
+	values := make(map[string]string, len(server.Variables))
+	serverURL := server.URL
+	for name, variable := range server.Variables {
+		value, ok := vars[name]
+		if !ok {
+			if value, ok = schemeVars[name]; !ok {
+				value = variable.Default
+			}
+		}
+		values[name] = value
+		serverURL = strings.ReplaceAll(serverURL, "{"+name+"}", value)
+	}
+
+	base := serverURL
+	if _, rest, ok := strings.Cut(serverURL, "://"); ok {
+		base = ""
+		if i := strings.IndexByte(rest, '/'); i >= 0 {
+			base = rest[i:]
+		}
+	}
+	route.ServerURL = serverURL
+	route.ServerVariables = values
+	route.RelativePath = strings.TrimPrefix(path, strings.TrimSuffix(base, "/"))
+}
+
+// ValidateServerVariables fails if a value of route.ServerVariables is not one of the values
+// enumerated by its variable.
+//
+// Routers match requests whatever the values of server variables: call it on a found route
+// to reject the values a server does not enumerate.
+func (route *Route) ValidateServerVariables() error {
+	if route.Server == nil {
+		return nil
+	}
+	names := make([]string, 0, len(route.ServerVariables))
+	for name := range route.ServerVariables {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		variable := route.Server.Variables[name]
+		if variable == nil || len(variable.Enum) == 0 {
+			continue
+		}
+		if value := route.ServerVariables[name]; !slices.Contains(variable.Enum, value) {
+			return fmt.Errorf("value %q of server variable %q is not one of the allowed values %v", value, name, variable.Enum)
+		}
+	}
+	return nil
+}
+
+func requestScheme(req *http.Request) string {
+	if scheme := req.URL.Scheme; scheme != "" {
+		return scheme
+	}
+	if req.TLS == nil {
+		return "http"
+	}
+	return "https"
+}
+
+// matchScheme finds the values of the variables of a scheme template such as "{proto}s"
+// among their default and enumerated values.
+func matchScheme(scheme string, server *openapi3.Server, actual string) map[string]string {
+	var names []string
+	for rest := scheme; ; {
+		i := strings.IndexByte(rest, '{')
+		if i < 0 {
+			break
+		}
+		j := strings.IndexByte(rest[i:], '}')
+		if j < 0 {
+			break
+		}
+		names = append(names, rest[i+1:i+j])
+		rest = rest[i+j+1:]
+	}
+
+	values := make(map[string]string, len(names))
+	var try func(i int, partial string) bool
+	try = func(i int, partial string) bool {
+		if i == len(names) {
+			return partial == actual
+		}
+		variable := server.Variables[names[i]]
+		if variable == nil {
+			return false
+		}
+		for _, value := range append([]string{variable.Default}, variable.Enum...) {
+			values[names[i]] = value
+			if try(i+1, strings.ReplaceAll(partial, "{"+names[i]+"}", value)) {
+				return true
+			}
+		}
+		delete(values, names[i])
+		return false
+	}
+	if !try(0, scheme) {
+		return nil
+	}
+	return values
+}
@@ -0,0 +1,125 @@
+package routers_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/getkin/kin-openapi/routers/legacy"
+	"github.com/getkin/kin-openapi/routers/radix"
+	"github.com/getkin/kin-openapi/routers/stdmux"
+)
+
+func TestRouteResolvesServer(t *testing.T) {
+	spec := []byte(`
+openapi: 3.0.0
+info:
+  title: Example
+  version: '1.0'
+servers:
+- url: '{scheme}://{tenant}.api.example.com/{version}'
+  variables:
+    scheme:
+      default: https
+      enum: [https, http]
+    tenant:
+      default: www
+    version:
+      default: v1
+      enum: [v1, v2]
+paths:
+  /users/{id}:
+    get:
+      parameters:
+      - name: id
+        in: path
+        required: true
+        schema: {type: string}
+      responses:
+        '200':
+          description: OK
+  /status:
+    get:
+      responses:
+        '200':
+          description: OK
+`)
+
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData(spec)
+	require.NoError(t, err)
+	err = doc.Validate(loader.Context)
+	require.NoError(t, err)
+
+	for name, newRouter := range map[string]func(*openapi3.T) (routers.Router, error){
+		"gorillamux": gorillamux.NewRouter,
+		"legacy":     func(doc *openapi3.T) (routers.Router, error) { return legacy.NewRouter(doc) },
+		"stdmux":     stdmux.NewRouter,
+		"radix":      radix.NewRouter,
+	} {
+		t.Run(name, func(t *testing.T) {
+			router, err := newRouter(doc)
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodGet, "http://acme.api.example.com/v2/users/42", nil)
+			require.NoError(t, err)
+			route, pathParams, err := router.FindRoute(req)
+			require.NoError(t, err)
+			require.Equal(t, "/users/{id}", route.Path)
+			require.Equal(t, "http://acme.api.example.com/v2", route.ServerURL)
+			require.Equal(t, map[string]string{"scheme": "http", "tenant": "acme", "version": "v2"}, route.ServerVariables)
+			require.Equal(t, "/users/42", route.RelativePath)
+			require.Equal(t, "42", pathParams["id"])
+
+			req, err = http.NewRequest(http.MethodGet, "https://www.api.example.com/v1/status", nil)
+			require.NoError(t, err)
+			route, _, err = router.FindRoute(req)
+			require.NoError(t, err)
+			require.Equal(t, "https://www.api.example.com/v1", route.ServerURL)
+			require.Equal(t, map[string]string{"scheme": "https", "tenant": "www", "version": "v1"}, route.ServerVariables)
+			require.Equal(t, "/status", route.RelativePath)
+
+			require.NoError(t, route.ValidateServerVariables())
+
+			// v3 is not one of the values enumerated for version: the route matches but does not validate
+			req, err = http.NewRequest(http.MethodGet, "https://acme.api.example.com/v3/status", nil)
+			require.NoError(t, err)
+			route, _, err = router.FindRoute(req)
+			require.NoError(t, err)
+			require.Equal(t, "v3", route.ServerVariables["version"])
+			require.EqualError(t, route.ValidateServerVariables(), `value "v3" of server variable "version" is not one of the allowed values [v1 v2]`)
+		})
+	}
+}
+
+func TestRouteWithoutServers(t *testing.T) {
+	helloGET := &openapi3.Operation{Responses: openapi3.NewResponses(openapi3.WithStatus(200, &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("OK")}))}
+	doc := &openapi3.T{
+		OpenAPI: "3.0.0",
+		Info:    &openapi3.Info{Title: "MyAPI", Version: "0.1"},
+		Paths:   openapi3.NewPaths(openapi3.WithPath("/hello", &openapi3.PathItem{Get: helloGET})),
+	}
+
+	for name, newRouter := range map[string]func(*openapi3.T) (routers.Router, error){
+		"gorillamux": gorillamux.NewRouter,
+		"legacy":     func(doc *openapi3.T) (routers.Router, error) { return legacy.NewRouter(doc) },
+		"stdmux":     stdmux.NewRouter,
+		"radix":      radix.NewRouter,
+	} {
+		t.Run(name, func(t *testing.T) {
+			router, err := newRouter(doc)
+			require.NoError(t, err)
+			req, err := http.NewRequest(http.MethodGet, "/hello", nil)
+			require.NoError(t, err)
+			route, _, err := router.FindRoute(req)
+			require.NoError(t, err)
+			require.Empty(t, route.ServerURL)
+			require.Nil(t, route.ServerVariables)
+			require.Equal(t, "/hello", route.RelativePath)
+		})
+	}
+}
@@ -0,0 +1,462 @@
+// Package stdmux implements a router on top of net/http.ServeMux patterns.
+//
+// It mirrors the gorilla/mux router without depending on it:
+// * it provides somewhat granular errors: "path not found", "method not allowed".
+// * it handles matching routes with extensions (e.g. /books/{id}.json)
+// * it handles a trailing catch-all path pattern (e.g. /params/{x}/{y}/{z:.*})
+//
+// Paths compile into patterns such as "GET example.com/books/{p0}" (see net/http.ServeMux):
+// wildcards are renamed so that any parameter name is accepted and
+// segments mixing literals and variables are checked once the pattern matched.
+// http.ServeMux refuses to register patterns that overlap without one being
+// more specific than the other, in which case NewRouter returns an error.
+package stdmux
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/internal/serverurl"
+)
+
+var _ routers.Router = &Router{}
+
+// Router helps link http.Request.s and an OpenAPIv3 spec
+type Router struct {
+	mux *http.ServeMux
+}
+
+// routeGroup is the http.Handler registered for a single http.ServeMux pattern.
+// Its candidates are tried in matching order.
+type routeGroup struct {
+	candidates []*candidate
+	methods    []string
+}
+
+type candidate struct {
+	route     *routers.Route
+	schemes   *regexp.Regexp
+	host      *hostMatcher
+	wildcards []wildcard
+	vars      map[string]string
+}
+
+type hostMatcher struct {
+	re       *regexp.Regexp
+	names    []string
+	withPort bool
+}
+
+// wildcard maps an http.ServeMux wildcard back to the variables of the segment it matches.
+type wildcard struct {
+	key   string
+	index int
+	rest  bool
+	re    *regexp.Regexp
+	names []string
+}
+
+type matchKey struct{}
+
+type match struct {
+	route            *routers.Route
+	vars             map[string]string
+	methodNotAllowed bool
+}
+
+var templateMatcher = regexp.MustCompile(`\{([^{}]+)\}`)
+
+var singleVariableMatcher = regexp.MustCompile(`^\{([^{}]+)\}$`)
+
+// NewRouter creates a router on top of an http.ServeMux.
+// Assumes spec is .Validate()d
+// Note that a variable for the port number MUST have a default value and only this value will match as the port (see issue #367).
+func NewRouter(doc *openapi3.T) (routers.Router, error) {
+	docServers, err := makeServers(doc.Servers)
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []*routeGroup
+	patterns := make(map[string]*routeGroup)
+	var keys []string
+	for _, path := range doc.Paths.InMatchingOrder() {
+		pathItem := doc.Paths.Value(path)
+		servers := docServers
+		if len(pathItem.Servers) > 0 {
+			if servers, err = makeServers(pathItem.Servers); err != nil {
+				return nil, err
+			}
+		}
+
+		methods := make([]string, 0, len(pathItem.Operations()))
+		for method := range pathItem.Operations() {
+			methods = append(methods, method)
+		}
+		slices.Sort(methods)
+
+		for _, s := range servers {
+			pattern, wildcards, err := compilePath(s.base + path)
+			if err != nil {
+				return nil, err
+			}
+			key := s.muxHost + pattern
+			group := patterns[key]
+			if group == nil {
+				group = &routeGroup{}
+				patterns[key] = group
+				groups = append(groups, group)
+				keys = append(keys, key)
+			}
+			for _, method := range methods {
+				if !slices.Contains(group.methods, method) {
+					group.methods = append(group.methods, method)
+				}
+			}
+			group.candidates = append(group.candidates, &candidate{
+				route: &routers.Route{
+					Spec:      doc,
+					Server:    s.server,
+					Path:      path,
+					PathItem:  pathItem,
+					Method:    "",
+					Operation: nil,
+				},
+				schemes:   s.schemes,
+				host:      s.host,
+				wildcards: wildcards,
+				vars:      s.vars,
+			})
+		}
+	}
+
+	r := &Router{mux: http.NewServeMux()}
+	for i, group := range groups {
+		for _, method := range group.methods {
+			if err := r.handle(method+" "+keys[i], group); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return r, nil
+}
+
+// handle registers a pattern, turning http.ServeMux's panics on conflicting patterns into errors.
+func (r *Router) handle(pattern string, group *routeGroup) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = fmt.Errorf("cannot route %q: %v", pattern, v)
+		}
+	}()
+	r.mux.Handle(pattern, group)
+	return nil
+}
+
+// FindRoute extracts the route and parameters of an http.Request
+func (r *Router) FindRoute(req *http.Request) (*routers.Route, map[string]string, error) {
+	m := &match{}
+	w := &statusWriter{}
+	r.mux.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), matchKey{}, m)))
+	switch {
+	case m.route != nil:
+		return m.route, m.vars, nil
+	case m.methodNotAllowed, w.status == http.StatusMethodNotAllowed:
+		return nil, nil, routers.ErrMethodNotAllowed
+	default:
+		return nil, nil, routers.ErrPathNotFound
+	}
+}
+
+// ServeHTTP records the first candidate matching the request.
+// The http.ResponseWriter is never written to.
+func (group *routeGroup) ServeHTTP(_ http.ResponseWriter, req *http.Request) {
+	m, ok := req.Context().Value(matchKey{}).(*match)
+	if !ok {
+		return
+	}
+	for _, c := range group.candidates {
+		vars, ok := c.match(req)
+		if !ok {
+			continue
+		}
+		operation := c.route.PathItem.GetOperation(req.Method)
+		if operation == nil {
+			m.methodNotAllowed = true
+			return
+		}
+		route := *c.route
+		route.ResolveServer(req, vars)
+		route.Method = req.Method
+		route.Operation = operation
+		m.route = &route
+		m.vars = vars
+		return
+	}
+}
+
+func (c *candidate) match(req *http.Request) (map[string]string, bool) {
+	vars := make(map[string]string, len(c.vars)+len(c.wildcards))
+	maps.Copy(vars, c.vars)
+
+	if c.schemes != nil {
+		scheme := req.URL.Scheme
+		if scheme == "" {
+			if req.TLS == nil {
+				scheme = "http"
+			} else {
+				scheme = "https"
+			}
+		}
+		if !c.schemes.MatchString(scheme) {
+			return nil, false
+		}
+	}
+
+	if c.host != nil {
+		host := req.Host
+		if req.URL.IsAbs() {
+			host = req.URL.Host
+		}
+		if !c.host.withPort {
+			if h, _, err := net.SplitHostPort(host); err == nil {
+				host = h
+			}
+		}
+		submatch := c.host.re.FindStringSubmatch(host)
+		if submatch == nil {
+			return nil, false
+		}
+		for i, name := range c.host.names {
+			vars[name] = submatch[i+1]
+		}
+	}
+
+	var segments []string
+	for _, w := range c.wildcards {
+		value := req.PathValue(w.key)
+		if req.URL.RawPath != "" {
+			// Keep the value escaped exactly as it was sent, e.g. with %2F
+			if segments == nil {
+				segments = strings.Split(req.URL.EscapedPath(), "/")
+			}
+			if w.rest {
+				value = strings.Join(segments[w.index:], "/")
+			} else {
+				value = segments[w.index]
+			}
+		} else {
+			value = (&url.URL{Path: value}).EscapedPath()
+		}
+
+		if w.re == nil {
+			vars[w.names[0]] = value
+			continue
+		}
+		submatch := w.re.FindStringSubmatch(value)
+		if submatch == nil {
+			return nil, false
+		}
+		for i, name := range w.names {
+			vars[name] = submatch[i+1]
+		}
+	}
+	return vars, true
+}
+
+// compilePath turns an OpenAPI path template into an http.ServeMux path pattern
+func compilePath(path string) (string, []wildcard, error) {
+	segments := strings.Split(path, "/")
+	if segments[0] != "" {
+		return "", nil, fmt.Errorf("path %q does not start with a slash", path)
+	}
+
+	var wildcards []wildcard
+	for i, segment := range segments {
+		if !strings.Contains(segment, "{") {
+			continue
+		}
+		key := "p" + strconv.Itoa(len(wildcards))
+		w := wildcard{key: key, index: i}
+
+		if submatch := singleVariableMatcher.FindStringSubmatch(segment); submatch != nil {
+			name, re, hasRe := strings.Cut(submatch[1], ":")
+			switch {
+			case !hasRe:
+				w.names = []string{name}
+				segments[i] = "{" + key + "}"
+				wildcards = append(wildcards, w)
+				continue
+			case re == ".*" && i == len(segments)-1:
+				w.names = []string{name}
+				w.rest = true
+				segments[i] = "{" + key + "...}"
+				wildcards = append(wildcards, w)
+				continue
+			}
+		}
+
+		var err error
+		if w.re, w.names, err = compileTemplate(segment, "[^/]+"); err != nil {
+			return "", nil, fmt.Errorf("path %q: %w", path, err)
+		}
+		segments[i] = "{" + key + "}"
+		wildcards = append(wildcards, w)
+	}
+
+	if last := len(segments) - 1; segments[last] == "" {
+		segments[last] = "{$}"
+	}
+	return strings.Join(segments, "/"), wildcards, nil
+}
+
+// compileTemplate builds a regexp matching a template such as "{name}.{ext}",
+// capturing its variables in order.
+func compileTemplate(template, defaultPattern string) (*regexp.Regexp, []string, error) {
+	var names []string
+	var sb strings.Builder
+	sb.WriteByte('^')
+	last := 0
+	for _, loc := range templateMatcher.FindAllStringSubmatchIndex(template, -1) {
+		sb.WriteString(regexp.QuoteMeta(template[last:loc[0]]))
+		name, pattern, hasPattern := strings.Cut(template[loc[2]:loc[3]], ":")
+		if !hasPattern {
+			pattern = defaultPattern
+		}
+		names = append(names, name)
+		sb.WriteString("((?:" + pattern + "))")
+		last = loc[1]
+	}
+	sb.WriteString(regexp.QuoteMeta(template[last:]))
+	sb.WriteByte('$')
+
+	re, err := regexp.Compile(sb.String())
+	if err != nil {
+		return nil, nil, err
+	}
+	if re.NumSubexp() != len(names) {
+		return nil, nil, fmt.Errorf("template %q must not contain capturing groups", template)
+	}
+	return re, names, nil
+}
+
+type srv struct {
+	schemes *regexp.Regexp
+	host    *hostMatcher
+	muxHost string
+	base    string
+	server  *openapi3.Server
+	vars    map[string]string
+}
+
+func makeServers(in openapi3.Servers) ([]srv, error) {
+	servers := make([]srv, 0, len(in))
+	for _, server := range in {
+		serverURL, vars, err := serverurl.Default(server)
+		if err != nil {
+			return nil, err
+		}
+		svr, err := newSrv(serverURL, server, vars)
+		if err != nil {
+			return nil, err
+		}
+		servers = append(servers, svr)
+	}
+	if len(servers) == 0 {
+		servers = append(servers, srv{})
+	}
+	return servers, nil
+}
+
+func newSrv(serverURL string, server *openapi3.Server, vars map[string]string) (srv, error) {
+	if _, err := url.Parse(templateMatcher.ReplaceAllString(serverURL, "x")); err != nil {
+		return srv{}, err
+	}
+	svr := srv{server: server, vars: vars}
+
+	scheme, rest, hasScheme := strings.Cut(serverURL, "://")
+	if !hasScheme {
+		rest = serverURL
+	} else {
+		re, err := compileScheme(scheme, server)
+		if err != nil {
+			return srv{}, err
+		}
+		svr.schemes = re
+	}
+
+	path := rest
+	if hasScheme {
+		host := rest
+		if i := strings.IndexByte(rest, '/'); i >= 0 {
+			host, path = rest[:i], rest[i:]
+		} else {
+			path = ""
+		}
+		if host != "" {
+			if !strings.Contains(host, "{") && !strings.Contains(host, ":") {
+				svr.muxHost = host
+			} else {
+				re, names, err := compileTemplate(host, "[^.]+")
+				if err != nil {
+					return srv{}, err
+				}
+				_, _, err = net.SplitHostPort(host)
+				svr.host = &hostMatcher{re: re, names: names, withPort: err == nil}
+			}
+		}
+	}
+
+	svr.base = strings.TrimSuffix(path, "/")
+	return svr, nil
+}
+
+// compileScheme matches the default and enumerated values of the scheme's variables.
+func compileScheme(scheme string, server *openapi3.Server) (*regexp.Regexp, error) {
+	var sb strings.Builder
+	sb.WriteByte('^')
+	last := 0
+	for _, loc := range templateMatcher.FindAllStringSubmatchIndex(scheme, -1) {
+		sb.WriteString(regexp.QuoteMeta(scheme[last:loc[0]]))
+		last = loc[1]
+		v := server.Variables[scheme[loc[2]:loc[3]]]
+		if v == nil {
+			return nil, fmt.Errorf("server %q: missing variable %q", server.URL, scheme[loc[2]:loc[3]])
+		}
+		values := []string{regexp.QuoteMeta(v.Default)}
+		for _, value := range v.Enum {
+			values = append(values, regexp.QuoteMeta(value))
+		}
+		sb.WriteString("(?:" + strings.Join(values, "|") + ")")
+	}
+	sb.WriteString(regexp.QuoteMeta(scheme[last:]))
+	sb.WriteByte('$')
+	return regexp.Compile(sb.String())
+}
+
+// statusWriter discards everything but the status http.ServeMux replies with
+// when no pattern matches.
+type statusWriter struct {
+	header http.Header
+	status int
+}
+
+func (w *statusWriter) Header() http.Header {
+	if w.header == nil {
+		w.header = make(http.Header)
+	}
+	return w.header
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) { return len(b), nil }
+
+func (w *statusWriter) WriteHeader(status int) { w.status = status }
@@ -0,0 +1,437 @@
+package stdmux
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/routers"
+)
+
+func TestRouter(t *testing.T) {
+	helloCONNECT := &openapi3.Operation{Responses: openapi3.NewResponses(openapi3.WithStatus(200, &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("OK")}))}
+	helloDELETE := &openapi3.Operation{Responses: openapi3.NewResponses(openapi3.WithStatus(200, &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("OK")}))}
+	helloGET := &openapi3.Operation{Responses: openapi3.NewResponses(openapi3.WithStatus(200, &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("OK")}))}
+	helloHEAD := &openapi3.Operation{Responses: openapi3.NewResponses(openapi3.WithStatus(200, &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("OK")}))}
+	helloOPTIONS := &openapi3.Operation{Responses: openapi3.NewResponses(openapi3.WithStatus(200, &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("OK")}))}
+	helloPATCH := &openapi3.Operation{Responses: openapi3.NewResponses(openapi3.WithStatus(200, &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("OK")}))}
+	helloPOST := &openapi3.Operation{Responses: openapi3.NewResponses(openapi3.WithStatus(200, &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("OK")}))}
+	helloPUT := &openapi3.Operation{Responses: openapi3.NewResponses(openapi3.WithStatus(200, &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("OK")}))}
+	helloTRACE := &openapi3.Operation{Responses: openapi3.NewResponses(openapi3.WithStatus(200, &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("OK")}))}
+	paramsGET := &openapi3.Operation{Responses: openapi3.NewResponses(openapi3.WithStatus(200, &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("OK")}))}
+	booksPOST := &openapi3.Operation{Responses: openapi3.NewResponses(openapi3.WithStatus(200, &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("OK")}))}
+	partialGET := &openapi3.Operation{Responses: openapi3.NewResponses(openapi3.WithStatus(200, &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("OK")}))}
+	doc := &openapi3.T{
+		OpenAPI: "3.0.0",
+		Info: &openapi3.Info{
+			Title:   "MyAPI",
+			Version: "0.1",
+		},
+		Paths: openapi3.NewPaths(
+			openapi3.WithPath("/hello", &openapi3.PathItem{
+				Connect: helloCONNECT,
+				Delete:  helloDELETE,
+				Get:     helloGET,
+				Head:    helloHEAD,
+				Options: helloOPTIONS,
+				Patch:   helloPATCH,
+				Post:    helloPOST,
+				Put:     helloPUT,
+				Trace:   helloTRACE,
+			}),
+			openapi3.WithPath("/onlyGET", &openapi3.PathItem{
+				Get: helloGET,
+			}),
+			openapi3.WithPath("/params/{x}/{y}/{z:.*}", &openapi3.PathItem{
+				Get: paramsGET,
+				Parameters: openapi3.Parameters{
+					&openapi3.ParameterRef{Value: openapi3.NewPathParameter("x").WithSchema(openapi3.NewStringSchema())},
+					&openapi3.ParameterRef{Value: openapi3.NewPathParameter("y").WithSchema(openapi3.NewFloat64Schema())},
+					&openapi3.ParameterRef{Value: openapi3.NewPathParameter("z").WithSchema(openapi3.NewIntegerSchema())},
+				},
+			}),
+			openapi3.WithPath("/books/{bookid}", &openapi3.PathItem{
+				Get: paramsGET,
+				Parameters: openapi3.Parameters{
+					&openapi3.ParameterRef{Value: openapi3.NewPathParameter("bookid").WithSchema(openapi3.NewStringSchema())},
+				},
+			}),
+			openapi3.WithPath("/books/{bookid}.json", &openapi3.PathItem{
+				Post: booksPOST,
+				Parameters: openapi3.Parameters{
+					&openapi3.ParameterRef{Value: openapi3.NewPathParameter("bookid2").WithSchema(openapi3.NewStringSchema())},
+				},
+			}),
+			openapi3.WithPath("/partial", &openapi3.PathItem{
+				Get: partialGET,
+			}),
+		),
+	}
+
+	expect := func(r routers.Router, method string, uri string, operation *openapi3.Operation, params map[string]string) {
+		t.Helper()
+		req, err := http.NewRequest(method, uri, nil)
+		require.NoError(t, err)
+		route, pathParams, err := r.FindRoute(req)
+		if err != nil {
+			if operation == nil {
+				pathItem := doc.Paths.Value(uri)
+				if pathItem == nil {
+					if err.Error() != routers.ErrPathNotFound.Error() {
+						t.Fatalf("'%s %s': should have returned %q, but it returned an error: %v", method, uri, routers.ErrPathNotFound, err)
+					}
+					return
+				}
+				if pathItem.GetOperation(method) == nil {
+					if err.Error() != routers.ErrMethodNotAllowed.Error() {
+						t.Fatalf("'%s %s': should have returned %q, but it returned an error: %v", method, uri, routers.ErrMethodNotAllowed, err)
+					}
+				}
+			} else {
+				t.Fatalf("'%s %s': should have returned an operation, but it returned an error: %v", method, uri, err)
+			}
+		}
+		if operation == nil && err == nil {
+			t.Fatalf("'%s %s': should have failed, but returned\nroute = %+v\npathParams = %+v", method, uri, route, pathParams)
+		}
+		if route == nil {
+			return
+		}
+		if route.Operation != operation {
+			t.Fatalf("'%s %s': Returned wrong operation (%v)",
+				method, uri, route.Operation)
+		}
+		if len(params) == 0 {
+			if len(pathParams) != 0 {
+				t.Fatalf("'%s %s': should return no path arguments, but found %+v", method, uri, pathParams)
+			}
+		} else {
+			names := make([]string, 0, len(params))
+			for name := range params {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				expected := params[name]
+				actual, exists := pathParams[name]
+				if !exists {
+					t.Fatalf("'%s %s': path parameter %q should be %q, but it's not defined.", method, uri, name, expected)
+				}
+				if actual != expected {
+					t.Fatalf("'%s %s': path parameter %q should be %q, but it's %q", method, uri, name, expected, actual)
+				}
+			}
+		}
+	}
+
+	err := doc.Validate(context.Background())
+	require.NoError(t, err)
+	r, err := NewRouter(doc)
+	require.NoError(t, err)
+
+	expect(r, http.MethodGet, "/not_existing", nil, nil)
+	expect(r, http.MethodDelete, "/hello", helloDELETE, nil)
+	expect(r, http.MethodGet, "/hello", helloGET, nil)
+	expect(r, http.MethodHead, "/hello", helloHEAD, nil)
+	expect(r, http.MethodPatch, "/hello", helloPATCH, nil)
+	expect(r, http.MethodPost, "/hello", helloPOST, nil)
+	expect(r, http.MethodPut, "/hello", helloPUT, nil)
+	expect(r, http.MethodGet, "/params/a/b/", paramsGET, map[string]string{
+		"x": "a",
+		"y": "b",
+		"z": "",
+	})
+	expect(r, http.MethodGet, "/params/a/b/c%2Fd", paramsGET, map[string]string{
+		"x": "a",
+		"y": "b",
+		"z": "c%2Fd",
+	})
+	expect(r, http.MethodGet, "/books/War.and.Peace", paramsGET, map[string]string{
+		"bookid": "War.and.Peace",
+	})
+	expect(r, http.MethodPost, "/books/War.and.Peace.json", booksPOST, map[string]string{
+		"bookid": "War.and.Peace",
+	})
+	expect(r, http.MethodPost, "/partial", nil, nil)
+
+	doc.Servers = []*openapi3.Server{
+		{URL: "https://www.example.com/api/v1"},
+		{URL: "{scheme}://{d0}.{d1}.com/api/v1/", Variables: map[string]*openapi3.ServerVariable{
+			"d0":     {Default: "www"},
+			"d1":     {Default: "example", Enum: []string{"example"}},
+			"scheme": {Default: "https", Enum: []string{"https", "http"}},
+		}},
+		{URL: "http://127.0.0.1:{port}/api/v1", Variables: map[string]*openapi3.ServerVariable{
+			"port": {Default: "8000"},
+		}},
+	}
+	err = doc.Validate(context.Background())
+	require.NoError(t, err)
+	r, err = NewRouter(doc)
+	require.NoError(t, err)
+	expect(r, http.MethodGet, "/hello", nil, nil)
+	expect(r, http.MethodGet, "/api/v1/hello", nil, nil)
+	expect(r, http.MethodGet, "www.example.com/api/v1/hello", nil, nil)
+	expect(r, http.MethodGet, "https:///api/v1/hello", nil, nil)
+	expect(r, http.MethodGet, "https://www.example.com/hello", nil, nil)
+	expect(r, http.MethodGet, "https://www.example.com/api/v1/hello", helloGET, nil)
+	expect(r, http.MethodGet, "https://domain0.domain1.com/api/v1/hello", helloGET, map[string]string{
+		"d0": "domain0",
+		"d1": "domain1",
+		// "scheme": "https", TODO: https://github.com/gorilla/mux/issues/624
+	})
+	expect(r, http.MethodGet, "http://127.0.0.1:8000/api/v1/hello", helloGET, map[string]string{
+		"port": "8000",
+	})
+
+	doc.Servers = []*openapi3.Server{
+		{URL: "{server}", Variables: map[string]*openapi3.ServerVariable{
+			"server": {Default: "/api/v1"},
+		}},
+	}
+	err = doc.Validate(context.Background())
+	require.NoError(t, err)
+	r, err = NewRouter(doc)
+	require.NoError(t, err)
+	expect(r, http.MethodGet, "https://myserver/api/v1/hello", helloGET, nil)
+
+	{
+		uri := "https://www.example.com/api/v1/onlyGET"
+		expect(r, http.MethodGet, uri, helloGET, nil)
+		req, err := http.NewRequest(http.MethodDelete, uri, nil)
+		require.NoError(t, err)
+		require.NotNil(t, req)
+		route, pathParams, err := r.FindRoute(req)
+		require.EqualError(t, err, routers.ErrMethodNotAllowed.Error())
+		require.Nil(t, route)
+		require.Nil(t, pathParams)
+	}
+}
+
+func TestServerPath(t *testing.T) {
+	server := &openapi3.Server{URL: "http://example.com"}
+	err := server.Validate(context.Background())
+	require.NoError(t, err)
+
+	helloGET := &openapi3.Operation{Responses: openapi3.NewResponses(openapi3.WithStatus(200, &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("OK")}))}
+	for _, tc := range []struct {
+		server *openapi3.Server
+		url    string
+	}{
+		{server: server, url: "http://example.com/hello"},
+		{server: &openapi3.Server{URL: "http://example.com/"}, url: "http://example.com/hello"},
+		{server: &openapi3.Server{URL: "http://example.com/path"}, url: "http://example.com/path/hello"},
+		{
+			server: &openapi3.Server{URL: "{scheme}://localhost", Variables: map[string]*openapi3.ServerVariable{"scheme": {Default: "https"}}},
+			url:    "https://localhost/hello",
+		},
+		{
+			server: &openapi3.Server{URL: "{url}", Variables: map[string]*openapi3.ServerVariable{"url": {Default: "http://example.com/path"}}},
+			url:    "http://example.com/path/hello",
+		},
+		{
+			server: &openapi3.Server{URL: "http://example.com:{port}/path", Variables: map[string]*openapi3.ServerVariable{"port": {Default: "8088"}}},
+			url:    "http://example.com:8088/path/hello",
+		},
+		{
+			server: &openapi3.Server{URL: "{server}", Variables: map[string]*openapi3.ServerVariable{"server": {Default: "/"}}},
+			url:    "http://example.com/hello",
+		},
+		{server: &openapi3.Server{URL: "/"}, url: "http://example.com/hello"},
+	} {
+		t.Run(tc.server.URL, func(t *testing.T) {
+			router, err := NewRouter(&openapi3.T{
+				Servers: openapi3.Servers{tc.server},
+				Paths:   openapi3.NewPaths(openapi3.WithPath("/hello", &openapi3.PathItem{Get: helloGET})),
+			})
+			require.NoError(t, err)
+			req, err := http.NewRequest(http.MethodGet, tc.url, nil)
+			require.NoError(t, err)
+			route, _, err := router.FindRoute(req)
+			require.NoError(t, err)
+			require.Equal(t, "/hello", route.Path)
+		})
+	}
+}
+
+func TestInvalidServerURL(t *testing.T) {
+	helloGET := &openapi3.Operation{Responses: openapi3.NewResponses(openapi3.WithStatus(200, &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("OK")}))}
+	for _, tc := range []struct {
+		url string
+		err string
+	}{
+		{url: "{url}", err: `server "{url}": missing variable "url"`},
+		{url: "http://example.com:{port}/path", err: `server "http://example.com:{port}/path": missing variable "port"`},
+		{url: "http://example.com:{port/path", err: `server "http://example.com:{port/path": unclosed '{' at offset 19`},
+		{url: "http://example.com}/path", err: `server "http://example.com}/path": unexpected '}' at offset 18`},
+	} {
+		t.Run(tc.url, func(t *testing.T) {
+			_, err := NewRouter(&openapi3.T{
+				Servers: openapi3.Servers{{URL: tc.url}},
+				Paths:   openapi3.NewPaths(openapi3.WithPath("/hello", &openapi3.PathItem{Get: helloGET})),
+			})
+			require.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestServerOverrideAtPathLevel(t *testing.T) {
+	helloGET := &openapi3.Operation{Responses: openapi3.NewResponses(openapi3.WithStatus(200, &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("OK")}))}
+	doc := &openapi3.T{
+		OpenAPI: "3.0.0",
+		Info: &openapi3.Info{
+			Title:   "rel",
+			Version: "1",
+		},
+		Servers: openapi3.Servers{
+			&openapi3.Server{
+				URL: "https://example.com",
+			},
+		},
+		Paths: openapi3.NewPaths(
+			openapi3.WithPath("/hello", &openapi3.PathItem{
+				Servers: openapi3.Servers{
+					&openapi3.Server{
+						URL: "https://another.com",
+					},
+				},
+				Get: helloGET,
+			}),
+		),
+	}
+	err := doc.Validate(context.Background())
+	require.NoError(t, err)
+	router, err := NewRouter(doc)
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodGet, "https://another.com/hello", nil)
+	require.NoError(t, err)
+	route, _, err := router.FindRoute(req)
+	require.NoError(t, err)
+	require.Equal(t, "/hello", route.Path)
+
+	req, err = http.NewRequest(http.MethodGet, "https://example.com/hello", nil)
+	require.NoError(t, err)
+	route, _, err = router.FindRoute(req)
+	require.Nil(t, route)
+	require.Error(t, err)
+}
+
+func TestRelativeURL(t *testing.T) {
+	helloGET := &openapi3.Operation{Responses: openapi3.NewResponses(openapi3.WithStatus(200, &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("OK")}))}
+	doc := &openapi3.T{
+		OpenAPI: "3.0.0",
+		Info: &openapi3.Info{
+			Title:   "rel",
+			Version: "1",
+		},
+		Servers: openapi3.Servers{
+			&openapi3.Server{
+				URL: "/api/v1",
+			},
+		},
+		Paths: openapi3.NewPaths(
+			openapi3.WithPath("/hello", &openapi3.PathItem{
+				Get: helloGET,
+			}),
+		),
+	}
+	err := doc.Validate(context.Background())
+	require.NoError(t, err)
+	router, err := NewRouter(doc)
+	require.NoError(t, err)
+	req, err := http.NewRequest(http.MethodGet, "https://example.com/api/v1/hello", nil)
+	require.NoError(t, err)
+	route, _, err := router.FindRoute(req)
+	require.NoError(t, err)
+	require.Equal(t, "/hello", route.Path)
+}
+
+func TestServeMuxPatterns(t *testing.T) {
+	okResponses := openapi3.NewResponses(openapi3.WithStatus(200, &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("OK")}))
+	fileGET := &openapi3.Operation{Responses: okResponses}
+	userGET := &openapi3.Operation{Responses: okResponses}
+	dirGET := &openapi3.Operation{Responses: okResponses}
+	doc := &openapi3.T{
+		OpenAPI: "3.0.0",
+		Info:    &openapi3.Info{Title: "MyAPI", Version: "0.1"},
+		Servers: openapi3.Servers{{URL: "https://example.com/{version}", Variables: map[string]*openapi3.ServerVariable{
+			"version": {Default: "v1"},
+		}}},
+		Paths: openapi3.NewPaths(
+			openapi3.WithPath("/files/{name}.{ext}", &openapi3.PathItem{
+				Get: fileGET,
+				Parameters: openapi3.Parameters{
+					&openapi3.ParameterRef{Value: openapi3.NewPathParameter("name").WithSchema(openapi3.NewStringSchema())},
+					&openapi3.ParameterRef{Value: openapi3.NewPathParameter("ext").WithSchema(openapi3.NewStringSchema())},
+				},
+			}),
+			openapi3.WithPath("/users/{user-id}", &openapi3.PathItem{
+				Get: userGET,
+				Parameters: openapi3.Parameters{
+					&openapi3.ParameterRef{Value: openapi3.NewPathParameter("user-id").WithSchema(openapi3.NewStringSchema())},
+				},
+			}),
+			openapi3.WithPath("/dir/", &openapi3.PathItem{
+				Get: dirGET,
+			}),
+		),
+	}
+	err := doc.Validate(context.Background())
+	require.NoError(t, err)
+	r, err := NewRouter(doc)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		method, uri string
+		operation   *openapi3.Operation
+		params      map[string]string
+		err         error
+	}{
+		{http.MethodGet, "https://example.com/v2/files/report.2024.pdf", fileGET, map[string]string{"version": "v2", "name": "report.2024", "ext": "pdf"}, nil},
+		{http.MethodGet, "https://example.com/v1/files/report", nil, nil, routers.ErrPathNotFound},
+		{http.MethodGet, "https://example.com/v1/users/a%20b", userGET, map[string]string{"version": "v1", "user-id": "a%20b"}, nil},
+		{http.MethodHead, "https://example.com/v1/users/me", nil, nil, routers.ErrMethodNotAllowed},
+		{http.MethodGet, "https://example.com/v1/dir/", dirGET, map[string]string{"version": "v1"}, nil},
+		{http.MethodGet, "https://example.com/v1/dir/more", nil, nil, routers.ErrPathNotFound},
+		{http.MethodGet, "https://example.com/v1/dir", nil, nil, routers.ErrPathNotFound},
+		{http.MethodGet, "https://other.com/v1/dir/", nil, nil, routers.ErrPathNotFound},
+	} {
+		t.Run(tc.method+" "+tc.uri, func(t *testing.T) {
+			req, err := http.NewRequest(tc.method, tc.uri, nil)
+			require.NoError(t, err)
+			route, pathParams, err := r.FindRoute(req)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				require.Nil(t, route)
+				return
+			}
+			require.NoError(t, err)
+			require.Same(t, tc.operation, route.Operation)
+			require.Equal(t, tc.method, route.Method)
+			require.Equal(t, tc.params, pathParams)
+		})
+	}
+}
+
+func TestConflictingPatterns(t *testing.T) {
+	okResponses := openapi3.NewResponses(openapi3.WithStatus(200, &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("OK")}))
+	doc := &openapi3.T{
+		OpenAPI: "3.0.0",
+		Info:    &openapi3.Info{Title: "MyAPI", Version: "0.1"},
+		Paths: openapi3.NewPaths(
+			openapi3.WithPath("/a/{x}", &openapi3.PathItem{
+				Get: &openapi3.Operation{Responses: okResponses},
+			}),
+			openapi3.WithPath("/{y}/b", &openapi3.PathItem{
+				Get: &openapi3.Operation{Responses: okResponses},
+			}),
+		),
+	}
+	_, err := NewRouter(doc)
+	require.ErrorContains(t, err, `cannot route "GET /`)
+}
@@ -26,6 +26,15 @@ type Route struct {
 	PathItem  *openapi3.PathItem
 	Method    string
 	Operation *openapi3.Operation
+
+	// ServerURL is the URL of Server with its variables replaced by their values.
+	// It is empty when the document declares no servers.
+	ServerURL string
+	// ServerVariables holds the value of each variable of Server,
+	// either matched from the request or its default value.
+	ServerVariables map[string]string
+	// RelativePath is the (escaped) path of the request relative to ServerURL.
+	RelativePath string
 }
 
 // ErrPathNotFound is returned when no route match is found